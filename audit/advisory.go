@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Advisory is a vulnerability record in OSV format, only fields used by audit are decoded,
+// see https://ossf.github.io/osv-schema.
+type Advisory struct {
+	ID               string           `json:"id"`
+	Aliases          []string         `json:"aliases,omitempty"`
+	Summary          string           `json:"summary,omitempty"`
+	Withdrawn        string           `json:"withdrawn,omitempty"`
+	Severity         []Severity       `json:"severity,omitempty"`
+	Affected         []Affected       `json:"affected"`
+	References       []Reference      `json:"references,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package          Package          `json:"package"`
+	Ranges           []Range          `json:"ranges,omitempty"`
+	Versions         []string         `json:"versions,omitempty"`
+	Severity         []Severity       `json:"severity,omitempty"`
+	DatabaseSpecific DatabaseSpecific `json:"database_specific"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Purl      string `json:"purl,omitempty"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type Reference struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+// DatabaseSpecific holds fields that are not in OSV schema but widely used by feeds,
+// such as the severity of GitHub advisories and CPEs of NVD.
+type DatabaseSpecific struct {
+	Severity string   `json:"severity,omitempty"`
+	CPEs     []string `json:"cpes,omitempty"`
+}
+
+// Load reads advisories from path, it's either a json file or a directory that
+// contains json files. Every file holds an advisory or an array of advisories.
+func Load(path string) ([]Advisory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("advisory database %s is not found", path)
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadFile(path)
+	}
+
+	var advisories []Advisory
+	if err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(filePath), ".json") {
+			return nil
+		}
+
+		loaded, err := loadFile(filePath)
+		if err != nil {
+			return err
+		}
+		advisories = append(advisories, loaded...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return advisories, nil
+}
+
+func loadFile(filePath string) ([]Advisory, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var advisories []Advisory
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &advisories)
+	} else {
+		var advisory Advisory
+		err = json.Unmarshal(data, &advisory)
+		advisories = append(advisories, advisory)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode advisory %s -> %w", filePath, err)
+	}
+	return advisories, nil
+}
@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/version"
+)
+
+// Finding is an advisory that affects a port.
+type Finding struct {
+	Port     string   `json:"port"`
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Severity string   `json:"severity"`
+	Score    float64  `json:"score,omitempty"`
+	MatchBy  string   `json:"match_by"` // name, purl or cpe.
+	Fixed    []string `json:"fixed,omitempty"`
+	Url      string   `json:"url,omitempty"`
+}
+
+// Check matches ports against advisories, findings are sorted by severity from
+// high to low, and then by port and advisory id.
+func Check(ports []configs.Port, advisories []Advisory) []Finding {
+	var findings []Finding
+	for _, port := range ports {
+		for _, advisory := range advisories {
+			if advisory.Withdrawn != "" {
+				continue
+			}
+
+			for _, affected := range advisory.Affected {
+				matchBy := matchPackage(port, affected)
+				if matchBy == "" || !affectsVersion(affected, port.Version) {
+					continue
+				}
+
+				level, score := severityOf(advisory, affected)
+				findings = append(findings, Finding{
+					Port:     port.NameVersion(),
+					ID:       advisory.ID,
+					Aliases:  advisory.Aliases,
+					Summary:  advisory.Summary,
+					Severity: level,
+					Score:    score,
+					MatchBy:  matchBy,
+					Fixed:    fixedVersions(affected),
+					Url:      referenceUrl(advisory),
+				})
+				break
+			}
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(SeverityRank(b.Severity), SeverityRank(a.Severity)),
+			cmp.Compare(b.Score, a.Score),
+			strings.Compare(a.Port, b.Port),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+	return findings
+}
+
+// matchPackage tells how the affected package matches port, it's empty when not matched.
+// purl and cpe declared in port.toml identify the port more precisely than its name,
+// so name is not compared when they're provided by both sides but don't match.
+func matchPackage(port configs.Port, affected Affected) string {
+	if port.Package.Purl != "" && affected.Package.Purl != "" {
+		if trimPurl(port.Package.Purl) == trimPurl(affected.Package.Purl) {
+			return "purl"
+		}
+		return ""
+	}
+
+	if port.Package.CPE != "" && len(affected.DatabaseSpecific.CPEs) > 0 {
+		product := cpeProduct(port.Package.CPE)
+		for _, cpe := range affected.DatabaseSpecific.CPEs {
+			if product != "" && cpeProduct(cpe) == product {
+				return "cpe"
+			}
+		}
+		return ""
+	}
+
+	if strings.EqualFold(affected.Package.Name, port.Name) {
+		return "name"
+	}
+	return ""
+}
+
+// trimPurl removes version, qualifiers and subpath of purl.
+func trimPurl(purl string) string {
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, _, _ = strings.Cut(purl, "?")
+	if index := strings.LastIndex(purl, "@"); index > strings.LastIndex(purl, "/") {
+		purl = purl[:index]
+	}
+	return strings.ToLower(purl)
+}
+
+// cpeProduct returns "vendor:product" of CPE 2.3 or 2.2 uri.
+func cpeProduct(cpe string) string {
+	var parts []string
+	switch {
+	case strings.HasPrefix(cpe, "cpe:2.3:"):
+		parts = strings.Split(strings.TrimPrefix(cpe, "cpe:2.3:"), ":")
+	case strings.HasPrefix(cpe, "cpe:/"):
+		parts = strings.Split(strings.TrimPrefix(cpe, "cpe:/"), ":")
+	default:
+		return ""
+	}
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.ToLower(parts[1] + ":" + parts[2])
+}
+
+// affectsVersion evaluates version against enumerated versions and ranges of affected
+// package. GIT ranges need commit graph which is unavailable offline, so they're
+// only taken into account through enumerated versions.
+func affectsVersion(affected Affected, portVersion string) bool {
+	for _, affectedVersion := range affected.Versions {
+		if version.Compare(affectedVersion, portVersion) == 0 {
+			return true
+		}
+	}
+
+	for _, affectedRange := range affected.Ranges {
+		if affectedRange.Type == "GIT" {
+			continue
+		}
+		if inRange(affectedRange.Events, portVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange follows the evaluation of OSV: events are sorted by version, and
+// the last event that is not greater than the version decides.
+func inRange(events []Event, portVersion string) bool {
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return compareEventVersion(eventVersion(a), eventVersion(b))
+	})
+
+	var affected bool
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if compareEventVersion(event.Introduced, portVersion) <= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if version.Compare(event.Fixed, portVersion) <= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if version.Compare(event.LastAffected, portVersion) < 0 {
+				affected = false
+			}
+		case event.Limit != "":
+			if event.Limit != "*" && version.Compare(event.Limit, portVersion) <= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func eventVersion(event Event) string {
+	return cmp.Or(event.Introduced, event.Fixed, event.LastAffected, event.Limit)
+}
+
+// compareEventVersion compares versions in events, "0" of introduced means
+// the very beginning.
+func compareEventVersion(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "0":
+		return -1
+	case b == "0":
+		return 1
+	default:
+		return version.Compare(a, b)
+	}
+}
+
+func fixedVersions(affected Affected) []string {
+	var fixed []string
+	for _, affectedRange := range affected.Ranges {
+		if affectedRange.Type == "GIT" {
+			continue
+		}
+		for _, event := range affectedRange.Events {
+			if event.Fixed != "" && !slices.Contains(fixed, event.Fixed) {
+				fixed = append(fixed, event.Fixed)
+			}
+		}
+	}
+	return fixed
+}
+
+// referenceUrl prefers the advisory page of references.
+func referenceUrl(advisory Advisory) string {
+	for _, reference := range advisory.References {
+		if reference.Type == "ADVISORY" {
+			return reference.Url
+		}
+	}
+	if len(advisory.References) > 0 {
+		return advisory.References[0].Url
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celer-pkg/celer/configs"
+)
+
+const testFeed = `[
+  {
+    "id": "OSV-2022-37434",
+    "aliases": ["CVE-2022-37434"],
+    "summary": "heap-based buffer over-read in inflate",
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+    "affected": [{
+      "package": {"ecosystem": "GIT", "name": "zlib"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.2.13"}]}]
+    }],
+    "references": [{"type": "WEB", "url": "https://example.com/web"}, {"type": "ADVISORY", "url": "https://example.com/advisory"}]
+  },
+  {
+    "id": "GHSA-0000-0000-0000",
+    "summary": "matched by cpe only",
+    "database_specific": {"severity": "MODERATE"},
+    "affected": [{
+      "package": {"name": "libjpeg"},
+      "database_specific": {"cpes": ["cpe:2.3:a:libjpeg-turbo:libjpeg-turbo:*:*:*:*:*:*:*:*"]},
+      "versions": ["3.0.1"]
+    }]
+  },
+  {
+    "id": "OSV-WITHDRAWN",
+    "withdrawn": "2024-01-01T00:00:00Z",
+    "affected": [{"package": {"name": "zlib"}, "versions": ["1.2.12"]}]
+  }
+]`
+
+func newTestPort(name, version string) configs.Port {
+	return configs.Port{Name: name, Version: version}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "feed.json"), []byte(testFeed), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	single := `{"id": "OSV-SINGLE", "affected": [{"package": {"name": "nasm"}, "versions": ["2.16.03"]}]}`
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nested", "single.json"), []byte(single), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	advisories, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(advisories) != 4 {
+		t.Fatalf("Load() = %d advisories, want 4", len(advisories))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	feedPath := filepath.Join(dir, "feed.json")
+	if err := os.WriteFile(feedPath, []byte(testFeed), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	advisories, err := Load(feedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jpeg := newTestPort("libjpeg-turbo", "3.0.1")
+	jpeg.Package.CPE = "cpe:2.3:a:libjpeg-turbo:libjpeg-turbo:3.0.1:*:*:*:*:*:*:*"
+	ports := []configs.Port{
+		newTestPort("zlib", "1.2.12"),
+		newTestPort("zlib", "1.3.1"),
+		jpeg,
+	}
+
+	findings := Check(ports, advisories)
+	if len(findings) != 2 {
+		t.Fatalf("Check() = %+v, want 2 findings", findings)
+	}
+
+	zlib := findings[0]
+	if zlib.Port != "zlib@1.2.12" || zlib.ID != "OSV-2022-37434" {
+		t.Errorf("findings[0] = %+v", zlib)
+	}
+	if zlib.Severity != SeverityCritical || zlib.Score != 9.8 {
+		t.Errorf("zlib severity = %s %.1f, want critical 9.8", zlib.Severity, zlib.Score)
+	}
+	if zlib.Url != "https://example.com/advisory" || len(zlib.Fixed) != 1 || zlib.Fixed[0] != "1.2.13" {
+		t.Errorf("zlib finding = %+v", zlib)
+	}
+
+	if jpeg := findings[1]; jpeg.MatchBy != "cpe" || jpeg.Severity != SeverityMedium {
+		t.Errorf("findings[1] = %+v", jpeg)
+	}
+}
+
+func TestInRange(t *testing.T) {
+	events := []Event{{Introduced: "1.0"}, {Fixed: "1.2"}, {Introduced: "2.0"}, {LastAffected: "2.1"}}
+	tests := map[string]bool{
+		"0.9":   false,
+		"1.0":   true,
+		"1.1.5": true,
+		"1.2":   false,
+		"1.9":   false,
+		"2.1":   true,
+		"2.1.1": false,
+	}
+	for portVersion, want := range tests {
+		if got := inRange(events, portVersion); got != want {
+			t.Errorf("inRange(%s) = %t, want %t", portVersion, got, want)
+		}
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.0/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:N/A:N": 5.5,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	}
+	for vector, want := range tests {
+		got, err := cvss3BaseScore(vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("cvss3BaseScore(%s) = %.1f, want %.1f", vector, got, want)
+		}
+	}
+
+	if _, err := cvss3BaseScore("CVSS:3.1/AV:X"); err == nil {
+		t.Error("invalid vector should fail")
+	}
+}
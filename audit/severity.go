@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Severity levels from low to high, unknown means no severity is provided
+// or it can't be parsed.
+const (
+	SeverityUnknown  = "unknown"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityLevels = []string{SeverityUnknown, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// SeverityRank returns rank of severity level, it's -1 for invalid level.
+func SeverityRank(level string) int {
+	return slices.Index(severityLevels, level)
+}
+
+// severityOf returns the severity level and CVSS score of advisory for the affected entry,
+// severity of affected entry wins over the advisory's.
+func severityOf(advisory Advisory, affected Affected) (string, float64) {
+	for _, severities := range [][]Severity{affected.Severity, advisory.Severity} {
+		for _, severity := range severities {
+			if score, ok := parseScore(severity); ok {
+				return levelOfScore(score), score
+			}
+		}
+	}
+
+	for _, level := range []string{affected.DatabaseSpecific.Severity, advisory.DatabaseSpecific.Severity} {
+		if level := normalizeLevel(level); level != SeverityUnknown {
+			return level, 0
+		}
+	}
+
+	for _, severity := range slices.Concat(affected.Severity, advisory.Severity) {
+		if level := normalizeLevel(severity.Score); level != SeverityUnknown {
+			return level, 0
+		}
+	}
+	return SeverityUnknown, 0
+}
+
+// parseScore accepts a plain score or a CVSS v3 vector.
+func parseScore(severity Severity) (float64, bool) {
+	if score, err := strconv.ParseFloat(severity.Score, 64); err == nil {
+		return score, true
+	}
+	if strings.HasPrefix(severity.Score, "CVSS:3.") {
+		score, err := cvss3BaseScore(severity.Score)
+		return score, err == nil
+	}
+	return 0, false
+}
+
+func levelOfScore(score float64) string {
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// normalizeLevel maps severity names used by feeds, like "MODERATE" of GitHub, to levels.
+func normalizeLevel(level string) string {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "critical":
+		return SeverityCritical
+	case "high", "important":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low", "negligible":
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore computes base score of CVSS v3.x vector, see https://www.first.org/cvss/v3.1/specification-document.
+func cvss3BaseScore(vector string) (float64, error) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/")[1:] {
+		key, value, _ := strings.Cut(part, ":")
+		metrics[key] = value
+	}
+
+	weights := make(map[string]float64)
+	for metric, values := range cvss3Weights {
+		weight, ok := values[metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("invalid metric %s in cvss vector %s", metric, vector)
+		}
+		weights[metric] = weight
+	}
+
+	if metrics["S"] != "U" && metrics["S"] != "C" {
+		return 0, fmt.Errorf("invalid metric S in cvss vector %s", vector)
+	}
+	scopeChanged := metrics["S"] == "C"
+	if scopeChanged {
+		switch metrics["PR"] {
+		case "L":
+			weights["PR"] = 0.68
+		case "H":
+			weights["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-weights["C"])*(1-weights["I"])*(1-weights["A"])
+	impact := 6.42 * iss
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * weights["AV"] * weights["AC"] * weights["PR"] * weights["UI"]
+	if scopeChanged {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp rounds up to one decimal place as CVSS v3.1 defined, avoiding float errors.
+func roundUp(value float64) float64 {
+	integer := int(math.Round(value * 100000))
+	if integer%10000 == 0 {
+		return float64(integer) / 100000
+	}
+	return float64(integer/10000+1) / 10
+}
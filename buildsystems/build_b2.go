@@ -105,6 +105,7 @@ func (b b2) Configure(options []string) error {
 	}
 
 	executor := cmd.NewExecutor(title, configure)
+	executor.SetSandbox(b.sandbox)
 	executor.SetWorkDir(b.PortConfig.SrcDir)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
@@ -348,6 +349,7 @@ func (b b2) Build(options []string) error {
 	logPath := b.getLogPath("build")
 	title := fmt.Sprintf("[build %s@%s]", b.PortConfig.LibName, b.PortConfig.LibVersion)
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(b.sandbox)
 	executor.SetWorkDir(b.PortConfig.SrcDir)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
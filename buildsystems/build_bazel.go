@@ -137,6 +137,7 @@ func (b bazel) Configure(options []string) error {
 		scripts = b.expandVariables(scripts)
 		title := fmt.Sprintf("[configure %s]", b.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(b.sandbox)
 		executor.SetLogPath(b.getLogPath("configure"))
 		executor.SetWorkDir(b.PortConfig.SrcDir)
 		if err := executor.Execute(); err != nil {
@@ -168,6 +169,7 @@ func (b bazel) Build(options []string) error {
 		scripts = b.expandVariables(scripts)
 		title := fmt.Sprintf("[build %s]", b.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(b.sandbox)
 		executor.SetLogPath(b.getLogPath("build"))
 		executor.SetWorkDir(b.PortConfig.SrcDir)
 		if err := executor.Execute(); err != nil {
@@ -195,6 +197,7 @@ func (b bazel) Build(options []string) error {
 
 	title := fmt.Sprintf("[build %s]", b.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(b.sandbox)
 	executor.SetLogPath(b.getLogPath("build"))
 	executor.SetWorkDir(b.PortConfig.SrcDir)
 	if err := executor.Execute(); err != nil {
@@ -207,6 +210,7 @@ func (b bazel) Build(options []string) error {
 func (b bazel) shutdownServer() {
 	title := fmt.Sprintf("[bazel shutdown %s]", b.PortConfig.nameVersion())
 	shutdown := cmd.NewExecutor(title, "bazel", "shutdown")
+	shutdown.SetSandbox(b.sandbox)
 	shutdown.SetWorkDir(b.PortConfig.SrcDir)
 	_ = shutdown.Execute()
 
@@ -229,6 +233,7 @@ func (b bazel) Install(options []string) error {
 		scripts = b.expandVariables(scripts)
 		title := fmt.Sprintf("[install %s]", b.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(b.sandbox)
 		executor.SetLogPath(b.getLogPath("install"))
 		executor.SetWorkDir(b.PortConfig.SrcDir)
 		if err := executor.Execute(); err != nil {
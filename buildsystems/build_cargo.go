@@ -0,0 +1,613 @@
+package buildsystems
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/cmd"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// nativeStaticLibsRegex matches the system libraries that rustc prints for staticlib,
+// like: "note: native-static-libs: -lgcc_s -lutil -lrt -lpthread -lm -ldl -lc".
+var nativeStaticLibsRegex = regexp.MustCompile(`native-static-libs:\s*(.*)`)
+
+func NewCargo(config *BuildConfig) *cargo {
+	return &cargo{BuildConfig: config}
+}
+
+// cargo builds rust crates that export C ABIs as staticlib or cdylib.
+type cargo struct {
+	*BuildConfig
+}
+
+func (cargo) Name() string {
+	return "cargo"
+}
+
+func (c cargo) CheckTools() []string {
+	// cargo and rustup targets are managed by rustup, they are checked in configure.
+	return slices.Clone(c.BuildConfig.BuildTools)
+}
+
+func (c cargo) configured() bool {
+	configFile := filepath.Join(c.PortConfig.BuildDir, ".cargo", "config.toml")
+	return fileio.PathExists(c.PortConfig.RepoDir) && fileio.PathExists(configFile)
+}
+
+func (c cargo) Configure(options []string) error {
+	if _, err := exec.LookPath("cargo"); err != nil {
+		return fmt.Errorf("cargo is not found in PATH, please install rust toolchain with rustup")
+	}
+
+	// Create build dir if not exists.
+	if err := os.MkdirAll(filepath.Join(c.PortConfig.BuildDir, ".cargo"), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Crates must be vendored before building offline.
+	vendorDir, err := c.vendorCrates()
+	if err != nil {
+		return fmt.Errorf("vendor crates -> %w", err)
+	}
+
+	target, err := c.targetTriple()
+	if err != nil {
+		return err
+	}
+
+	// Cargo reads .cargo/config.toml from the working dir, so it never
+	// touches the source tree.
+	content := c.generateConfig(target, vendorDir)
+	configFile := filepath.Join(c.PortConfig.BuildDir, ".cargo", "config.toml")
+	if err := os.WriteFile(configFile, []byte(content), os.ModePerm); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c cargo) buildOptions() ([]string, error) {
+	var options []string
+	for _, option := range c.Options {
+		options = append(options, c.expandVariables(option))
+	}
+	return options, nil
+}
+
+func (c cargo) Build(options []string) error {
+	target, err := c.targetTriple()
+	if err != nil {
+		return err
+	}
+
+	crateTypes := c.crateTypes()
+	static := slices.Contains(crateTypes, "staticlib")
+
+	args := []string{
+		"rustc", "--lib",
+		"--manifest-path", filepath.Join(c.PortConfig.SrcDir, "Cargo.toml"),
+		"--crate-type", strings.Join(crateTypes, ","),
+		"--offline",
+		"-j", fmt.Sprintf("%d", c.PortConfig.Jobs),
+	}
+	if fileio.PathExists(filepath.Join(c.PortConfig.SrcDir, "Cargo.lock")) {
+		args = append(args, "--locked")
+	}
+	if c.profile() == "release" {
+		args = append(args, "--release")
+	}
+	if target != "" && !slices.ContainsFunc(options, func(option string) bool {
+		return option == "--target" || strings.HasPrefix(option, "--target=")
+	}) {
+		args = append(args, "--target", target)
+	}
+
+	// Options after "--" are passed to rustc.
+	cargoOptions, rustcOptions, _ := cutOptions(options, "--")
+	args = append(args, cargoOptions...)
+	if static {
+		rustcOptions = append(rustcOptions, "--print=native-static-libs")
+	}
+	if len(rustcOptions) > 0 {
+		args = append(args, "--")
+		args = append(args, rustcOptions...)
+	}
+
+	c.setupProfileEnvs()
+
+	title := fmt.Sprintf("[build %s]", c.PortConfig.nameVersion())
+	executor := cmd.NewExecutor(title, "cargo", args...)
+	executor.SetSandbox(c.sandbox)
+	executor.SetLogPath(c.getLogPath("build"))
+	executor.SetWorkDir(c.PortConfig.BuildDir)
+	if err := executor.Execute(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c cargo) Install(options []string) error {
+	target, err := c.targetTriple()
+	if err != nil {
+		return err
+	}
+
+	outputDir := filepath.Join(c.PortConfig.BuildDir, "target", target, c.profile())
+	entities, err := os.ReadDir(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to read cargo output dir -> %w", err)
+	}
+
+	// Install libraries, which are always in the top level of output dir.
+	var libraries []string
+	for _, entity := range entities {
+		if entity.IsDir() {
+			continue
+		}
+
+		name := entity.Name()
+		destDir := ""
+		switch {
+		case strings.HasSuffix(name, ".dll"):
+			destDir = "bin"
+		case strings.HasSuffix(name, ".a"), strings.HasSuffix(name, ".lib"),
+			strings.HasSuffix(name, ".so"), strings.HasSuffix(name, ".dylib"):
+			destDir = "lib"
+		default:
+			continue
+		}
+
+		dest := filepath.Join(c.PortConfig.PackageDir, destDir, name)
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return err
+		}
+		if err := fileio.CopyFile(filepath.Join(outputDir, name), dest); err != nil {
+			return fmt.Errorf("failed to install %s -> %w", name, err)
+		}
+		if destDir == "lib" {
+			libraries = append(libraries, name)
+		}
+	}
+	if len(libraries) == 0 {
+		return fmt.Errorf("no library is found in %s, crate-type of [lib] should be staticlib or cdylib", outputDir)
+	}
+
+	// Install headers shipped with the crate, generated headers can be installed with post_install.
+	includeDir := filepath.Join(c.PortConfig.SrcDir, "include")
+	if fileio.PathExists(includeDir) {
+		if err := fileio.CopyDir(includeDir, filepath.Join(c.PortConfig.PackageDir, "include")); err != nil {
+			return fmt.Errorf("failed to install headers -> %w", err)
+		}
+	}
+
+	// Generate pkg-config and cmake config, since cargo doesn't produce them.
+	linkName := c.linkName(libraries)
+	nativeLibs := c.nativeStaticLibs()
+	if err := c.generatePkgConfig(linkName, nativeLibs); err != nil {
+		return fmt.Errorf("generate pkg-config file -> %w", err)
+	}
+
+	// cmake_config.toml in port dir takes priority, see generateCMakeConfig.
+	cmakeConfigPath := filepath.Join(filepath.Dir(c.PortConfig.PortFile), "cmake_config.toml")
+	if !fileio.PathExists(cmakeConfigPath) {
+		if err := c.generateCMakeConfigFiles(libraries, nativeLibs); err != nil {
+			return fmt.Errorf("generate cmake config files -> %w", err)
+		}
+	}
+
+	return nil
+}
+
+// crateTypes maps build_shared/build_static to crate types, staticlib is built
+// when neither is selected, like build_shared is false and build_static is not set.
+func (c cargo) crateTypes() []string {
+	libraryType := c.buildLibraryType()
+	var crateTypes []string
+	if libraryType.static {
+		crateTypes = append(crateTypes, "staticlib")
+	}
+	if libraryType.shared {
+		crateTypes = append(crateTypes, "cdylib")
+	}
+	if len(crateTypes) == 0 {
+		crateTypes = append(crateTypes, "staticlib")
+	}
+	return crateTypes
+}
+
+// vendorCrates returns the vendored registry, crates are vendored into downloads
+// once when source doesn't ship a vendor dir, then all builds run offline.
+func (c cargo) vendorCrates() (string, error) {
+	vendorDir := filepath.Join(c.PortConfig.SrcDir, "vendor")
+	if fileio.PathExists(vendorDir) {
+		return vendorDir, nil
+	}
+
+	nameVersion := fmt.Sprintf("%s@%s", c.PortConfig.LibName, c.PortConfig.LibVersion)
+	vendorDir = filepath.Join(c.Ctx.Downloads(), "cargo-vendor", nameVersion)
+	if fileio.PathExists(vendorDir) {
+		return vendorDir, nil
+	}
+	if c.Ctx.Offline() {
+		return "", fmt.Errorf("crates of %s are not vendored and offline mode forbids downloading them", nameVersion)
+	}
+
+	// Vendor into a tmp dir first, in case of interruption.
+	tmpDir := vendorDir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return "", err
+	}
+	args := []string{"vendor", "--manifest-path", filepath.Join(c.PortConfig.SrcDir, "Cargo.toml")}
+	if fileio.PathExists(filepath.Join(c.PortConfig.SrcDir, "Cargo.lock")) {
+		args = append(args, "--locked")
+	}
+	args = append(args, tmpDir)
+
+	title := fmt.Sprintf("[vendor crates %s]", c.PortConfig.nameVersion())
+	executor := cmd.NewExecutor(title, "cargo", args...)
+	executor.SetSandbox(c.sandbox)
+	executor.SetLogPath(c.getLogPath("vendor"))
+	executor.SetWorkDir(c.PortConfig.SrcDir)
+	if err := executor.Execute(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpDir, vendorDir); err != nil {
+		return "", err
+	}
+
+	return vendorDir, nil
+}
+
+// generateConfig generates .cargo/config.toml for building offline with
+// vendored crates, and linking with toolchain of platform.
+func (c cargo) generateConfig(target, vendorDir string) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "# Generated by celer for %s\n", c.PortConfig.nameVersion())
+
+	fmt.Fprintf(&buffer, "\n[build]\n")
+	fmt.Fprintf(&buffer, "target-dir = %q\n", filepath.ToSlash(filepath.Join(c.PortConfig.BuildDir, "target")))
+
+	fmt.Fprintf(&buffer, "\n[net]\n")
+	fmt.Fprintf(&buffer, "offline = true\n")
+
+	fmt.Fprintf(&buffer, "\n[source.crates-io]\n")
+	fmt.Fprintf(&buffer, "replace-with = \"vendored-sources\"\n")
+	fmt.Fprintf(&buffer, "\n[source.vendored-sources]\n")
+	fmt.Fprintf(&buffer, "directory = %q\n", filepath.ToSlash(vendorDir))
+
+	// Native build uses default linker of rust.
+	if target == "" {
+		return buffer.String()
+	}
+
+	toolchain := c.Ctx.Platform().GetToolchain()
+	rootfs := c.Ctx.Platform().GetRootFS()
+
+	// Flags shared by linker of rust and C compiler of cc-rs.
+	var flags []string
+	if rootfs != nil {
+		flags = append(flags, "--sysroot="+filepath.ToSlash(rootfs.GetAbsDir()))
+	}
+	flags = append(flags, toolchain.RuntimeFlags()...)
+
+	var rustflags []string
+	for _, flag := range flags {
+		rustflags = append(rustflags, "-C", "link-arg="+flag)
+	}
+	for _, libDir := range c.linkDirs(rootfs) {
+		rustflags = append(rustflags, "-L", "native="+libDir)
+	}
+	for _, libDir := range c.LibDirs {
+		rustflags = append(rustflags, "-L", "native="+filepath.ToSlash(c.ExprVars.Expand(libDir)))
+	}
+
+	msvc := toolchain.GetName() == "msvc" || toolchain.GetName() == "clang-cl"
+	fmt.Fprintf(&buffer, "\n[target.%s]\n", target)
+	if !msvc {
+		fmt.Fprintf(&buffer, "linker = %q\n", c.toolPath(toolchain, toolchain.GetCC()))
+		if ar := toolchain.GetAR(); ar != "" {
+			fmt.Fprintf(&buffer, "ar = %q\n", c.toolPath(toolchain, ar))
+		}
+	}
+	fmt.Fprintf(&buffer, "rustflags = [%s]\n", quoteJoin(rustflags))
+
+	// Environment variables for build scripts of -sys crates, they are
+	// suffixed with target, so that build scripts for host are not affected.
+	suffix := strings.ReplaceAll(target, "-", "_")
+	fmt.Fprintf(&buffer, "\n[env]\n")
+	if !msvc {
+		fmt.Fprintf(&buffer, "CC_%s = %q\n", suffix, c.toolPath(toolchain, toolchain.GetCC()))
+		fmt.Fprintf(&buffer, "CXX_%s = %q\n", suffix, c.toolPath(toolchain, toolchain.GetCXX()))
+		if ar := toolchain.GetAR(); ar != "" {
+			fmt.Fprintf(&buffer, "AR_%s = %q\n", suffix, c.toolPath(toolchain, ar))
+		}
+	}
+
+	var cflags []string
+	cflags = append(cflags, flags...)
+	depIncludeDir := filepath.Join(dirs.TmpDepsDir, c.PortConfig.LibraryDir, "include")
+	cflags = append(cflags, "-I"+filepath.ToSlash(depIncludeDir))
+	for _, includeDir := range c.IncludeDirs {
+		cflags = append(cflags, "-I"+filepath.ToSlash(c.ExprVars.Expand(includeDir)))
+	}
+	if !msvc {
+		fmt.Fprintf(&buffer, "CFLAGS_%s = %q\n", suffix, strings.Join(cflags, " "))
+		fmt.Fprintf(&buffer, "CXXFLAGS_%s = %q\n", suffix, strings.Join(cflags, " "))
+	}
+
+	// pkg-config crate refuses to cross compile by default.
+	fmt.Fprintf(&buffer, "PKG_CONFIG_ALLOW_CROSS = \"1\"\n")
+
+	return buffer.String()
+}
+
+// targetTriple maps toolchain of platform to target triple of rust,
+// it's empty for native build, and `--target` in options wins.
+func (c cargo) targetTriple() (string, error) {
+	for index, option := range c.Options {
+		if after, ok := strings.CutPrefix(option, "--target="); ok {
+			return after, nil
+		}
+		if option == "--target" && index+1 < len(c.Options) {
+			return c.Options[index+1], nil
+		}
+	}
+
+	if c.DevDep || c.HostDev {
+		return "", nil
+	}
+
+	toolchain := c.Ctx.Platform().GetToolchain()
+	triple := rustTargetTriple(toolchain.GetName(), toolchain.GetSystemName(),
+		toolchain.GetSystemProcessor(), toolchain.GetHost())
+	if triple == "" {
+		return "", fmt.Errorf("cannot map toolchain %s (%s) to a rust target, please specify it with `--target=` in options",
+			toolchain.GetName(), toolchain.GetHost())
+	}
+	return triple, nil
+}
+
+// rustTargetTriple maps system name, processor and host of toolchain to target triple of rust,
+// for example: aarch64-linux-gnu -> aarch64-unknown-linux-gnu.
+func rustTargetTriple(toolchainName, systemName, systemProcessor, host string) string {
+	arch := strings.ToLower(systemProcessor)
+	switch arch {
+	case "amd64", "x64":
+		arch = "x86_64"
+	case "arm64":
+		arch = "aarch64"
+	case "x86", "i386", "i586":
+		arch = "i686"
+	case "riscv64":
+		arch = "riscv64gc"
+	}
+
+	host = strings.ToLower(host)
+	abi := host[strings.LastIndex(host, "-")+1:]
+
+	switch strings.ToLower(systemName) {
+	case "linux":
+		// Rust names 32-bit arm triples by the arm version.
+		if arch == "arm" && strings.HasSuffix(abi, "hf") {
+			arch = "armv7"
+		}
+		if !strings.HasPrefix(abi, "gnu") && !strings.HasPrefix(abi, "musl") {
+			abi = "gnu"
+		}
+		return fmt.Sprintf("%s-unknown-linux-%s", arch, abi)
+
+	case "android":
+		if arch == "arm" {
+			return "armv7-linux-androideabi"
+		}
+		return arch + "-linux-android"
+
+	case "windows":
+		msvc := toolchainName == "msvc" || toolchainName == "clang-cl"
+		return fmt.Sprintf("%s-pc-windows-%s", arch, expr.If(msvc, "msvc", "gnu"))
+
+	case "darwin":
+		return arch + "-apple-darwin"
+
+	default:
+		return ""
+	}
+}
+
+// profile is the cargo profile of build type, cargo only has release and dev
+// profiles by default, so other build types are tuned with profile envs.
+func (c cargo) profile() string {
+	if c.DevDep || c.BuildType != "debug" {
+		return "release"
+	}
+	return "debug"
+}
+
+func (c cargo) setupProfileEnvs() {
+	if c.DevDep {
+		return
+	}
+
+	switch c.BuildType {
+	case "relwithdebinfo":
+		c.envBackup.setenv("CARGO_PROFILE_RELEASE_DEBUG", "true")
+	case "minsizerel":
+		c.envBackup.setenv("CARGO_PROFILE_RELEASE_OPT_LEVEL", "s")
+	}
+}
+
+// linkDirs returns lib dirs of dependencies and rootfs for linking.
+func (c cargo) linkDirs(rootfs context.RootFS) []string {
+	libDirs := []string{filepath.ToSlash(filepath.Join(dirs.TmpDepsDir, c.PortConfig.LibraryDir, "lib"))}
+	if rootfs != nil {
+		for _, libDir := range rootfs.GetLibDirs() {
+			libDirs = append(libDirs, filepath.ToSlash(filepath.Join(rootfs.GetAbsDir(), libDir)))
+		}
+	}
+	return libDirs
+}
+
+// toolPath prefers tool in toolchain dir, otherwise it's searched in PATH.
+func (c cargo) toolPath(toolchain context.Toolchain, tool string) string {
+	if toolchain.GetAbsDir() != "" {
+		path := filepath.Join(toolchain.GetAbsDir(), tool)
+		if fileio.PathExists(path) {
+			return filepath.ToSlash(path)
+		}
+	}
+	return tool
+}
+
+// linkName returns the name to link with, like "rustls" of "librustls.a".
+func (c cargo) linkName(libraries []string) string {
+	for _, library := range libraries {
+		name := strings.TrimSuffix(library, filepath.Ext(library))
+		name = strings.TrimSuffix(name, ".dll")
+		if after, ok := strings.CutPrefix(name, "lib"); ok {
+			return after
+		}
+		return name
+	}
+	return c.PortConfig.LibName
+}
+
+// nativeStaticLibs returns system libraries that staticlib depends on, which is printed by rustc.
+func (c cargo) nativeStaticLibs() []string {
+	bytes, err := os.ReadFile(c.getLogPath("build"))
+	if err != nil {
+		return nil
+	}
+
+	var libs []string
+	for _, match := range nativeStaticLibsRegex.FindAllStringSubmatch(string(bytes), -1) {
+		for lib := range strings.FieldsSeq(match[1]) {
+			if !slices.Contains(libs, lib) {
+				libs = append(libs, lib)
+			}
+		}
+	}
+	return libs
+}
+
+func (c cargo) generatePkgConfig(linkName string, nativeLibs []string) error {
+	pkgconfigDir := filepath.Join(c.PortConfig.PackageDir, "lib", "pkgconfig")
+	pcFile := filepath.Join(pkgconfigDir, c.PortConfig.LibName+".pc")
+	if fileio.PathExists(pcFile) {
+		return nil
+	}
+	if err := os.MkdirAll(pkgconfigDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "prefix=${pcfiledir}/../..\n")
+	fmt.Fprintf(&buffer, "exec_prefix=${prefix}\n")
+	fmt.Fprintf(&buffer, "libdir=${prefix}/lib\n")
+	fmt.Fprintf(&buffer, "includedir=${prefix}/include\n\n")
+	fmt.Fprintf(&buffer, "Name: %s\n", c.PortConfig.LibName)
+	fmt.Fprintf(&buffer, "Description: %s built with cargo\n", c.PortConfig.LibName)
+	fmt.Fprintf(&buffer, "Version: %s\n", c.PortConfig.LibVersion)
+	fmt.Fprintf(&buffer, "Libs: -L${libdir} -l%s\n", linkName)
+	if len(nativeLibs) > 0 {
+		fmt.Fprintf(&buffer, "Libs.private: %s\n", strings.Join(nativeLibs, " "))
+	}
+	fmt.Fprintf(&buffer, "Cflags: -I${includedir}\n")
+
+	return os.WriteFile(pcFile, buffer.Bytes(), os.ModePerm)
+}
+
+// generateCMakeConfigFiles writes a relocatable <name>Config.cmake with an imported target
+// `<name>::<name>`, the shared library is preferred when both are built.
+func (c cargo) generateCMakeConfigFiles(libraries, nativeLibs []string) error {
+	libName := c.PortConfig.LibName
+	cmakeDir := filepath.Join(c.PortConfig.PackageDir, "lib", "cmake", libName)
+	if err := os.MkdirAll(cmakeDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	library := libraries[0]
+	libraryType := "STATIC"
+	for _, name := range libraries {
+		if strings.HasSuffix(name, ".so") || strings.HasSuffix(name, ".dylib") || strings.HasSuffix(name, ".dll.lib") {
+			library = name
+			libraryType = "SHARED"
+			break
+		}
+	}
+
+	// System libraries are only required to link staticlib.
+	var interfaceLibs []string
+	if libraryType == "STATIC" {
+		for _, lib := range nativeLibs {
+			interfaceLibs = append(interfaceLibs, strings.TrimPrefix(lib, "-l"))
+		}
+	}
+
+	target := libName + "::" + libName
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "# Generated by celer for %s\n", c.PortConfig.nameVersion())
+	fmt.Fprintf(&buffer, "get_filename_component(_IMPORT_PREFIX \"${CMAKE_CURRENT_LIST_DIR}/../../..\" ABSOLUTE)\n\n")
+	fmt.Fprintf(&buffer, "if(NOT TARGET %s)\n", target)
+	fmt.Fprintf(&buffer, "  add_library(%s %s IMPORTED)\n", target, libraryType)
+	fmt.Fprintf(&buffer, "  set_target_properties(%s PROPERTIES\n", target)
+	fmt.Fprintf(&buffer, "    INTERFACE_INCLUDE_DIRECTORIES \"${_IMPORT_PREFIX}/include\"\n")
+	if strings.HasSuffix(library, ".dll.lib") {
+		dll := strings.TrimSuffix(library, ".lib")
+		fmt.Fprintf(&buffer, "    IMPORTED_IMPLIB \"${_IMPORT_PREFIX}/lib/%s\"\n", library)
+		fmt.Fprintf(&buffer, "    IMPORTED_LOCATION \"${_IMPORT_PREFIX}/bin/%s\"\n", dll)
+	} else {
+		fmt.Fprintf(&buffer, "    IMPORTED_LOCATION \"${_IMPORT_PREFIX}/lib/%s\"\n", library)
+	}
+	if len(interfaceLibs) > 0 {
+		fmt.Fprintf(&buffer, "    INTERFACE_LINK_LIBRARIES \"%s\"\n", strings.Join(interfaceLibs, ";"))
+	}
+	fmt.Fprintf(&buffer, "  )\n")
+	fmt.Fprintf(&buffer, "endif()\n\n")
+	fmt.Fprintf(&buffer, "unset(_IMPORT_PREFIX)\n")
+
+	configFile := filepath.Join(cmakeDir, libName+"Config.cmake")
+	if err := os.WriteFile(configFile, buffer.Bytes(), os.ModePerm); err != nil {
+		return err
+	}
+
+	var version bytes.Buffer
+	fmt.Fprintf(&version, "set(PACKAGE_VERSION %q)\n\n", c.PortConfig.LibVersion)
+	fmt.Fprintf(&version, "if(PACKAGE_FIND_VERSION VERSION_GREATER PACKAGE_VERSION)\n")
+	fmt.Fprintf(&version, "  set(PACKAGE_VERSION_COMPATIBLE FALSE)\n")
+	fmt.Fprintf(&version, "else()\n")
+	fmt.Fprintf(&version, "  set(PACKAGE_VERSION_COMPATIBLE TRUE)\n")
+	fmt.Fprintf(&version, "  if(PACKAGE_FIND_VERSION STREQUAL PACKAGE_VERSION)\n")
+	fmt.Fprintf(&version, "    set(PACKAGE_VERSION_EXACT TRUE)\n")
+	fmt.Fprintf(&version, "  endif()\n")
+	fmt.Fprintf(&version, "endif()\n")
+
+	versionFile := filepath.Join(cmakeDir, libName+"ConfigVersion.cmake")
+	return os.WriteFile(versionFile, version.Bytes(), os.ModePerm)
+}
+
+// cutOptions splits options around separator.
+func cutOptions(options []string, separator string) (before, after []string, found bool) {
+	if index := slices.Index(options, separator); index >= 0 {
+		return slices.Clone(options[:index]), slices.Clone(options[index+1:]), true
+	}
+	return slices.Clone(options), nil, false
+}
+
+func quoteJoin(values []string) string {
+	var quoted []string
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return strings.Join(quoted, ", ")
+}
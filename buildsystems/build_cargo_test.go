@@ -0,0 +1,305 @@
+package buildsystems
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/context"
+)
+
+// cargoContext provides platform with toolchain and rootfs to cargo.
+type cargoContext struct {
+	context.Context
+	toolchain *cargoToolchain
+	rootfs    context.RootFS
+}
+
+func (c cargoContext) Platform() context.Platform {
+	return cargoPlatform{toolchain: c.toolchain, rootfs: c.rootfs}
+}
+
+type cargoPlatform struct {
+	context.Platform
+	toolchain *cargoToolchain
+	rootfs    context.RootFS
+}
+
+func (c cargoPlatform) GetToolchain() context.Toolchain { return c.toolchain }
+func (c cargoPlatform) GetRootFS() context.RootFS       { return c.rootfs }
+
+type cargoToolchain struct {
+	context.Toolchain
+	name   string
+	absDir string
+	cc     string
+	cxx    string
+	ar     string
+}
+
+func (c cargoToolchain) GetName() string            { return c.name }
+func (c cargoToolchain) GetAbsDir() string          { return c.absDir }
+func (c cargoToolchain) GetCC() string              { return c.cc }
+func (c cargoToolchain) GetCXX() string             { return c.cxx }
+func (c cargoToolchain) GetAR() string              { return c.ar }
+func (c cargoToolchain) RuntimeFlags() []string     { return nil }
+func (c cargoToolchain) GetSystemName() string      { return "Linux" }
+func (c cargoToolchain) GetSystemProcessor() string { return "aarch64" }
+
+type cargoRootFS struct {
+	context.RootFS
+	absDir string
+}
+
+func (c cargoRootFS) GetAbsDir() string    { return c.absDir }
+func (c cargoRootFS) GetLibDirs() []string { return []string{"usr/lib"} }
+
+func newTestCargo(t *testing.T) *cargo {
+	t.Helper()
+	workDir := t.TempDir()
+	return NewCargo(&BuildConfig{
+		PortConfig: PortConfig{
+			LibName:    "rustls",
+			LibVersion: "0.14.0",
+			SrcDir:     filepath.Join(workDir, "src"),
+			BuildDir:   filepath.Join(workDir, "buildtrees", "rustls@0.14.0", "aarch64-linux-release"),
+			PackageDir: filepath.Join(workDir, "packages", "rustls@0.14.0"),
+			LibraryDir: "aarch64-linux/project/release",
+		},
+	})
+}
+
+func TestCargo_RustTargetTriple(t *testing.T) {
+	tests := []struct {
+		toolchainName   string
+		systemName      string
+		systemProcessor string
+		host            string
+		expected        string
+	}{
+		{"gcc", "Linux", "x86_64", "x86_64-linux-gnu", "x86_64-unknown-linux-gnu"},
+		{"gcc", "Linux", "aarch64", "aarch64-none-linux-gnu", "aarch64-unknown-linux-gnu"},
+		{"gcc", "Linux", "arm", "arm-linux-gnueabihf", "armv7-unknown-linux-gnueabihf"},
+		{"gcc", "Linux", "aarch64", "aarch64-linux-musl", "aarch64-unknown-linux-musl"},
+		{"clang", "Android", "arm64", "aarch64-linux-android", "aarch64-linux-android"},
+		{"msvc", "Windows", "AMD64", "", "x86_64-pc-windows-msvc"},
+		{"gcc", "Windows", "x86_64", "x86_64-w64-mingw32", "x86_64-pc-windows-gnu"},
+		{"clang", "Darwin", "arm64", "arm64-apple-darwin", "aarch64-apple-darwin"},
+		{"qcc", "QNX", "aarch64", "aarch64-nto-qnx", ""},
+	}
+
+	for _, test := range tests {
+		actual := rustTargetTriple(test.toolchainName, test.systemName, test.systemProcessor, test.host)
+		if actual != test.expected {
+			t.Errorf("rustTargetTriple(%s, %s, %s, %s) = %q, want %q", test.toolchainName,
+				test.systemName, test.systemProcessor, test.host, actual, test.expected)
+		}
+	}
+}
+
+func TestCargo_CrateTypes(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		buildShared *bool
+		buildStatic *bool
+		expected    []string
+	}{
+		{nil, nil, []string{"cdylib"}},
+		{&yes, nil, []string{"cdylib"}},
+		{nil, &yes, []string{"staticlib"}},
+		{&yes, &yes, []string{"staticlib", "cdylib"}},
+		{&no, nil, []string{"staticlib"}},
+		{&no, &no, []string{"staticlib"}},
+	}
+
+	for _, test := range tests {
+		cargo := NewCargo(&BuildConfig{BuildShared: test.buildShared, BuildStatic: test.buildStatic})
+		if actual := cargo.crateTypes(); !slices.Equal(actual, test.expected) {
+			t.Errorf("crateTypes(build_shared=%v, build_static=%v) = %v, want %v",
+				deref(test.buildShared), deref(test.buildStatic), actual, test.expected)
+		}
+	}
+}
+
+func TestCargo_GenerateConfig(t *testing.T) {
+	toolchainDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(toolchainDir, "aarch64-linux-gnu-gcc"), nil, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	cargo := newTestCargo(t)
+	toolchain := &cargoToolchain{
+		name:   "gcc",
+		absDir: toolchainDir,
+		cc:     "aarch64-linux-gnu-gcc",
+		cxx:    "aarch64-linux-gnu-g++",
+		ar:     "aarch64-linux-gnu-ar",
+	}
+	rootfsDir := t.TempDir()
+	cargo.Ctx = cargoContext{toolchain: toolchain, rootfs: cargoRootFS{absDir: rootfsDir}}
+	vendorDir := filepath.Join(cargo.PortConfig.SrcDir, "vendor")
+
+	// Native build uses default linker of rust.
+	content := cargo.generateConfig("", vendorDir)
+	for _, expected := range []string{
+		"offline = true",
+		`replace-with = "vendored-sources"`,
+		"directory = " + quote(filepath.ToSlash(vendorDir)),
+		"target-dir = " + quote(filepath.ToSlash(filepath.Join(cargo.PortConfig.BuildDir, "target"))),
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("config of native build should contain %s, but got:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(content, "[target.") || strings.Contains(content, "linker") {
+		t.Errorf("config of native build should not set linker, but got:\n%s", content)
+	}
+
+	// Linker in toolchain dir is referred by abs path, others are searched in PATH.
+	content = cargo.generateConfig("aarch64-unknown-linux-gnu", vendorDir)
+	for _, expected := range []string{
+		"[target.aarch64-unknown-linux-gnu]",
+		"linker = " + quote(filepath.ToSlash(filepath.Join(toolchainDir, "aarch64-linux-gnu-gcc"))),
+		`ar = "aarch64-linux-gnu-ar"`,
+		`"-C", "link-arg=--sysroot=` + filepath.ToSlash(rootfsDir) + `"`,
+		`"-L", "native=` + filepath.ToSlash(filepath.Join(rootfsDir, "usr/lib")) + `"`,
+		"CC_aarch64_unknown_linux_gnu = ",
+		`AR_aarch64_unknown_linux_gnu = "aarch64-linux-gnu-ar"`,
+		`PKG_CONFIG_ALLOW_CROSS = "1"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("config of cross build should contain %s, but got:\n%s", expected, content)
+		}
+	}
+
+	// MSVC is found by rust itself.
+	toolchain.name = "msvc"
+	content = cargo.generateConfig("x86_64-pc-windows-msvc", vendorDir)
+	if !strings.Contains(content, "[target.x86_64-pc-windows-msvc]") {
+		t.Errorf("config of msvc should contain target section, but got:\n%s", content)
+	}
+	if strings.Contains(content, "linker =") || strings.Contains(content, "ar =") || strings.Contains(content, "CC_") {
+		t.Errorf("config of msvc should not set linker, ar and CC, but got:\n%s", content)
+	}
+}
+
+func TestCargo_GeneratePkgConfig(t *testing.T) {
+	cargo := newTestCargo(t)
+	if err := cargo.generatePkgConfig("rustls", []string{"-lpthread", "-ldl"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pcFile := filepath.Join(cargo.PortConfig.PackageDir, "lib", "pkgconfig", "rustls.pc")
+	bytes, err := os.ReadFile(pcFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"prefix=${pcfiledir}/../..",
+		"Version: 0.14.0",
+		"Libs: -L${libdir} -lrustls",
+		"Libs.private: -lpthread -ldl",
+		"Cflags: -I${includedir}",
+	} {
+		if !strings.Contains(string(bytes), expected) {
+			t.Errorf("pkg-config file should contain %s, but got:\n%s", expected, bytes)
+		}
+	}
+
+	// pkg-config file shipped with crate is kept.
+	if err := os.WriteFile(pcFile, []byte("shipped"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := cargo.generatePkgConfig("rustls", nil); err != nil {
+		t.Fatal(err)
+	}
+	if bytes, _ := os.ReadFile(pcFile); string(bytes) != "shipped" {
+		t.Errorf("existing pkg-config file should be kept, but got:\n%s", bytes)
+	}
+}
+
+func TestCargo_GenerateCMakeConfigFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		libraries  []string
+		nativeLibs []string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:       "staticlib",
+			libraries:  []string{"librustls.a"},
+			nativeLibs: []string{"-lpthread", "-ldl"},
+			expected: []string{
+				"add_library(rustls::rustls STATIC IMPORTED)",
+				`IMPORTED_LOCATION "${_IMPORT_PREFIX}/lib/librustls.a"`,
+				`INTERFACE_LINK_LIBRARIES "pthread;dl"`,
+			},
+		},
+		{
+			name:       "shared_is_preferred",
+			libraries:  []string{"librustls.a", "librustls.so"},
+			nativeLibs: []string{"-lpthread"},
+			expected: []string{
+				"add_library(rustls::rustls SHARED IMPORTED)",
+				`IMPORTED_LOCATION "${_IMPORT_PREFIX}/lib/librustls.so"`,
+			},
+			unexpected: []string{"INTERFACE_LINK_LIBRARIES"},
+		},
+		{
+			name:      "dll_with_import_lib",
+			libraries: []string{"rustls.dll.lib"},
+			expected: []string{
+				"add_library(rustls::rustls SHARED IMPORTED)",
+				`IMPORTED_IMPLIB "${_IMPORT_PREFIX}/lib/rustls.dll.lib"`,
+				`IMPORTED_LOCATION "${_IMPORT_PREFIX}/bin/rustls.dll"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cargo := newTestCargo(t)
+			if err := cargo.generateCMakeConfigFiles(test.libraries, test.nativeLibs); err != nil {
+				t.Fatal(err)
+			}
+
+			cmakeDir := filepath.Join(cargo.PortConfig.PackageDir, "lib", "cmake", "rustls")
+			config, err := os.ReadFile(filepath.Join(cmakeDir, "rustlsConfig.cmake"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(string(config), expected) {
+					t.Errorf("cmake config should contain %s, but got:\n%s", expected, config)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(string(config), unexpected) {
+					t.Errorf("cmake config should not contain %s, but got:\n%s", unexpected, config)
+				}
+			}
+
+			version, err := os.ReadFile(filepath.Join(cmakeDir, "rustlsConfigVersion.cmake"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(version), `set(PACKAGE_VERSION "0.14.0")`) {
+				t.Errorf("cmake config version should contain package version, but got:\n%s", version)
+			}
+		})
+	}
+}
+
+func deref(value *bool) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func quote(value string) string {
+	return `"` + value + `"`
+}
@@ -298,6 +298,7 @@ func (c cmake) Configure(options []string) error {
 	logPath := c.getLogPath("configure")
 	title := fmt.Sprintf("[configure %s]", c.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, "cmake", args...)
+	executor.SetSandbox(c.sandbox)
 	executor.SetWorkDir(c.PortConfig.BuildDir)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
@@ -328,6 +329,7 @@ func (c cmake) Build(options []string) error {
 	logPath := c.getLogPath("build")
 	title := fmt.Sprintf("[build %s@%s]", c.PortConfig.LibName, c.PortConfig.LibVersion)
 	executor := cmd.NewExecutor(title, "cmake", args...)
+	executor.SetSandbox(c.sandbox)
 	executor.SetWorkDir(c.PortConfig.BuildDir)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
@@ -357,6 +359,7 @@ func (c cmake) Install(options []string) error {
 	logPath := c.getLogPath("install")
 	title := fmt.Sprintf("[install %s@%s]", c.PortConfig.LibName, c.PortConfig.LibVersion)
 	executor := cmd.NewExecutor(title, "cmake", args...)
+	executor.SetSandbox(c.sandbox)
 	executor.SetWorkDir(c.PortConfig.BuildDir)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
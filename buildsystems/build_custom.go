@@ -80,6 +80,7 @@ func (c custom) Configure(options []string) error {
 		scripts = c.expandVariables(scripts)
 		title := fmt.Sprintf("[configure %s]", c.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(c.sandbox)
 		executor.SetLogPath(c.getLogPath("configure"))
 		executor.SetWorkDir(expr.If(c.BuildInSource, c.PortConfig.SrcDir, c.PortConfig.BuildDir))
 		if err := executor.Execute(); err != nil {
@@ -105,6 +106,7 @@ func (c custom) Build(options []string) error {
 		scripts = c.expandVariables(scripts)
 		title := fmt.Sprintf("[build %s]", c.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(c.sandbox)
 		executor.SetLogPath(c.getLogPath("build"))
 		executor.SetWorkDir(expr.If(c.BuildInSource, c.PortConfig.SrcDir, c.PortConfig.BuildDir))
 		if err := executor.Execute(); err != nil {
@@ -130,6 +132,7 @@ func (c custom) Install(options []string) error {
 		scripts = c.expandVariables(scripts)
 		title := fmt.Sprintf("[install %s]", c.PortConfig.nameVersion())
 		executor := cmd.NewExecutor(title, scripts)
+		executor.SetSandbox(c.sandbox)
 		executor.SetLogPath(c.getLogPath("install"))
 		executor.SetWorkDir(expr.If(c.BuildInSource, c.PortConfig.SrcDir, c.PortConfig.BuildDir))
 		if err := executor.Execute(); err != nil {
@@ -71,6 +71,7 @@ func (g gyp) Build(options []string) error {
 	logPath := g.getLogPath("build")
 	title := fmt.Sprintf("[build %s@%s]", g.PortConfig.LibName, g.PortConfig.LibVersion)
 	executor := cmd.NewExecutor(title, "./build.sh "+joinedOptions)
+	executor.SetSandbox(g.sandbox)
 	executor.SetLogPath(logPath)
 	executor.SetWorkDir(g.PortConfig.SrcDir)
 	if err := executor.Execute(); err != nil {
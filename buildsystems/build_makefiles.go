@@ -114,6 +114,7 @@ func (m *makefiles) preConfigure() error {
 		title := fmt.Sprintf("[pre configure %s]", m.PortConfig.nameVersion())
 		command = m.expandVariables(command)
 		executor := cmd.NewExecutor(title, command)
+		executor.SetSandbox(m.sandbox)
 		executor.SetWorkDir(m.PortConfig.RepoDir)
 		executor.MSYS2Env(runtime.GOOS == "windows")
 		if err := executor.Execute(); err != nil {
@@ -284,6 +285,7 @@ func (m makefiles) Configure(options []string) error {
 
 	title := fmt.Sprintf("[configure %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(m.getLogPath("configure"))
 	executor.SetWorkDir(expr.If(m.BuildInSource, m.PortConfig.SrcDir, m.PortConfig.BuildDir))
 
@@ -324,6 +326,7 @@ func (m makefiles) Build(options []string) error {
 	// Execute build.
 	title := fmt.Sprintf("[build %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(m.getLogPath("build"))
 
 	// Use msys2 and msvc envs for Windows builds (only for autoconf projects).
@@ -372,6 +375,7 @@ func (m makefiles) Install(options []string) error {
 	// Execute install.
 	title := fmt.Sprintf("[install %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(m.getLogPath("install"))
 
 	// Use msys2 and msvc envs for Windows builds (only for autoconf projects).
@@ -192,6 +192,7 @@ func (m meson) Configure(options []string) error {
 	logPath := m.getLogPath("configure")
 	title := fmt.Sprintf("[configure %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(logPath)
 	executor.SetWorkDir(m.PortConfig.SrcDir)
 	if err := executor.Execute(); err != nil {
@@ -209,6 +210,7 @@ func (m meson) Build(options []string) error {
 	logPath := m.getLogPath("build")
 	title := fmt.Sprintf("[build %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(logPath)
 	executor.SetWorkDir(m.PortConfig.BuildDir)
 	if err := executor.Execute(); err != nil {
@@ -226,6 +228,7 @@ func (m meson) Install(options []string) error {
 	logPath := m.getLogPath("install")
 	title := fmt.Sprintf("[install %s]", m.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(m.sandbox)
 	executor.SetLogPath(logPath)
 	if err := executor.Execute(); err != nil {
 		return err
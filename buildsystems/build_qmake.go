@@ -44,6 +44,7 @@ func (q qmake) preConfigure() error {
 		title := fmt.Sprintf("[post confiure %s]", q.PortConfig.nameVersion())
 		script = q.expandVariables(script)
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(q.sandbox)
 		if err := executor.Execute(); err != nil {
 			return err
 		}
@@ -118,6 +119,7 @@ func (q qmake) Configure(options []string) error {
 	command := fmt.Sprintf("%s/configure %s", q.PortConfig.SrcDir, joinedOptions)
 	title := fmt.Sprintf("[configure %s]", q.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(q.sandbox)
 	executor.SetLogPath(q.getLogPath("configure"))
 	executor.SetWorkDir(expr.If(q.BuildInSource, q.PortConfig.SrcDir, q.PortConfig.BuildDir))
 	if err := executor.Execute(); err != nil {
@@ -138,6 +140,7 @@ func (q qmake) Build(options []string) error {
 	// Execute build.
 	title := fmt.Sprintf("[build %s]", q.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, command)
+	executor.SetSandbox(q.sandbox)
 	executor.SetLogPath(q.getLogPath("build"))
 	executor.SetWorkDir(q.PortConfig.BuildDir)
 	if err := executor.Execute(); err != nil {
@@ -151,6 +154,7 @@ func (q qmake) Install(options []string) error {
 	// Execute install.
 	title := fmt.Sprintf("[install %s]", q.PortConfig.nameVersion())
 	executor := cmd.NewExecutor(title, "make install")
+	executor.SetSandbox(q.sandbox)
 	executor.SetLogPath(q.getLogPath("install"))
 	executor.SetWorkDir(q.PortConfig.BuildDir)
 	if err := executor.Execute(); err != nil {
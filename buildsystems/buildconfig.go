@@ -12,6 +12,7 @@ import (
 	"github.com/celer-pkg/celer/context"
 	"github.com/celer-pkg/celer/generator"
 	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgs/arch"
 	pkgcmake "github.com/celer-pkg/celer/pkgs/cmake"
 	"github.com/celer-pkg/celer/pkgs/cmd"
 	"github.com/celer-pkg/celer/pkgs/color"
@@ -21,12 +22,13 @@ import (
 	"github.com/celer-pkg/celer/pkgs/git"
 	"github.com/celer-pkg/celer/pkgs/pc"
 	"github.com/celer-pkg/celer/pkgs/refs"
+	"github.com/celer-pkg/celer/pkgs/relocate"
 )
 
 var (
 	CStandards   = []string{"c90", "c99", "c11", "c17", "c23"}
 	CXXStandards = []string{"c++98", "c++11", "c++14", "c++17", "c++20", "c++23"}
-	buildSystems = []string{"cmake", "makefiles", "meson", "qmake", "b2", "gyp", "bazel", "nobuild", "prebuilt", "python", "custom"}
+	buildSystems = []string{"cmake", "makefiles", "meson", "qmake", "b2", "gyp", "bazel", "cargo", "nobuild", "prebuilt", "python", "custom"}
 )
 
 type PortConfig struct {
@@ -182,7 +184,8 @@ type BuildConfig struct {
 	BuildInSource_Darwin  *bool `toml:"build_in_source_darwin,omitempty"`
 
 	// ApplyEnvs: pass port envs to CMake builds (normally skipped for CMake).
-	// Useful for Rust/Cargo cross-compilation that needs CC/CXX env vars.
+	// Useful for CMake-wrapped Rust/Cargo builds that need CC/CXX env vars,
+	// crates exporting C ABIs can be built with "cargo" build system directly.
 	ApplyEnvs bool `toml:"apply_envs,omitempty"`
 
 	// Autogen Options
@@ -295,6 +298,19 @@ type BuildConfig struct {
 	PortConfig  PortConfig       `toml:"-"`
 	buildSystem buildSystem
 	envBackup   envsBackup
+	sandbox     *cmd.Sandbox // Sandbox of commands in hermetic build, nil for no sandbox.
+
+	// NonRelocatable is the reason why installed package cannot be reused on
+	// other machines, storing it into pkgcache should be skipped.
+	NonRelocatable string `toml:"-"`
+
+	// BuildHash and FromStage make build resumable, stages completed by last build
+	// with the same build hash are skipped, and stages since FromStage are always rerun.
+	BuildHash string `toml:"-"`
+	FromStage string `toml:"-"`
+
+	// StageStats records time and resource usage of stages run by Install.
+	StageStats []StageStats `toml:"-"`
 }
 
 func (b BuildConfig) Validate() error {
@@ -617,6 +633,8 @@ func (b BuildConfig) installOptions() ([]string, error) {
 }
 
 func (b *BuildConfig) Install(url, ref, archive string) error {
+	b.StageStats = nil
+
 	// Setup envs.
 	b.setupEnvs()
 	defer b.rollbackEnvs()
@@ -638,18 +656,40 @@ func (b *BuildConfig) Install(url, ref, archive string) error {
 		}
 	}
 
-	// Apply patches.
-	if err := b.buildSystem.ApplyPatches(); err != nil {
-		return fmt.Errorf("patch %s -> %w", b.PortConfig.nameVersion(), err)
+	point, err := loadCheckpoint(b.PortConfig.BuildDir, b.BuildHash, b.FromStage)
+	if err != nil {
+		return err
 	}
 
-	// Update submodules if exist and not ignored that configured in port.toml.
-	if err := b.buildSystem.UpdateSubmodules(); err != nil {
-		return fmt.Errorf("update submodules %s -> %w", b.PortConfig.nameVersion(), err)
+	// Patch related steps, restart from patch means to reset source to be patched again.
+	if err := b.runStage(point, StagePatch, func() error {
+		if b.FromStage == StagePatch {
+			if err := b.Clean(); err != nil {
+				return fmt.Errorf("clean %s -> %w", b.PortConfig.nameVersion(), err)
+			}
+		}
+		if err := b.buildSystem.ApplyPatches(); err != nil {
+			return fmt.Errorf("patch %s -> %w", b.PortConfig.nameVersion(), err)
+		}
+
+		// Update submodules if exist and not ignored that configured in port.toml.
+		if err := b.buildSystem.UpdateSubmodules(); err != nil {
+			return fmt.Errorf("update submodules %s -> %w", b.PortConfig.nameVersion(), err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	// Configure related steps.
-	if !b.buildSystem.configured() {
+	// Stages since configure don't need network, run them in sandbox if hermetic is enabled.
+	leave := b.enterHermetic()
+	defer leave()
+
+	// Configure related steps, it's skipped when configured unless restarting from it.
+	if err := b.runStage(point, StageConfigure, func() error {
+		if b.FromStage != StageConfigure && b.FromStage != StagePatch && b.buildSystem.configured() {
+			return nil
+		}
 		if err := b.buildSystem.preConfigure(); err != nil {
 			return fmt.Errorf("failed to pre configure %s -> %w", b.PortConfig.nameVersion(), err)
 		}
@@ -663,9 +703,61 @@ func (b *BuildConfig) Install(url, ref, archive string) error {
 		if err := b.buildSystem.postConfigure(); err != nil {
 			return fmt.Errorf("post configure %s -> %w", b.PortConfig.nameVersion(), err)
 		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	// Build related steps.
+	if err := b.runStage(point, StageBuild, b.build); err != nil {
+		return err
+	}
+
+	// Install related steps, package dir may have been removed after last install.
+	if !fileio.PathExists(b.PortConfig.PackageDir) {
+		if err := point.start(StageInstall); err != nil {
+			return fmt.Errorf("failed to save checkpoint -> %w", err)
+		}
+	}
+	if err := b.runStage(point, StageInstall, b.install); err != nil {
+		return err
+	}
+
+	// Fixup and check installed package.
+	if err := b.runStage(point, StageFixups, b.fixups); err != nil {
+		return err
+	}
+
+	// All stages are completed, no need to resume any more.
+	if err := point.clear(); err != nil {
+		return fmt.Errorf("failed to remove checkpoint -> %w", err)
+	}
+
+	return nil
+}
+
+// runStage runs fn for stage, unless it's completed by last build, and records it when done.
+func (b *BuildConfig) runStage(point *checkpoint, stage string, fn func() error) error {
+	if point.done(stage) {
+		color.Printf(color.Hint, "-- skip %s stage of %s, since it's completed by last build.\n", stage, b.PortConfig.nameVersion())
+		return nil
+	}
+
+	if err := point.start(stage); err != nil {
+		return fmt.Errorf("failed to save checkpoint -> %w", err)
+	}
+	stats, err := MeasureStage(stage, fn)
+	b.StageStats = append(b.StageStats, stats)
+	if err != nil {
+		return err
+	}
+	if err := point.finish(stage); err != nil {
+		return fmt.Errorf("failed to save checkpoint -> %w", err)
+	}
+	return nil
+}
+
+func (b *BuildConfig) build() error {
 	if err := b.buildSystem.preBuild(); err != nil {
 		return fmt.Errorf("pre build %s -> %w", b.PortConfig.nameVersion(), err)
 	}
@@ -679,8 +771,10 @@ func (b *BuildConfig) Install(url, ref, archive string) error {
 	if err := b.buildSystem.postBuild(); err != nil {
 		return fmt.Errorf("post build %s -> %w", b.PortConfig.nameVersion(), err)
 	}
+	return nil
+}
 
-	// Install related steps.
+func (b *BuildConfig) install() error {
 	if err := b.buildSystem.preInstall(); err != nil {
 		return fmt.Errorf("pre install %s -> %w", b.PortConfig.nameVersion(), err)
 	}
@@ -694,6 +788,10 @@ func (b *BuildConfig) Install(url, ref, archive string) error {
 	if err := b.buildSystem.postInstall(); err != nil {
 		return fmt.Errorf("post install %s -> %w", b.PortConfig.nameVersion(), err)
 	}
+	return nil
+}
+
+func (b *BuildConfig) fixups() error {
 	if err := b.removeLaFiles(b.PortConfig.PackageDir); err != nil {
 		return fmt.Errorf("remove libtool archives %s -> %w", b.PortConfig.nameVersion(), err)
 	}
@@ -721,6 +819,97 @@ func (b *BuildConfig) Install(url, ref, archive string) error {
 		}
 	}
 
+	// Check binaries and pkg-config files for absolute workspace paths.
+	if err := b.checkRelocatable(); err != nil {
+		return fmt.Errorf("check relocatable %s -> %w", b.PortConfig.nameVersion(), err)
+	}
+
+	// Check binaries are built for the target, but not accidentally for the host.
+	if features == nil || !features.ShouldIgnoreCheckArch() {
+		if err := b.checkArchitecture(); err != nil {
+			return fmt.Errorf("check architecture %s -> %w", b.PortConfig.nameVersion(), err)
+		}
+	}
+
+	return nil
+}
+
+// checkArchitecture checks machine type, bitness and float ABI of ELF and PE/COFF objects
+// in package, dev and host packages are checked against architecture of host.
+func (b *BuildConfig) checkArchitecture() error {
+	var target arch.Target
+	if b.DevDep || b.HostDev {
+		target = arch.HostTarget()
+	} else {
+		toolchain := b.Ctx.Platform().GetToolchain()
+		target = arch.NewTarget(toolchain.GetSystemProcessor(), toolchain.GetHost(), toolchain.GetCFlags())
+	}
+
+	mismatches, err := arch.Check(b.PortConfig.PackageDir, target)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	var details []string
+	for _, mismatch := range mismatches {
+		details = append(details, mismatch.String())
+	}
+	return fmt.Errorf("binaries are not built for %s, please check build_tool and envs of port:\n  - %s",
+		target, strings.Join(details, "\n  - "))
+}
+
+// checkRelocatable reports absolute workspace paths in RPATH/RUNPATH and embedded strings
+// of ELF files, and in pkg-config files, RPATH/RUNPATH can be rewritten to be relative to
+// $ORIGIN with feature `fix_elf_rpath`.
+func (b *BuildConfig) checkRelocatable() error {
+	packageDir := b.PortConfig.PackageDir
+
+	features := b.Ctx.Features()
+	if features != nil && features.ShouldFixELFRPath() {
+		prefixes := []string{
+			packageDir,
+			filepath.Join(dirs.TmpDepsDir, b.PortConfig.LibraryDir),
+			filepath.Join(dirs.TmpDepsDir, b.PortConfig.HostName+"-dev"),
+			filepath.Join(dirs.InstalledDir, b.PortConfig.LibraryDir),
+			filepath.Join(dirs.InstalledDir, b.PortConfig.HostName+"-dev"),
+		}
+		fixed, err := relocate.FixELFRPaths(packageDir, dirs.WorkspaceDir, prefixes)
+		if err != nil {
+			return err
+		}
+		for _, file := range fixed {
+			color.Printf(color.Hint, "-- rpath is relocated: %s\n", filepath.ToSlash(file))
+		}
+	}
+
+	elfFindings, err := relocate.CheckELFAbsPaths(packageDir, dirs.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	pcFindings, err := relocate.CheckPkgConfigAbsPaths(packageDir, dirs.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+	laFindings, err := relocate.CheckLibtoolAbsPaths(packageDir, dirs.WorkspaceDir)
+	if err != nil {
+		return err
+	}
+
+	var blocking int
+	for _, finding := range slices.Concat(elfFindings, pcFindings, laFindings) {
+		if finding.Blocking() {
+			blocking++
+		}
+		color.Printf(color.Warning, "-- absolute workspace path: %s\n", finding)
+	}
+	if blocking > 0 {
+		b.NonRelocatable = fmt.Sprintf("%d absolute workspace path(s) found in RPATH/RUNPATH, pkg-config or libtool files", blocking)
+		color.PrintWarning("%s is not relocatable, %s.", b.PortConfig.nameVersion(), b.NonRelocatable)
+	}
+
 	return nil
 }
 
@@ -771,6 +960,7 @@ func (b *BuildConfig) generateCMakeConfig() error {
 		args = append(args, fmt.Sprintf("-DCMAKE_TOOLCHAIN_FILE=%s/toolchain_file.cmake", dirs.WorkspaceDir))
 	}
 	exec := cmd.NewExecutor(title, "cmake", args...)
+	exec.SetSandbox(b.sandbox)
 	exec.SetWorkDir(b.PortConfig.PackageDir)
 	exec.SetLogPath(b.getLogPath("cmake-config-configure"))
 	if err := exec.Execute(); err != nil {
@@ -779,6 +969,7 @@ func (b *BuildConfig) generateCMakeConfig() error {
 
 	// cmake build.
 	exec = cmd.NewExecutor(title, "cmake", "--build", buildDir)
+	exec.SetSandbox(b.sandbox)
 	exec.SetWorkDir(b.PortConfig.PackageDir)
 	exec.SetLogPath(b.getLogPath("cmake-config-build"))
 	if err := exec.Execute(); err != nil {
@@ -787,6 +978,7 @@ func (b *BuildConfig) generateCMakeConfig() error {
 
 	// cmake install.
 	exec = cmd.NewExecutor(title, "cmake", "--install", buildDir)
+	exec.SetSandbox(b.sandbox)
 	exec.SetWorkDir(b.PortConfig.PackageDir)
 	exec.SetLogPath(b.getLogPath("cmake-config-install"))
 	if err := exec.Execute(); err != nil {
@@ -817,6 +1009,8 @@ func (b *BuildConfig) InitBuildSystem() error {
 		b.buildSystem = NewQMake(b)
 	case "bazel":
 		b.buildSystem = NewBazel(b)
+	case "cargo":
+		b.buildSystem = NewCargo(b)
 	case "prebuilt":
 		b.buildSystem = NewPrebuilt(b)
 	case "nobuild":
@@ -0,0 +1,121 @@
+package buildsystems
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/cmd"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// hermeticEnvs are environment variables passed to commands in hermetic build,
+// names ending with "*" are prefixes.
+var hermeticEnvs = []string{
+	// Basic runtime of shell and tools.
+	"PATH", "HOME", "USER", "SHELL", "TERM", "LANG", "LC_ALL", "TZ", "TMPDIR", "SOURCE_DATE_EPOCH",
+
+	// Toolchain.
+	"CC", "CXX", "CPP", "FC", "AR", "AS", "LD", "NM", "OBJCOPY", "OBJDUMP", "RANLIB", "READELF", "STRIP",
+	"HOST", "CROSSTOOL_PREFIX", "SYSROOT", "QNX_*",
+
+	// Flags and search paths.
+	"CFLAGS", "CXXFLAGS", "CPPFLAGS", "LDFLAGS", "CPATH", "LIBRARY_PATH", "LD_LIBRARY_PATH",
+	"PKG_CONFIG_*", "ACLOCAL_PATH", "AUTOCONF", "MAKEFLAGS",
+
+	// Build tools.
+	"CCACHE_*", "PYTHON*", "VIRTUAL_ENV", "CARGO_*", "RUSTUP_*", "RUSTFLAGS", "USE_BAZEL_VERSION",
+}
+
+// hostDevDirs are host dirs of headers, libraries and their metadata, they're hidden
+// in hermetic build to avoid leaking host headers and libraries into cross build.
+var hostDevDirs = []string{
+	"/usr/include", "/usr/local/include",
+	"/usr/local/lib", "/usr/local/lib64",
+	"/usr/lib/pkgconfig", "/usr/lib64/pkgconfig", "/usr/share/pkgconfig", "/usr/lib/*/pkgconfig",
+	"/usr/lib/cmake", "/usr/lib64/cmake", "/usr/lib/*/cmake",
+}
+
+// enterHermetic runs the following commands of build in sandbox when hermetic is enabled,
+// with allowlisted environment variables, no network, rootfs and toolchain mounted read-only,
+// and host headers and libraries hidden when building with rootfs.
+func (b *BuildConfig) enterHermetic() (leave func()) {
+	features := b.Ctx.Features()
+	if features == nil || !features.ShouldBuildHermetic() {
+		return func() {}
+	}
+	if runtime.GOOS != "linux" {
+		color.PrintWarning("hermetic build is only supported on linux, %s is built without sandbox.", b.PortConfig.nameVersion())
+		return func() {}
+	}
+
+	var readOnlyDirs []string
+	var withRootFS bool
+	if rootfs := b.Ctx.Platform().GetRootFS(); rootfs != nil && fileio.PathExists(rootfs.GetAbsDir()) {
+		readOnlyDirs = append(readOnlyDirs, rootfs.GetAbsDir())
+		withRootFS = true
+	}
+	if toolchain := b.Ctx.Platform().GetToolchain(); toolchain != nil && fileio.PathExists(toolchain.GetRootDir()) {
+		readOnlyDirs = append(readOnlyDirs, toolchain.GetRootDir())
+	}
+
+	// Host headers and libraries are expected for dev and host build.
+	var hiddenDirs []string
+	if withRootFS && !b.DevDep && !b.HostDev {
+		hiddenDirs = hiddenHostDirs(readOnlyDirs)
+	}
+
+	b.sandbox = &cmd.Sandbox{
+		AllowEnv:     b.allowHermeticEnv,
+		ReadOnlyDirs: readOnlyDirs,
+		HiddenDirs:   hiddenDirs,
+	}
+	return func() { b.sandbox = nil }
+}
+
+// hiddenHostDirs returns existing host dev dirs, except the ones overlapping with
+// rootfs or toolchain, which must be kept visible.
+func hiddenHostDirs(keepDirs []string) []string {
+	var hiddenDirs []string
+	for _, pattern := range hostDevDirs {
+		matches, _ := filepath.Glob(pattern)
+		for _, dir := range matches {
+			if !fileio.PathExists(dir) || slices.ContainsFunc(keepDirs, func(keepDir string) bool {
+				return isSubPath(keepDir, dir) || isSubPath(dir, keepDir)
+			}) {
+				continue
+			}
+			hiddenDirs = append(hiddenDirs, dir)
+		}
+	}
+	return hiddenDirs
+}
+
+// isSubPath reports whether path is dir itself or inside dir.
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// allowHermeticEnv allows the allowlisted environment variables, and the ones
+// defined in port or set by buildsystem.
+func (b *BuildConfig) allowHermeticEnv(key string) bool {
+	for _, pattern := range hermeticEnvs {
+		if pattern == key {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	if b.envBackup.modifiedEnvs[key] {
+		return true
+	}
+	return slices.ContainsFunc(b.Envs, func(env string) bool {
+		name, _, _ := strings.Cut(env, "=")
+		return strings.TrimSpace(name) == key
+	})
+}
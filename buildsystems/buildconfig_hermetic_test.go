@@ -0,0 +1,36 @@
+package buildsystems
+
+import "testing"
+
+func TestIsSubPath(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		expected  bool
+	}{
+		{"/usr/include", "/usr/include", true},
+		{"/usr/include", "/usr/include/zlib.h", true},
+		{"/usr", "/usr/local/include", true},
+		{"/usr/local/include", "/usr/local", false},
+		{"/usr/include", "/usr/include2", false},
+	}
+
+	for _, test := range tests {
+		if actual := isSubPath(test.dir, test.path); actual != test.expected {
+			t.Errorf("isSubPath(%q, %q) = %v, want %v", test.dir, test.path, actual, test.expected)
+		}
+	}
+}
+
+func TestHiddenHostDirs_KeepDirs(t *testing.T) {
+	// Toolchain installed in /usr/local must be kept visible.
+	for _, dir := range hiddenHostDirs([]string{"/usr/local"}) {
+		if isSubPath("/usr/local", dir) {
+			t.Errorf("%s contains toolchain and should not be hidden", dir)
+		}
+	}
+
+	// Nothing is hidden when rootfs is extracted into host dir.
+	if dirs := hiddenHostDirs([]string{"/usr"}); len(dirs) > 0 {
+		t.Errorf("dirs overlapping with rootfs should not be hidden, but got %v", dirs)
+	}
+}
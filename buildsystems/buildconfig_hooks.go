@@ -32,6 +32,7 @@ func (b BuildConfig) preConfigure() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -60,6 +61,7 @@ func (b BuildConfig) postConfigure() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -88,6 +90,7 @@ func (b BuildConfig) preBuild() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -115,6 +118,7 @@ func (b BuildConfig) postBuild() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -142,6 +146,7 @@ func (b BuildConfig) preInstall() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -169,6 +174,7 @@ func (b BuildConfig) postInstall() error {
 			return err
 		}
 		executor := cmd.NewExecutor(title, script)
+		executor.SetSandbox(b.sandbox)
 
 		// prebuild port does not have repo dir.
 		if fileio.PathExists(b.PortConfig.RepoDir) {
@@ -81,6 +81,24 @@ func (b *BuildConfig) mergeConfig() {
 	}
 }
 
+// PatchesOf returns patches applied when building for target system,
+// the system-specific patches override the common ones like mergeConfig.
+func (b BuildConfig) PatchesOf(target string) []string {
+	var patches []string
+	switch target {
+	case "windows":
+		patches = b.Patches_Windows
+	case "linux":
+		patches = b.Patches_Linux
+	case "darwin":
+		patches = b.Patches_Darwin
+	}
+	if len(patches) > 0 {
+		return patches
+	}
+	return b.Patches
+}
+
 // getPlatformSuffix returns the platform suffix for field names
 func getPlatformSuffix(target string) string {
 	switch target {
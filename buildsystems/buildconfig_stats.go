@@ -0,0 +1,35 @@
+package buildsystems
+
+import (
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/cmd"
+)
+
+// StageClone is the stage of cloning or downloading source, it runs before building.
+const StageClone = "clone"
+
+// StageStats is the time and resource usage of a stage.
+type StageStats struct {
+	Stage       string  `json:"stage"`
+	WallSeconds float64 `json:"wall_seconds"`
+	CPUSeconds  float64 `json:"cpu_seconds"`      // CPU time of commands run in stage.
+	PeakRSS     int64   `json:"peak_rss_bytes"`   // Max RSS in bytes of a single command, zero if unknown.
+	Failed      bool    `json:"failed,omitempty"` // Stage is interrupted by error.
+}
+
+// MeasureStage runs fn and measures its wall time, and CPU time and peak RSS of the commands it runs.
+func MeasureStage(stage string, fn func() error) (StageStats, error) {
+	startedAt := time.Now()
+	tracker := cmd.TrackUsage()
+	err := fn()
+	usage := tracker.Stop()
+
+	return StageStats{
+		Stage:       stage,
+		WallSeconds: time.Since(startedAt).Seconds(),
+		CPUSeconds:  usage.CPUTime.Seconds(),
+		PeakRSS:     usage.PeakRSS,
+		Failed:      err != nil,
+	}, err
+}
@@ -0,0 +1,147 @@
+package buildsystems
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/fileio"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Stages of building a port from source, in the order they run.
+const (
+	StagePatch     = "patch"
+	StageConfigure = "configure"
+	StageBuild     = "build"
+	StageInstall   = "install"
+	StageFixups    = "fixups"
+)
+
+var Stages = []string{StagePatch, StageConfigure, StageBuild, StageInstall, StageFixups}
+
+// ValidateStage checks stage is one of the build stages, empty stage is valid.
+func ValidateStage(stage string) error {
+	if stage != "" && !slices.Contains(Stages, stage) {
+		return fmt.Errorf("unknown stage %q, it should be one of: %s", stage, strings.Join(Stages, ", "))
+	}
+	return nil
+}
+
+// checkpoint records completed stages of the last build, a retried build continues
+// from the first incomplete stage as long as build hash of the port is not changed.
+type checkpoint struct {
+	BuildHash string   `toml:"build_hash"`
+	Stages    []string `toml:"stages"`
+
+	path      string `toml:"-"`
+	fromStage string `toml:"-"`
+}
+
+// checkpointPath returns path of checkpoint file, it's beside build dir like logs,
+// since build dir may be removed during configure.
+func checkpointPath(buildDir string) string {
+	return filepath.Join(filepath.Dir(buildDir), filepath.Base(buildDir)+"-checkpoint.toml")
+}
+
+// RemoveCheckpoint removes checkpoint file of build dir.
+func RemoveCheckpoint(buildDir string) error {
+	if err := os.Remove(checkpointPath(buildDir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// StageCompleted reports whether stage is completed by last build and will be skipped.
+func (b BuildConfig) StageCompleted(stage string) (bool, error) {
+	point, err := loadCheckpoint(b.PortConfig.BuildDir, b.BuildHash, b.FromStage)
+	if err != nil {
+		return false, err
+	}
+	return point.done(stage), nil
+}
+
+// loadCheckpoint reads checkpoint of build dir, stages recorded with another build hash are dropped.
+// Stages since fromStage are always rerun.
+func loadCheckpoint(buildDir, buildHash, fromStage string) (*checkpoint, error) {
+	point := checkpoint{
+		path:      checkpointPath(buildDir),
+		fromStage: fromStage,
+	}
+
+	if buildHash != "" && fileio.PathExists(point.path) {
+		var recorded checkpoint
+		if _, err := toml.DecodeFile(point.path, &recorded); err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint %s -> %w", point.path, err)
+		}
+		if recorded.BuildHash == buildHash {
+			point.Stages = recorded.Stages
+		}
+	}
+	point.BuildHash = buildHash
+
+	return &point, nil
+}
+
+// done reports whether stage is completed by last build and can be skipped.
+func (c checkpoint) done(stage string) bool {
+	if c.BuildHash == "" || !slices.Contains(c.Stages, stage) {
+		return false
+	}
+	if c.fromStage != "" && slices.Index(Stages, stage) >= slices.Index(Stages, c.fromStage) {
+		return false
+	}
+
+	// All stages before it must be completed as well.
+	for _, previous := range Stages[:slices.Index(Stages, stage)] {
+		if !slices.Contains(c.Stages, previous) {
+			return false
+		}
+	}
+	return true
+}
+
+// start drops records of stage and all stages after it, since they're going to be rerun.
+func (c *checkpoint) start(stage string) error {
+	index := slices.Index(Stages, stage)
+	c.Stages = slices.DeleteFunc(c.Stages, func(recorded string) bool {
+		return slices.Index(Stages, recorded) >= index
+	})
+	return c.save()
+}
+
+// finish records stage as completed.
+func (c *checkpoint) finish(stage string) error {
+	if !slices.Contains(c.Stages, stage) {
+		c.Stages = append(c.Stages, stage)
+	}
+	return c.save()
+}
+
+// clear removes checkpoint once all stages are completed.
+func (c *checkpoint) clear() error {
+	c.Stages = nil
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c checkpoint) save() error {
+	// Build without build hash is not resumable.
+	if c.BuildHash == "" {
+		return nil
+	}
+
+	bytes, err := toml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := fileio.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(c.path, bytes, os.ModePerm)
+}
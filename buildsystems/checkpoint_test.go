@@ -0,0 +1,82 @@
+package buildsystems
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	buildDir := filepath.Join(t.TempDir(), "zlib@1.3.1", "x86_64-linux-release")
+
+	// Last build failed in build stage.
+	point, err := loadCheckpoint(buildDir, "hash1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stage := range []string{StagePatch, StageConfigure} {
+		if err := point.start(stage); err != nil {
+			t.Fatal(err)
+		}
+		if err := point.finish(stage); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := point.start(StageBuild); err != nil {
+		t.Fatal(err)
+	}
+
+	expectDone := func(point *checkpoint, expected map[string]bool) {
+		t.Helper()
+		for _, stage := range Stages {
+			if point.done(stage) != expected[stage] {
+				t.Errorf("done(%s) = %v, want %v", stage, point.done(stage), expected[stage])
+			}
+		}
+	}
+
+	// Retry with the same build hash continues from build stage.
+	point, err = loadCheckpoint(buildDir, "hash1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectDone(point, map[string]bool{StagePatch: true, StageConfigure: true})
+
+	// Stages since --from-stage are rerun.
+	point, err = loadCheckpoint(buildDir, "hash1", StageConfigure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectDone(point, map[string]bool{StagePatch: true})
+
+	// Port changed, nothing can be resumed.
+	point, err = loadCheckpoint(buildDir, "hash2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectDone(point, nil)
+
+	// Checkpoint is removed when all stages are completed.
+	point, err = loadCheckpoint(buildDir, "hash1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := point.clear(); err != nil {
+		t.Fatal(err)
+	}
+	point, err = loadCheckpoint(buildDir, "hash1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectDone(point, nil)
+}
+
+func TestCheckpoint_ValidateStage(t *testing.T) {
+	for _, stage := range append([]string{""}, Stages...) {
+		if err := ValidateStage(stage); err != nil {
+			t.Errorf("stage %q should be valid, but got %s", stage, err)
+		}
+	}
+	if err := ValidateStage("link"); err == nil {
+		t.Error("stage link should be invalid")
+	}
+}
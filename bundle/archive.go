@@ -0,0 +1,163 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// writeFile creates file with write, and removes it on failure.
+func writeFile(filePath string, write func(writer io.Writer) error) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s -> %w", filePath, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(filePath)
+		return fmt.Errorf("failed to write %s -> %w", filePath, err)
+	}
+	return file.Close()
+}
+
+func writeTarGz(filePath, rootDir string, entries []entry) error {
+	return writeFile(filePath, func(writer io.Writer) error {
+		return writeTar(writer, rootDir, entries)
+	})
+}
+
+// writeTar writes entries under rootDir into gzipped tar, parent dirs are
+// written before their files.
+func writeTar(writer io.Writer, rootDir string, entries []entry) error {
+	gzipWriter := gzip.NewWriter(writer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	now := time.Now()
+	for _, dir := range parentDirs(rootDir, entries) {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir + "/",
+			Mode:     0755,
+			ModTime:  now,
+			Uname:    "root",
+			Gname:    "root",
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:    rootDir + "/" + entry.path,
+			Mode:    int64(entry.mode.Perm()),
+			ModTime: entry.modTime,
+			Uname:   "root",
+			Gname:   "root",
+		}
+		if entry.link != "" {
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = entry.link
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			continue
+		}
+
+		header.Typeflag = tar.TypeReg
+		header.Size = entry.size
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyEntry(tarWriter, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func writeZip(filePath, rootDir string, entries []entry) error {
+	return writeFile(filePath, func(writer io.Writer) error {
+		zipWriter := zip.NewWriter(writer)
+		for _, entry := range entries {
+			header := &zip.FileHeader{
+				Name:     rootDir + "/" + entry.path,
+				Method:   zip.Deflate,
+				Modified: entry.modTime,
+			}
+
+			// Symlink is stored as a file of its target with symlink mode.
+			if entry.link != "" {
+				header.SetMode(fs.ModeSymlink | 0777)
+				fileWriter, err := zipWriter.CreateHeader(header)
+				if err != nil {
+					return err
+				}
+				if _, err := io.WriteString(fileWriter, entry.link); err != nil {
+					return err
+				}
+				continue
+			}
+
+			header.SetMode(entry.mode.Perm())
+			fileWriter, err := zipWriter.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			if err := copyEntry(fileWriter, entry); err != nil {
+				return err
+			}
+		}
+		return zipWriter.Close()
+	})
+}
+
+func copyEntry(writer io.Writer, entry entry) error {
+	reader, err := entry.open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	written, err := io.Copy(writer, reader)
+	if err != nil {
+		return err
+	}
+	if written != entry.size {
+		return fmt.Errorf("%s is changed while bundling", entry.path)
+	}
+	return nil
+}
+
+// parentDirs returns sorted dirs of entries under rootDir, including rootDir
+// and its parents, they're joined without cleaning, so "./" is kept.
+func parentDirs(rootDir string, entries []entry) []string {
+	added := make(map[string]bool)
+	for _, entry := range entries {
+		name := rootDir + "/" + entry.path
+		for index := strings.LastIndex(name, "/"); index > 0; index = strings.LastIndex(name, "/") {
+			name = name[:index]
+			if added[name] {
+				break
+			}
+			added[name] = true
+		}
+	}
+
+	var dirs []string
+	for dir := range added {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
@@ -0,0 +1,284 @@
+package bundle
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+)
+
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+	FormatDeb   = "deb"
+	FormatRPM   = "rpm"
+
+	ProfileRuntime = "runtime"
+	ProfileSDK     = "sdk"
+)
+
+var (
+	// Formats are all supported bundle formats.
+	Formats = []string{FormatTarGz, FormatZip, FormatDeb, FormatRPM}
+
+	// Profiles are all supported bundle profiles.
+	Profiles = []string{ProfileRuntime, ProfileSDK}
+)
+
+// metadataDir contains manifest and snapshot report in bundle.
+const metadataDir = "share/celer"
+
+// Options of bundle, empty fields are filled with `[bundle]` of project.
+type Options struct {
+	Format  string
+	Profile string
+	Version string
+	Output  string
+}
+
+// File is an installed file selected into bundle.
+type File struct {
+	Path   string // Relative to installed dir with "/", like "lib/libz.so".
+	Source string // Absolute path in installed dir.
+	Port   string // name@version of the port that installed it.
+}
+
+// Bundle is the assembled content of a bundle.
+type Bundle struct {
+	Name        string // Package name, like "project-test" or "project-test-dev".
+	Version     string
+	Profile     string
+	Maintainer  string
+	Description string
+	License     string // Licenses of bundled ports joined by "AND".
+	Prefix      string // Install prefix of deb and rpm.
+	SystemName  string
+	Processor   string
+	Requires    string // Package required by sdk of deb and rpm.
+}
+
+// ValidateFormat checks whether format is supported.
+func ValidateFormat(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("unsupported bundle format %q, it should be one of %v", format, Formats)
+	}
+	return nil
+}
+
+// ValidateProfile checks whether profile is supported.
+func ValidateProfile(profile string) error {
+	if !slices.Contains(Profiles, profile) {
+		return fmt.Errorf("unsupported bundle profile %q, it should be one of %v", profile, Profiles)
+	}
+	return nil
+}
+
+// Create assembles deployed files of current project and writes bundle into
+// output dir, it returns the bundle path.
+func Create(ctx context.Context, config configs.Bundle, options Options) (string, error) {
+	projectName := ctx.Project().GetName()
+	toolchain := ctx.Platform().GetToolchain()
+	bundle := Bundle{
+		Name:        packageName(projectName),
+		Version:     expr.If(options.Version != "", options.Version, expr.If(config.Version != "", config.Version, "1.0.0")),
+		Profile:     options.Profile,
+		Maintainer:  expr.If(config.Maintainer != "", config.Maintainer, "celer <celer@localhost>"),
+		Description: expr.If(config.Description != "", config.Description, fmt.Sprintf("%s deployed by celer.", projectName)),
+		Prefix:      expr.If(config.Prefix != "", config.Prefix, "/opt/"+projectName),
+		SystemName:  strings.ToLower(toolchain.GetSystemName()),
+		Processor:   toolchain.GetSystemProcessor(),
+	}
+	if err := configs.ValidateBundleVersion(bundle.Version); err != nil {
+		return "", err
+	}
+	if (options.Format == FormatDeb || options.Format == FormatRPM) && bundle.SystemName != "linux" {
+		return "", fmt.Errorf("%s is only available for linux, but platform is %s", options.Format, bundle.SystemName)
+	}
+
+	// Collect files of profile, sdk of deb and rpm contains only development files,
+	// and requires runtime package, so that they can be installed together.
+	libraryDir := ctx.LibraryFolder()
+	custom := expr.If(options.Profile == ProfileSDK, config.SDK, config.Runtime)
+	selected, err := newRules(options.Profile, custom)
+	if err != nil {
+		return "", err
+	}
+	files, err := collectFiles(libraryDir, selected)
+	if err != nil {
+		return "", err
+	}
+	if options.Profile == ProfileSDK && (options.Format == FormatDeb || options.Format == FormatRPM) {
+		runtime, err := newRules(ProfileRuntime, config.Runtime)
+		if err != nil {
+			return "", err
+		}
+		files = slices.DeleteFunc(files, func(file File) bool {
+			return runtime.selected(file.Path)
+		})
+		bundle.Requires = bundle.Name
+		bundle.Name += expr.If(options.Format == FormatDeb, "-dev", "-devel")
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no file of %s is selected by %s profile, please deploy it first", projectName, options.Profile)
+	}
+
+	// Embed manifest and snapshot report.
+	entries, err := fileEntries(files)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := newManifest(ctx, bundle, options.Format, entries)
+	if err != nil {
+		return "", err
+	}
+	bundle.License = manifest.License()
+	snapshotPath, err := findSnapshot(projectName, ctx.Platform().GetName(), ctx.BuildType())
+	if err != nil {
+		return "", err
+	}
+	snapshot, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read snapshot report -> %w", err)
+	}
+	metadata, err := metadataEntries(manifest, snapshot)
+	if err != nil {
+		return "", err
+	}
+	entries = append(entries, metadata...)
+
+	if err := os.MkdirAll(options.Output, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create bundle dir -> %w", err)
+	}
+	platformName := expr.If(ctx.Platform().GetName() != "", ctx.Platform().GetName(), "native")
+	switch options.Format {
+	case FormatTarGz, FormatZip:
+		rootDir := fmt.Sprintf("%s-%s-%s-%s-%s", projectName, bundle.Version, platformName, ctx.BuildType(), options.Profile)
+		bundlePath := filepath.Join(options.Output, rootDir+"."+options.Format)
+		writer := expr.If(options.Format == FormatTarGz, writeTarGz, writeZip)
+		return bundlePath, writer(bundlePath, rootDir, entries)
+
+	case FormatDeb:
+		bundlePath := filepath.Join(options.Output, fmt.Sprintf("%s_%s_%s.deb", bundle.Name, bundle.Version, debArch(bundle.Processor)))
+		return bundlePath, writeDeb(bundlePath, bundle, entries)
+
+	case FormatRPM:
+		bundlePath := filepath.Join(options.Output, fmt.Sprintf("%s-%s-%s.%s.rpm", bundle.Name, bundle.Version, rpmRelease, rpmArch(bundle.Processor)))
+		return bundlePath, writeRPM(bundlePath, bundle, entries)
+
+	default:
+		return "", ValidateFormat(options.Format)
+	}
+}
+
+// collectFiles reads trace files of ports installed into library dir, and
+// selects files by rules, files are sorted by path.
+func collectFiles(libraryDir string, selected rules) ([]File, error) {
+	tracesDir := filepath.Join(dirs.InstalledDir, "celer", "traces", libraryDir)
+	entries, err := os.ReadDir(tracesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trace files -> %w", err)
+	}
+
+	collected := make(map[string]bool)
+	var files []File
+	for _, entry := range entries {
+		nameVersion, ok := strings.CutSuffix(entry.Name(), ".trace")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		lines, err := readTraceFile(filepath.Join(tracesDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			// Only files inside library dir are bundled, trace lines outside it are dropped,
+			// files already collected from other ports or not selected are skipped as well.
+			relative, ok := strings.CutPrefix(filepath.ToSlash(line), filepath.ToSlash(libraryDir)+"/")
+			if !ok || collected[relative] || !selected.selected(relative) {
+				continue
+			}
+
+			source := filepath.Join(dirs.InstalledDir, line)
+			if _, err := os.Lstat(source); err != nil {
+				return nil, fmt.Errorf("%s of %s is missing, please deploy again -> %w", relative, nameVersion, err)
+			}
+			collected[relative] = true
+			files = append(files, File{Path: relative, Source: source, Port: nameVersion})
+		}
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return files, nil
+}
+
+func readTraceFile(traceFile string) ([]string, error) {
+	file, err := os.Open(traceFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasSuffix(line, ".meta") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s -> %w", traceFile, err)
+	}
+	return lines, nil
+}
+
+// findSnapshot returns the latest snapshot report saved by `celer deploy`, the
+// one saved by matrix of platform and build type is also accepted.
+func findSnapshot(projectName, platformName, buildType string) (string, error) {
+	pattern := regexp.MustCompile(fmt.Sprintf(`^%s(_%s_%s)?_\d{8}_\d{6}\.md$`,
+		regexp.QuoteMeta(projectName), regexp.QuoteMeta(platformName), regexp.QuoteMeta(buildType)))
+
+	var (
+		latest     string
+		latestInfo fs.FileInfo
+	)
+	deploymentsDir := filepath.Join(dirs.InstalledDir, "celer", "deployments")
+	entries, _ := os.ReadDir(deploymentsDir)
+	for _, entry := range entries {
+		if entry.IsDir() || !pattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = filepath.Join(deploymentsDir, entry.Name()), info
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no snapshot report of %s is found in %s, please deploy it first", projectName, deploymentsDir)
+	}
+	return latest, nil
+}
+
+// packageName converts project name into name accepted by deb and rpm.
+func packageName(projectName string) string {
+	return strings.ReplaceAll(strings.ToLower(projectName), "_", "-")
+}
@@ -0,0 +1,398 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+// setupInstalled creates installed files and trace file of zlib in a temp workspace.
+func setupInstalled(t *testing.T) string {
+	t.Helper()
+
+	originalWorkspaceDir := dirs.WorkspaceDir
+	dirs.Init(t.TempDir())
+	t.Cleanup(func() { dirs.Init(originalWorkspaceDir) })
+
+	libraryDir := filepath.Join("x86_64-linux", "project_test", "release")
+	files := map[string]string{
+		"include/zlib.h":                   "header",
+		"lib/libz.a":                       "static",
+		"lib/libz.so.1.3.1":                "shared",
+		"lib/cmake/zlib/zlib-config.cmake": "cmake",
+		"lib/pkgconfig/zlib.pc":            "pc",
+		"bin/minigzip":                     "binary",
+		"share/zlib/data.txt":              "data",
+	}
+	var lines []string
+	for file, content := range files {
+		path := filepath.Join(dirs.InstalledDir, libraryDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, filepath.Join(libraryDir, file))
+	}
+	if err := os.Symlink("libz.so.1.3.1", filepath.Join(dirs.InstalledDir, libraryDir, "lib", "libz.so")); err != nil {
+		t.Fatal(err)
+	}
+	lines = append(lines, filepath.Join(libraryDir, "lib", "libz.so"), "venv-3.10@project_test/site.py")
+
+	traceFile := filepath.Join(dirs.InstalledDir, "celer", "traces", libraryDir, "zlib@1.3.1.trace")
+	if err := os.MkdirAll(filepath.Dir(traceFile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(traceFile, []byte(strings.Join(lines, "\n")), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	return libraryDir
+}
+
+func testEntries(t *testing.T, libraryDir string, profile string) []entry {
+	t.Helper()
+
+	selected, err := newRules(profile, configs.BundleRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := collectFiles(libraryDir, selected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fileEntries(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(entries, dataEntry("share/celer/project-test.snapshot.md", []byte("# Build snapshot\n")))
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		file    string
+		matched bool
+	}{
+		{"*.a", "lib/libz.a", true},
+		{"*.a", "libz.a", true},
+		{"include/**", "include/zlib.h", true},
+		{"include/**", "include/sub/dir/x.h", true},
+		{"include/**", "lib/include/x.h", false},
+		{"lib/**/*.cmake", "lib/cmake/zlib/zlib-config.cmake", true},
+		{"lib/*.so", "lib/sub/libz.so", false},
+		{"/bin/*", "bin/minigzip", true},
+	} {
+		if matched := matchPattern(test.pattern, test.file); matched != test.matched {
+			t.Errorf("%s matching %s should be %t", test.pattern, test.file, test.matched)
+		}
+	}
+
+	if err := configs.ValidateBundlePattern("lib/[.a"); err == nil {
+		t.Error("pattern with unclosed bracket should be invalid")
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	libraryDir := setupInstalled(t)
+
+	collect := func(profile string, custom configs.BundleRules) []string {
+		selected, err := newRules(profile, custom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files, err := collectFiles(libraryDir, selected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, file := range files {
+			if file.Port != "zlib@1.3.1" {
+				t.Errorf("%s should be owned by zlib@1.3.1, but got %s", file.Path, file.Port)
+			}
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	runtime := collect(ProfileRuntime, configs.BundleRules{})
+	expected := []string{"bin/minigzip", "lib/libz.so", "lib/libz.so.1.3.1", "share/zlib/data.txt"}
+	if !slices.Equal(runtime, expected) {
+		t.Errorf("runtime should be %v, but got %v", expected, runtime)
+	}
+
+	sdk := collect(ProfileSDK, configs.BundleRules{})
+	if len(sdk) != 8 || !slices.Contains(sdk, "include/zlib.h") {
+		t.Errorf("sdk should contain all installed files, but got %v", sdk)
+	}
+
+	custom := collect(ProfileRuntime, configs.BundleRules{Include: []string{"include/zlib.h"}, Exclude: []string{"share/**"}})
+	expected = []string{"bin/minigzip", "include/zlib.h", "lib/libz.so", "lib/libz.so.1.3.1"}
+	if !slices.Equal(custom, expected) {
+		t.Errorf("custom rules should select %v, but got %v", expected, custom)
+	}
+}
+
+func TestWriteTarGzAndZip(t *testing.T) {
+	libraryDir := setupInstalled(t)
+	entries := testEntries(t, libraryDir, ProfileRuntime)
+
+	tarPath := filepath.Join(t.TempDir(), "project.tar.gz")
+	if err := writeTarGz(tarPath, "project", entries); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := readTarGz(t, data)
+	if headers["project/lib/libz.so"].Linkname != "libz.so.1.3.1" {
+		t.Errorf("symlink should be kept in tar, but got %+v", headers["project/lib/libz.so"])
+	}
+	if headers["project/bin/minigzip"].Mode&0111 == 0 {
+		t.Error("executable mode should be kept in tar")
+	}
+	if _, ok := headers["project/share/celer/project-test.snapshot.md"]; !ok {
+		t.Error("snapshot report should be embedded in tar")
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "project.zip")
+	if err := writeZip(zipPath, "project", entries); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if file.Name == "project/lib/libz.so" && file.Mode()&os.ModeSymlink == 0 {
+			t.Error("symlink should be kept in zip")
+		}
+	}
+	if !slices.Contains(names, "project/lib/libz.so.1.3.1") || slices.Contains(names, "project/include/zlib.h") {
+		t.Errorf("zip should contain runtime files only, but got %v", names)
+	}
+}
+
+func TestWriteDeb(t *testing.T) {
+	libraryDir := setupInstalled(t)
+	entries := testEntries(t, libraryDir, ProfileSDK)
+
+	bundle := Bundle{
+		Name:        "project-test-dev",
+		Version:     "1.2.0",
+		Maintainer:  "celer <celer@localhost>",
+		Description: "Project test.\nIt's deployed by celer.\n\nAll rights reserved.",
+		Prefix:      "/opt/project_test",
+		Processor:   "aarch64",
+		Requires:    "project-test",
+	}
+	debPath := filepath.Join(t.TempDir(), "project.deb")
+	if err := writeDeb(debPath, bundle, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	members := readAr(t, debPath)
+	if string(members["debian-binary"]) != "2.0\n" {
+		t.Errorf("debian-binary should be 2.0, but got %q", members["debian-binary"])
+	}
+
+	control := readTarGz(t, members["control.tar.gz"])
+	for _, field := range []string{
+		"Package: project-test-dev\n",
+		"Version: 1.2.0\n",
+		"Architecture: arm64\n",
+		"Depends: project-test (= 1.2.0)\n",
+		"Description: Project test.\n It's deployed by celer.\n .\n All rights reserved.\n",
+	} {
+		if !strings.Contains(control["./control"].content, field) {
+			t.Errorf("control should contain %q, but got:\n%s", field, control["./control"].content)
+		}
+	}
+	if !strings.Contains(control["./md5sums"].content, "  opt/project_test/include/zlib.h\n") {
+		t.Errorf("md5sums should list installed files, but got:\n%s", control["./md5sums"].content)
+	}
+
+	data := readTarGz(t, members["data.tar.gz"])
+	for _, name := range []string{"./", "./opt/", "./opt/project_test/include/", "./opt/project_test/include/zlib.h"} {
+		if _, ok := data[name]; !ok {
+			t.Errorf("data should contain %s", name)
+		}
+	}
+}
+
+func TestWriteRPM(t *testing.T) {
+	libraryDir := setupInstalled(t)
+	entries := testEntries(t, libraryDir, ProfileRuntime)
+
+	bundle := Bundle{
+		Name:        "project-test",
+		Version:     "1.2.0",
+		Maintainer:  "celer <celer@localhost>",
+		Description: "Project test.",
+		License:     "Zlib",
+		Prefix:      "/opt/project_test",
+		Processor:   "x86_64",
+	}
+	rpmPath := filepath.Join(t.TempDir(), "project.rpm")
+	if err := writeRPM(rpmPath, bundle, entries); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(rpmPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(data, []byte{0xed, 0xab, 0xee, 0xdb}) {
+		t.Fatal("rpm should start with lead magic")
+	}
+	signature, size := readRPMHeader(t, data[96:], rpmTagHeaderSignatures)
+	offset := 96 + size + (8-size%8)%8
+	header, size := readRPMHeader(t, data[offset:], rpmTagHeaderImmutable)
+	payload := data[offset+size:]
+
+	if got := binary.BigEndian.Uint32(signature[rpmSigTagSize]); int(got) != len(data)-offset {
+		t.Errorf("size in signature should be %d, but got %d", len(data)-offset, got)
+	}
+	if name := strings.TrimRight(string(header[rpmTagName]), "\x00"); name != "project-test" {
+		t.Errorf("name should be project-test, but got %s", name)
+	}
+	baseNames := strings.Split(strings.TrimRight(string(header[rpmTagBaseNames]), "\x00"), "\x00")
+	if !slices.Equal(baseNames, []string{"minigzip", "libz.so", "libz.so.1.3.1", "project-test.snapshot.md", "data.txt"}) {
+		t.Errorf("base names should be sorted by path, but got %v", baseNames)
+	}
+
+	// Payload is gzipped cpio of newc format.
+	gzipReader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpio, err := io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for len(cpio) >= 110 {
+		nameSize, _ := strconv.ParseUint(string(cpio[94:102]), 16, 32)
+		fileSize, _ := strconv.ParseUint(string(cpio[54:62]), 16, 32)
+		name := string(cpio[110 : 110+nameSize-1])
+		names = append(names, name)
+		cpio = cpio[(110+int(nameSize)+3)/4*4:]
+		cpio = cpio[min(len(cpio), (int(fileSize)+3)/4*4):]
+	}
+	if len(names) != 6 || names[0] != "./opt/project_test/bin/minigzip" || names[5] != "TRAILER!!!" {
+		t.Errorf("cpio should contain files in order and trailer, but got %v", names)
+	}
+}
+
+type tarFile struct {
+	tar.Header
+	content string
+}
+
+func readTarGz(t *testing.T, data []byte) map[string]tarFile {
+	t.Helper()
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]tarFile)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = tarFile{Header: *header, content: string(content)}
+	}
+	return files
+}
+
+func readAr(t *testing.T, filePath string) map[string][]byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("!<arch>\n")) {
+		t.Fatal("deb should be ar archive")
+	}
+	data = data[8:]
+
+	members := make(map[string][]byte)
+	for len(data) >= 60 {
+		name := strings.TrimSpace(string(data[:16]))
+		size, err := strconv.Atoi(strings.TrimSpace(string(data[48:58])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		members[name] = data[60 : 60+size]
+		data = data[min(len(data), 60+size+size%2):]
+	}
+	return members
+}
+
+// readRPMHeader returns data of tags in header and size of header.
+func readRPMHeader(t *testing.T, data []byte, regionTag int) (map[int][]byte, int) {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, []byte{0x8e, 0xad, 0xe8, 0x01}) {
+		t.Fatal("rpm header magic is invalid")
+	}
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	storeSize := int(binary.BigEndian.Uint32(data[12:]))
+	store := data[16+count*16 : 16+count*16+storeSize]
+
+	tags := make(map[int][]byte)
+	offsets := make(map[int]int)
+	var ordered []int
+	for index := range count {
+		entry := data[16+index*16:]
+		tag := int(binary.BigEndian.Uint32(entry))
+		offsets[tag] = int(binary.BigEndian.Uint32(entry[8:]))
+		ordered = append(ordered, offsets[tag])
+		if index == 0 && tag != regionTag {
+			t.Fatalf("first tag should be region %d, but got %d", regionTag, tag)
+		}
+	}
+
+	// Data of tag ends where data of next tag starts.
+	slices.Sort(ordered)
+	for tag, offset := range offsets {
+		end := storeSize
+		if index := slices.Index(ordered, offset); index+1 < len(ordered) {
+			end = ordered[index+1]
+		}
+		tags[tag] = store[offset:end]
+	}
+
+	trailer := tags[regionTag]
+	if len(trailer) != 16 || int32(binary.BigEndian.Uint32(trailer[8:])) != int32(-count*16) {
+		t.Fatalf("region trailer should point back to index, but got %v", trailer)
+	}
+	return tags, 16 + count*16 + storeSize
+}
@@ -0,0 +1,135 @@
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/expr"
+)
+
+// debArch converts system processor into architecture of debian.
+func debArch(processor string) string {
+	switch processor {
+	case "x86_64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "i386", "i686", "x86":
+		return "i386"
+	case "arm", "armv7", "armv7l", "armhf":
+		return "armhf"
+	default:
+		return processor
+	}
+}
+
+// writeDeb writes deb, which is an ar archive of debian-binary, control.tar.gz
+// and data.tar.gz, files are installed into prefix.
+func writeDeb(filePath string, bundle Bundle, entries []entry) error {
+	rootDir := "." + strings.TrimSuffix(bundle.Prefix, "/")
+
+	// Data is written into temp file, since size of ar member is written ahead.
+	data, err := os.CreateTemp(filepath.Dir(filePath), ".bundle-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(data.Name())
+	defer data.Close()
+	if err := writeTar(data, rootDir, entries); err != nil {
+		return err
+	}
+	dataSize, err := data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	var control bytes.Buffer
+	controlEntries := []entry{
+		dataEntry("control", []byte(debControl(bundle, entries))),
+		dataEntry("md5sums", []byte(debMD5Sums(rootDir, entries))),
+	}
+	if err := writeTar(&control, ".", controlEntries); err != nil {
+		return err
+	}
+
+	return writeFile(filePath, func(writer io.Writer) error {
+		if _, err := io.WriteString(writer, "!<arch>\n"); err != nil {
+			return err
+		}
+		if err := writeArMember(writer, "debian-binary", strings.NewReader("2.0\n"), 4); err != nil {
+			return err
+		}
+		if err := writeArMember(writer, "control.tar.gz", &control, int64(control.Len())); err != nil {
+			return err
+		}
+		if _, err := data.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return writeArMember(writer, "data.tar.gz", data, dataSize)
+	})
+}
+
+func debControl(bundle Bundle, entries []entry) string {
+	var installedSize int64
+	for _, entry := range entries {
+		installedSize += entry.size
+	}
+
+	var lines []string
+	lines = append(lines, "Package: "+bundle.Name)
+	lines = append(lines, "Version: "+bundle.Version)
+	lines = append(lines, "Architecture: "+debArch(bundle.Processor))
+	lines = append(lines, "Maintainer: "+bundle.Maintainer)
+	lines = append(lines, fmt.Sprintf("Installed-Size: %d", (installedSize+1023)/1024))
+	if bundle.Requires != "" {
+		lines = append(lines, fmt.Sprintf("Depends: %s (= %s)", bundle.Requires, bundle.Version))
+		lines = append(lines, "Section: libdevel")
+	} else {
+		lines = append(lines, "Section: libs")
+	}
+	lines = append(lines, "Priority: optional")
+
+	// Extended description is indented, and empty line is written as ".".
+	summary, extended, _ := strings.Cut(strings.TrimSpace(bundle.Description), "\n")
+	lines = append(lines, "Description: "+strings.TrimSpace(summary))
+	if extended != "" {
+		for line := range strings.SplitSeq(extended, "\n") {
+			line = strings.TrimSpace(line)
+			lines = append(lines, " "+expr.If(line == "", ".", line))
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func debMD5Sums(rootDir string, entries []entry) string {
+	var buffer strings.Builder
+	for _, entry := range entries {
+		if entry.link == "" {
+			fmt.Fprintf(&buffer, "%s  %s\n", entry.md5, strings.TrimPrefix(rootDir+"/"+entry.path, "./"))
+		}
+	}
+	return buffer.String()
+}
+
+// writeArMember writes a member of ar archive in common format, its data is
+// padded to even size.
+func writeArMember(writer io.Writer, name string, reader io.Reader, size int64) error {
+	header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, time.Now().Unix(), 0, 0, "100644", size)
+	if _, err := io.WriteString(writer, header); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(writer, reader, size); err != nil {
+		return err
+	}
+	if size%2 != 0 {
+		if _, err := io.WriteString(writer, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
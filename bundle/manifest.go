@@ -0,0 +1,221 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/context"
+)
+
+// Manifest lists ports and files in bundle, it's embedded as share/celer/<name>.manifest.json.
+type Manifest struct {
+	configs.OutputHeader
+	Project      string         `json:"project"`
+	Name         string         `json:"name"`
+	Version      string         `json:"version"`
+	Profile      string         `json:"profile"`
+	Format       string         `json:"format"`
+	Platform     string         `json:"platform"`
+	BuildType    string         `json:"build_type"`
+	CelerVersion string         `json:"celer_version"`
+	GeneratedAt  string         `json:"generated_at"`
+	Ports        []ManifestPort `json:"ports"`
+	Files        []ManifestFile `json:"files"`
+}
+
+type ManifestPort struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	License string `json:"license,omitempty"`
+	Url     string `json:"url,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Files   int    `json:"files"`
+}
+
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Port   string `json:"port"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+// entry is a file or symlink written into bundle, its content is read from
+// source, or data for generated files.
+type entry struct {
+	path    string
+	source  string
+	data    []byte
+	mode    fs.FileMode
+	link    string
+	size    int64
+	modTime time.Time
+	port    string
+	sha256  string
+	md5     string
+}
+
+func (e entry) open() (io.ReadCloser, error) {
+	if e.source == "" {
+		return io.NopCloser(bytes.NewReader(e.data)), nil
+	}
+	return os.Open(e.source)
+}
+
+// fileEntries stats files and computes their digests.
+func fileEntries(files []File) ([]entry, error) {
+	var entries []entry
+	for _, file := range files {
+		info, err := os.Lstat(file.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := entry{
+			path:    file.Path,
+			source:  file.Source,
+			mode:    info.Mode(),
+			modTime: info.ModTime(),
+			port:    file.Port,
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if entry.link, err = os.Readlink(file.Source); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		entry.size = info.Size()
+		if entry.sha256, entry.md5, err = digest(file.Source); err != nil {
+			return nil, fmt.Errorf("failed to digest %s -> %w", file.Source, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// dataEntry creates entry of generated file.
+func dataEntry(path string, data []byte) entry {
+	sum256, sum5 := sha256.Sum256(data), md5.Sum(data)
+	return entry{
+		path:    path,
+		data:    data,
+		mode:    0644,
+		size:    int64(len(data)),
+		modTime: time.Now(),
+		sha256:  hex.EncodeToString(sum256[:]),
+		md5:     hex.EncodeToString(sum5[:]),
+	}
+}
+
+func digest(filePath string) (sha256Sum, md5Sum string, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	sha256Hash, md5Hash := sha256.New(), md5.New()
+	if _, err := io.Copy(io.MultiWriter(sha256Hash, md5Hash), file); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(sha256Hash.Sum(nil)), hex.EncodeToString(md5Hash.Sum(nil)), nil
+}
+
+// newManifest lists bundled ports with their license and source declared in port.toml.
+func newManifest(ctx context.Context, bundle Bundle, format string, entries []entry) (Manifest, error) {
+	manifest := Manifest{
+		OutputHeader: configs.NewOutputHeader("bundle_manifest"),
+		Project:      ctx.Project().GetName(),
+		Name:         bundle.Name,
+		Version:      bundle.Version,
+		Profile:      bundle.Profile,
+		Format:       format,
+		Platform:     ctx.Platform().GetName(),
+		BuildType:    ctx.BuildType(),
+		CelerVersion: ctx.Version(),
+		GeneratedAt:  time.Now().Format(time.RFC3339),
+		Ports:        []ManifestPort{},
+		Files:        []ManifestFile{},
+	}
+
+	fileCounts := make(map[string]int)
+	for _, entry := range entries {
+		manifest.Files = append(manifest.Files, ManifestFile{
+			Path:   entry.path,
+			Port:   entry.port,
+			Size:   entry.size,
+			SHA256: entry.sha256,
+			Link:   entry.link,
+		})
+		fileCounts[entry.port]++
+	}
+
+	var nameVersions []string
+	for nameVersion := range fileCounts {
+		nameVersions = append(nameVersions, nameVersion)
+	}
+	slices.Sort(nameVersions)
+	for _, nameVersion := range nameVersions {
+		var port configs.Port
+		if err := port.Init(ctx, nameVersion); err != nil {
+			return Manifest{}, fmt.Errorf("failed to init %s -> %w", nameVersion, err)
+		}
+		manifest.Ports = append(manifest.Ports, ManifestPort{
+			Name:    port.Name,
+			Version: port.Version,
+			License: port.Package.License,
+			Url:     port.Package.Url,
+			Ref:     port.Package.Ref,
+			Files:   fileCounts[nameVersion],
+		})
+	}
+
+	return manifest, nil
+}
+
+// License joins distinct licenses of ports as SPDX expression.
+func (m Manifest) License() string {
+	var licenses []string
+	for _, port := range m.Ports {
+		if port.License != "" && !slices.Contains(licenses, port.License) {
+			licenses = append(licenses, port.License)
+		}
+	}
+	if len(licenses) == 0 {
+		return "NOASSERTION"
+	}
+	slices.Sort(licenses)
+	for index, license := range licenses {
+		if strings.Contains(license, " ") {
+			licenses[index] = "(" + license + ")"
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+// metadataEntries are manifest and snapshot report embedded into bundle.
+func metadataEntries(manifest Manifest, snapshot []byte) ([]entry, error) {
+	var buffer bytes.Buffer
+	if err := configs.WriteJSON(&buffer, manifest); err != nil {
+		return nil, err
+	}
+	return []entry{
+		dataEntry(metadataDir+"/"+manifest.Name+".manifest.json", buffer.Bytes()),
+		dataEntry(metadataDir+"/"+manifest.Name+".snapshot.md", snapshot),
+	}, nil
+}
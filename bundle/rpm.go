@@ -0,0 +1,429 @@
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const rpmRelease = "1"
+
+// Types of rpm header entry.
+const (
+	rpmInt16       = 3
+	rpmInt32       = 4
+	rpmString      = 6
+	rpmBin         = 7
+	rpmStringArray = 8
+	rpmI18NString  = 9
+)
+
+// Tags of rpm signature and header.
+const (
+	rpmTagHeaderSignatures = 62
+	rpmTagHeaderImmutable  = 63
+	rpmTagI18NTable        = 100
+
+	rpmSigTagSHA1        = 269
+	rpmSigTagSHA256      = 273
+	rpmSigTagSize        = 1000
+	rpmSigTagMD5         = 1004
+	rpmSigTagPayloadSize = 1007
+
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagBuildHost         = 1007
+	rpmTagSize              = 1009
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRdevs         = 1033
+	rpmTagFileMtimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRPM         = 1044
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagFileDevices       = 1095
+	rpmTagFileInodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+	rpmTagPayloadDigest     = 5092
+	rpmTagPayloadDigestAlgo = 5093
+)
+
+// Flags of rpm dependency.
+const (
+	rpmSenseLess   = 1 << 1
+	rpmSenseEqual  = 1 << 3
+	rpmSenseRPMLib = 1 << 24
+
+	rpmDigestSHA256 = 8
+)
+
+// rpmArch converts system processor into architecture of rpm.
+func rpmArch(processor string) string {
+	switch processor {
+	case "x86_64", "amd64":
+		return "x86_64"
+	case "aarch64", "arm64":
+		return "aarch64"
+	case "i386", "i686", "x86":
+		return "i686"
+	case "arm", "armv7", "armv7l", "armhf":
+		return "armv7hl"
+	default:
+		return processor
+	}
+}
+
+// rpmHeader is the header structure shared by signature and header of rpm.
+type rpmHeader struct {
+	entries []rpmEntry
+}
+
+type rpmEntry struct {
+	tag   int
+	kind  int
+	count int
+	data  []byte
+}
+
+func (r *rpmHeader) addString(tag int, value string) {
+	r.entries = append(r.entries, rpmEntry{tag, rpmString, 1, append([]byte(value), 0)})
+}
+
+func (r *rpmHeader) addI18NString(tag int, value string) {
+	r.entries = append(r.entries, rpmEntry{tag, rpmI18NString, 1, append([]byte(value), 0)})
+}
+
+func (r *rpmHeader) addStrings(tag int, values ...string) {
+	var data []byte
+	for _, value := range values {
+		data = append(append(data, value...), 0)
+	}
+	r.entries = append(r.entries, rpmEntry{tag, rpmStringArray, len(values), data})
+}
+
+func (r *rpmHeader) addInt32(tag int, values ...uint32) {
+	data := make([]byte, 0, len(values)*4)
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint32(data, value)
+	}
+	r.entries = append(r.entries, rpmEntry{tag, rpmInt32, len(values), data})
+}
+
+func (r *rpmHeader) addInt16(tag int, values ...uint16) {
+	data := make([]byte, 0, len(values)*2)
+	for _, value := range values {
+		data = binary.BigEndian.AppendUint16(data, value)
+	}
+	r.entries = append(r.entries, rpmEntry{tag, rpmInt16, len(values), data})
+}
+
+func (r *rpmHeader) addBin(tag int, data []byte) {
+	r.entries = append(r.entries, rpmEntry{tag, rpmBin, len(data), data})
+}
+
+// bytes encodes header, entries are wrapped in an immutable region of regionTag,
+// which is the first entry of index, and its trailer is the last data.
+func (r rpmHeader) bytes(regionTag int) []byte {
+	entries := slices.Clone(r.entries)
+	slices.SortStableFunc(entries, func(a, b rpmEntry) int {
+		return a.tag - b.tag
+	})
+
+	var index, store bytes.Buffer
+	for _, entry := range entries {
+		align := map[int]int{rpmInt16: 2, rpmInt32: 4}[entry.kind]
+		for align > 0 && store.Len()%align != 0 {
+			store.WriteByte(0)
+		}
+		writeInt32s(&index, entry.tag, entry.kind, store.Len(), entry.count)
+		store.Write(entry.data)
+	}
+	trailerOffset := store.Len()
+	writeInt32s(&store, regionTag, rpmBin, -(len(entries)+1)*16, 16)
+
+	var header bytes.Buffer
+	header.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+	writeInt32s(&header, len(entries)+1, store.Len())
+	writeInt32s(&header, regionTag, rpmBin, trailerOffset, 16)
+	header.Write(index.Bytes())
+	header.Write(store.Bytes())
+	return header.Bytes()
+}
+
+func writeInt32s(buffer *bytes.Buffer, values ...int) {
+	for _, value := range values {
+		buffer.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(value))))
+	}
+}
+
+// writeRPM writes rpm with lead, signature, header and gzipped cpio payload,
+// files are installed into prefix.
+func writeRPM(filePath string, bundle Bundle, entries []entry) error {
+	prefix := strings.TrimSuffix(bundle.Prefix, "/")
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.path, b.path)
+	})
+
+	// Payload is written into temp file, since its digest is part of header.
+	payload, err := os.CreateTemp(filepath.Dir(filePath), ".bundle-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(payload.Name())
+	defer payload.Close()
+
+	payloadHash := sha256.New()
+	gzipWriter, _ := gzip.NewWriterLevel(io.MultiWriter(payload, payloadHash), gzip.BestCompression)
+	cpio := &countingWriter{writer: gzipWriter}
+	if err := writeCpio(cpio, prefix, entries); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	payloadSize, err := payload.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	// Header.
+	buildTime := uint32(time.Now().Unix())
+	fullVersion := bundle.Version + "-" + rpmRelease
+	summary, _, _ := strings.Cut(strings.TrimSpace(bundle.Description), "\n")
+
+	var header rpmHeader
+	header.addStrings(rpmTagI18NTable, "C")
+	header.addString(rpmTagName, bundle.Name)
+	header.addString(rpmTagVersion, bundle.Version)
+	header.addString(rpmTagRelease, rpmRelease)
+	header.addI18NString(rpmTagSummary, strings.TrimSpace(summary))
+	header.addI18NString(rpmTagDescription, strings.TrimSpace(bundle.Description))
+	header.addInt32(rpmTagBuildTime, buildTime)
+	header.addString(rpmTagBuildHost, "localhost")
+	header.addString(rpmTagLicense, bundle.License)
+	header.addString(rpmTagPackager, bundle.Maintainer)
+	header.addI18NString(rpmTagGroup, "Unspecified")
+	header.addString(rpmTagOS, "linux")
+	header.addString(rpmTagArch, rpmArch(bundle.Processor))
+	header.addString(rpmTagSourceRPM, fmt.Sprintf("%s-%s.src.rpm", bundle.Name, fullVersion))
+	header.addStrings(rpmTagProvideName, bundle.Name)
+	header.addInt32(rpmTagProvideFlags, rpmSenseEqual)
+	header.addStrings(rpmTagProvideVersion, fullVersion)
+
+	requireNames := []string{"rpmlib(CompressedFileNames)", "rpmlib(FileDigests)", "rpmlib(PayloadFilesHavePrefix)"}
+	requireVersions := []string{"3.0.4-1", "4.6.0-1", "4.0-1"}
+	requireFlags := []uint32{rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual, rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual, rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual}
+	if bundle.Requires != "" {
+		requireNames = append(requireNames, bundle.Requires)
+		requireVersions = append(requireVersions, fullVersion)
+		requireFlags = append(requireFlags, rpmSenseEqual)
+	}
+	header.addStrings(rpmTagRequireName, requireNames...)
+	header.addStrings(rpmTagRequireVersion, requireVersions...)
+	header.addInt32(rpmTagRequireFlags, requireFlags...)
+
+	// Files are referred by dir index and base name.
+	var (
+		totalSize                                   uint32
+		sizes, mtimes, flags, devices, inodes, dirs []uint32
+		modes, rdevs                                []uint16
+		digests, links, users, groups, langs        []string
+		baseNames, dirNames                         []string
+	)
+	for index, entry := range entries {
+		fullPath := prefix + "/" + entry.path
+		dirName := path.Dir(fullPath) + "/"
+		dirIndex := slices.Index(dirNames, dirName)
+		if dirIndex < 0 {
+			dirIndex = len(dirNames)
+			dirNames = append(dirNames, dirName)
+		}
+
+		totalSize += uint32(entry.size)
+		sizes = append(sizes, uint32(entry.size))
+		modes = append(modes, uint16(rpmFileMode(entry)))
+		rdevs = append(rdevs, 0)
+		mtimes = append(mtimes, uint32(entry.modTime.Unix()))
+		digests = append(digests, entry.sha256)
+		links = append(links, entry.link)
+		flags = append(flags, 0)
+		users = append(users, "root")
+		groups = append(groups, "root")
+		devices = append(devices, 1)
+		inodes = append(inodes, uint32(index+1))
+		langs = append(langs, "")
+		dirs = append(dirs, uint32(dirIndex))
+		baseNames = append(baseNames, path.Base(fullPath))
+	}
+	header.addInt32(rpmTagSize, totalSize)
+	header.addInt32(rpmTagFileSizes, sizes...)
+	header.addInt16(rpmTagFileModes, modes...)
+	header.addInt16(rpmTagFileRdevs, rdevs...)
+	header.addInt32(rpmTagFileMtimes, mtimes...)
+	header.addStrings(rpmTagFileDigests, digests...)
+	header.addStrings(rpmTagFileLinkTos, links...)
+	header.addInt32(rpmTagFileFlags, flags...)
+	header.addStrings(rpmTagFileUserName, users...)
+	header.addStrings(rpmTagFileGroupName, groups...)
+	header.addInt32(rpmTagFileDevices, devices...)
+	header.addInt32(rpmTagFileInodes, inodes...)
+	header.addStrings(rpmTagFileLangs, langs...)
+	header.addInt32(rpmTagDirIndexes, dirs...)
+	header.addStrings(rpmTagBaseNames, baseNames...)
+	header.addStrings(rpmTagDirNames, dirNames...)
+	header.addString(rpmTagPayloadFormat, "cpio")
+	header.addString(rpmTagPayloadCompressor, "gzip")
+	header.addString(rpmTagPayloadFlags, "9")
+	header.addInt32(rpmTagFileDigestAlgo, rpmDigestSHA256)
+	header.addStrings(rpmTagPayloadDigest, hex.EncodeToString(payloadHash.Sum(nil)))
+	header.addInt32(rpmTagPayloadDigestAlgo, rpmDigestSHA256)
+	headerBytes := header.bytes(rpmTagHeaderImmutable)
+
+	// Signature covers header and payload.
+	headerSHA1, headerSHA256 := sha1.Sum(headerBytes), sha256.Sum256(headerBytes)
+	md5Hash := md5.New()
+	md5Hash.Write(headerBytes)
+	if _, err := payload.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(md5Hash, payload); err != nil {
+		return err
+	}
+
+	var signature rpmHeader
+	signature.addString(rpmSigTagSHA1, hex.EncodeToString(headerSHA1[:]))
+	signature.addString(rpmSigTagSHA256, hex.EncodeToString(headerSHA256[:]))
+	signature.addInt32(rpmSigTagSize, uint32(int64(len(headerBytes))+payloadSize))
+	signature.addBin(rpmSigTagMD5, md5Hash.Sum(nil))
+	signature.addInt32(rpmSigTagPayloadSize, uint32(cpio.count))
+	signatureBytes := signature.bytes(rpmTagHeaderSignatures)
+
+	return writeFile(filePath, func(writer io.Writer) error {
+		for _, data := range [][]byte{
+			rpmLead(bundle.Name + "-" + fullVersion),
+			signatureBytes,
+			make([]byte, (8-len(signatureBytes)%8)%8),
+			headerBytes,
+		} {
+			if _, err := writer.Write(data); err != nil {
+				return err
+			}
+		}
+		if _, err := payload.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err := io.Copy(writer, payload)
+		return err
+	})
+}
+
+// rpmLead is the legacy lead of binary rpm, it's ignored by rpm except the magic.
+func rpmLead(name string) []byte {
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0})
+	binary.BigEndian.PutUint16(lead[6:], 0) // binary
+	binary.BigEndian.PutUint16(lead[8:], 1) // archnum
+	copy(lead[10:75], name)
+	binary.BigEndian.PutUint16(lead[76:], 1) // linux
+	binary.BigEndian.PutUint16(lead[78:], 5) // header style signature
+	return lead
+}
+
+func rpmFileMode(entry entry) uint32 {
+	if entry.link != "" {
+		return 0120777
+	}
+	return 0100000 | uint32(entry.mode.Perm())
+}
+
+// writeCpio writes entries into cpio of newc format, names are prefixed with ".".
+func writeCpio(writer *countingWriter, prefix string, entries []entry) error {
+	pad := func() error {
+		_, err := writer.Write(make([]byte, (4-writer.count%4)%4))
+		return err
+	}
+	writeHeader := func(name string, inode, mode uint32, size int64, mtime uint32) error {
+		if _, err := fmt.Fprintf(writer, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			inode, mode, 0, 0, 1, mtime, size, 0, 0, 0, 0, len(name)+1, 0); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, name+"\x00"); err != nil {
+			return err
+		}
+		return pad()
+	}
+
+	for index, entry := range entries {
+		name := "." + prefix + "/" + entry.path
+		mtime := uint32(entry.modTime.Unix())
+		if entry.link != "" {
+			if err := writeHeader(name, uint32(index+1), rpmFileMode(entry), int64(len(entry.link)), mtime); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(writer, entry.link); err != nil {
+				return err
+			}
+		} else {
+			if err := writeHeader(name, uint32(index+1), rpmFileMode(entry), entry.size, mtime); err != nil {
+				return err
+			}
+			if err := copyEntry(writer, entry); err != nil {
+				return err
+			}
+		}
+		if err := pad(); err != nil {
+			return err
+		}
+	}
+	return writeHeader("TRAILER!!!", 0, 0, 0, 0)
+}
+
+// countingWriter counts written bytes, which is required to align cpio.
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (c *countingWriter) Write(data []byte) (int, error) {
+	written, err := c.writer.Write(data)
+	c.count += int64(written)
+	return written, err
+}
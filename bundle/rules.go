@@ -0,0 +1,91 @@
+package bundle
+
+import (
+	"path"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+)
+
+// defaultRules are files selected by profiles, runtime drops headers, static
+// libraries and build files, sdk keeps everything installed.
+var defaultRules = map[string]configs.BundleRules{
+	ProfileRuntime: {
+		Exclude: []string{
+			"include/**",
+			"lib/cmake/**", "lib64/cmake/**", "share/cmake/**",
+			"lib/pkgconfig/**", "lib64/pkgconfig/**", "share/pkgconfig/**",
+			"share/aclocal/**", "share/doc/**", "share/man/**", "share/info/**",
+			"*.a", "*.la", "*.lib", "*.prl", "*.cmake", "*.pc",
+		},
+	},
+	ProfileSDK: {},
+}
+
+// rules combines default rules of profile with custom rules in project.
+type rules struct {
+	include []string
+	exclude []string
+}
+
+func newRules(profile string, custom configs.BundleRules) (rules, error) {
+	defaults := defaultRules[profile]
+	combined := rules{
+		include: append(append([]string{}, defaults.Include...), custom.Include...),
+		exclude: append(append([]string{}, defaults.Exclude...), custom.Exclude...),
+	}
+	for _, pattern := range append(combined.include, combined.exclude...) {
+		if err := configs.ValidateBundlePattern(pattern); err != nil {
+			return rules{}, err
+		}
+	}
+	return combined, nil
+}
+
+// selected reports whether file relative to installed dir should be bundled.
+func (r rules) selected(file string) bool {
+	for _, pattern := range r.include {
+		if matchPattern(pattern, file) {
+			return true
+		}
+	}
+	for _, pattern := range r.exclude {
+		if matchPattern(pattern, file) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPattern matches file with pattern, pattern without "/" matches file name
+// in any dir, and "**" matches zero or more dirs.
+func matchPattern(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+
+	if patterns[0] == "**" {
+		for index := 0; index <= len(segments); index++ {
+			if matchSegments(patterns[1:], segments[index:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(patterns[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
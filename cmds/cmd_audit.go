@@ -0,0 +1,213 @@
+package cmds
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/audit"
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/snapshot"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	auditFailOnAny  = "any"
+	auditFailOnNone = "none"
+)
+
+type auditCmd struct {
+	celer    *configs.Celer
+	database string
+	failOn   string
+	format   string
+}
+
+// auditOutput is the JSON schema of `celer audit --format=json`.
+type auditOutput struct {
+	configs.OutputHeader
+	Project  string          `json:"project"`
+	Database string          `json:"database"`
+	Ports    int             `json:"ports"`
+	Findings []audit.Finding `json:"findings"`
+}
+
+func (a *auditCmd) Command(celer *configs.Celer) *cobra.Command {
+	a.celer = celer
+	command := &cobra.Command{
+		Use:   "audit",
+		Short: "Check ports of current project against known vulnerabilities.",
+		Long: `Check ports of current project against known vulnerabilities.
+
+This command collects all ports of current project and their dependencies,
+then matches them against a local advisory database in OSV format, which is
+kept in conf repo (conf/advisories) by default. It works fully offline.
+
+A port is matched by its name, or by "purl" and "cpe" declared in [package]
+of port.toml when they're provided. The command exits with non-zero when
+any finding reaches the severity of --fail-on, so it can gate CI.
+
+Examples:
+  celer audit                          # Audit with conf/advisories
+  celer audit --db=osv/all.json        # Audit with another advisory file or dir
+  celer audit --fail-on=high           # Only fail on high and critical findings
+  celer audit --format=json            # Print findings as json`,
+		Args: a.validateArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.audit()
+		},
+		ValidArgsFunction: a.completion,
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&a.database, "db", "", "Advisory file or dir in OSV format, default is conf/advisories.")
+	flags.StringVar(&a.failOn, "fail-on", auditFailOnAny, "Lowest severity to fail: any, low, medium, high, critical or none.")
+	flags.StringVar(&a.format, "format", configs.OutputFormatText, "Output format, text or json.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (a *auditCmd) validateArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		return err
+	}
+
+	failOn, err := cmd.Flags().GetString("fail-on")
+	if err != nil {
+		return err
+	}
+	if failOn != auditFailOnAny && failOn != auditFailOnNone && audit.SeverityRank(failOn) <= 0 {
+		return fmt.Errorf("unsupported --fail-on %q, it should be any, low, medium, high, critical or none", failOn)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	return configs.ValidateOutputFormat(format)
+}
+
+func (a *auditCmd) audit() error {
+	if err := a.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to initialize celer.")
+	}
+
+	projectName := a.celer.Project().GetName()
+	if projectName == "" {
+		return color.PrintError(fmt.Errorf("no project is selected"), "failed to audit project.")
+	}
+
+	database := strings.TrimSpace(a.database)
+	if database == "" {
+		database = dirs.AdvisoriesDir
+	}
+	advisories, err := audit.Load(database)
+	if err != nil {
+		return color.PrintError(err, "failed to load advisory database.")
+	}
+
+	// Audit the same ports as what snapshot exports.
+	collector := snapshot.NewCollector(a.celer)
+	usedPorts, err := collector.CollectUsedPorts(a.celer)
+	if err != nil {
+		return color.PrintError(err, "failed to collect ports of %s.", projectName)
+	}
+	var ports []configs.Port
+	for _, port := range usedPorts {
+		ports = append(ports, *port)
+	}
+	slices.SortFunc(ports, func(x, y configs.Port) int {
+		return strings.Compare(x.NameVersion(), y.NameVersion())
+	})
+
+	findings := audit.Check(ports, advisories)
+	failed := slices.ContainsFunc(findings, func(finding audit.Finding) bool {
+		return a.shouldFail(finding.Severity)
+	})
+
+	if a.format == configs.OutputFormatJSON {
+		if err := configs.PrintJSON(auditOutput{
+			OutputHeader: configs.NewOutputHeader("audit"),
+			Project:      projectName,
+			Database:     database,
+			Ports:        len(ports),
+			Findings:     findings,
+		}); err != nil {
+			return err
+		}
+		if failed {
+			return color.ErrSilent
+		}
+		return nil
+	}
+
+	title := fmt.Sprintf("\nAuditing %d port(s) of %s with %d advisory(s) in %s", len(ports), projectName, len(advisories), database)
+	color.Println(color.Title, title)
+	color.Println(color.Line, strings.Repeat("-", len(title)))
+
+	if len(findings) == 0 {
+		color.PrintSuccess("no known vulnerability is found.")
+		return nil
+	}
+
+	for _, finding := range findings {
+		severity := strings.ToUpper(finding.Severity)
+		if finding.Score > 0 {
+			severity = fmt.Sprintf("%s %.1f", severity, finding.Score)
+		}
+		style := color.Warning
+		if a.shouldFail(finding.Severity) {
+			style = color.Error
+		}
+
+		line := fmt.Sprintf("✘ %-32s [%s] %s", finding.Port, severity, finding.ID)
+		if len(finding.Aliases) > 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(finding.Aliases, ", "))
+		}
+		color.Println(style, line)
+		if finding.Summary != "" {
+			color.Printf(color.Hint, "    %s\n", finding.Summary)
+		}
+		if len(finding.Fixed) > 0 {
+			color.Printf(color.Hint, "    fixed in: %s\n", strings.Join(finding.Fixed, ", "))
+		}
+		if finding.Url != "" {
+			color.Printf(color.Hint, "    %s\n", finding.Url)
+		}
+	}
+
+	if failed {
+		return color.PrintError(fmt.Errorf("%d finding(s) in %s", len(findings), projectName),
+			"vulnerable ports found, severity reaches --fail-on=%s.", a.failOn)
+	}
+	color.Printf(color.Summary, "\ntotal: %d finding(s), all below --fail-on=%s\n", len(findings), a.failOn)
+	return nil
+}
+
+// shouldFail tells whether a finding of severity fails the audit.
+func (a *auditCmd) shouldFail(severity string) bool {
+	switch a.failOn {
+	case auditFailOnNone:
+		return false
+	case auditFailOnAny:
+		return true
+	default:
+		return audit.SeverityRank(severity) >= audit.SeverityRank(a.failOn)
+	}
+}
+
+func (a *auditCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var suggestions []string
+	for _, flag := range []string{"--db", "--fail-on", "--format"} {
+		if strings.HasPrefix(flag, toComplete) {
+			suggestions = append(suggestions, flag)
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -150,9 +150,9 @@ func (a *autoremoveCmd) collectPackages(nameVersion string) error {
 		return fmt.Errorf("found version conflict %s -> %w", nameVersion, err)
 	}
 
-	// Add if not added before.
-	if !slices.Contains(a.packages, nameVersion) {
-		a.packages = append(a.packages, nameVersion)
+	// Add if not added before, selected features are not part of package name.
+	if !slices.Contains(a.packages, port.NameVersion()) {
+		a.packages = append(a.packages, port.NameVersion())
 	}
 
 	for _, nameVersion := range port.MatchedConfig.Dependencies {
@@ -0,0 +1,121 @@
+package cmds
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/bundle"
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+
+	"github.com/spf13/cobra"
+)
+
+type bundleCmd struct {
+	celer   *configs.Celer
+	format  string
+	profile string
+	version string
+	output  string
+}
+
+func (b *bundleCmd) Command(celer *configs.Celer) *cobra.Command {
+	b.celer = celer
+	command := &cobra.Command{
+		Use:   "bundle",
+		Short: "Bundle deployed output of current project into a distributable package.",
+		Long: `Bundle deployed output of current project into a distributable package.
+
+This command assembles files installed by ports of current project, which are
+recorded in their trace files, and writes them into tar.gz, zip, deb or rpm.
+Files are selected by profile:
+  • runtime: executables, shared libraries and data, headers, static
+             libraries, cmake and pkg-config files are excluded
+  • sdk:     all installed files
+
+Rules of profile can be customized in [bundle] of project toml. Snapshot report
+of the latest deployment and a manifest of bundled ports and files are embedded
+into share/celer of bundle. Deb and rpm are generated without dpkg or rpmbuild,
+sdk of them contains development files only and depends on runtime package.
+
+Examples:
+  celer bundle                                 # Bundle runtime into tar.gz
+  celer bundle --format=zip --profile=sdk      # Bundle sdk into zip
+  celer bundle --format=deb --version=1.2.0    # Bundle runtime into deb
+  celer bundle --format=rpm --output=dist      # Bundle runtime into dist`,
+		Args: b.validateArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return b.bundle()
+		},
+		ValidArgsFunction: b.completion,
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&b.format, "format", bundle.FormatTarGz, "Format of bundle, tar.gz, zip, deb or rpm.")
+	flags.StringVar(&b.profile, "profile", bundle.ProfileRuntime, "Profile of bundle, runtime or sdk.")
+	flags.StringVar(&b.version, "version", "", "Version of bundle, default is version in [bundle] of project.")
+	flags.StringVar(&b.output, "output", "", "Directory to write bundle, default is workspace.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (b *bundleCmd) validateArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		return err
+	}
+	if err := bundle.ValidateFormat(b.format); err != nil {
+		return err
+	}
+	if err := bundle.ValidateProfile(b.profile); err != nil {
+		return err
+	}
+	if b.version != "" {
+		if err := configs.ValidateBundleVersion(b.version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bundleCmd) bundle() error {
+	if err := b.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to initialize celer.")
+	}
+
+	projectName := b.celer.Project().GetName()
+	if projectName == "" {
+		return color.PrintError(fmt.Errorf("no project is selected"), "failed to bundle.")
+	}
+
+	output := strings.TrimSpace(b.output)
+	if output == "" {
+		output = dirs.WorkspaceDir
+	}
+	bundlePath, err := bundle.Create(b.celer, b.celer.ProjectBundle(), bundle.Options{
+		Format:  b.format,
+		Profile: b.profile,
+		Version: b.version,
+		Output:  filepath.Clean(output),
+	})
+	if err != nil {
+		return color.PrintError(err, "failed to bundle %s.", projectName)
+	}
+
+	color.Printf(color.Success, "Bundle (%s, %s) saved to: %s\n", b.format, b.profile, bundlePath)
+	return nil
+}
+
+func (b *bundleCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var suggestions []string
+	for _, flag := range []string{"--format", "--profile", "--version", "--output"} {
+		if strings.HasPrefix(flag, toComplete) {
+			suggestions = append(suggestions, flag)
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
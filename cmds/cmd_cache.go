@@ -0,0 +1,641 @@
+package cmds
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgcache/manage"
+	"github.com/celer-pkg/celer/pkgcache/meta"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/errors"
+	"github.com/celer-pkg/celer/pkgs/expr"
+
+	"github.com/spf13/cobra"
+)
+
+type cacheCmd struct {
+	celer *configs.Celer
+
+	kinds     []string
+	format    string
+	platform  string
+	project   string
+	buildType string
+	fix       bool
+	olderThan string
+	unusedFor string
+	maxSize   string
+	dryRun    bool
+	dev       bool
+}
+
+// cacheListOutput is the JSON schema of `celer cache ls --format=json`.
+type cacheListOutput struct {
+	configs.OutputHeader
+	Entries []manage.Entry `json:"entries"`
+}
+
+// cacheStatOutput is the JSON schema of `celer cache stat --format=json`.
+type cacheStatOutput struct {
+	configs.OutputHeader
+	Caches []manage.Summary `json:"caches"`
+}
+
+// cacheVerifyOutput is the JSON schema of `celer cache verify --format=json`.
+type cacheVerifyOutput struct {
+	configs.OutputHeader
+	Problems []manage.Problem `json:"problems"`
+	Fixed    bool             `json:"fixed"`
+}
+
+// cacheExplainOutput is the JSON schema of `celer cache explain --format=json`.
+type cacheExplainOutput struct {
+	configs.OutputHeader
+	Package     string             `json:"package"`
+	BuildHash   string             `json:"build_hash"`
+	Hit         bool               `json:"hit"`
+	HitPath     string             `json:"hit_path,omitempty"`
+	ClosestPath string             `json:"closest_path,omitempty"`
+	ClosestHash string             `json:"closest_hash,omitempty"`
+	Candidates  int                `json:"candidates"`
+	Sections    []meta.SectionDiff `json:"sections"`
+}
+
+func (c *cacheCmd) Command(celer *configs.Celer) *cobra.Command {
+	c.celer = celer
+	command := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect, verify and prune package cache.",
+		Long: `Inspect, verify and prune package cache.
+
+This command manages caches on disk, they are:
+  • artifacts: built packages in pkgcache, stored per platform/project/build type
+  • repos:     source repos in pkgcache
+  • downloads: downloaded files in pkgcache
+  • dev:       built host/dev packages under user's home dir
+
+Only pkgcache of netfs backend can be managed, caches of http and s3
+backend are managed by their servers.
+
+Examples:
+  celer cache ls                                 # List all cached entries
+  celer cache ls --kind=artifacts --project=p1   # List cached artifacts of project p1
+  celer cache stat                               # Show count and size of each cache
+  celer cache verify --fix                       # Verify caches and remove broken entries
+  celer cache prune --older-than=90d             # Remove entries stored 90 days ago
+  celer cache prune --max-size=200G --dry-run    # Show what to remove to keep caches under 200GB
+  celer cache rm ffmpeg@3.4.13                   # Remove all cached entries of ffmpeg@3.4.13
+  celer cache explain ffmpeg@3.4.13              # Explain why ffmpeg@3.4.13 missed the cache`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	command.AddCommand(c.lsCommand(), c.statCommand(), c.verifyCommand(), c.pruneCommand(), c.rmCommand(), c.explainCommand())
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (c *cacheCmd) lsCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "ls",
+		Short: "List cached entries.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.list()
+		},
+	}
+
+	command.Flags().StringSliceVar(&c.kinds, "kind", nil, "kinds of cache: artifacts, repos, downloads or dev, default is all.")
+	command.Flags().StringVar(&c.platform, "platform", "", "only list artifacts of the platform.")
+	command.Flags().StringVar(&c.project, "project", "", "only list artifacts of the project.")
+	command.Flags().StringVar(&c.buildType, "build-type", "", "only list artifacts of the build type.")
+	command.Flags().StringVar(&c.format, "format", configs.OutputFormatText, "output format, text or json.")
+	command.RegisterFlagCompletionFunc("kind", kindCompletion)
+	command.RegisterFlagCompletionFunc("platform", platformCompletgion)
+	command.RegisterFlagCompletionFunc("project", projectCompletgion)
+	return command
+}
+
+func (c *cacheCmd) statCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "stat",
+		Short: "Show count and size of caches.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.stat()
+		},
+	}
+
+	command.Flags().StringSliceVar(&c.kinds, "kind", nil, "kinds of cache: artifacts, repos, downloads or dev, default is all.")
+	command.Flags().StringVar(&c.format, "format", configs.OutputFormatText, "output format, text or json.")
+	command.RegisterFlagCompletionFunc("kind", kindCompletion)
+	return command
+}
+
+func (c *cacheCmd) verifyCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify checksums of cached entries and find orphan archives or metas.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.verify()
+		},
+	}
+
+	command.Flags().StringSliceVar(&c.kinds, "kind", nil, "kinds of cache: artifacts, repos, downloads or dev, default is all.")
+	command.Flags().BoolVar(&c.fix, "fix", false, "remove broken entries.")
+	command.Flags().StringVar(&c.format, "format", configs.OutputFormatText, "output format, text or json.")
+	command.RegisterFlagCompletionFunc("kind", kindCompletion)
+	return command
+}
+
+func (c *cacheCmd) pruneCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict cached entries by age, last access time or total size.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.prune()
+		},
+	}
+
+	command.Flags().StringSliceVar(&c.kinds, "kind", nil, "kinds of cache: artifacts, repos, downloads or dev, default is all.")
+	command.Flags().StringVar(&c.olderThan, "older-than", "", "evict entries stored before the age, for example: 90d.")
+	command.Flags().StringVar(&c.unusedFor, "unused-for", "", "evict entries not accessed for the age, for example: 30d.")
+	command.Flags().StringVar(&c.maxSize, "max-size", "", "evict least recently accessed entries until total size is under it, for example: 200G.")
+	command.Flags().BoolVar(&c.dryRun, "dry-run", false, "only show entries to evict.")
+	command.RegisterFlagCompletionFunc("kind", kindCompletion)
+	return command
+}
+
+func (c *cacheCmd) rmCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "rm <name@version>...",
+		Short: "Remove cached entries of packages.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.remove(args)
+		},
+	}
+
+	command.Flags().StringSliceVar(&c.kinds, "kind", nil, "kinds of cache: artifacts, repos or dev, default is all.")
+	command.Flags().BoolVar(&c.dryRun, "dry-run", false, "only show entries to remove.")
+	command.RegisterFlagCompletionFunc("kind", kindCompletion)
+	return command
+}
+
+func (c *cacheCmd) explainCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "explain <name@version>",
+		Short: "Explain cache miss by diffing current build meta with the closest cached one.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.explain(args[0])
+		},
+	}
+
+	command.Flags().BoolVar(&c.dev, "dev", false, "explain the package built as dev dependency.")
+	command.Flags().StringVar(&c.format, "format", configs.OutputFormatText, "output format, text or json.")
+	return command
+}
+
+func (c *cacheCmd) list() error {
+	kinds, dirs, err := c.prepare()
+	if err != nil {
+		return color.PrintError(err, "failed to list caches.")
+	}
+
+	entries, err := manage.Scan(dirs, kinds)
+	if err != nil {
+		return color.PrintError(err, "failed to list caches.")
+	}
+	entries = slices.DeleteFunc(entries, func(entry manage.Entry) bool {
+		return entry.Kind == manage.KindArtifacts &&
+			(c.platform != "" && entry.Platform != c.platform ||
+				c.project != "" && entry.Project != c.project ||
+				c.buildType != "" && entry.BuildType != strings.ToLower(c.buildType))
+	})
+
+	if c.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(cacheListOutput{
+			OutputHeader: configs.NewOutputHeader("cache_list"),
+			Entries:      entries,
+		})
+	}
+
+	// Group entries by kind, and artifacts are also grouped by platform/project/buildType.
+	var group string
+	for _, entry := range entries {
+		title := fmt.Sprintf("[%s] %s", entry.Kind, expr.If(entry.Kind == manage.KindArtifacts, entry.Workspace(), dirs.Get(entry.Kind)))
+		if title != group {
+			group = title
+			color.Println(color.Title, "\n"+title)
+			color.Println(color.Line, strings.Repeat("-", len(title)))
+		}
+		color.Printf(color.Hint, "%-40s %-16s %10s  %s\n", entry.Name, shortHash(entry.Hash),
+			expr.FormatSize(entry.Size), entry.ModTime.Format(time.DateTime))
+	}
+
+	if len(entries) == 0 {
+		color.Println(color.Error, "no cached entry found.")
+		return nil
+	}
+	color.Printf(color.Summary, "\ntotal: %d entry(s), %s\n", len(entries), expr.FormatSize(totalSize(entries)))
+	return nil
+}
+
+func (c *cacheCmd) stat() error {
+	kinds, dirs, err := c.prepare()
+	if err != nil {
+		return color.PrintError(err, "failed to stat caches.")
+	}
+
+	entries, err := manage.Scan(dirs, kinds)
+	if err != nil {
+		return color.PrintError(err, "failed to stat caches.")
+	}
+	summaries := manage.Summarize(dirs, kinds, entries)
+
+	if c.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(cacheStatOutput{
+			OutputHeader: configs.NewOutputHeader("cache_stat"),
+			Caches:       summaries,
+		})
+	}
+
+	for _, summary := range summaries {
+		color.Println(color.Title, fmt.Sprintf("\n[%s] %s", summary.Kind, expr.If(summary.Dir != "", summary.Dir, "not configured")))
+		if summary.Count == 0 {
+			color.Println(color.Hint, "entries: 0")
+			continue
+		}
+		color.Printf(color.Hint, "entries: %d\n", summary.Count)
+		color.Printf(color.Hint, "size:    %s\n", expr.FormatSize(summary.Size))
+		color.Printf(color.Hint, "oldest:  %s\n", summary.Oldest.Format(time.DateTime))
+		color.Printf(color.Hint, "newest:  %s\n", summary.Newest.Format(time.DateTime))
+	}
+	return nil
+}
+
+func (c *cacheCmd) verify() error {
+	kinds, dirs, err := c.prepare()
+	if err != nil {
+		return color.PrintError(err, "failed to verify caches.")
+	}
+	if c.fix {
+		if err := c.checkWritable(kinds); err != nil {
+			return color.PrintError(err, "failed to verify caches.")
+		}
+	}
+
+	problems, err := manage.Verify(dirs, kinds)
+	if err != nil {
+		return color.PrintError(err, "failed to verify caches.")
+	}
+
+	if c.fix {
+		for _, problem := range problems {
+			if err := manage.RemoveFiles(problem.Files); err != nil {
+				return color.PrintError(err, "failed to remove broken entry.")
+			}
+		}
+	}
+
+	if c.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(cacheVerifyOutput{
+			OutputHeader: configs.NewOutputHeader("cache_verify"),
+			Problems:     problems,
+			Fixed:        c.fix,
+		})
+	}
+
+	if len(problems) == 0 {
+		color.PrintSuccess("all cached entries are valid.")
+		return nil
+	}
+	for _, problem := range problems {
+		color.Printf(color.Warning, "✘ [%s] %s: %s\n", problem.Kind, problem.Path, problem.Reason)
+	}
+	if c.fix {
+		color.PrintSuccess("%d broken entry(s) are removed.", len(problems))
+		return nil
+	}
+	return color.PrintError(fmt.Errorf("%d broken entry(s) found", len(problems)), "run `celer cache verify --fix` to remove them.")
+}
+
+func (c *cacheCmd) prune() error {
+	kinds, dirs, err := c.prepare()
+	if err != nil {
+		return color.PrintError(err, "failed to prune caches.")
+	}
+
+	var options manage.PruneOptions
+	if c.olderThan != "" {
+		if options.OlderThan, err = manage.ParseAge(c.olderThan); err != nil {
+			return color.PrintError(err, "invalid --older-than.")
+		}
+	}
+	if c.unusedFor != "" {
+		if options.UnusedFor, err = manage.ParseAge(c.unusedFor); err != nil {
+			return color.PrintError(err, "invalid --unused-for.")
+		}
+	}
+	if c.maxSize != "" {
+		if options.MaxSize, err = manage.ParseSize(c.maxSize); err != nil {
+			return color.PrintError(err, "invalid --max-size.")
+		}
+	}
+	if options.Empty() {
+		return color.PrintError(fmt.Errorf("no prune policy specified"), "please specify --older-than, --unused-for or --max-size.")
+	}
+
+	entries, err := manage.Scan(dirs, kinds)
+	if err != nil {
+		return color.PrintError(err, "failed to prune caches.")
+	}
+	return c.evict(kinds, manage.SelectPrune(entries, options, time.Now()))
+}
+
+func (c *cacheCmd) remove(nameVersions []string) error {
+	for _, nameVersion := range nameVersions {
+		if !strings.Contains(nameVersion, "@") {
+			return color.PrintError(fmt.Errorf("invalid package %q", nameVersion), "package should be like name@version.")
+		}
+	}
+
+	kinds, dirs, err := c.prepare()
+	if err != nil {
+		return color.PrintError(err, "failed to remove caches.")
+	}
+
+	// Downloads are not owned by any package.
+	kinds = slices.DeleteFunc(kinds, func(kind manage.Kind) bool {
+		return kind == manage.KindDownloads
+	})
+	entries, err := manage.Scan(dirs, kinds)
+	if err != nil {
+		return color.PrintError(err, "failed to remove caches.")
+	}
+
+	var matched []manage.Entry
+	for _, nameVersion := range nameVersions {
+		matched = append(matched, manage.FilterByName(entries, nameVersion)...)
+	}
+	return c.evict(kinds, matched)
+}
+
+func (c *cacheCmd) explain(nameVersion string) error {
+	if !strings.Contains(nameVersion, "@") {
+		return color.PrintError(fmt.Errorf("invalid package %q", nameVersion), "package should be like name@version.")
+	}
+	if err := c.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+	if err := configs.ValidateOutputFormat(c.format); err != nil {
+		return color.PrintError(err, "failed to explain cache.")
+	}
+
+	var port configs.Port
+	port.DevDep = c.dev
+	if err := port.Init(c.celer, nameVersion); err != nil {
+		return color.PrintError(err, "failed to init %s.", nameVersion)
+	}
+
+	currentMeta, err := port.BuildMeta()
+	if err != nil {
+		if errors.Is(err, errors.ErrRepoNotExit) {
+			return color.PrintError(err, "source of %s is required to compute its meta, run `celer install %s` first.", nameVersion, nameVersion)
+		}
+		return color.PrintError(err, "failed to compute meta of %s.", nameVersion)
+	}
+	buildHash := fmt.Sprintf("%x", sha256.Sum256([]byte(currentMeta)))
+
+	metaPaths, err := c.storedMetas(port)
+	if err != nil {
+		return color.PrintError(err, "failed to find cached metas of %s.", nameVersion)
+	}
+
+	output := cacheExplainOutput{
+		OutputHeader: configs.NewOutputHeader("cache_explain"),
+		Package:      nameVersion,
+		BuildHash:    buildHash,
+		Candidates:   len(metaPaths),
+		Sections:     []meta.SectionDiff{},
+	}
+
+	// Find the stored meta that is the same as current or the closest one.
+	var (
+		closestMeta     string
+		closestDistance = -1
+	)
+	for _, metaPath := range metaPaths {
+		bytes, err := os.ReadFile(metaPath)
+		if err != nil {
+			return color.PrintError(err, "failed to read %s.", metaPath)
+		}
+		storedMeta := string(bytes)
+
+		if storedMeta == currentMeta {
+			output.Hit = true
+			output.HitPath = metaPath
+			break
+		}
+		if distance := meta.Distance(storedMeta, currentMeta); closestDistance < 0 || distance < closestDistance {
+			closestDistance = distance
+			closestMeta = storedMeta
+			output.ClosestPath = metaPath
+		}
+	}
+	if output.Hit {
+		output.ClosestPath = ""
+	} else if output.ClosestPath != "" {
+		output.ClosestHash = fmt.Sprintf("%x", sha256.Sum256([]byte(closestMeta)))
+		output.Sections = meta.Diff(closestMeta, currentMeta)
+	}
+
+	if c.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(output)
+	}
+
+	color.Printf(color.Hint, "package:    %s\n", nameVersion)
+	color.Printf(color.Hint, "build hash: %s\n", buildHash)
+	switch {
+	case output.Hit:
+		color.PrintSuccess("cache hit: %s", output.HitPath)
+	case output.ClosestPath == "":
+		color.PrintWarning("no cached meta found for %s.", nameVersion)
+	default:
+		color.Printf(color.Hint, "closest:    %s (%d candidate(s))\n", output.ClosestPath, len(metaPaths))
+		for _, section := range output.Sections {
+			title := expr.If(section.Title != "", section.Title, "header")
+			color.Println(color.Title, fmt.Sprintf("\n[%s] %s", section.Status, title))
+			for _, line := range section.Lines {
+				color.Println(expr.If(strings.HasPrefix(line, "+"), color.Warning, color.Error), line)
+			}
+		}
+		if len(output.Sections) == 0 {
+			color.Println(color.Hint, "\nmetas differ only in trailing whitespaces.")
+		}
+	}
+	return nil
+}
+
+// storedMetas returns metas of port stored in caches and the installed package dir.
+func (c *cacheCmd) storedMetas(port configs.Port) ([]string, error) {
+	nameVersion := port.NameVersion()
+
+	var patterns []string
+	if port.DevDep || port.HostDep {
+		if devCacheConfig := c.celer.DevCacheConfig(); devCacheConfig != nil {
+			patterns = append(patterns, filepath.Join(devCacheConfig.GetDir(), nameVersion, "metas", "*.meta"))
+		}
+	} else if pkgCacheConfig := c.celer.PkgCacheConfig(); pkgCacheConfig != nil {
+		if config, ok := pkgCacheConfig.(*configs.PkgCacheConfig); ok && config.IsRemote() {
+			color.PrintWarning("metas in pkgcache of %s backend cannot be listed, only installed package is compared.", config.Backend)
+		} else if artifactsDir := pkgCacheConfig.GetDir(pkgcache.PkgCacheDirArtifacts); artifactsDir != "" {
+			patterns = append(patterns, filepath.Join(artifactsDir,
+				c.celer.Platform().GetName(), c.celer.Project().GetName(),
+				c.celer.BuildType(), nameVersion, "metas", "*.meta"))
+		}
+	}
+	if port.PackageDir != "" {
+		patterns = append(patterns, filepath.Join(port.PackageDir, "*.meta"))
+	}
+
+	var metaPaths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		metaPaths = append(metaPaths, matches...)
+	}
+	return metaPaths, nil
+}
+
+// evict removes entries, or only prints them when dry run.
+func (c *cacheCmd) evict(kinds []manage.Kind, entries []manage.Entry) error {
+	if len(entries) == 0 {
+		color.PrintSuccess("nothing to remove.")
+		return nil
+	}
+	if !c.dryRun {
+		if err := c.checkWritable(kinds); err != nil {
+			return color.PrintError(err, "failed to remove caches.")
+		}
+	}
+
+	for _, entry := range entries {
+		if !c.dryRun {
+			if err := manage.Remove(entry); err != nil {
+				return color.PrintError(err, "failed to remove %s.", entry.Path)
+			}
+		}
+		color.Printf(color.Hint, "%s [%s] %s\n", expr.If(c.dryRun, "-", "✔"), entry.Kind, entry.Path)
+	}
+
+	if c.dryRun {
+		color.Printf(color.Summary, "%d entry(s), %s would be removed.\n", len(entries), expr.FormatSize(totalSize(entries)))
+	} else {
+		color.PrintSuccess("%d entry(s), %s are removed.", len(entries), expr.FormatSize(totalSize(entries)))
+	}
+	return nil
+}
+
+// prepare initializes celer and returns dirs of the requested kinds of cache.
+func (c *cacheCmd) prepare() ([]manage.Kind, manage.Dirs, error) {
+	if err := c.celer.Init(); err != nil {
+		return nil, manage.Dirs{}, fmt.Errorf("failed to init celer -> %w", err)
+	}
+	if err := configs.ValidateOutputFormat(c.format); err != nil {
+		return nil, manage.Dirs{}, err
+	}
+
+	kinds, err := manage.ParseKinds(c.kinds)
+	if err != nil {
+		return nil, manage.Dirs{}, err
+	}
+
+	var dirs manage.Dirs
+	if devCacheConfig := c.celer.DevCacheConfig(); devCacheConfig != nil {
+		dirs.Dev = devCacheConfig.GetDir()
+	}
+
+	pkgCacheConfig := c.celer.PkgCacheConfig()
+	if pkgCacheConfig != nil {
+		if config, ok := pkgCacheConfig.(*configs.PkgCacheConfig); ok && config.IsRemote() {
+			kinds, err := remoteKinds(c.kinds, kinds, config.Backend)
+			if err != nil {
+				return nil, manage.Dirs{}, err
+			}
+			if len(c.kinds) == 0 && c.format != configs.OutputFormatJSON {
+				color.PrintWarning("artifacts, repos and downloads are not supported for remote backends, pkgcache of %s backend is managed by its server, only dev cache is managed.", config.Backend)
+			}
+			return kinds, dirs, nil
+		}
+
+		dirs.Artifacts = pkgCacheConfig.GetDir(pkgcache.PkgCacheDirArtifacts)
+		dirs.Repos = pkgCacheConfig.GetDir(pkgcache.PkgCacheDirRepos)
+		dirs.Downloads = pkgCacheConfig.GetDir(pkgcache.PkgCacheDirDownloads)
+	}
+
+	return kinds, dirs, nil
+}
+
+// remoteKinds returns kinds of cache that can be managed when pkgcache is stored in remote server,
+// its artifacts, repos and downloads are managed by the server, so only dev cache is left.
+func remoteKinds(requested []string, kinds []manage.Kind, backend string) ([]manage.Kind, error) {
+	for _, kind := range kinds {
+		if kind != manage.KindDev && len(requested) > 0 {
+			return nil, fmt.Errorf("--kind=%s is not supported for remote backends, pkgcache of %s backend is managed by its server, only --kind=dev is available", kind, backend)
+		}
+	}
+	return []manage.Kind{manage.KindDev}, nil
+}
+
+// checkWritable refuses to remove entries from pkgcache that is configured as read-only.
+func (c *cacheCmd) checkWritable(kinds []manage.Kind) error {
+	pkgCacheConfig := c.celer.PkgCacheConfig()
+	if pkgCacheConfig == nil || pkgCacheConfig.IsWritable() {
+		return nil
+	}
+	if slices.ContainsFunc(kinds, func(kind manage.Kind) bool { return kind != manage.KindDev }) {
+		return fmt.Errorf("pkgcache is not writable, only --kind=dev can be removed")
+	}
+	return nil
+}
+
+func kindCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var kinds []string
+	for _, kind := range manage.AllKinds {
+		kinds = append(kinds, string(kind))
+	}
+	return kinds, cobra.ShellCompDirectiveNoFileComp
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func totalSize(entries []manage.Entry) int64 {
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	return size
+}
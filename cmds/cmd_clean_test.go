@@ -420,7 +420,7 @@ func TestClean(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	check(t, celer.Deploy(true, false))
+	check(t, celer.Deploy(configs.DeployOptions{Force: true}))
 
 	buildDir := func(nameVersion string, dev bool) string {
 		if dev {
@@ -478,7 +478,7 @@ func TestClean(t *testing.T) {
 		if err := os.RemoveAll(dirs.PackagesDir); err != nil {
 			t.Fatal(err)
 		}
-		if err := celer.Deploy(true, false); err != nil {
+		if err := celer.Deploy(configs.DeployOptions{Force: true}); err != nil {
 			t.Fatal(err)
 		}
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/celer-pkg/celer/configs"
 	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/expr"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +16,8 @@ type createCmd struct {
 	platform string
 	project  string
 	port     string
+	from     string
+	like     string
 }
 
 func (c *createCmd) Command(celer *configs.Celer) *cobra.Command {
@@ -33,10 +36,22 @@ COMPONENT TYPES:
   --project     Create a new project configuration
   --port        Create a new port with name@version format
 
+With --from, the port is scaffolded from upstream source, which can be a git url
+or an archive url. Build system is detected, checksum of archive is filled, top
+level CMake or meson options are listed as commented candidates, and
+dependencies are guessed from find_package/dependency() calls that match
+existing ports.
+
+With --like, the port is created by copying an existing version of port, url,
+ref and checksum are updated for the new version, and patches that no longer
+apply are reported.
+
 EXAMPLES:
   celer create --platform windows-x86_64-msvc
   celer create --project my-awesome-project
-  celer create --port opencv@4.8.0`,
+  celer create --port opencv@4.8.0
+  celer create --port zlib@1.3.1 --from https://github.com/madler/zlib/releases/download/v1.3.1/zlib-1.3.1.tar.gz
+  celer create --port zlib@1.3.2 --like zlib@1.3.1`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.doCreate(cmd)
@@ -48,8 +63,11 @@ EXAMPLES:
 	command.Flags().StringVar(&c.platform, "platform", "", "create a new platform.")
 	command.Flags().StringVar(&c.project, "project", "", "create a new project.")
 	command.Flags().StringVar(&c.port, "port", "", "create a new port.")
+	command.Flags().StringVar(&c.from, "from", "", "scaffold port from git url or archive url.")
+	command.Flags().StringVar(&c.like, "like", "", "create port by copying an existing name@version.")
 
 	command.MarkFlagsMutuallyExclusive("platform", "project", "port")
+	command.MarkFlagsMutuallyExclusive("from", "like")
 
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
@@ -76,6 +94,13 @@ func (c *createCmd) createProject(projectName string) error {
 }
 
 func (c *createCmd) createPort(nameVersion string) error {
+	if c.from != "" {
+		return c.createPortFrom(nameVersion)
+	}
+	if c.like != "" {
+		return c.createPortLike(nameVersion)
+	}
+
 	if err := c.celer.CreatePort(nameVersion); err != nil {
 		return color.PrintError(err, "%s could not be created.", nameVersion)
 	}
@@ -84,6 +109,41 @@ func (c *createCmd) createPort(nameVersion string) error {
 	return nil
 }
 
+func (c *createCmd) createPortFrom(nameVersion string) error {
+	if err := c.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+
+	scaffold, err := c.celer.CreatePortFrom(nameVersion, c.from)
+	if err != nil {
+		return color.PrintError(err, "%s could not be created.", nameVersion)
+	}
+
+	color.PrintSuccess("%s is created from %s, please proceed with its refinement.", nameVersion, c.from)
+	color.PrintHint("Build system: %s, options: %d, dependencies: %d.",
+		expr.If(scaffold.BuildSystem == "", "unknown", scaffold.BuildSystem),
+		len(scaffold.Options), len(scaffold.Dependencies))
+	return nil
+}
+
+func (c *createCmd) createPortLike(nameVersion string) error {
+	if err := c.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+
+	update, err := c.celer.CreatePortLike(nameVersion, c.like)
+	if err != nil {
+		return color.PrintError(err, "%s could not be created.", nameVersion)
+	}
+
+	color.PrintSuccess("%s is created like %s, please proceed with its refinement.", nameVersion, c.like)
+	color.PrintHint("Location: %s", update.PortFile)
+	if len(update.BrokenPatches) > 0 {
+		color.PrintWarning("patches no longer apply, please rebase them: %s", strings.Join(update.BrokenPatches, ", "))
+	}
+	return nil
+}
+
 func (c *createCmd) doCreate(cmd *cobra.Command) error {
 	// Check that exactly one flag is provided.
 	flags := cmd.Flags()
@@ -106,6 +166,10 @@ func (c *createCmd) doCreate(cmd *cobra.Command) error {
 		err := fmt.Errorf("invalid input argument")
 		return color.PrintError(err, "You must specify exactly one component to create (--platform, --project, or --port).")
 	}
+	if (flags.Changed("from") || flags.Changed("like")) && !portChanged {
+		err := fmt.Errorf("invalid input argument")
+		return color.PrintError(err, "--from and --like can only be used with --port.")
+	}
 
 	// Validate inputs and create.
 	if platformChanged {
@@ -126,6 +190,14 @@ func (c *createCmd) doCreate(cmd *cobra.Command) error {
 		if err := c.validatePortName(c.port); err != nil {
 			return color.PrintError(err, "Invalid port name.")
 		}
+		if flags.Changed("from") && strings.TrimSpace(c.from) == "" {
+			return color.PrintError(fmt.Errorf("--from cannot be empty"), "Invalid source url.")
+		}
+		if flags.Changed("like") {
+			if err := c.validatePortName(c.like); err != nil {
+				return color.PrintError(err, "Invalid --like port name.")
+			}
+		}
 		return c.createPort(c.port)
 	}
 
@@ -195,7 +267,7 @@ func (c *createCmd) validatePortName(nameVersion string) error {
 
 func (c *createCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var suggestions []string
-	for _, flag := range []string{"--platform", "--project", "--port"} {
+	for _, flag := range []string{"--platform", "--project", "--port", "--from", "--like"} {
 		if strings.HasPrefix(flag, toComplete) {
 			suggestions = append(suggestions, flag)
 		}
@@ -224,6 +224,19 @@ func TestCreateCmd_CommandExecutionValidation(t *testing.T) {
 		}
 	})
 
+	t.Run("from without port should fail", func(t *testing.T) {
+		celer := configs.NewCeler()
+		cmd := &createCmd{}
+
+		stderr, err := runCommand(t, cmd.Command(celer), "--project=test_project", "--from=https://example.com/foo.git")
+		if err == nil {
+			t.Fatal("expected error when --from is used without --port")
+		}
+		if !strings.Contains(stderr, "--from and --like can only be used with --port") {
+			t.Fatalf("stderr should report --from misuse, got:\n%s", stderr)
+		}
+	})
+
 	t.Run("positional args should fail", func(t *testing.T) {
 		celer := newInitializedCeler(t)
 		cmd := &createCmd{}
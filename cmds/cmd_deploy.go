@@ -1,6 +1,7 @@
 package cmds
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,10 +10,12 @@ import (
 
 	"github.com/celer-pkg/celer/configs"
 	"github.com/celer-pkg/celer/depcheck"
+	"github.com/celer-pkg/celer/lockfile"
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/expr"
 	"github.com/celer-pkg/celer/pkgs/refs"
+	"github.com/celer-pkg/celer/sbom"
 	"github.com/celer-pkg/celer/snapshot"
 
 	"github.com/spf13/cobra"
@@ -23,6 +26,22 @@ type deployCmd struct {
 	force        bool
 	snapshotPath string
 	strip        bool
+	splitDebug   bool
+	parallel     int
+	keepGoing    bool
+	format       string
+	locked       bool
+	sbomPath     string
+	sbomSpec     string
+	matrix       string
+
+	// Collected and resolved before deploy, they're reused by sbom.
+	ports        []configs.Port
+	resolvedRefs []refs.ResolvedRef
+
+	// Combination in deploying and refs resolved by previous combinations of matrix.
+	target    *configs.MatrixTarget
+	refsCache map[string]refs.ResolvedRef
 }
 
 func (d *deployCmd) Command(celer *configs.Celer) *cobra.Command {
@@ -36,50 +55,44 @@ After successful deployment, you can optionally export a snapshot
 for reproducible builds using the --snapshot flag, and you can also
 strip installed binaries and libraies with --strip.
 
+With --split-debug, installed binaries and libraries are stripped with objcopy
+of toolchain, and their debug symbols are kept in a build-id keyed symbol store
+installed/celer/debug/<platform>/<project>/<build_type>/.build-id, they're also
+stored next to the artifact cache entries when pkgcache is writable.
+
+With --parallel=N, independent ports are built at the same time, they share
+the jobs configured in celer.toml, and each port writes its own log file.
+
+With --format=json, install report is written as json instead of markdown.
+
+With --sbom=<path>, sbom of deployed ports is written into the file, it's SPDX for
+*.spdx.json and CycloneDX for *.cdx.json, or the one specified by --sbom-format.
+
+With --matrix=<file|list>, project is deployed for every platform and build type
+combination in one invocation without changing celer.toml, a consolidated report
+with a pass/fail cell per combination is written into installed/celer/deployments.
+
 Examples:
   celer deploy --force                  # Force deploy and ignore installed
   celer deploy --snapshot=${filepath}   # Initialize with conf repo
-  celer deploy --strip                  # Strip installed binaries and libraries`,
+  celer deploy --strip                  # Strip installed binaries and libraries
+  celer deploy --split-debug            # Strip and keep debug symbols in symbol store
+  celer deploy --locked                 # Deploy with sources locked by "celer lock"
+  celer deploy --sbom=dist/app.spdx.json # Write SPDX sbom after deployed
+  celer deploy --sbom=dist/sbom.json --sbom-format=cyclonedx
+  celer deploy --matrix=matrix.toml     # Deploy all combinations in matrix file
+  celer deploy --matrix=x86_64-linux-ubuntu-22.04-gcc-11.5.0:release,x86_64-linux-ubuntu-22.04-gcc-11.5.0:debug
+  celer deploy --parallel=4 --keep-going # Build 4 ports at the same time and don't stop at failure`,
 		Args: d.validateArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := d.celer.Init(); err != nil {
 				return color.PrintError(err, "failed to init celer.")
 			}
 
-			platformName := expr.If(d.celer.Platform().GetName() != "", d.celer.Platform().GetName(), "native")
-			projectName := d.celer.Project().GetName()
-
-			// Display deployment header.
-			color.Println(color.Title, "=======================================================================")
-			color.Printf(color.Title, "🚀 start to deploy:\n")
-			color.Printf(color.Title, "📌 platform: %s\n", platformName)
-			color.Printf(color.Title, "📌 project: %s\n", projectName)
-			color.Println(color.Title, "=======================================================================")
-
-			// Check circular dependency and version conflict.
-			if err := d.checkProject(); err != nil {
-				return color.PrintError(err, "failed to check circular dependency and version conflict.")
-			}
-
-			// Resolve all dependency refs before any clone/download begins.
-			if err := d.resolveAllRefs(); err != nil {
-				return color.PrintError(err, "failed to resolve refs.")
-			}
-
-			if err := d.celer.Deploy(d.force, d.strip); err != nil {
-				return color.PrintError(err, "failed to deploy celer.")
-			}
-
-			color.PrintSuccess("%s has been successfully deployed.", projectName)
-
-			// Export snapshot if requested.
-			if d.snapshotPath != "" {
-				if err := snapshot.Export(d.celer, d.snapshotPath); err != nil {
-					return fmt.Errorf("failed to export snapshot -> %w", err)
-				}
+			if d.matrix != "" {
+				return d.deployMatrix()
 			}
-
-			return nil
+			return d.deploy()
 		},
 		ValidArgsFunction: d.completion,
 	}
@@ -88,6 +101,14 @@ Examples:
 	flags.StringVar(&d.snapshotPath, "snapshot", "", "Export workspace snapshot after successfully deployed.")
 	flags.BoolVarP(&d.force, "force", "", false, "Force deployment, ignoring any installed packages.")
 	flags.BoolVarP(&d.strip, "strip", "", false, "Strip installed binaries and libraries.")
+	flags.BoolVar(&d.splitDebug, "split-debug", false, "Strip installed binaries and libraries, and split their debug symbols into symbol store.")
+	flags.IntVarP(&d.parallel, "parallel", "", 1, "Number of ports to build at the same time.")
+	flags.BoolVarP(&d.keepGoing, "keep-going", "", false, "Keep building independent ports after a failure, works with --parallel.")
+	flags.BoolVar(&d.locked, "locked", false, "Fail when sources drift from the lock file generated by `celer lock`.")
+	flags.StringVar(&d.sbomPath, "sbom", "", "Write sbom into the file after successfully deployed.")
+	flags.StringVar(&d.sbomSpec, "sbom-format", "", "Spec of sbom, spdx or cyclonedx, default is told by extension of --sbom.")
+	flags.StringVar(&d.format, "format", configs.OutputFormatText, "Format of install report, text or json.")
+	flags.StringVar(&d.matrix, "matrix", "", "Deploy every platform:build_type combination in a toml file or comma separated list.")
 
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
@@ -95,11 +116,116 @@ Examples:
 	return command
 }
 
+func (d *deployCmd) deploy() error {
+	platformName := expr.If(d.celer.Platform().GetName() != "", d.celer.Platform().GetName(), "native")
+	projectName := d.celer.Project().GetName()
+
+	// Display deployment header.
+	color.Println(color.Title, "=======================================================================")
+	color.Printf(color.Title, "🚀 start to deploy:\n")
+	color.Printf(color.Title, "📌 platform: %s\n", platformName)
+	color.Printf(color.Title, "📌 project: %s\n", projectName)
+	color.Println(color.Title, "=======================================================================")
+
+	// Check circular dependency and version conflict.
+	if err := d.checkProject(); err != nil {
+		return color.PrintError(err, "failed to check circular dependency and version conflict.")
+	}
+
+	// Resolve all dependency refs before any clone/download begins.
+	if err := d.resolveAllRefs(); err != nil {
+		return color.PrintError(err, "failed to resolve refs.")
+	}
+
+	options := configs.DeployOptions{
+		Force:        d.force,
+		Strip:        d.strip,
+		SplitDebug:   d.splitDebug,
+		Parallel:     d.parallel,
+		KeepGoing:    d.keepGoing,
+		ReportFormat: d.format,
+	}
+	if err := d.celer.Deploy(options); err != nil {
+		return color.PrintError(err, "failed to deploy celer.")
+	}
+
+	color.PrintSuccess("%s has been successfully deployed.", projectName)
+
+	// Export snapshot if requested.
+	if d.snapshotPath != "" {
+		if err := snapshot.Export(d.celer, d.snapshotPath); err != nil {
+			return fmt.Errorf("failed to export snapshot -> %w", err)
+		}
+	}
+
+	// Write sbom if requested.
+	if d.sbomPath != "" {
+		if err := writeSBOMFile(d.celer, d.ports, d.resolvedRefs, d.sbomPath, d.sbomSpec); err != nil {
+			return color.PrintError(err, "failed to generate sbom.")
+		}
+	}
+
+	return nil
+}
+
 func (d *deployCmd) validateArgs(cmd *cobra.Command, args []string) error {
 	if err := cobra.NoArgs(cmd, args); err != nil {
 		return err
 	}
 
+	if parallel, err := cmd.Flags().GetInt("parallel"); err == nil && parallel <= 0 {
+		return fmt.Errorf("--parallel must be greater than 0")
+	}
+
+	if format, err := cmd.Flags().GetString("format"); err == nil {
+		if err := configs.ValidateOutputFormat(format); err != nil {
+			return err
+		}
+	}
+
+	if cmd.Flags().Changed("sbom") {
+		sbomPath, err := cmd.Flags().GetString("sbom")
+		if err != nil {
+			return err
+		}
+		sbomPath = strings.TrimSpace(sbomPath)
+		if sbomPath == "" {
+			return fmt.Errorf("--sbom requires a non-empty path")
+		}
+		d.sbomPath = filepath.Clean(sbomPath)
+
+		spec, err := cmd.Flags().GetString("sbom-format")
+		if err != nil {
+			return err
+		}
+		if spec == "" {
+			var ok bool
+			if spec, ok = sbom.SpecOfFile(d.sbomPath); !ok {
+				return fmt.Errorf("cannot tell sbom spec of %s, name it *.spdx.json or *.cdx.json, or specify --sbom-format", d.sbomPath)
+			}
+		}
+		if err := sbom.ValidateSpec(spec); err != nil {
+			return err
+		}
+		d.sbomSpec = spec
+	} else if cmd.Flags().Changed("sbom-format") {
+		return fmt.Errorf("--sbom-format requires --sbom")
+	}
+
+	if cmd.Flags().Changed("matrix") {
+		matrix, err := cmd.Flags().GetString("matrix")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(matrix) == "" {
+			return fmt.Errorf("--matrix requires a non-empty file or list")
+		}
+		if cmd.Flags().Changed("snapshot") || cmd.Flags().Changed("sbom") {
+			return fmt.Errorf("--matrix cannot be used with --snapshot or --sbom")
+		}
+		d.matrix = strings.TrimSpace(matrix)
+	}
+
 	if !cmd.Flags().Changed("snapshot") {
 		return nil
 	}
@@ -118,6 +244,81 @@ func (d *deployCmd) validateArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// deployMatrix deploys every combination of matrix with a fresh celer, so that
+// celer.toml is never changed, and keeps going after failures to fill all cells.
+func (d *deployCmd) deployMatrix() error {
+	targets, err := configs.ParseDeployMatrix(d.matrix, d.celer.BuildType())
+	if err != nil {
+		return color.PrintError(err, "failed to parse matrix.")
+	}
+
+	projectName := d.celer.Project().GetName()
+	var results []configs.MatrixResult
+	for _, target := range targets {
+		startedAt := time.Now()
+		err := d.deployTarget(target)
+		result := configs.MatrixResult{
+			MatrixTarget: target,
+			Status:       configs.MatrixPassed,
+			Seconds:      time.Since(startedAt).Seconds(),
+		}
+		if err != nil {
+			result.Status = configs.MatrixFailed
+			result.Error = matrixError(err)
+		}
+		results = append(results, result)
+	}
+
+	// Print and save consolidated report.
+	output := configs.NewDeployMatrixOutput(projectName, results)
+	header, rows := output.Table()
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for index, cell := range row {
+			widths[index] = max(widths[index], len([]rune(cell)))
+		}
+	}
+	color.Println(color.Title, "=======================================================================")
+	color.Printf(color.Title, "🧩 deploy matrix of %s:\n", projectName)
+	for _, row := range append([][]string{header}, rows...) {
+		for index, cell := range row {
+			fmt.Printf("  %-*s", widths[index], cell)
+		}
+		fmt.Println()
+	}
+	color.Println(color.Title, "=======================================================================")
+
+	reportPath, err := output.Write(d.format)
+	if err != nil {
+		return color.PrintError(err, "failed to write matrix report.")
+	}
+	color.Printf(color.Success, "Matrix report saved to: %s\n", reportPath)
+
+	if output.Summary.Failed > 0 {
+		color.Printf(color.Error, "%d of %d combinations failed to deploy.\n", output.Summary.Failed, output.Summary.Total)
+		return color.ErrSilent
+	}
+	color.PrintSuccess("all %d combinations of %s have been successfully deployed.", output.Summary.Total, projectName)
+	return nil
+}
+
+func (d *deployCmd) deployTarget(target configs.MatrixTarget) error {
+	celer := configs.NewCeler()
+	if err := celer.InitWithPlatform(target.Platform, configs.InitOption{BuildType: target.BuildType}); err != nil {
+		return color.PrintError(err, "failed to init celer with %s.", target)
+	}
+	d.celer, d.target = celer, &target
+	return d.deploy()
+}
+
+// matrixError returns message of error, which is already printed and silent.
+func matrixError(err error) string {
+	if inner := errors.Unwrap(err); inner != nil && err.Error() == "" {
+		return inner.Error()
+	}
+	return err.Error()
+}
+
 func (d *deployCmd) checkProject() error {
 	depcheck := depcheck.NewDepCheck()
 
@@ -145,51 +346,16 @@ func (d *deployCmd) checkProject() error {
 }
 
 func (d *deployCmd) resolveAllRefs() error {
-	// Collect all ports (top-level + transitive dependencies) into []refs.PortInfo.
-	collected := make(map[string]refs.PortInfo)
-	var collect func(nameVersion string) error
-
-	collect = func(nameVersion string) error {
-		if _, exists := collected[nameVersion]; exists {
-			return nil
-		}
-
-		var port configs.Port
-		if err := port.Init(d.celer, nameVersion); err != nil {
-			return err
-		}
-		collected[nameVersion] = refs.PortInfo{
-			NameVersion: port.NameVersion(),
-			Url:         port.Package.Url,
-			Ref:         port.Package.Ref,
-			Checksum:    port.Package.Checksum,
-		}
-		for _, dep := range port.MatchedConfig.Dependencies {
-			if err := collect(dep); err != nil {
-				return err
-			}
-		}
-		for _, dep := range port.MatchedConfig.DevDependencies {
-			if err := collect(dep); err != nil {
-				return err
-			}
-		}
-		return nil
-	}
-
-	for _, port := range d.celer.Project().GetPorts() {
-		if err := collect(port); err != nil {
-			return err
-		}
-	}
-
-	var portInfos []refs.PortInfo
-	for _, info := range collected {
-		portInfos = append(portInfos, info)
+	// Collect all ports (top-level + transitive dependencies).
+	ports, err := lockfile.CollectPorts(d.celer, d.celer.Project().GetPorts())
+	if err != nil {
+		return err
 	}
+	portInfos := lockfile.PortInfos(ports)
 
 	projectName := d.celer.Project().GetName()
-	resolvedRefs := refs.ResolvePorts(portInfos)
+	resolvedRefs := d.resolvePorts(portInfos)
+	d.ports, d.resolvedRefs = ports, resolvedRefs
 
 	// Store resolved commits for use during clone/checkout.
 	commits := make(map[string]string, len(resolvedRefs))
@@ -201,9 +367,13 @@ func (d *deployCmd) resolveAllRefs() error {
 	refs.StoreResolvedCommits(commits)
 	refs.PrintResolvedRefs(projectName, resolvedRefs)
 
-	// Save to file in deployments.
-	timestamp := time.Now().Format(fmt.Sprintf("%s_20060102_150405", projectName))
-	filePath := filepath.Join(dirs.InstalledDir, "celer", "deployments", timestamp+".md")
+	// Save to file in deployments, combination is part of name when deploying matrix.
+	fileBase := projectName
+	if d.target != nil {
+		fileBase = fmt.Sprintf("%s_%s_%s", projectName, d.target.Platform, d.target.BuildType)
+	}
+	timestamp := time.Now().Format("20060102_150405")
+	filePath := filepath.Join(dirs.InstalledDir, "celer", "deployments", fileBase+"_"+timestamp+".md")
 	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
 		return err
 	}
@@ -230,12 +400,63 @@ func (d *deployCmd) resolveAllRefs() error {
 		return fmt.Errorf("ref resolution failed for: %s", strings.Join(failedPorts, ", "))
 	}
 
+	// Abort deploy if any port drifted from the lock file.
+	if d.locked {
+		lock, err := lockfile.Load(projectName)
+		if err != nil {
+			return err
+		}
+		if err := lock.Verify(d.celer, ports, resolvedRefs); err != nil {
+			return err
+		}
+		color.Printf(color.Success, "All ports match lock file: %s\n", lockfile.Path(projectName))
+	}
+
 	return nil
 }
 
+// resolvePorts resolves refs of ports, refs resolved by previous combinations
+// of matrix are reused, since sources are shared by all platforms.
+func (d *deployCmd) resolvePorts(portInfos []refs.PortInfo) []refs.ResolvedRef {
+	if d.refsCache == nil {
+		d.refsCache = make(map[string]refs.ResolvedRef)
+	}
+
+	var unresolved []refs.PortInfo
+	for _, info := range portInfos {
+		if _, ok := d.refsCache[refsCacheKey(info)]; !ok {
+			unresolved = append(unresolved, info)
+		}
+	}
+	resolved := make(map[string]refs.ResolvedRef, len(unresolved))
+	for index, ref := range refs.ResolvePorts(unresolved) {
+		resolved[refsCacheKey(unresolved[index])] = ref
+	}
+
+	resolvedRefs := make([]refs.ResolvedRef, 0, len(portInfos))
+	for _, info := range portInfos {
+		key := refsCacheKey(info)
+		if ref, ok := d.refsCache[key]; ok {
+			resolvedRefs = append(resolvedRefs, ref)
+			continue
+		}
+
+		ref := resolved[key]
+		if ref.Error == "" {
+			d.refsCache[key] = ref
+		}
+		resolvedRefs = append(resolvedRefs, ref)
+	}
+	return resolvedRefs
+}
+
+func refsCacheKey(info refs.PortInfo) string {
+	return strings.Join([]string{info.NameVersion, info.Url, info.Ref, info.Checksum}, "|")
+}
+
 func (d *deployCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var suggestions []string
-	for _, flag := range []string{"--snapshot", "--force", "--strip"} {
+	for _, flag := range []string{"--snapshot", "--force", "--strip", "--split-debug", "--parallel", "--keep-going", "--format", "--locked", "--sbom", "--sbom-format", "--matrix"} {
 		if strings.HasPrefix(flag, toComplete) {
 			suggestions = append(suggestions, flag)
 		}
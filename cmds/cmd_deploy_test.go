@@ -110,6 +110,121 @@ func TestDeployCmd_ArgsValidation(t *testing.T) {
 	}
 }
 
+func TestDeployCmd_MatrixArgs(t *testing.T) {
+	dirs.RemoveAllForTest()
+
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		expectError bool
+	}{
+		{
+			name:  "matrix_should_succeed",
+			flags: map[string]string{"matrix": "x86_64-linux:release"},
+		},
+		{
+			name:        "empty_matrix_should_fail",
+			flags:       map[string]string{"matrix": " "},
+			expectError: true,
+		},
+		{
+			name:        "matrix_with_snapshot_should_fail",
+			flags:       map[string]string{"matrix": "x86_64-linux", "snapshot": "snapshots"},
+			expectError: true,
+		},
+		{
+			name:        "matrix_with_sbom_should_fail",
+			flags:       map[string]string{"matrix": "x86_64-linux", "sbom": "dist/app.spdx.json"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deploy := &deployCmd{}
+			cmd := deploy.Command(configs.NewCeler())
+			for name, value := range test.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("failed to set --%s: %v", name, err)
+				}
+			}
+
+			err := cmd.Args(cmd, nil)
+			if test.expectError && err == nil {
+				t.Fatal("expected args validation error")
+			}
+			if !test.expectError && err != nil {
+				t.Fatalf("expected args validation success, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeployCmd_SbomArgs(t *testing.T) {
+	dirs.RemoveAllForTest()
+
+	tests := []struct {
+		name         string
+		flags        map[string]string
+		expectError  bool
+		expectedSpec string
+	}{
+		{
+			name:         "spdx_by_extension",
+			flags:        map[string]string{"sbom": "dist/app.spdx.json"},
+			expectedSpec: "spdx",
+		},
+		{
+			name:         "cyclonedx_by_extension",
+			flags:        map[string]string{"sbom": "dist/app.cdx.json"},
+			expectedSpec: "cyclonedx",
+		},
+		{
+			name:         "spec_by_flag",
+			flags:        map[string]string{"sbom": "dist/sbom.json", "sbom-format": "cyclonedx"},
+			expectedSpec: "cyclonedx",
+		},
+		{
+			name:        "unknown_extension_should_fail",
+			flags:       map[string]string{"sbom": "dist/sbom.json"},
+			expectError: true,
+		},
+		{
+			name:        "unsupported_spec_should_fail",
+			flags:       map[string]string{"sbom": "dist/sbom.json", "sbom-format": "swid"},
+			expectError: true,
+		},
+		{
+			name:        "spec_without_sbom_should_fail",
+			flags:       map[string]string{"sbom-format": "spdx"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deploy := &deployCmd{}
+			cmd := deploy.Command(configs.NewCeler())
+			for name, value := range test.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatalf("failed to set --%s: %v", name, err)
+				}
+			}
+
+			err := cmd.Args(cmd, nil)
+			if test.expectError && err == nil {
+				t.Fatal("expected args validation error")
+			}
+			if !test.expectError && err != nil {
+				t.Fatalf("expected args validation success, got: %v", err)
+			}
+			if test.expectedSpec != "" && deploy.sbomSpec != test.expectedSpec {
+				t.Errorf("expected sbom spec %s, got %s", test.expectedSpec, deploy.sbomSpec)
+			}
+		})
+	}
+}
+
 func TestDeployCmd_Completion(t *testing.T) {
 	dirs.RemoveAllForTest()
 
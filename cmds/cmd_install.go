@@ -6,14 +6,17 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/celer-pkg/celer/buildsystems"
 	"github.com/celer-pkg/celer/buildtools"
 	"github.com/celer-pkg/celer/configs"
 	"github.com/celer-pkg/celer/depcheck"
+	"github.com/celer-pkg/celer/lockfile"
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/errors"
 	"github.com/celer-pkg/celer/pkgs/expr"
 	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/refs"
 
 	"github.com/spf13/cobra"
 )
@@ -25,6 +28,14 @@ type installCmd struct {
 	recursive      bool
 	jobs           int
 	verbose        bool
+	parallel       int
+	keepGoing      bool
+	fromStage      string
+	resultFile     string
+	platform       string
+	buildType      string
+	format         string
+	locked         bool
 	jobsChanged    bool
 	verboseChanged bool
 }
@@ -46,6 +57,8 @@ FEATURES:
   • Force reinstallation with dependency handling
   • Best-effort package cache storing by default
   • Parallel build support
+  • Build independent ports at the same time
+  • Resume failed builds from the first incomplete stage
   • Circular dependency detection
   • Version conflict checking
 
@@ -55,13 +68,22 @@ FLAGS:
   -r, --recursive   With --force, recursively reinstall dependencies
   -j, --jobs        Number of parallel build jobs (default: system cores)
   -v, --verbose     Enable verbose output for debugging
+  -p, --parallel    Number of ports to build at the same time (default: 1)
+      --keep-going  With --parallel, keep building independent ports after a failure
+      --from-stage  Rebuild from a stage: patch, configure, build, install or fixups
+      --format      Format of install report: text or json (default: text)
+      --locked      Fail when sources drift from the lock file of current project
 
 EXAMPLES:
   celer install opencv@4.8.0
   celer install opencv@4.8.0 eigen@3.4.0
   celer install --dev gtest@1.12.1
   celer install --force --recursive boost@1.82.0
-  celer install --jobs=8 --verbose opencv@4.8.0`,
+  celer install --jobs=8 --verbose opencv@4.8.0
+  celer install --parallel=4 --keep-going opencv@4.8.0
+  celer install --from-stage=build opencv@4.8.0
+  celer install --format=json opencv@4.8.0
+  celer install --locked opencv@4.8.0`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			i.jobsChanged = cmd.Flags().Changed("jobs")
@@ -78,6 +100,19 @@ EXAMPLES:
 	flags.BoolVarP(&i.recursive, "recursive", "r", false, "combine with --force, recursively reinstall dependencies.")
 	flags.IntVarP(&i.jobs, "jobs", "j", i.celer.Jobs(), "the number of jobs to run in parallel.")
 	flags.BoolVarP(&i.verbose, "verbose", "v", false, "verbose detail information.")
+	flags.IntVarP(&i.parallel, "parallel", "p", 1, "the number of ports to build at the same time.")
+	flags.BoolVarP(&i.keepGoing, "keep-going", "", false, "combine with --parallel, keep building independent ports after a failure.")
+	flags.StringVar(&i.fromStage, "from-stage", "", "rebuild from the stage: patch, configure, build, install or fixups.")
+	flags.BoolVar(&i.locked, "locked", false, "fail when sources drift from the lock file generated by `celer lock`.")
+	flags.StringVar(&i.format, "format", configs.OutputFormatText, "format of install report, text or json.")
+
+	// Used by the install scheduler to run a port build in worker process.
+	flags.StringVar(&i.resultFile, "result-file", "", "write where the port was installed from into this file.")
+	flags.MarkHidden("result-file")
+	flags.StringVar(&i.platform, "platform", "", "install with this platform instead of the one in celer.toml.")
+	flags.MarkHidden("platform")
+	flags.StringVar(&i.buildType, "build-type", "", "install with this build type instead of the one in celer.toml.")
+	flags.MarkHidden("build-type")
 
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
@@ -96,7 +131,7 @@ func (i *installCmd) runInstall(nameVersions []string) error {
 		cleanedNameVersions = append(cleanedNameVersions, cleanedNameVersion)
 	}
 
-	if err := i.celer.Init(); err != nil {
+	if err := i.celer.InitWithPlatform(i.platform, configs.InitOption{BuildType: i.buildType}); err != nil {
 		return color.PrintError(err, "failed to initialize celer.")
 	}
 
@@ -120,6 +155,28 @@ func (i *installCmd) runInstall(nameVersions []string) error {
 	return nil
 }
 
+// checkLocked verifies port and its dependencies against lock file of current project,
+// then pins them to the locked commits.
+func (i *installCmd) checkLocked(nameVersion string) error {
+	projectName := i.celer.Project().GetName()
+	lock, err := lockfile.Load(projectName)
+	if err != nil {
+		return err
+	}
+
+	ports, err := lockfile.CollectPorts(i.celer, []string{nameVersion})
+	if err != nil {
+		return err
+	}
+	resolvedRefs := refs.ResolvePorts(lockfile.PortInfos(ports))
+	if err := lock.Verify(i.celer, ports, resolvedRefs); err != nil {
+		return err
+	}
+
+	refs.StoreResolvedCommits(lock.Commits())
+	return nil
+}
+
 // validateAndCleanInput validates and cleans the package name@version input.
 func (i *installCmd) validateAndCleanInput(nameVersion string) (string, error) {
 	if strings.TrimSpace(nameVersion) == "" {
@@ -181,10 +238,22 @@ func (i *installCmd) install(nameVersion string) error {
 		return color.PrintError(err, "failed to check version conflict.")
 	}
 
+	// Check and pin sources with lock file.
+	if i.locked {
+		if err := i.checkLocked(nameVersion); err != nil {
+			return color.PrintError(err, "failed to check lock file.")
+		}
+	}
+
 	// Do install.
 	options := configs.InstallOptions{
-		Force:     i.force,
-		Recursive: i.recursive,
+		Force:        i.force,
+		Recursive:    i.recursive,
+		Parallel:     i.parallel,
+		KeepGoing:    i.keepGoing,
+		FromStage:    i.fromStage,
+		ResultFile:   i.resultFile,
+		ReportFormat: i.format,
 	}
 	fromWhere, err := port.Install(options)
 	if err != nil {
@@ -212,7 +281,25 @@ func (i *installCmd) overrideFlags() error {
 		if i.jobs <= 0 {
 			return fmt.Errorf("--jobs must be greater than 0")
 		}
-		i.celer.SetJobs(i.jobs)
+
+		// Workers only get a share of the jobs, that must not be saved into celer.toml.
+		if i.resultFile != "" {
+			i.celer.Main.Jobs = i.jobs
+		} else {
+			i.celer.SetJobs(i.jobs)
+		}
+	}
+
+	if i.parallel <= 0 {
+		return fmt.Errorf("--parallel must be greater than 0")
+	}
+
+	if err := configs.ValidateOutputFormat(i.format); err != nil {
+		return err
+	}
+
+	if err := buildsystems.ValidateStage(i.fromStage); err != nil {
+		return fmt.Errorf("invalid --from-stage -> %w", err)
 	}
 
 	if i.verboseChanged {
@@ -251,8 +338,10 @@ func (i *installCmd) buildSuggestions(suggestions *[]string, portDir string, toC
 func (i *installCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var suggestions []string
 
-	if fileio.PathExists(dirs.PortsDir) {
-		i.buildSuggestions(&suggestions, dirs.PortsDir, toComplete)
+	for _, registry := range i.celer.Registries() {
+		if fileio.PathExists(registry.Dir) {
+			i.buildSuggestions(&suggestions, registry.Dir, toComplete)
+		}
 	}
 
 	projectName := i.celer.GetProjectName()
@@ -270,6 +359,11 @@ func (i *installCmd) completion(cmd *cobra.Command, args []string, toComplete st
 		"--recursive", "-r",
 		"--jobs", "-j",
 		"--verbose", "-v",
+		"--parallel", "-p",
+		"--keep-going",
+		"--from-stage",
+		"--format",
+		"--locked",
 	}
 
 	for _, flag := range commands {
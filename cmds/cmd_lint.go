@@ -0,0 +1,216 @@
+package cmds
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+
+	"github.com/spf13/cobra"
+)
+
+type lintCmd struct {
+	celer    *configs.Celer
+	all      bool
+	platform bool
+	project  bool
+	format   string
+}
+
+// lintOutput is the JSON schema of `celer lint --format=json`.
+type lintOutput struct {
+	configs.OutputHeader
+	Files       int                      `json:"files"`
+	Errors      int                      `json:"errors"`
+	Warnings    int                      `json:"warnings"`
+	Diagnostics []configs.LintDiagnostic `json:"diagnostics"`
+}
+
+func (l *lintCmd) Command(celer *configs.Celer) *cobra.Command {
+	l.celer = celer
+	command := &cobra.Command{
+		Use:   "lint",
+		Short: "Check port, platform and project TOML files.",
+		Long: `Check port, platform and project TOML files.
+
+Unknown keys are reported, since they're silently ignored when ports, platforms
+and projects are loaded, typos like "build_sytem" would never take effect.
+Build configs of ports are validated, and missing checksums of archives, missing
+patches, build configs that never match any platform and fields used with the
+wrong build system are reported as well.
+
+Diagnostics are printed as "file:line: severity: message", and the command exits
+with non-zero when any error is found, so it can gate CI.
+
+Examples:
+  celer lint zlib@1.3.1             # Lint port.toml of zlib@1.3.1, including the ones in projects
+  celer lint --platform             # Lint all platforms in conf/platforms
+  celer lint --project              # Lint all projects in conf/projects
+  celer lint --all                  # Lint all ports, platforms and projects
+  celer lint --all --format=json    # Print diagnostics as json`,
+		Args: l.validateArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return l.lint(args)
+		},
+		ValidArgsFunction: l.completion,
+	}
+
+	flags := command.Flags()
+	flags.BoolVar(&l.all, "all", false, "Lint all ports, platforms and projects.")
+	flags.BoolVar(&l.platform, "platform", false, "Lint all platforms in conf/platforms.")
+	flags.BoolVar(&l.project, "project", false, "Lint all projects in conf/projects.")
+	flags.StringVar(&l.format, "format", configs.OutputFormatText, "Output format, text or json.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (l *lintCmd) validateArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+		return err
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	platform, _ := cmd.Flags().GetBool("platform")
+	project, _ := cmd.Flags().GetBool("project")
+	if all && (len(args) > 0 || platform || project) {
+		return fmt.Errorf("--all cannot be used with name@version, --platform or --project")
+	}
+	if !all && len(args) == 0 && !platform && !project {
+		return fmt.Errorf("name@version, --platform, --project or --all is required")
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	return configs.ValidateOutputFormat(format)
+}
+
+func (l *lintCmd) lint(args []string) error {
+	linter, err := configs.NewLinter(l.celer.Registries())
+	if err != nil {
+		return color.PrintError(err, "failed to init linter.")
+	}
+
+	// Collect files to lint.
+	var portFiles, platformFiles, projectFiles []string
+	if len(args) > 0 {
+		if portFiles, err = configs.LintPortFiles(l.celer.Registries(), args[0]); err != nil {
+			return color.PrintError(err, "failed to lint %s.", args[0])
+		}
+	}
+	if l.all {
+		if portFiles, err = configs.LintAllPortFiles(l.celer.Registries()); err != nil {
+			return color.PrintError(err, "failed to collect ports.")
+		}
+	}
+	if l.all || l.platform {
+		if platformFiles, err = configs.LintPlatformFiles(); err != nil {
+			return color.PrintError(err, "failed to collect platforms.")
+		}
+	}
+	if l.all || l.project {
+		if projectFiles, err = configs.LintProjectFiles(); err != nil {
+			return color.PrintError(err, "failed to collect projects.")
+		}
+	}
+
+	// Lint them all.
+	var diagnostics []configs.LintDiagnostic
+	for _, group := range []struct {
+		files []string
+		lint  func(string) ([]configs.LintDiagnostic, error)
+	}{
+		{portFiles, linter.LintPort},
+		{platformFiles, linter.LintPlatform},
+		{projectFiles, linter.LintProject},
+	} {
+		for _, file := range group.files {
+			fileDiagnostics, err := group.lint(file)
+			if err != nil {
+				return color.PrintError(err, "failed to lint %s.", file)
+			}
+			diagnostics = append(diagnostics, fileDiagnostics...)
+		}
+	}
+
+	var errors, warnings int
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == configs.LintError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	files := len(portFiles) + len(platformFiles) + len(projectFiles)
+
+	if l.format == configs.OutputFormatJSON {
+		if diagnostics == nil {
+			diagnostics = []configs.LintDiagnostic{}
+		}
+		if err := configs.PrintJSON(lintOutput{
+			OutputHeader: configs.NewOutputHeader("lint"),
+			Files:        files,
+			Errors:       errors,
+			Warnings:     warnings,
+			Diagnostics:  diagnostics,
+		}); err != nil {
+			return err
+		}
+		if errors > 0 {
+			return color.ErrSilent
+		}
+		return nil
+	}
+
+	for _, diagnostic := range diagnostics {
+		style := color.Warning
+		if diagnostic.Severity == configs.LintError {
+			style = color.Error
+		}
+		color.Println(style, diagnostic.String())
+	}
+
+	if errors > 0 {
+		return color.PrintError(fmt.Errorf("%d error(s) and %d warning(s) in %d file(s)", errors, warnings, files),
+			"lint failed.")
+	}
+	if warnings > 0 {
+		color.Printf(color.Summary, "\ntotal: %d warning(s) in %d file(s)\n", warnings, files)
+		return nil
+	}
+	color.PrintSuccess("%d file(s) are checked, no problem is found.", files)
+	return nil
+}
+
+func (l *lintCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var suggestions []string
+
+	install := installCmd{celer: l.celer}
+	for _, registry := range l.celer.Registries() {
+		if fileio.PathExists(registry.Dir) {
+			install.buildSuggestions(&suggestions, registry.Dir, toComplete)
+		}
+	}
+	if projectName := l.celer.GetProjectName(); projectName != "" {
+		projectPortsDir := filepath.Join(dirs.ConfProjectsDir, projectName)
+		if fileio.PathExists(projectPortsDir) {
+			install.buildSuggestions(&suggestions, projectPortsDir, toComplete)
+		}
+	}
+
+	for _, flag := range []string{"--all", "--platform", "--project", "--format"} {
+		if strings.HasPrefix(flag, toComplete) {
+			suggestions = append(suggestions, flag)
+		}
+	}
+
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,83 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/celer-pkg/celer/buildtools"
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/lockfile"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/refs"
+
+	"github.com/spf13/cobra"
+)
+
+type lockCmd struct {
+	celer *configs.Celer
+}
+
+func (l *lockCmd) Command(celer *configs.Celer) *cobra.Command {
+	l.celer = celer
+	command := &cobra.Command{
+		Use:   "lock",
+		Short: "Lock resolved sources of current project.",
+		Long: `Lock resolved sources of current project.
+
+This command resolves all ports of current project and their dependencies,
+then writes a lock file next to the project file (conf/projects/<name>.lock.toml).
+For every port, it records:
+  • the resolved commit of git source, or the sha-256 of archive source
+  • the sha-256 of its port.toml
+
+Use "celer install --locked" or "celer deploy --locked" to build exactly
+the locked sources, they fail when a branch ref has drifted or a port file
+has changed since locked.
+
+Examples:
+  celer lock                  # Lock current project
+  celer deploy --locked       # Deploy with locked sources`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return l.lock()
+		},
+	}
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (l *lockCmd) lock() error {
+	if err := l.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to initialize celer.")
+	}
+	if err := buildtools.CheckTools(l.celer, "git"); err != nil {
+		return color.PrintError(err, "failed to check build tool: git")
+	}
+
+	projectName := l.celer.Project().GetName()
+	if projectName == "" {
+		return color.PrintError(fmt.Errorf("no project is selected"), "failed to lock project.")
+	}
+
+	ports, err := lockfile.CollectPorts(l.celer, l.celer.Project().GetPorts())
+	if err != nil {
+		return color.PrintError(err, "failed to collect ports of %s.", projectName)
+	}
+
+	resolvedRefs := refs.ResolvePorts(lockfile.PortInfos(ports))
+	refs.PrintResolvedRefs(projectName, resolvedRefs)
+
+	lock, err := lockfile.Generate(l.celer, ports, resolvedRefs)
+	if err != nil {
+		return color.PrintError(err, "failed to lock %s.", projectName)
+	}
+	if err := lock.Save(); err != nil {
+		return color.PrintError(err, "failed to save lock file.")
+	}
+
+	color.PrintSuccess("%d port(s) of %s are locked.", len(lock.Ports), projectName)
+	color.PrintHint("Location: %s\n", lockfile.Path(projectName))
+	return nil
+}
@@ -0,0 +1,110 @@
+package cmds
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+
+	"github.com/spf13/cobra"
+)
+
+type outdatedCmd struct {
+	celer   *configs.Celer
+	project bool
+	format  string
+}
+
+// outdatedOutput is the JSON schema of `celer outdated --format=json`.
+type outdatedOutput struct {
+	configs.OutputHeader
+	Ports []configs.OutdatedPort `json:"ports"`
+}
+
+func (o *outdatedCmd) Command(celer *configs.Celer) *cobra.Command {
+	o.celer = celer
+	command := &cobra.Command{
+		Use:   "outdated",
+		Short: "List newer upstream versions of ports.",
+		Long: `List newer upstream versions of ports.
+
+For ports with git url, semver-like tags of the remote repo are checked, such as
+"v1.2.3", "zlib-1.3.1" and "curl-8_5_0". Archive urls of github releases are
+checked by tags as well. For other archive urls, the parent dir of url is read
+and archive names are matched with the version replaced, and they can be
+configured in [package] of port.toml:
+
+  update_url = "https://zlib.net/"                        # Page that lists releases
+  update_pattern = "zlib-([0-9]+(?:\\.[0-9]+)+)\\.tar\\.gz"   # Regex that captures version
+
+Newer version can be created from the existing port with:
+
+  celer create --port=name@newversion --like=name@oldversion
+
+Examples:
+  celer outdated                    # Check latest version of all ports
+  celer outdated --project          # Check ports of current project
+  celer outdated --format=json      # Print result as json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.doExecute()
+		},
+	}
+
+	// Register flags.
+	command.Flags().BoolVar(&o.project, "project", false, "check ports of current project only.")
+	command.Flags().StringVar(&o.format, "format", configs.OutputFormatText, "output format, text or json.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (o *outdatedCmd) doExecute() error {
+	if err := configs.ValidateOutputFormat(o.format); err != nil {
+		return color.PrintError(err, "invalid format.")
+	}
+
+	if err := o.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+
+	nameVersions, err := o.celer.OutdatedPortNames(o.project)
+	if err != nil {
+		return color.PrintError(err, "failed to collect ports.")
+	}
+	ports := o.celer.CheckOutdated(nameVersions)
+
+	if o.format == configs.OutputFormatJSON {
+		if ports == nil {
+			ports = []configs.OutdatedPort{}
+		}
+		return configs.PrintJSON(outdatedOutput{
+			OutputHeader: configs.NewOutputHeader("outdated"),
+			Ports:        ports,
+		})
+	}
+
+	var outdated, failed int
+	for _, port := range ports {
+		switch {
+		case port.Error != "":
+			failed++
+			color.Printf(color.Warning, "%-36s %s\n", port.Port, port.Error)
+		case port.Latest != "":
+			outdated++
+			name, _, _ := strings.Cut(port.Port, "@")
+			fmt.Printf("%-36s %-12s -> %-12s (%s, %d newer)\n", port.Port, port.Current, port.Latest, port.Source, len(port.Newer))
+			color.Printf(color.Hint, "  celer create --port=%s@%s --like=%s\n", name, port.Latest, port.Port)
+		}
+	}
+
+	if outdated == 0 && failed == 0 {
+		color.PrintSuccess("%d port(s) are checked, all are up to date.", len(ports))
+		return nil
+	}
+	color.Printf(color.Summary, "\ntotal: %d port(s) are checked, %d outdated, %d failed to check.\n", len(ports), outdated, failed)
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmds
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+
+	"github.com/spf13/cobra"
+)
+
+type ownsCmd struct {
+	celer  *configs.Celer
+	format string
+}
+
+// ownsOutput is the JSON schema of `celer owns --format=json`.
+type ownsOutput struct {
+	configs.OutputHeader
+	Path  string      `json:"path"`
+	Files []ownedFile `json:"files"`
+}
+
+type ownedFile struct {
+	File   string   `json:"file"`
+	Owners []string `json:"owners"`
+}
+
+func (o *ownsCmd) Command(celer *configs.Celer) *cobra.Command {
+	o.celer = celer
+	command := &cobra.Command{
+		Use:   "owns",
+		Short: "Query which package installed the specified file.",
+		Long: `Query which package installed the specified file.
+
+The path can be absolute, relative to the installed dir, or relative to the
+installed dir of current platform, project and build type. When there is no
+exact match, all installed files that end with the path are reported.
+
+Examples:
+  # Query with path relative to installed dir of current project
+  celer owns include/zlib.h
+
+  # Query with path relative to installed dir
+  celer owns x86_64-linux-dev/bin/nasm
+
+  # Query with absolute path
+  celer owns /home/phil/workspace/installed/x86_64-linux/project_001/release/lib/libz.so
+
+  # Print owners as json
+  celer owns lib/libz.so --format=json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.doExecute(args[0])
+		},
+	}
+
+	// Register flags.
+	command.Flags().StringVar(&o.format, "format", configs.OutputFormatText, "output format, text or json.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (o *ownsCmd) doExecute(path string) error {
+	if err := configs.ValidateOutputFormat(o.format); err != nil {
+		return color.PrintError(err, "invalid format.")
+	}
+
+	if err := o.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+
+	owners, err := configs.LoadFileOwners()
+	if err != nil {
+		return color.PrintError(err, "failed to load installed files.")
+	}
+
+	var files []ownedFile
+	for _, file := range owners.Search(o.relativePath(owners, path)) {
+		var nameVersions []string
+		for _, owner := range owners.Owners(file) {
+			nameVersions = append(nameVersions, owner.String())
+		}
+		files = append(files, ownedFile{
+			File:   filepath.ToSlash(file),
+			Owners: nameVersions,
+		})
+	}
+
+	if o.format == configs.OutputFormatJSON {
+		if files == nil {
+			files = []ownedFile{}
+		}
+		return configs.PrintJSON(ownsOutput{
+			OutputHeader: configs.NewOutputHeader("owns"),
+			Path:         path,
+			Files:        files,
+		})
+	}
+
+	if len(files) == 0 {
+		return color.PrintError(fmt.Errorf("%s is not installed by any package", path), "failed to query owner.")
+	}
+	for _, file := range files {
+		color.Printf(color.Title, "%s\n", file.File)
+		for _, owner := range file.Owners {
+			fmt.Printf("  %s\n", owner)
+		}
+	}
+	return nil
+}
+
+// relativePath converts path to be relative to installed dir, paths that exist in
+// installed dir of current project are preferred.
+func (o *ownsCmd) relativePath(owners configs.FileOwners, path string) string {
+	if filepath.IsAbs(path) {
+		if relative, err := filepath.Rel(dirs.InstalledDir, path); err == nil && !strings.HasPrefix(relative, "..") {
+			return relative
+		}
+		return path
+	}
+
+	for _, libraryDir := range []string{
+		o.celer.LibraryFolder(),
+		o.celer.Platform().GetHostName() + "-dev",
+	} {
+		candidate := filepath.Join(libraryDir, path)
+		if len(owners.Owners(candidate)) > 0 {
+			return candidate
+		}
+	}
+	return path
+}
@@ -15,14 +15,24 @@ import (
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/errors"
+	"github.com/celer-pkg/celer/pkgs/expr"
 	"github.com/celer-pkg/celer/pkgs/fileio"
 
 	"github.com/spf13/cobra"
 )
 
 type reverseCmd struct {
-	celer *configs.Celer
-	dev   bool
+	celer  *configs.Celer
+	dev    bool
+	format string
+}
+
+// reverseOutput is the JSON schema of `celer reverse --format=json`.
+type reverseOutput struct {
+	configs.OutputHeader
+	Target     string   `json:"target"`
+	Dev        bool     `json:"dev"`
+	Dependents []string `json:"dependents"`
 }
 
 func (r *reverseCmd) Command(celer *configs.Celer) *cobra.Command {
@@ -43,7 +53,10 @@ Examples:
   celer reverse nasm@2.16.03 --dev
   
   # Check reverse dependencies before removing a package
-  celer reverse boost@1.87.0`,
+  celer reverse boost@1.87.0
+
+  # Print reverse dependencies as json
+  celer reverse zlib@1.3.1 --format=json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return r.doExecute(args)
@@ -53,6 +66,7 @@ Examples:
 
 	// Register flags.
 	command.Flags().BoolVarP(&r.dev, "dev", "d", false, "include dev dependencies in reverse lookup.")
+	command.Flags().StringVar(&r.format, "format", configs.OutputFormatText, "output format, text or json.")
 
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
@@ -65,6 +79,9 @@ func (r *reverseCmd) doExecute(args []string) error {
 	if err := r.validatePackageName(args[0]); err != nil {
 		return fmt.Errorf("invalid package name -> %w", err)
 	}
+	if err := configs.ValidateOutputFormat(r.format); err != nil {
+		return fmt.Errorf("invalid format -> %w", err)
+	}
 
 	if err := r.celer.Init(); err != nil {
 		return fmt.Errorf("failed to init celer -> %w", err)
@@ -75,6 +92,15 @@ func (r *reverseCmd) doExecute(args []string) error {
 		return fmt.Errorf("failed to query %s -> %w", args[0], err)
 	}
 
+	if r.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(reverseOutput{
+			OutputHeader: configs.NewOutputHeader("reverse"),
+			Target:       args[0],
+			Dev:          r.dev,
+			Dependents:   expr.If(libraries != nil, libraries, []string{}),
+		})
+	}
+
 	r.displayResults(args[0], libraries)
 	return nil
 }
@@ -100,6 +126,10 @@ func (r *reverseCmd) query(target string) ([]string, error) {
 			libName := filepath.Base(filepath.Dir(portDir))
 			nameVersion := libName + "@" + libVersion
 
+			// Ports filtered out by registry are never looked up.
+			if registry, ok := r.celer.Registries().RegistryOf(path); ok && !registry.Provides(libName) {
+				return nil
+			}
 			if visited[nameVersion] {
 				return nil
 			}
@@ -112,8 +142,10 @@ func (r *reverseCmd) query(target string) ([]string, error) {
 		})
 	}
 
-	if fileio.PathExists(dirs.PortsDir) {
-		walkPorts(dirs.PortsDir)
+	for _, registry := range r.celer.Registries() {
+		if fileio.PathExists(registry.Dir) {
+			walkPorts(registry.Dir)
+		}
 	}
 	projectName := r.celer.GetProjectName()
 	if projectName != "" {
@@ -188,8 +220,10 @@ func (r *reverseCmd) completion(cmd *cobra.Command, args []string, toComplete st
 		})
 	}
 
-	if fileio.PathExists(dirs.PortsDir) {
-		walkPorts(dirs.PortsDir)
+	for _, registry := range r.celer.Registries() {
+		if fileio.PathExists(registry.Dir) {
+			walkPorts(registry.Dir)
+		}
 	}
 	projectName := r.celer.GetProjectName()
 	if projectName != "" {
@@ -199,7 +233,7 @@ func (r *reverseCmd) completion(cmd *cobra.Command, args []string, toComplete st
 		}
 	}
 
-	for _, flag := range []string{"--dev", "-d"} {
+	for _, flag := range []string{"--dev", "-d", "--format"} {
 		if strings.HasPrefix(flag, toComplete) {
 			suggestions = append(suggestions, flag)
 		}
@@ -0,0 +1,149 @@
+package cmds
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/buildtools"
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/lockfile"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/refs"
+	"github.com/celer-pkg/celer/sbom"
+
+	"github.com/spf13/cobra"
+)
+
+type sbomCmd struct {
+	celer  *configs.Celer
+	output string
+	specs  []string
+}
+
+func (s *sbomCmd) Command(celer *configs.Celer) *cobra.Command {
+	s.celer = celer
+	command := &cobra.Command{
+		Use:   "sbom",
+		Short: "Generate software bill of materials of current project.",
+		Long: `Generate software bill of materials of current project.
+
+This command resolves all ports of current project and their dependencies,
+then writes SPDX 2.3 json (<project>.spdx.json) and CycloneDX 1.5 json
+(<project>.cdx.json) into the output directory. For every port, it records:
+  • name, version and download location
+  • the resolved commit of git source, or the sha-256 of archive source
+  • the license declared by "license" in [package] of port.toml
+  • runtime and dev dependencies
+
+Use "celer deploy --sbom=<path>" to generate one along with deployment.
+
+Examples:
+  celer sbom                          # Write both specs into workspace
+  celer sbom --output=dist            # Write both specs into dist
+  celer sbom --spec=spdx              # Write SPDX only`,
+		Args: s.validateArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.generate()
+		},
+		ValidArgsFunction: s.completion,
+	}
+
+	flags := command.Flags()
+	flags.StringVar(&s.output, "output", "", "Directory to write sbom files, default is workspace.")
+	flags.StringSliceVar(&s.specs, "spec", sbom.Specs, "Specs of sbom, spdx or cyclonedx.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (s *sbomCmd) validateArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		return err
+	}
+
+	specs, err := cmd.Flags().GetStringSlice("spec")
+	if err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if err := sbom.ValidateSpec(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sbomCmd) generate() error {
+	if err := s.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to initialize celer.")
+	}
+	if err := buildtools.CheckTools(s.celer, "git"); err != nil {
+		return color.PrintError(err, "failed to check build tool: git")
+	}
+
+	projectName := s.celer.Project().GetName()
+	if projectName == "" {
+		return color.PrintError(fmt.Errorf("no project is selected"), "failed to generate sbom.")
+	}
+
+	ports, err := lockfile.CollectPorts(s.celer, s.celer.Project().GetPorts())
+	if err != nil {
+		return color.PrintError(err, "failed to collect ports of %s.", projectName)
+	}
+
+	resolvedRefs := refs.ResolvePorts(lockfile.PortInfos(ports))
+	refs.PrintResolvedRefs(projectName, resolvedRefs)
+
+	output := strings.TrimSpace(s.output)
+	if output == "" {
+		output = dirs.WorkspaceDir
+	}
+	if err := writeSBOM(s.celer, ports, resolvedRefs, filepath.Clean(output), s.specs); err != nil {
+		return color.PrintError(err, "failed to generate sbom of %s.", projectName)
+	}
+	return nil
+}
+
+func (s *sbomCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var suggestions []string
+	for _, flag := range []string{"--output", "--spec"} {
+		if strings.HasPrefix(flag, toComplete) {
+			suggestions = append(suggestions, flag)
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// writeSBOM writes sbom of ports in specs into dir, it's shared by sbom and deploy.
+func writeSBOM(celer *configs.Celer, ports []configs.Port, resolvedRefs []refs.ResolvedRef, dir string, specs []string) error {
+	document, err := sbom.Generate(celer, ports, resolvedRefs)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range specs {
+		filePath, err := document.Write(dir, spec)
+		if err != nil {
+			return err
+		}
+		color.Printf(color.Success, "SBOM (%s) saved to: %s\n", spec, filePath)
+	}
+	return nil
+}
+
+// writeSBOMFile writes sbom of ports in spec into file path.
+func writeSBOMFile(celer *configs.Celer, ports []configs.Port, resolvedRefs []refs.ResolvedRef, filePath, spec string) error {
+	document, err := sbom.Generate(celer, ports, resolvedRefs)
+	if err != nil {
+		return err
+	}
+	if err := document.WriteFile(filePath, spec); err != nil {
+		return err
+	}
+	color.Printf(color.Success, "SBOM (%s) saved to: %s\n", spec, filePath)
+	return nil
+}
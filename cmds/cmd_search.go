@@ -9,13 +9,23 @@ import (
 	"github.com/celer-pkg/celer/configs"
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
 	"github.com/celer-pkg/celer/pkgs/fileio"
 
 	"github.com/spf13/cobra"
 )
 
 type searchCmd struct {
-	celer *configs.Celer
+	celer  *configs.Celer
+	format string
+}
+
+// searchOutput is the JSON schema of `celer search --format=json`.
+type searchOutput struct {
+	configs.OutputHeader
+	Pattern    string            `json:"pattern"`
+	Results    []string          `json:"results"`
+	Registries map[string]string `json:"registries"` // Registry that each result came from.
 }
 
 func (s *searchCmd) Command(celer *configs.Celer) *cobra.Command {
@@ -26,7 +36,9 @@ func (s *searchCmd) Command(celer *configs.Celer) *cobra.Command {
 		Long: `Search available ports from ports repository.
 
 This command searches for ports by name and version pattern. It supports
-wildcard matching for flexible searches.
+wildcard matching for flexible searches. Ports are searched in project,
+registries of celer.toml and the default ports repo by priority, and every
+result shows the registry it came from.
 
 Pattern matching rules:
   - Exact match:     zlib@1.3.1
@@ -38,7 +50,8 @@ Examples:
   celer search zlib@1.3.1    # Search for exact match
   celer search zlib*         # Search for all zlib versions
   celer search *@1.3.1       # Search for all ports with version 1.3.1
-  celer search *ffmpeg*      # Search for all ports containing 'ffmpeg'`,
+  celer search *ffmpeg*      # Search for all ports containing 'ffmpeg'
+  celer search zlib* --format=json  # Print search results as json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return s.doSearch(args[0])
@@ -46,6 +59,9 @@ Examples:
 		ValidArgsFunction: s.completion,
 	}
 
+	// Register flags.
+	command.Flags().StringVar(&s.format, "format", configs.OutputFormatText, "output format, text or json.")
+
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
 	command.SilenceUsage = true
@@ -53,24 +69,41 @@ Examples:
 }
 
 func (s *searchCmd) doSearch(pattern string) error {
+	if err := configs.ValidateOutputFormat(s.format); err != nil {
+		return color.PrintError(err, "Invalid output format.")
+	}
+
 	// Initialize celer configuration.
 	if err := s.celer.Init(); err != nil {
 		return color.PrintError(err, "Failed to initialize celer.")
 	}
 
 	// Perform search.
-	libraries, err := s.search(pattern)
+	libraries, registries, err := s.search(pattern)
 	if err != nil {
 		return color.PrintError(err, "Failed to search available ports.")
 	}
 
 	// Display results.
+	if s.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(searchOutput{
+			OutputHeader: configs.NewOutputHeader("search"),
+			Pattern:      pattern,
+			Results:      expr.If(libraries != nil, libraries, []string{}),
+			Registries:   registries,
+		})
+	}
+
 	title := fmt.Sprintf("search results that match pattern '%s':", pattern)
 	color.Println(color.Title, title)
 	color.Println(color.Line, strings.Repeat("-", len(title)))
 	if len(libraries) > 0 {
+		var width int
+		for _, lib := range libraries {
+			width = max(width, len(lib))
+		}
 		for _, lib := range libraries {
-			color.Println(color.Hint, lib)
+			color.Printf(color.Hint, "%-*s  [%s]\n", width, lib, registries[lib])
 		}
 		color.Println(color.Line, strings.Repeat("-", len(title)))
 		color.Printf(color.Summary, "total: %d port(s)\n", len(libraries))
@@ -81,11 +114,14 @@ func (s *searchCmd) doSearch(pattern string) error {
 	return nil
 }
 
-func (s *searchCmd) search(pattern string) ([]string, error) {
+// search returns matched ports and the registry that each one came from,
+// a port shadowed by the one in higher priority registry is not returned.
+func (s *searchCmd) search(pattern string) ([]string, map[string]string, error) {
 	var results []string
+	registries := make(map[string]string)
 
 	// Helper function to search in a directory.
-	searchInDir := func(dir string) error {
+	searchInDir := func(dir, registry string, provides func(name string) bool) error {
 		if !fileio.PathExists(dir) {
 			return nil
 		}
@@ -101,31 +137,29 @@ func (s *searchCmd) search(pattern string) ([]string, error) {
 				libVersion := filepath.Base(portDir)            // 1.0.0
 				libName := filepath.Base(filepath.Dir(portDir)) // testlib
 				nameVersion := libName + "@" + libVersion
+				if _, ok := registries[nameVersion]; ok || !provides(libName) {
+					return nil
+				}
 
+				var matched bool
 				switch {
 				case !strings.Contains(pattern, "*"):
-					if nameVersion == pattern {
-						results = append(results, nameVersion)
-					}
+					matched = nameVersion == pattern
 
 				case strings.HasPrefix(pattern, "*") && strings.Count(pattern, "*") == 1:
-					if strings.HasSuffix(nameVersion, pattern[1:]) {
-						results = append(results, nameVersion)
-					}
+					matched = strings.HasSuffix(nameVersion, pattern[1:])
 
 				case strings.HasSuffix(pattern, "*") && strings.Count(pattern, "*") == 1:
-					if strings.HasPrefix(nameVersion, pattern[:len(pattern)-1]) {
-						results = append(results, nameVersion)
-					}
+					matched = strings.HasPrefix(nameVersion, pattern[:len(pattern)-1])
 
 				case strings.Count(pattern, "*") == 2 && strings.HasPrefix(pattern, "*") && strings.HasSuffix(pattern, "*"):
 					content := pattern[1 : len(pattern)-1]
-					if strings.Contains(nameVersion, content) {
-						results = append(results, nameVersion)
-					}
+					matched = strings.Contains(nameVersion, content)
+				}
 
-				default:
-					return nil
+				if matched {
+					results = append(results, nameVersion)
+					registries[nameVersion] = registry
 				}
 			}
 
@@ -133,21 +167,24 @@ func (s *searchCmd) search(pattern string) ([]string, error) {
 		})
 	}
 
-	// Search in global ports.
-	if err := searchInDir(dirs.PortsDir); err != nil {
-		return nil, err
-	}
-
-	// Search in project-specific ports (only if project is configured).
+	// Search in project-specific ports first (only if project is configured), they override others.
 	projectName := s.celer.GetProjectName()
 	if projectName != "" {
 		projectPortsDir := filepath.Join(dirs.ConfProjectsDir, projectName)
-		if err := searchInDir(projectPortsDir); err != nil {
-			return nil, err
+		provideAll := func(string) bool { return true }
+		if err := searchInDir(projectPortsDir, configs.ProjectRegistry, provideAll); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Search in registries and global ports by priority.
+	for _, registry := range s.celer.Registries() {
+		if err := searchInDir(registry.Dir, registry.Name, registry.Provides); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	return results, nil
+	return results, registries, nil
 }
 
 func (s *searchCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/context"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 
 	"github.com/spf13/cobra"
@@ -60,7 +61,7 @@ func TestSearchCmd_Search_ExactMatch(t *testing.T) {
 
 	// Test exact match search.
 	searchCmd := searchCmd{celer: celer}
-	results, err := searchCmd.search("zlib@1.3.1")
+	results, _, err := searchCmd.search("zlib@1.3.1")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -97,7 +98,7 @@ func TestSearchCmd_Search_PrefixMatch(t *testing.T) {
 
 	// Test prefix match search.
 	searchCmd := searchCmd{celer: celer}
-	results, err := searchCmd.search("zlib*")
+	results, _, err := searchCmd.search("zlib*")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -132,7 +133,7 @@ func TestSearchCmd_Search_SuffixMatch(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test suffix match search.
-	results, err := searchCmd.search("*@1.3.1")
+	results, _, err := searchCmd.search("*@1.3.1")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -167,7 +168,7 @@ func TestSearchCmd_Search_ContainsMatch(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test contains match search.
-	results, err := searchCmd.search("*lib*")
+	results, _, err := searchCmd.search("*lib*")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -202,7 +203,7 @@ func TestSearchCmd_Search_NoMatch(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search with no matches.
-	results, err := searchCmd.search("nonexistent-package@99.99.99")
+	results, _, err := searchCmd.search("nonexistent-package@99.99.99")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -235,7 +236,7 @@ func TestSearchCmd_Search_PortsDirNotExist(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search when ports directory doesn't exist.
-	results, err := searchCmd.search("zlib*")
+	results, _, err := searchCmd.search("zlib*")
 	if err != nil {
 		t.Fatalf("Search should not fail when ports dir doesn't exist: %v", err)
 	}
@@ -275,7 +276,7 @@ func TestSearchCmd_Search_ProjectSpecificPorts(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search for project-specific port.
-	results, err := searchCmd.search("testlib*")
+	results, _, err := searchCmd.search("testlib*")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -315,7 +316,7 @@ func TestSearchCmd_Search_InvalidWildcard(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search with invalid wildcard pattern (more than 2 wildcards).
-	results, err := searchCmd.search("*zlib*@*")
+	results, _, err := searchCmd.search("*zlib*@*")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -488,7 +489,7 @@ func TestSearchCmd_Search_DuplicateResults(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search
-	results, err := searchCmd.search("duplib*")
+	results, _, err := searchCmd.search("duplib*")
 	if err != nil {
 		t.Fatalf("Search failed: %v", err)
 	}
@@ -532,7 +533,7 @@ func TestSearchCmd_Search_EmptyPortsDir(t *testing.T) {
 	searchCmd := searchCmd{celer: celer}
 
 	// Test search in empty directory.
-	results, err := searchCmd.search("*")
+	results, _, err := searchCmd.search("*")
 	if err != nil {
 		t.Fatalf("Search should not fail with empty ports dir: %v", err)
 	}
@@ -572,9 +573,60 @@ func TestSearchCmd_Search_SpecialCharacters(t *testing.T) {
 	}
 
 	for _, pattern := range testPatterns {
-		_, err := searchCmd.search(pattern)
+		_, _, err := searchCmd.search(pattern)
 		if err != nil {
 			t.Errorf("Search with pattern '%s' should not fail: %v", pattern, err)
 		}
 	}
 }
+
+func TestSearchCmd_Search_Registries(t *testing.T) {
+	// Use a temp workspace, registries are set without cloning.
+	originalWorkspaceDir := dirs.WorkspaceDir
+	dirs.Init(t.TempDir())
+	t.Cleanup(func() { dirs.Init(originalWorkspaceDir) })
+
+	for _, portFile := range []string{
+		filepath.Join(dirs.RegistriesDir, "company", "z", "zlib", "1.3.1", "port.toml"),
+		filepath.Join(dirs.RegistriesDir, "company", "z", "zstd", "1.5.6", "port.toml"),
+		filepath.Join(dirs.PortsDir, "z", "zlib", "1.3.1", "port.toml"),
+		filepath.Join(dirs.PortsDir, "z", "zlib", "1.2.0", "port.toml"),
+		filepath.Join(dirs.PortsDir, "z", "zstd", "1.5.6", "port.toml"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(portFile), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(portFile, []byte("[package]\n"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	celerToml := "[[registries]]\nname = \"company\"\nurl = \"https://example.com/company.git\"\nports = [\"zlib\"]\n"
+	if err := os.WriteFile(filepath.Join(dirs.WorkspaceDir, "celer.toml"), []byte(celerToml), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	celer := configs.NewCeler()
+	if err := celer.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	searchCmd := searchCmd{celer: celer}
+	results, registries, err := searchCmd.search("z*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Shadowed zlib@1.3.1 of default ports repo and filtered zstd@1.5.6 of company are not returned.
+	slices.Sort(results)
+	if !slices.Equal(results, []string{"zlib@1.2.0", "zlib@1.3.1", "zstd@1.5.6"}) {
+		t.Fatalf("unexpected search results: %v", results)
+	}
+	for nameVersion, registry := range map[string]string{
+		"zlib@1.3.1": "company",
+		"zlib@1.2.0": context.DefaultRegistry,
+		"zstd@1.5.6": context.DefaultRegistry,
+	} {
+		if registries[nameVersion] != registry {
+			t.Errorf("%s should come from %s, but got %s", nameVersion, registry, registries[nameVersion])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package cmds
+
+import (
+	"fmt"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/expr"
+
+	"github.com/spf13/cobra"
+)
+
+type statsCmd struct {
+	celer  *configs.Celer
+	format string
+	limit  int
+}
+
+// statsOutput is the JSON schema of `celer stats --format=json`.
+type statsOutput struct {
+	configs.OutputHeader
+	Runs          int                  `json:"runs"`
+	CacheHits     int                  `json:"cache_hits"`
+	CacheMisses   int                  `json:"cache_misses"`
+	CacheHitRatio float64              `json:"cache_hit_ratio"`
+	SlowestPorts  []configs.SlowPort   `json:"slowest_ports"`
+	Trends        []configs.StatsTrend `json:"trends"`
+}
+
+func (s *statsCmd) Command(celer *configs.Celer) *cobra.Command {
+	s.celer = celer
+	command := &cobra.Command{
+		Use:   "stats",
+		Short: "Show build time, resource usage and cache statistics of installs.",
+		Long: `Show build time, resource usage and cache statistics of installs.
+
+Every install and deploy records wall time, CPU time and peak RSS of clone,
+configure, build and install stages of each port, together with artifact
+pkgcache hits and misses. They are stored beside install reports in
+installed/celer/statistics, and summarized by this command.
+
+Examples:
+  # Show slowest ports, cache hit ratio and trends of recent runs
+  celer stats
+
+  # Show at most 20 ports and runs
+  celer stats --limit=20
+
+  # Print statistics as json
+  celer stats --format=json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.doExecute()
+		},
+	}
+
+	// Register flags.
+	command.Flags().StringVar(&s.format, "format", configs.OutputFormatText, "output format, text or json.")
+	command.Flags().IntVar(&s.limit, "limit", 10, "max number of ports and runs to show.")
+
+	// Silence cobra's error and usage output to avoid duplicate messages.
+	command.SilenceErrors = true
+	command.SilenceUsage = true
+	return command
+}
+
+func (s *statsCmd) doExecute() error {
+	if err := configs.ValidateOutputFormat(s.format); err != nil {
+		return color.PrintError(err, "invalid format.")
+	}
+	if s.limit <= 0 {
+		return color.PrintError(fmt.Errorf("--limit must be greater than 0"), "invalid limit.")
+	}
+
+	if err := s.celer.Init(); err != nil {
+		return color.PrintError(err, "failed to init celer.")
+	}
+
+	runs, err := configs.LoadInstallStats()
+	if err != nil {
+		return color.PrintError(err, "failed to load install statistics.")
+	}
+
+	hits, misses, ratio := configs.CacheHitRatio(runs)
+	output := statsOutput{
+		OutputHeader:  configs.NewOutputHeader("stats"),
+		Runs:          len(runs),
+		CacheHits:     hits,
+		CacheMisses:   misses,
+		CacheHitRatio: ratio,
+		SlowestPorts:  configs.SlowestPorts(runs, s.limit),
+		Trends:        configs.StatsTrends(runs, s.limit),
+	}
+	if s.format == configs.OutputFormatJSON {
+		return configs.PrintJSON(output)
+	}
+
+	if len(runs) == 0 {
+		color.PrintHint("No install statistics yet, they're recorded by `celer install` and `celer deploy`.")
+		return nil
+	}
+
+	color.Printf(color.Title, "Slowest ports:\n")
+	if len(output.SlowestPorts) == 0 {
+		fmt.Println("  no port is built from source.")
+	}
+	for _, port := range output.SlowestPorts {
+		fmt.Printf("  %-36s latest %-8s avg %-8s builds %-3d cpu %-8s peak rss %-10s slowest stage: %s\n",
+			port.Port+expr.If(port.DevDep, " [dev]", ""),
+			configs.FormatSeconds(port.LatestSeconds),
+			configs.FormatSeconds(port.AverageSeconds),
+			port.Builds,
+			configs.FormatSeconds(port.CPUSeconds),
+			configs.FormatBytes(port.PeakRSS),
+			expr.If(port.Slowest == "", "-", port.Slowest),
+		)
+	}
+
+	color.Printf(color.Title, "\nCache hit ratio:\n")
+	fmt.Printf("  %.1f%% (%d hits, %d misses in %d runs)\n", ratio*100, hits, misses, len(runs))
+
+	color.Printf(color.Title, "\nRecent runs:\n")
+	for _, trend := range output.Trends {
+		total := trend.CacheHits + trend.CacheMisses
+		hitRatio := expr.If(total == 0, "-", fmt.Sprintf("%.1f%%", float64(trend.CacheHits)*100/float64(max(total, 1))))
+		fmt.Printf("  %s  %-30s %-24s built %-3d wall %-8s cache hit %s\n",
+			trend.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			trend.Root,
+			trend.Platform,
+			trend.Built,
+			configs.FormatSeconds(trend.WallSeconds),
+			hitRatio,
+		)
+	}
+	return nil
+}
@@ -37,6 +37,7 @@ func (f fakeContext) GenerateToolchainFile() error            { return nil }
 func (f fakeContext) ExprVars() *context.ExprVars             { return nil }
 func (f fakeContext) PythonConfig() context.PythonConfig      { return nil }
 func (f fakeContext) Features() context.Features              { return nil }
+func (f fakeContext) Registries() context.Registries          { return nil }
 
 // newInitializedCeler returns a Celer that has been Init'd and has a freshly
 // cloned conf repo — the minimum state required for configureCmd's
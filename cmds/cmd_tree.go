@@ -23,12 +23,31 @@ type portInfo struct {
 	depedencies     []*portInfo
 	devDependencies []*portInfo
 	devDep          bool
+	hostDep         bool
+	buildSystem     string
+	features        []string
+	installedFrom   string
+	buildHash       string
 	depth           int
 }
 
 type treeCmd struct {
 	celer      *configs.Celer
 	hideDevDep bool
+	format     string
+}
+
+// treeOutput is the JSON schema of `celer tree --format=json`.
+type treeOutput struct {
+	configs.OutputHeader
+	Target  string                  `json:"target"`
+	Root    *configs.DependencyNode `json:"root"`
+	Summary treeSummary             `json:"summary"`
+}
+
+type treeSummary struct {
+	Dependencies    int `json:"dependencies"`
+	DevDependencies int `json:"dev_dependencies"`
 }
 
 func (t *treeCmd) Command(celer *configs.Celer) *cobra.Command {
@@ -43,10 +62,13 @@ package or project, including both regular and development dependencies.
 It also performs dependency validation including circular dependency checks
 and version conflict detection.
 
+Selected features of ports are shown like "opencv@4.8.0[contrib,cuda]".
+
 Examples:
   celer tree boost@1.87.0              # Show dependencies for a specific package
   celer tree my_project                # Show dependencies for a project
-  celer tree opencv@4.11.0 --hide-dev  # Hide development dependencies`,
+  celer tree opencv@4.11.0 --hide-dev  # Hide development dependencies
+  celer tree boost@1.87.0 --format=json # Print dependencies tree as json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return t.tree(args[0])
@@ -56,6 +78,7 @@ Examples:
 
 	// Register flags.
 	command.Flags().BoolVar(&t.hideDevDep, "hide-dev", false, "hide dev dep in dependencies tree.")
+	command.Flags().StringVar(&t.format, "format", configs.OutputFormatText, "output format, text or json.")
 
 	// Silence cobra's error and usage output to avoid duplicate messages.
 	command.SilenceErrors = true
@@ -73,6 +96,9 @@ func (t *treeCmd) tree(target string) error {
 	if err := t.validateTarget(target); err != nil {
 		return fmt.Errorf("invalid target -> %w", err)
 	}
+	if err := configs.ValidateOutputFormat(t.format); err != nil {
+		return fmt.Errorf("invalid format -> %w", err)
+	}
 
 	depchecker := depcheck.NewDepCheck()
 	if strings.Contains(target, "@") {
@@ -122,6 +148,10 @@ func (t *treeCmd) showPortTree(target string, depchecker any) error {
 		return fmt.Errorf("failed to collect port information -> %w", err)
 	}
 
+	if t.format == configs.OutputFormatJSON {
+		return t.printJSON(target, &rootInfo)
+	}
+
 	color.Printf(color.Title, "display dependencies in tree view:\n")
 	color.Printf(color.Line, "--------------------------------------------\n")
 	t.printTree(&rootInfo)
@@ -181,6 +211,10 @@ func (t *treeCmd) showProjectTree(target string, depchecker any) error {
 		rootInfo.depedencies = append(rootInfo.depedencies, &portInfo)
 	}
 
+	if t.format == configs.OutputFormatJSON {
+		return t.printJSON(target, &rootInfo)
+	}
+
 	title := "display dependencies in tree view"
 	separator := strings.Repeat("-", len(title))
 	color.Printf(color.Title, "%s\n%s\n", title, separator)
@@ -189,12 +223,18 @@ func (t *treeCmd) showProjectTree(target string, depchecker any) error {
 }
 
 func (t *treeCmd) collectPortInfos(parent *portInfo, nameVersion string) error {
-	var port configs.Port
+	port := configs.Port{DevDep: parent.devDep, HostDep: parent.hostDep}
 	if err := port.Init(t.celer, nameVersion); err != nil {
 		return err
 	}
 
 	matchedConfig := port.MatchedConfig
+	parent.nameVersion = port.NameVersion()
+	parent.devDep = port.DevDep
+	parent.hostDep = port.HostDep
+	parent.buildSystem = matchedConfig.BuildSystem
+	parent.features = port.SelectedFeatures
+	parent.installedFrom, parent.buildHash = port.InstalledInfo()
 	nextDepth := parent.depth + 1
 
 	// Collect dependency ports.
@@ -209,6 +249,7 @@ func (t *treeCmd) collectPortInfos(parent *portInfo, nameVersion string) error {
 			nameVersion: depNameVersion,
 			depth:       nextDepth,
 			devDep:      parent.devDep,
+			hostDep:     parent.devDep || parent.hostDep,
 		}
 		parent.depedencies = append(parent.depedencies, &portInfo)
 		t.collectPortInfos(&portInfo, depNameVersion)
@@ -231,6 +272,7 @@ func (t *treeCmd) collectPortInfos(parent *portInfo, nameVersion string) error {
 			nameVersion: devDepNameVersion,
 			depth:       nextDepth,
 			devDep:      true,
+			hostDep:     true,
 		}
 		parent.devDependencies = append(parent.devDependencies, &portInfo)
 		t.collectPortInfos(&portInfo, devDepNameVersion)
@@ -250,6 +292,41 @@ func (t *treeCmd) printTree(info *portInfo) {
 	color.Printf(color.Summary, "dependencies: %d  dev_dependencies: %d\n", depCount, devDepCount)
 }
 
+func (t *treeCmd) printJSON(target string, info *portInfo) error {
+	depCount, devDepCount := t.countDependencies(info)
+	output := treeOutput{
+		OutputHeader: configs.NewOutputHeader("tree"),
+		Target:       target,
+		Root:         t.toDependencyNode(info, ""),
+		Summary: treeSummary{
+			Dependencies:    depCount,
+			DevDependencies: devDepCount,
+		},
+	}
+	return configs.PrintJSON(output)
+}
+
+func (t *treeCmd) toDependencyNode(info *portInfo, parent string) *configs.DependencyNode {
+	node := configs.NewDependencyNode(info.nameVersion)
+	node.Parent = parent
+	node.DevDep = info.devDep
+	node.HostDep = info.hostDep
+	node.BuildSystem = info.buildSystem
+	node.Features = info.features
+	node.InstalledFrom = info.installedFrom
+	node.BuildHash = info.buildHash
+
+	// Merge normal and dev dependencies (if not hidden).
+	children := info.depedencies
+	if !t.hideDevDep {
+		children = append(children, info.devDependencies...)
+	}
+	for _, child := range children {
+		node.Dependencies = append(node.Dependencies, t.toDependencyNode(child, info.nameVersion))
+	}
+	return node
+}
+
 func (t *treeCmd) countDependencies(info *portInfo) (int, int) {
 	depCount := 0
 	devDepCount := 0
@@ -304,7 +381,7 @@ func (t *treeCmd) printTreeWithPrefix(info *portInfo, prefix string, isLast bool
 	}
 
 	// Compose the line to print.
-	line := branch + info.nameVersion
+	line := branch + configs.JoinFeatures(info.nameVersion, info.features)
 	if info.devDep {
 		line += " -- [dev]"
 	}
@@ -335,9 +412,12 @@ func (t *treeCmd) printTreeWithPrefix(info *portInfo, prefix string, isLast bool
 func (t *treeCmd) completion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var suggestions []string
 
-	// Support port completion from global ports.
-	if fileio.PathExists(dirs.PortsDir) {
-		filepath.WalkDir(dirs.PortsDir, func(path string, d fs.DirEntry, err error) error {
+	// Support port completion from registries and global ports.
+	for _, registry := range t.celer.Registries() {
+		if !fileio.PathExists(registry.Dir) {
+			continue
+		}
+		filepath.WalkDir(registry.Dir, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
@@ -401,7 +481,7 @@ func (t *treeCmd) completion(cmd *cobra.Command, args []string, toComplete strin
 	}
 
 	// Support flags completion.
-	for _, flag := range []string{"--hide-dev"} {
+	for _, flag := range []string{"--hide-dev", "--format"} {
 		if strings.HasPrefix(flag, toComplete) {
 			suggestions = append(suggestions, flag)
 		}
@@ -224,6 +224,53 @@ func TestTreeCmd_PrintTree(t *testing.T) {
 	treeCmd.printTree(root) // Should not panic
 }
 
+func TestTreeCmd_ToDependencyNode(t *testing.T) {
+	root := &portInfo{
+		nameVersion: "root@1.0.0",
+		buildSystem: "cmake",
+	}
+	dep := &portInfo{
+		parent:      root,
+		nameVersion: "dep@2.0.0",
+		buildSystem: "meson",
+		depth:       1,
+	}
+	devDep := &portInfo{
+		parent:      root,
+		nameVersion: "devdep@1.0.0",
+		buildSystem: "makefiles",
+		depth:       1,
+		devDep:      true,
+		hostDep:     true,
+	}
+	root.depedencies = []*portInfo{dep}
+	root.devDependencies = []*portInfo{devDep}
+
+	treeCmd := treeCmd{}
+	node := treeCmd.toDependencyNode(root, "")
+	if node.Name != "root" || node.Version != "1.0.0" || node.BuildSystem != "cmake" {
+		t.Fatalf("unexpected root node: %+v", node)
+	}
+	if len(node.Dependencies) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(node.Dependencies))
+	}
+
+	child := node.Dependencies[0]
+	if child.Name != "dep" || child.Parent != "root@1.0.0" || child.DevDep || child.HostDep || child.BuildSystem != "meson" {
+		t.Errorf("unexpected dependency node: %+v", child)
+	}
+	devChild := node.Dependencies[1]
+	if devChild.Name != "devdep" || !devChild.DevDep || !devChild.HostDep {
+		t.Errorf("unexpected dev dependency node: %+v", devChild)
+	}
+
+	// Dev dependencies are hidden with --hide-dev.
+	treeCmd.hideDevDep = true
+	if node := treeCmd.toDependencyNode(root, ""); len(node.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency with --hide-dev, got %d", len(node.Dependencies))
+	}
+}
+
 func TestTreeCmd_CollectPortInfos(t *testing.T) {
 	// Cleanup.
 	dirs.RemoveAllForTest()
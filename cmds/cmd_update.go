@@ -33,12 +33,12 @@ func (u *updateCmd) Command(celer *configs.Celer) *cobra.Command {
 
 This command supports three types of updates:
   1. Update conf repository (configuration files)
-  2. Update ports repository (port configuration files)
+  2. Update ports repository and registries of celer.toml (port configuration files)
   3. Update source code repositories of third-party libraries
 
 Examples:
   celer update --conf-repo                      # Update conf repository
-  celer update --ports-repo                     # Update ports repository
+  celer update --ports-repo                     # Update ports repository and registries
   celer update zlib@1.3.1                       # Update single port
   celer update entt@3.16.0 fakeit@2.5.0         # Update multiple ports
   celer update --recursive ffmpeg@3.4.13        # Update port and all its dependencies
@@ -52,7 +52,7 @@ Examples:
 
 	// Register flags.
 	command.Flags().BoolVarP(&u.confRepo, "conf-repo", "c", false, "update conf repo")
-	command.Flags().BoolVarP(&u.portsRepo, "ports-repo", "p", false, "update ports repo")
+	command.Flags().BoolVarP(&u.portsRepo, "ports-repo", "p", false, "update ports repo and registries")
 	command.Flags().BoolVarP(&u.force, "force", "f", false, "update forcibly")
 	command.Flags().BoolVarP(&u.recursive, "recursive", "r", false, "update recursively")
 
@@ -146,7 +146,21 @@ func (u *updateCmd) updateConfRepo() error {
 	return git.UpdateRepo("conf repo", "", repoDir, u.force)
 }
 
+// updatePortsRepo updates registries of celer.toml to their branch or pinned commit,
+// and then the default ports repo. Registries not cloned yet are cloned.
 func (u *updateCmd) updatePortsRepo() error {
+	for _, registry := range u.celer.PortRegistries() {
+		if !registry.Cloned() {
+			if err := u.celer.CloneRegistry(registry); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := git.UpdateRepo("registry "+registry.Name, registry.Ref(), registry.Dir(), u.force); err != nil {
+			return fmt.Errorf("failed to update registry %s -> %w", registry.Name, err)
+		}
+	}
+
 	repoDir := filepath.Join(dirs.WorkspaceDir, "ports")
 	return git.UpdateRepo("ports repo", "", repoDir, u.force)
 }
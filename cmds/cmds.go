@@ -69,6 +69,15 @@ func Execute() error {
 		&autoremoveCmd{},
 		&reverseCmd{},
 		&searchCmd{},
+		&lockCmd{},
+		&cacheCmd{},
+		&sbomCmd{},
+		&bundleCmd{},
+		&auditCmd{},
+		&ownsCmd{},
+		&statsCmd{},
+		&lintCmd{},
+		&outdatedCmd{},
 	}
 
 	// Create celer but init it in command.
@@ -12,6 +12,7 @@ import (
 	"github.com/celer-pkg/celer/context"
 	"github.com/celer-pkg/celer/envs"
 	"github.com/celer-pkg/celer/pkgcache/netfs"
+	"github.com/celer-pkg/celer/pkgcache/remote"
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/errors"
@@ -30,6 +31,9 @@ const (
 type InitOption struct {
 	SkipPlatform bool
 	SkipProject  bool
+
+	// BuildType overrides build type of celer.toml in memory, it's never saved.
+	BuildType string
 }
 
 var Version = "v0.0.0" // It would be set by build script.
@@ -80,12 +84,32 @@ type Proxy struct {
 
 type features struct {
 	IgnoreCheckCMakeAbsPath bool `toml:"ignore_check_cmake_abs_path"`
+	AllowFileCollisions     bool `toml:"allow_file_collisions"`
+	FixELFRPath             bool `toml:"fix_elf_rpath"`
+	IgnoreCheckArch         bool `toml:"ignore_check_arch"`
+	Hermetic                bool `toml:"hermetic"`
 }
 
 func (i features) ShouldIgnoreCheckCMakeAbsPath() bool {
 	return i.IgnoreCheckCMakeAbsPath
 }
 
+func (i features) ShouldAllowFileCollisions() bool {
+	return i.AllowFileCollisions
+}
+
+func (i features) ShouldFixELFRPath() bool {
+	return i.FixELFRPath
+}
+
+func (i features) ShouldIgnoreCheckArch() bool {
+	return i.IgnoreCheckArch
+}
+
+func (i features) ShouldBuildHermetic() bool {
+	return i.Hermetic
+}
+
 type Python struct {
 	Version        string   `toml:"version,omitempty"`
 	IndexUrl       string   `toml:"index_url,omitempty"`
@@ -116,6 +140,7 @@ type configData struct {
 	CCache         *CCache         `toml:"ccache,omitempty"`
 	Python         *Python         `toml:"python,omitempty"`
 	Features       *features       `toml:"features,omitempty"`
+	Registries     []Registry      `toml:"registries,omitempty"`
 }
 
 // Init initializes celer with default options.
@@ -132,6 +157,9 @@ func (c *Celer) InitWithOptions(opts InitOption) error {
 func (c *Celer) InitWithPlatform(platform string, opts InitOption) error {
 	c.platform.ctx = c
 
+	// Port files or constraints may have been changed since last init.
+	resetSolvedVersions()
+
 	configPath := filepath.Join(dirs.WorkspaceDir, "celer.toml")
 	if !fileio.PathExists(configPath) {
 		// Create conf dir if not exists.
@@ -198,8 +226,16 @@ func (c *Celer) InitWithPlatform(platform string, opts InitOption) error {
 			c.configData.PkgCacheConfig.Dir = filepath.ToSlash(c.configData.PkgCacheConfig.Dir)
 		}
 
+		// Registries are validated before ports are looked up in them.
+		if err := c.validateRegistries(); err != nil {
+			return err
+		}
+
 		// Use lower case build type in celer as default.
 		c.Main.BuildType = strings.ToLower(c.Main.BuildType)
+		if opts.BuildType != "" {
+			c.Main.BuildType = strings.ToLower(opts.BuildType)
+		}
 
 		// Set platform and init platform if specified.
 		if platform != "" {
@@ -230,8 +266,8 @@ func (c *Celer) InitWithPlatform(platform string, opts InitOption) error {
 
 		// Validate package cache.
 		if c.configData.PkgCacheConfig != nil {
-			if strings.TrimSpace(c.configData.PkgCacheConfig.Dir) == "" {
-				return fmt.Errorf("pkgcache dir is empty")
+			if err := c.configData.PkgCacheConfig.Validate(); err != nil {
+				return err
 			}
 			c.initPkgCacheCaches()
 		}
@@ -337,11 +373,28 @@ func (c *Celer) InitWithPlatform(platform string, opts InitOption) error {
 		return err
 	}
 
+	// Clone registries of celer.toml if they're not cloned yet.
+	if err := c.cloneRegistries(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (c *Celer) Deploy(force, strip bool) error {
-	if err := c.project.deploy(force, strip); err != nil {
+// DeployOptions is the option for Deploy.
+type DeployOptions struct {
+	Force      bool
+	Strip      bool
+	SplitDebug bool // Split debug symbols into symbol store instead of discarding them when stripping.
+	Parallel   int  // Number of ports built at the same time, one by one when <= 1.
+	KeepGoing  bool // Keep building independent ports after a failure when Parallel > 1.
+
+	// ReportFormat is the format of install report, markdown is written when it's not "json".
+	ReportFormat string
+}
+
+func (c *Celer) Deploy(options DeployOptions) error {
+	if err := c.project.deploy(options); err != nil {
 		return fmt.Errorf("failed to deploy -> %w", err)
 	}
 
@@ -463,6 +516,15 @@ func (c *Celer) initPkgCacheCaches() {
 		return
 	}
 
+	// Http and s3 backend share the same client.
+	if c.configData.PkgCacheConfig.IsRemote() {
+		client := c.configData.PkgCacheConfig.remoteClient()
+		c.configData.PkgCacheConfig.repoCache = remote.NewRepoConfig(c, client)
+		c.configData.PkgCacheConfig.artifactCache = remote.NewArtifactConfig(c, client)
+		c.configData.PkgCacheConfig.downloadCache = remote.NewDownloadConfig(c, client)
+		return
+	}
+
 	c.configData.PkgCacheConfig.repoCache = netfs.NewRepoConfig(c)
 	c.configData.PkgCacheConfig.artifactCache = netfs.NewArtifactConfig(c)
 	c.configData.PkgCacheConfig.downloadCache = netfs.NewDownloadConfig(c)
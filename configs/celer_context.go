@@ -29,6 +29,14 @@ func (c *Celer) Project() context.Project {
 	return &c.project
 }
 
+// ProjectBundle returns `[bundle]` of current project.
+func (c *Celer) ProjectBundle() Bundle {
+	if c.project.Bundle == nil {
+		return Bundle{}
+	}
+	return *c.project.Bundle
+}
+
 // BuildType returns lower case build type.
 func (c *Celer) BuildType() string {
 	return c.Main.BuildType
@@ -229,11 +229,8 @@ func (c *Celer) SetPkgCacheWritable(writable bool) error {
 		return err
 	}
 
-	if c.configData.PkgCacheConfig == nil || strings.TrimSpace(c.configData.PkgCacheConfig.Dir) == "" {
-		return errors.ErrPkgCacheDirEmpty
-	}
-	if !fileio.PathExists(c.configData.PkgCacheConfig.Dir) {
-		return errors.ErrPkgCacheDirNotExist
+	if err := c.checkPkgCache(); err != nil {
+		return err
 	}
 
 	// Update pkgcache wriatable.
@@ -251,11 +248,8 @@ func (c *Celer) CacheArtifacts(cacheArtifacts bool) error {
 		return err
 	}
 
-	if c.configData.PkgCacheConfig == nil || strings.TrimSpace(c.configData.PkgCacheConfig.Dir) == "" {
-		return errors.ErrPkgCacheDirEmpty
-	}
-	if !fileio.PathExists(c.configData.PkgCacheConfig.Dir) {
-		return errors.ErrPkgCacheDirNotExist
+	if err := c.checkPkgCache(); err != nil {
+		return err
 	}
 
 	// Update cacheArtifacts.
@@ -272,11 +266,8 @@ func (c *Celer) CacheDownloads(cacheDownloads bool) error {
 		return err
 	}
 
-	if c.configData.PkgCacheConfig == nil || strings.TrimSpace(c.configData.PkgCacheConfig.Dir) == "" {
-		return errors.ErrPkgCacheDirEmpty
-	}
-	if !fileio.PathExists(c.configData.PkgCacheConfig.Dir) {
-		return errors.ErrPkgCacheDirNotExist
+	if err := c.checkPkgCache(); err != nil {
+		return err
 	}
 
 	// Update cachedownloads.
@@ -459,3 +450,18 @@ func (c *Celer) SetCCacheRemoteOnly(remoteOnly bool) error {
 
 	return nil
 }
+
+// checkPkgCache checks pkgcache is configured, and its dir exists for netfs backend.
+func (c *Celer) checkPkgCache() error {
+	pkgCacheConfig := c.configData.PkgCacheConfig
+	if pkgCacheConfig == nil {
+		return errors.ErrPkgCacheDirEmpty
+	}
+	if err := pkgCacheConfig.Validate(); err != nil {
+		return err
+	}
+	if !pkgCacheConfig.IsRemote() && !fileio.PathExists(pkgCacheConfig.Dir) {
+		return errors.ErrPkgCacheDirNotExist
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/errors"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+const (
+	MatrixPassed = "passed"
+	MatrixFailed = "failed"
+)
+
+// MatrixTarget is a platform and build type combination of `deploy --matrix`.
+type MatrixTarget struct {
+	Platform  string `toml:"platform" json:"platform"`
+	BuildType string `toml:"build_type" json:"build_type"`
+}
+
+func (m MatrixTarget) String() string {
+	return m.Platform + ":" + m.BuildType
+}
+
+// deployMatrix is the content of matrix file, platforms and build types are
+// combined with each other, and include appends extra combinations.
+type deployMatrix struct {
+	Platforms  []string       `toml:"platforms"`
+	BuildTypes []string       `toml:"build_types"`
+	Include    []MatrixTarget `toml:"include"`
+}
+
+// ParseDeployMatrix parses value of `--matrix`, which is either a toml file or
+// a comma separated list like `x86_64-linux:release,aarch64-linux:debug`.
+// Build type is optional in list, and defaults to defaultBuildType.
+func ParseDeployMatrix(value, defaultBuildType string) ([]MatrixTarget, error) {
+	var targets []MatrixTarget
+	if fileio.PathExists(value) {
+		var matrix deployMatrix
+		if _, err := toml.DecodeFile(value, &matrix); err != nil {
+			return nil, fmt.Errorf("failed to decode matrix file %s -> %w", value, err)
+		}
+
+		buildTypes := expr.If(len(matrix.BuildTypes) > 0, matrix.BuildTypes, []string{defaultBuildType})
+		for _, platform := range matrix.Platforms {
+			for _, buildType := range buildTypes {
+				targets = append(targets, MatrixTarget{Platform: platform, BuildType: buildType})
+			}
+		}
+		for _, target := range matrix.Include {
+			target.BuildType = expr.If(target.BuildType != "", target.BuildType, defaultBuildType)
+			targets = append(targets, target)
+		}
+	} else {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			platform, buildType, _ := strings.Cut(item, ":")
+			buildType = expr.If(buildType != "", buildType, defaultBuildType)
+			targets = append(targets, MatrixTarget{Platform: platform, BuildType: buildType})
+		}
+	}
+
+	// Validate and remove duplicated combinations.
+	var matrix []MatrixTarget
+	for _, target := range targets {
+		target.Platform = strings.TrimSpace(target.Platform)
+		target.BuildType = strings.ToLower(strings.TrimSpace(target.BuildType))
+		if target.Platform == "" {
+			return nil, fmt.Errorf("platform of matrix is empty")
+		}
+		if !slices.Contains([]string{"release", "debug", "relwithdebinfo", "minsizerel"}, target.BuildType) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrInvalidBuildType, target)
+		}
+		if !fileio.PathExists(filepath.Join(dirs.ConfPlatformsDir, target.Platform+".toml")) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrPlatformNotExist, target.Platform)
+		}
+		if !slices.Contains(matrix, target) {
+			matrix = append(matrix, target)
+		}
+	}
+	if len(matrix) == 0 {
+		return nil, fmt.Errorf("matrix is empty")
+	}
+
+	return matrix, nil
+}
+
+// MatrixResult is deploy result of a combination in matrix.
+type MatrixResult struct {
+	MatrixTarget
+	Status  string  `json:"status"`
+	Seconds float64 `json:"seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// DeployMatrixOutput is the JSON schema of deploy matrix report.
+type DeployMatrixOutput struct {
+	OutputHeader
+	Project     string              `json:"project"`
+	GeneratedAt string              `json:"generated_at"`
+	Summary     DeployMatrixSummary `json:"summary"`
+	Results     []MatrixResult      `json:"results"`
+}
+
+type DeployMatrixSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// NewDeployMatrixOutput summarizes results of matrix.
+func NewDeployMatrixOutput(project string, results []MatrixResult) DeployMatrixOutput {
+	output := DeployMatrixOutput{
+		OutputHeader: NewOutputHeader("deploy_matrix"),
+		Project:      project,
+		GeneratedAt:  time.Now().Format(time.RFC3339),
+		Summary:      DeployMatrixSummary{Total: len(results)},
+		Results:      results,
+	}
+	for _, result := range results {
+		if result.Status == MatrixPassed {
+			output.Summary.Passed++
+		} else {
+			output.Summary.Failed++
+		}
+	}
+	return output
+}
+
+// Table returns rows of platforms and columns of build types, combinations
+// not in matrix are left as "-".
+func (d DeployMatrixOutput) Table() (header []string, rows [][]string) {
+	var platforms, buildTypes []string
+	for _, result := range d.Results {
+		if !slices.Contains(platforms, result.Platform) {
+			platforms = append(platforms, result.Platform)
+		}
+		if !slices.Contains(buildTypes, result.BuildType) {
+			buildTypes = append(buildTypes, result.BuildType)
+		}
+	}
+
+	header = append([]string{"platform"}, buildTypes...)
+	for _, platform := range platforms {
+		row := []string{platform}
+		for _, buildType := range buildTypes {
+			cell := "-"
+			for _, result := range d.Results {
+				if result.Platform == platform && result.BuildType == buildType {
+					cell = expr.If(result.Status == MatrixPassed, "✅ passed", "❌ failed")
+					break
+				}
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// RenderMarkdown renders report as a pass/fail table, followed by errors of failures.
+func (d DeployMatrixOutput) RenderMarkdown() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("# Deploy Matrix of %s", d.Project))
+	lines = append(lines, "")
+	lines = append(lines, fmt.Sprintf("- Generated at: `%s`", d.GeneratedAt))
+	lines = append(lines, fmt.Sprintf("- Passed: `%d/%d`", d.Summary.Passed, d.Summary.Total))
+	lines = append(lines, "")
+
+	header, rows := d.Table()
+	lines = append(lines, "| "+strings.Join(header, " | ")+" |")
+	lines = append(lines, "|"+strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+	}
+
+	if d.Summary.Failed > 0 {
+		lines = append(lines, "")
+		lines = append(lines, "## Failures")
+		lines = append(lines, "")
+		for _, result := range d.Results {
+			if result.Status != MatrixPassed {
+				lines = append(lines, fmt.Sprintf("- `%s`: %s", result.MatrixTarget, result.Error))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Write writes report into deployments dir as markdown or json.
+func (d DeployMatrixOutput) Write(format string) (string, error) {
+	deploymentsDir := filepath.Join(dirs.InstalledDir, "celer", "deployments")
+	if err := os.MkdirAll(deploymentsDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	fileBase := fmt.Sprintf("matrix_%s_%s", d.Project, time.Now().Format("20060102_150405"))
+
+	if format == OutputFormatJSON {
+		filePath := filepath.Join(deploymentsDir, fileBase+".json")
+		file, err := os.Create(filePath)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		if err := WriteJSON(file, d); err != nil {
+			return "", err
+		}
+		return filePath, nil
+	}
+
+	filePath := filepath.Join(deploymentsDir, fileBase+".md")
+	if err := os.WriteFile(filePath, []byte(d.RenderMarkdown()), os.ModePerm); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
@@ -0,0 +1,78 @@
+package configs
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDeployMatrix(t *testing.T) {
+	workspaceDir := setupTestWorkspace(t, map[string]string{
+		"conf/platforms/x86_64-linux.toml":  "",
+		"conf/platforms/aarch64-linux.toml": "",
+		"matrix.toml": `platforms = ["x86_64-linux", "aarch64-linux"]
+build_types = ["Release", "debug"]
+
+[[include]]
+platform = "x86_64-linux"
+build_type = "relwithdebinfo"
+
+[[include]]
+platform = "aarch64-linux"
+`,
+	})
+
+	// List with default build type and duplicated combination.
+	targets, err := ParseDeployMatrix("x86_64-linux, aarch64-linux:Debug,x86_64-linux:release", "release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []MatrixTarget{{"x86_64-linux", "release"}, {"aarch64-linux", "debug"}}
+	if !slices.Equal(targets, expected) {
+		t.Errorf("expected %v, but got %v", expected, targets)
+	}
+
+	// File of platforms and build types combined, with extra combinations included.
+	targets, err = ParseDeployMatrix(filepath.Join(workspaceDir, "matrix.toml"), "release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []MatrixTarget{
+		{"x86_64-linux", "release"}, {"x86_64-linux", "debug"},
+		{"aarch64-linux", "release"}, {"aarch64-linux", "debug"},
+		{"x86_64-linux", "relwithdebinfo"},
+	}
+	if !slices.Equal(targets, expected) {
+		t.Errorf("expected %v, but got %v", expected, targets)
+	}
+
+	for _, value := range []string{"windows-msvc", "x86_64-linux:profile", ":release", " , "} {
+		if _, err := ParseDeployMatrix(value, "release"); err == nil {
+			t.Errorf("matrix %q should be invalid", value)
+		}
+	}
+}
+
+func TestDeployMatrixOutput_RenderMarkdown(t *testing.T) {
+	output := NewDeployMatrixOutput("project_x", []MatrixResult{
+		{MatrixTarget: MatrixTarget{"x86_64-linux", "release"}, Status: MatrixPassed},
+		{MatrixTarget: MatrixTarget{"x86_64-linux", "debug"}, Status: MatrixFailed, Error: "failed to build zlib@1.3.1"},
+		{MatrixTarget: MatrixTarget{"aarch64-linux", "release"}, Status: MatrixPassed},
+	})
+	if output.Summary.Passed != 2 || output.Summary.Failed != 1 {
+		t.Fatalf("expected 2 passed and 1 failed, but got %+v", output.Summary)
+	}
+
+	markdown := output.RenderMarkdown()
+	for _, line := range []string{
+		"| platform | release | debug |",
+		"| x86_64-linux | ✅ passed | ❌ failed |",
+		"| aarch64-linux | ✅ passed | - |",
+		"- `x86_64-linux:debug`: failed to build zlib@1.3.1",
+	} {
+		if !strings.Contains(markdown, line) {
+			t.Errorf("markdown should contain %q, but got:\n%s", line, markdown)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/celer-pkg/celer/pkgs/dirs"
@@ -18,17 +19,23 @@ type installReportEntry struct {
 	Parent        string
 	BuildSystem   string
 	InstalledFrom string
+	BuildHash     string
 	DevDep        bool
 	HostDev       bool
+	Stats         PortStats
 }
 
 type installReport struct {
 	rootPort     string
+	format       string
+	mutex        sync.Mutex
 	entries      map[string]installReportEntry
-	visitedPorts map[string]bool
+	visitedPorts *portSet
+	stats        map[string]*PortStats // Time and resource usage collected while installing, keyed by visitedKey.
+	startedAt    time.Time
 }
 
-func (i installReport) dependencyTypeOf(entry installReportEntry) string {
+func (i *installReport) dependencyTypeOf(entry installReportEntry) string {
 	switch {
 	case entry.DevDep && entry.HostDev:
 		return "buildtime - host"
@@ -41,7 +48,7 @@ func (i installReport) dependencyTypeOf(entry installReportEntry) string {
 	}
 }
 
-func (i installReport) dependencyTypeRank(depType string) int {
+func (i *installReport) dependencyTypeRank(depType string) int {
 	switch depType {
 	case "runtime":
 		return 0
@@ -58,7 +65,9 @@ func newInstallReport(rootNameVersion string) *installReport {
 	return &installReport{
 		rootPort:     rootNameVersion,
 		entries:      make(map[string]installReportEntry),
-		visitedPorts: make(map[string]bool),
+		visitedPorts: newPortSet(),
+		stats:        make(map[string]*PortStats),
+		startedAt:    time.Now(),
 	}
 }
 
@@ -73,10 +82,15 @@ func (i *installReport) add(port *Port, installedFrom string) {
 		Parent:        port.Parent,
 		BuildSystem:   port.MatchedConfig.BuildSystem,
 		InstalledFrom: installedFrom,
+		BuildHash:     port.installedBuildHash(),
 		DevDep:        port.DevDep,
 		HostDev:       port.HostDep,
 	}
 
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	entry.Stats = i.portStats(port, installedFrom)
 	old, ok := i.entries[key]
 	if !ok {
 		i.entries[key] = entry
@@ -127,6 +141,7 @@ func (i *installReport) renderMarkdown(p *Port) string {
 		installedFromCount[installedFrom]++
 	}
 	freshInstallCount := len(orderedEntries) - preinstalledCount
+	cacheHits, cacheMisses := cacheCountsOf(orderedEntries)
 
 	lines = append(lines, "# Install report")
 	lines = append(lines, "")
@@ -139,6 +154,8 @@ func (i *installReport) renderMarkdown(p *Port) string {
 	lines = append(lines, fmt.Sprintf("- Preinstalled: `%d`", preinstalledCount))
 	lines = append(lines, fmt.Sprintf("- Buildtime dependencies: `%d`", buildtimeCount))
 	lines = append(lines, fmt.Sprintf("- Runtime dependencies: `%d`", runtimeCount))
+	lines = append(lines, fmt.Sprintf("- Total time: `%s`", FormatSeconds(time.Since(i.startedAt).Seconds())))
+	lines = append(lines, fmt.Sprintf("- Cache hit/miss: `%d/%d` (`%s`)", cacheHits, cacheMisses, percent(cacheHits, cacheHits+cacheMisses)))
 	lines = append(lines, "")
 	lines = append(lines, "### Installation source distribution")
 	lines = append(lines, "")
@@ -193,15 +210,157 @@ func (i *installReport) renderMarkdown(p *Port) string {
 		))
 	}
 
+	lines = append(lines, i.renderStatsMarkdown(orderedEntries)...)
 	return strings.Join(lines, "\n") + "\n"
 }
 
+// renderStatsMarkdown renders time and resource usage of fresh installed ports, slowest first.
+func (i *installReport) renderStatsMarkdown(entries []installReportEntry) []string {
+	var fresh []installReportEntry
+	for _, entry := range entries {
+		if entry.InstalledFrom != "preinstalled" {
+			fresh = append(fresh, entry)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+	sort.SliceStable(fresh, func(a, b int) bool {
+		return fresh[a].Stats.WallSeconds() > fresh[b].Stats.WallSeconds()
+	})
+
+	lines := []string{
+		"",
+		"## Build statistics",
+		"",
+		"| Name Version | Installed From | Cache | Wall | CPU | Peak RSS | Package Size | Stages |",
+		"| --- | --- | --- | --- | --- | --- | --- | --- |",
+	}
+	for _, entry := range fresh {
+		stats := entry.Stats
+		var stages []string
+		for _, stage := range stats.Stages {
+			stages = append(stages, fmt.Sprintf("%s `%s`", stage.Stage, FormatSeconds(stage.WallSeconds)))
+		}
+
+		cache := expr.If(stats.Cache == "", "-", stats.Cache)
+		switch {
+		case stats.CacheStored && stats.ArchiveBytes > 0:
+			cache += fmt.Sprintf(" (stored `%s`)", FormatBytes(stats.ArchiveBytes))
+		case stats.CacheStored:
+			cache += " (stored)"
+		case stats.ArchiveBytes > 0:
+			cache += fmt.Sprintf(" (`%s`)", FormatBytes(stats.ArchiveBytes))
+		}
+
+		lines = append(lines, fmt.Sprintf("| `%s` | %s | %s | `%s` | `%s` | `%s` | `%s` | %s |",
+			entry.Port,
+			expr.If(entry.InstalledFrom == "", "-", entry.InstalledFrom),
+			cache,
+			FormatSeconds(stats.WallSeconds()),
+			FormatSeconds(stats.CPUSeconds()),
+			FormatBytes(stats.PeakRSS()),
+			FormatBytes(stats.PackageBytes),
+			expr.If(len(stages) == 0, "-", strings.Join(stages, ", ")),
+		))
+	}
+	return lines
+}
+
+// cacheCountsOf returns how many times artifact pkgcache is hit and missed.
+func cacheCountsOf(entries []installReportEntry) (hits, misses int) {
+	for _, entry := range entries {
+		switch entry.Stats.Cache {
+		case CacheHit:
+			hits++
+		case CacheMiss:
+			misses++
+		}
+	}
+	return hits, misses
+}
+
+// InstallReportOutput is the JSON schema of install report.
+type InstallReportOutput struct {
+	OutputHeader
+	Root        string               `json:"root"`
+	GeneratedAt string               `json:"generated_at"`
+	Platform    string               `json:"platform"`
+	Project     string               `json:"project"`
+	BuildType   string               `json:"build_type"`
+	Summary     InstallReportSummary `json:"summary"`
+	Packages    []*DependencyNode    `json:"packages"`
+	Stats       []PortStats          `json:"stats,omitempty"`
+}
+
+type InstallReportSummary struct {
+	Total          int            `json:"total"`
+	FreshInstalled int            `json:"fresh_installed"`
+	Preinstalled   int            `json:"preinstalled"`
+	Buildtime      int            `json:"buildtime"`
+	Runtime        int            `json:"runtime"`
+	InstalledFrom  map[string]int `json:"installed_from"`
+	WallSeconds    float64        `json:"wall_seconds"`
+	CacheHits      int            `json:"cache_hits"`
+	CacheMisses    int            `json:"cache_misses"`
+}
+
+func (i *installReport) renderJSON(p *Port) InstallReportOutput {
+	platformName := expr.If(p.DevDep || p.HostDep, p.ctx.Platform().GetHostName(), p.ctx.Platform().GetName())
+	output := InstallReportOutput{
+		OutputHeader: NewOutputHeader("install_report"),
+		Root:         i.rootPort,
+		GeneratedAt:  time.Now().Format(time.RFC3339),
+		Platform:     platformName,
+		Project:      p.ctx.Project().GetName(),
+		BuildType:    p.ctx.BuildType(),
+		Summary: InstallReportSummary{
+			InstalledFrom: make(map[string]int),
+		},
+		Packages: []*DependencyNode{},
+	}
+
+	orderedEntries := i.orderedEntries()
+	for _, entry := range orderedEntries {
+		if entry.DevDep || entry.HostDev {
+			output.Summary.Buildtime++
+		} else {
+			output.Summary.Runtime++
+		}
+		if entry.InstalledFrom == "preinstalled" {
+			output.Summary.Preinstalled++
+		}
+		output.Summary.InstalledFrom[entry.InstalledFrom]++
+
+		node := NewDependencyNode(entry.Port)
+		node.Parent = entry.Parent
+		node.DevDep = entry.DevDep
+		node.HostDep = entry.HostDev
+		node.BuildSystem = entry.BuildSystem
+		node.InstalledFrom = entry.InstalledFrom
+		node.BuildHash = entry.BuildHash
+		output.Packages = append(output.Packages, node)
+
+		if entry.InstalledFrom != "preinstalled" {
+			output.Stats = append(output.Stats, entry.Stats)
+		}
+	}
+	output.Summary.Total = len(output.Packages)
+	output.Summary.FreshInstalled = output.Summary.Total - output.Summary.Preinstalled
+	output.Summary.WallSeconds = time.Since(i.startedAt).Seconds()
+	output.Summary.CacheHits, output.Summary.CacheMisses = cacheCountsOf(orderedEntries)
+
+	return output
+}
+
 func (i *installReport) orderedEntries() []installReportEntry {
 	// Convert map to slice for deterministic ordering in report output.
+	i.mutex.Lock()
 	ordered := make([]installReportEntry, 0, len(i.entries))
 	for _, entry := range i.entries {
 		ordered = append(ordered, entry)
 	}
+	i.mutex.Unlock()
 
 	// Multi-key sort (high priority -> low priority):
 	// 1) dependency type group: runtime, buildtime, native
@@ -267,9 +426,30 @@ func (i *installReport) write(p *Port) (string, error) {
 	}
 
 	fileBase := strings.ReplaceAll(i.rootPort, "@", "_")
-	mdPath := filepath.Join(statisticDir, fileBase+".md")
+
+	// Append stats into history, which is used by `celer stats`.
+	platformName := expr.If(p.DevDep || p.HostDep, p.ctx.Platform().GetHostName(), p.ctx.Platform().GetName())
+	if err := i.appendStats(p, platformName, statisticDir, fileBase); err != nil {
+		return "", fmt.Errorf("failed to append install stats -> %w", err)
+	}
+
+	// Generate json report.
+	if i.format == OutputFormatJSON {
+		jsonPath := filepath.Join(statisticDir, fileBase+".json")
+		file, err := os.Create(jsonPath)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		if err := WriteJSON(file, i.renderJSON(p)); err != nil {
+			return "", err
+		}
+		return jsonPath, nil
+	}
 
 	// Generate markdown report.
+	mdPath := filepath.Join(statisticDir, fileBase+".md")
 	markdown := i.renderMarkdown(p)
 	if err := os.WriteFile(mdPath, []byte(markdown), os.ModePerm); err != nil {
 		return "", err
@@ -0,0 +1,133 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celer-pkg/celer/buildsystems"
+)
+
+func TestInstallReport_RenderJSON(t *testing.T) {
+	celer := &Celer{configData: configData{Main: Main{BuildType: "release"}}}
+	celer.platform.Name = "x86_64-linux-ubuntu-22.04-gcc-11.5"
+	celer.project.Name = "test_project"
+
+	packageDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(packageDir, "abc123.meta"), []byte("meta"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	root := Port{
+		Name:          "app",
+		Version:       "1.0.0",
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+		PackageDir:    packageDir,
+		ctx:           celer,
+	}
+	zlib := Port{
+		Name:          "zlib",
+		Version:       "1.3.1",
+		Parent:        "app@1.0.0",
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+	nasm := Port{
+		Name:          "nasm",
+		Version:       "2.16.03",
+		Parent:        "app@1.0.0",
+		DevDep:        true,
+		HostDep:       true,
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "makefiles"},
+	}
+	gtest := Port{
+		Name:          "gtest",
+		Version:       "1.15.2",
+		Parent:        "app@1.0.0",
+		DevDep:        true,
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+
+	report := newInstallReport(root.NameVersion())
+	report.add(&zlib, "preinstalled")
+	report.add(&nasm, "devcache")
+	report.add(&gtest, "source")
+	report.add(&root, "source")
+
+	var buffer bytes.Buffer
+	if err := WriteJSON(&buffer, report.renderJSON(&root)); err != nil {
+		t.Fatal(err)
+	}
+
+	var output InstallReportOutput
+	if err := json.Unmarshal(buffer.Bytes(), &output); err != nil {
+		t.Fatalf("failed to decode report -> %v", err)
+	}
+
+	if output.SchemaVersion != OutputSchemaVersion || output.Kind != "install_report" {
+		t.Errorf("header = %+v, want schema %d and kind install_report", output.OutputHeader, OutputSchemaVersion)
+	}
+	if output.Platform != celer.platform.Name || output.Project != "test_project" || output.BuildType != "release" {
+		t.Errorf("workspace = %s/%s/%s", output.Platform, output.Project, output.BuildType)
+	}
+	if output.Summary.Total != 4 || output.Summary.Preinstalled != 1 || output.Summary.FreshInstalled != 3 {
+		t.Errorf("summary = %+v", output.Summary)
+	}
+	if output.Summary.Runtime != 2 || output.Summary.Buildtime != 2 {
+		t.Errorf("summary = %+v", output.Summary)
+	}
+
+	packages := make(map[string]*DependencyNode)
+	for _, node := range output.Packages {
+		packages[node.Name] = node
+	}
+	if node := packages["app"]; node == nil || node.InstalledFrom != "source" || node.BuildHash != "abc123" {
+		t.Errorf("app = %+v, want installed from source with build hash abc123", node)
+	}
+	if node := packages["nasm"]; node == nil || !node.DevDep || !node.HostDep || node.BuildSystem != "makefiles" {
+		t.Errorf("nasm = %+v, want dev and host dependency built by makefiles", node)
+	}
+	if node := packages["gtest"]; node == nil || !node.DevDep || node.HostDep {
+		t.Errorf("gtest = %+v, want dev dependency built for target", node)
+	}
+	if node := packages["zlib"]; node == nil || node.Parent != "app@1.0.0" || node.DevDep || node.HostDep {
+		t.Errorf("zlib = %+v, want runtime dependency of app@1.0.0", node)
+	}
+}
+
+func TestPort_RecordedInstalledFrom(t *testing.T) {
+	port := Port{
+		Name:      "zlib",
+		Version:   "1.3.1",
+		traceFile: filepath.Join(t.TempDir(), "traces", "zlib@1.3.1.trace"),
+	}
+
+	// Ports installed before it's recorded are regarded as preinstalled.
+	if installedFrom := port.recordedInstalledFrom(); installedFrom != "preinstalled" {
+		t.Errorf("installed from = %q, want preinstalled", installedFrom)
+	}
+
+	if err := port.writeInstalledFrom("pkgcache"); err != nil {
+		t.Fatal(err)
+	}
+	if installedFrom := port.recordedInstalledFrom(); installedFrom != "pkgcache" {
+		t.Errorf("installed from = %q, want pkgcache", installedFrom)
+	}
+	if filepath.Ext(port.installedFromFile()) == ".trace" {
+		t.Errorf("installed from file %s should not be regarded as trace file", port.installedFromFile())
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range []string{OutputFormatText, OutputFormatJSON} {
+		if err := ValidateOutputFormat(format); err != nil {
+			t.Errorf("ValidateOutputFormat(%q) = %v, want nil", format, err)
+		}
+	}
+	for _, format := range []string{"", "yaml", "JSON"} {
+		if err := ValidateOutputFormat(format); err == nil {
+			t.Errorf("ValidateOutputFormat(%q) should fail", format)
+		}
+	}
+}
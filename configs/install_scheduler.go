@@ -0,0 +1,543 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/buildtools"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/git"
+	"github.com/celer-pkg/celer/pkgs/refs"
+)
+
+type nodeState int
+
+const (
+	nodePending nodeState = iota
+	nodeRunning
+	nodeDone
+	nodeFailed
+	nodeSkipped
+)
+
+// scheduleNode is one port of the install graph, target and dev/host builds
+// of the same name@version are different nodes, keyed like visitedKey.
+type scheduleNode struct {
+	key           string
+	port          Port
+	force         bool
+	fromStage     string // Only root nodes restart from stage.
+	prepared      bool
+	deps          []*scheduleNode
+	dependents    []*scheduleNode
+	pending       int // Count of deps not yet installed.
+	state         nodeState
+	installedFrom string
+	logPath       string
+	err           error
+}
+
+type scheduleResult struct {
+	node          *scheduleNode
+	jobs          int
+	installedFrom string
+	elapsed       time.Duration
+	err           error
+}
+
+// jobBudget shares the global jobs among concurrently running builds,
+// the sum of jobs handed out never exceeds the total.
+type jobBudget struct {
+	total int
+	used  int
+}
+
+// take returns the jobs granted for a new build, 0 means the budget is
+// exhausted and the build should wait for a running one to finish.
+func (j *jobBudget) take(wanted int) int {
+	available := j.total - j.used
+	if available <= 0 {
+		// Never starve the first build, even with a zero or negative budget.
+		if j.used > 0 {
+			return 0
+		}
+		available = 1
+	}
+
+	granted := min(max(wanted, 1), available)
+	j.used += granted
+	return granted
+}
+
+func (j *jobBudget) release(jobs int) {
+	j.used = max(j.used-jobs, 0)
+}
+
+// installScheduler installs a dependency graph with independent ports built
+// at the same time. Each source build runs in a worker celer process, since
+// build envs and tmp/deps are process-wide and cannot be shared by builds.
+type installScheduler struct {
+	ctx     context.Context
+	options InstallOptions
+	report  *installReport
+	nodes   []*scheduleNode
+	lookup  map[string]*scheduleNode
+
+	// prepare runs in the scheduling loop and reports whether node is
+	// already installed, build runs in its own goroutine.
+	prepare func(node *scheduleNode) (installedFrom string, err error)
+	build   func(node *scheduleNode, jobs int) (installedFrom string, err error)
+}
+
+func newInstallScheduler(ctx context.Context, options InstallOptions, report *installReport) *installScheduler {
+	scheduler := &installScheduler{
+		ctx:     ctx,
+		options: options,
+		report:  report,
+		lookup:  make(map[string]*scheduleNode),
+	}
+	scheduler.prepare = scheduler.prepareNode
+	scheduler.build = scheduler.buildNode
+	return scheduler
+}
+
+// addRoot adds a top-level port and all ports reachable from it.
+func (s *installScheduler) addRoot(port Port) error {
+	_, err := s.addNode(port, true, nil)
+	return err
+}
+
+func (s *installScheduler) addNode(port Port, root bool, visiting map[string]bool) (*scheduleNode, error) {
+	key := port.visitedKey()
+	if node, ok := s.lookup[key]; ok {
+		if visiting[key] {
+			return nil, fmt.Errorf("%s's dependencies contains circular dependency: %s", port.Parent, port.NameVersion())
+		}
+		return node, nil
+	}
+	if visiting == nil {
+		visiting = make(map[string]bool)
+	}
+
+	node := &scheduleNode{
+		key:   key,
+		port:  port,
+		force: s.options.Force && (root || s.options.Recursive),
+	}
+	if root {
+		node.fromStage = s.options.FromStage
+	}
+	s.lookup[key] = node
+	s.nodes = append(s.nodes, node)
+
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	addDep := func(depPort Port, nameVersion string) error {
+		depPort.Parent = port.NameVersion()
+		depPort.installReport = s.report
+		if err := depPort.Init(s.ctx, nameVersion); err != nil {
+			return err
+		}
+		dep, err := s.addNode(depPort, false, visiting)
+		if err != nil {
+			return err
+		}
+		node.deps = append(node.deps, dep)
+		dep.dependents = append(dep.dependents, node)
+		return nil
+	}
+
+	for _, nameVersion := range port.MatchedConfig.DevDependencies {
+		// Same name, version as parent and they are booth build with native toolchain, so skip.
+		if (port.DevDep || port.HostDep) && port.NameVersion() == nameVersion {
+			continue
+		}
+		if err := addDep(Port{DevDep: true, HostDep: true}, nameVersion); err != nil {
+			return nil, err
+		}
+	}
+	for _, nameVersion := range port.MatchedConfig.Dependencies {
+		name, _, _ := strings.Cut(nameVersion, "@")
+		if name == port.Name {
+			return nil, fmt.Errorf("%s's dependencies contains circular dependency: %s", port.NameVersion(), name)
+		}
+		if err := addDep(Port{DevDep: port.DevDep, HostDep: port.HostDep}, nameVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	node.pending = len(node.deps)
+	return node, nil
+}
+
+// run installs all nodes, at most options.Parallel of them at the same time.
+func (s *installScheduler) run() error {
+	var (
+		ready    []*scheduleNode
+		busy     = make(map[string]bool) // name@version whose source dir is in use.
+		results  = make(chan scheduleResult)
+		budget   = jobBudget{total: s.ctx.Jobs()}
+		running  int
+		finished int
+		stopped  bool
+		failures []*scheduleNode
+	)
+
+	for _, node := range s.nodes {
+		if node.pending == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	// complete releases the dependents of node, or skips them when node failed.
+	var complete func(node *scheduleNode)
+	complete = func(node *scheduleNode) {
+		finished++
+		for _, dependent := range node.dependents {
+			if dependent.state != nodePending {
+				continue
+			}
+			if node.state != nodeDone {
+				dependent.state = nodeSkipped
+				dependent.err = fmt.Errorf("dependency %s is not installed", node.port.NameVersion())
+				complete(dependent)
+				continue
+			}
+
+			dependent.pending--
+			if dependent.pending == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	fail := func(node *scheduleNode, err error) {
+		node.state = nodeFailed
+		node.err = err
+		failures = append(failures, node)
+		if !s.options.KeepGoing {
+			stopped = true
+		}
+		complete(node)
+	}
+
+	for finished < len(s.nodes) {
+		// Launch as many ready nodes as allowed.
+		for index := 0; !stopped && running < s.options.Parallel && index < len(ready); {
+			node := ready[index]
+			if node.state != nodePending {
+				ready = append(ready[:index], ready[index+1:]...)
+				continue
+			}
+
+			// Target and dev builds of the same port share one source dir.
+			if busy[node.port.NameVersion()] {
+				index++
+				continue
+			}
+
+			// A prepared node is only waiting for jobs, no need to prepare it again.
+			if !node.prepared {
+				installedFrom, err := s.prepare(node)
+				if err != nil {
+					ready = append(ready[:index], ready[index+1:]...)
+					fail(node, err)
+					continue
+				}
+				if installedFrom != "" {
+					ready = append(ready[:index], ready[index+1:]...)
+					node.state = nodeDone
+					node.installedFrom = installedFrom
+					s.report.add(&node.port, installedFrom)
+					complete(node)
+					continue
+				}
+				node.prepared = true
+			}
+
+			// Split the remaining jobs among the builds that could run now.
+			slots := min(s.options.Parallel-running, len(ready))
+			jobs := budget.take(max((budget.total-budget.used)/max(slots, 1), 1))
+			if jobs == 0 {
+				break
+			}
+
+			ready = append(ready[:index], ready[index+1:]...)
+			node.state = nodeRunning
+			busy[node.port.NameVersion()] = true
+			running++
+
+			color.Printf(color.Title, "\n[%d/%d] build %s with %d jobs\n", finished+running, len(s.nodes), node.key, jobs)
+			if node.logPath != "" {
+				color.PrintHint("Log: %s", node.logPath)
+			}
+
+			go func(node *scheduleNode, jobs int) {
+				startAt := time.Now()
+				installedFrom, err := s.build(node, jobs)
+				results <- scheduleResult{
+					node:          node,
+					jobs:          jobs,
+					installedFrom: installedFrom,
+					elapsed:       time.Since(startAt),
+					err:           err,
+				}
+			}(node, jobs)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		// Wait for one running build to finish.
+		result := <-results
+		running--
+		budget.release(result.jobs)
+		delete(busy, result.node.port.NameVersion())
+
+		elapsed := result.elapsed.Round(time.Second)
+		if result.err != nil {
+			color.Printf(color.Error, "✘ %s failed after %s -> %s\n", result.node.key, elapsed, result.err)
+			fail(result.node, result.err)
+			continue
+		}
+
+		result.node.state = nodeDone
+		result.node.installedFrom = result.installedFrom
+		s.report.add(&result.node.port, result.installedFrom)
+		color.PrintPass("%s is installed from %s in %s", result.node.key, result.installedFrom, elapsed)
+		complete(result.node)
+	}
+
+	return s.summary(failures)
+}
+
+// summary returns an error listing failed and skipped ports.
+func (s *installScheduler) summary(failures []*scheduleNode) error {
+	var skipped, notStarted int
+	for _, node := range s.nodes {
+		switch node.state {
+		case nodeSkipped:
+			skipped++
+		case nodePending:
+			notStarted++
+		}
+	}
+	if len(failures) == 0 {
+		if notStarted > 0 {
+			return fmt.Errorf("%d port(s) cannot be scheduled, their dependencies are never ready", notStarted)
+		}
+		return nil
+	}
+
+	var lines []string
+	for _, node := range failures {
+		line := fmt.Sprintf("%s: %s", node.key, node.err)
+		if node.logPath != "" {
+			line += fmt.Sprintf(" (log: %s)", node.logPath)
+		}
+		lines = append(lines, line)
+	}
+	if skipped > 0 {
+		lines = append(lines, fmt.Sprintf("%d port(s) skipped since their dependencies failed", skipped))
+	}
+	if notStarted > 0 {
+		lines = append(lines, fmt.Sprintf("%d port(s) not started, use --keep-going to build independent ports after a failure", notStarted))
+	}
+
+	return fmt.Errorf("failed to install %d port(s):\n  %s", len(failures), strings.Join(lines, "\n  "))
+}
+
+// checkTools checks build tools of all nodes once, then workers find them ready.
+func (s *installScheduler) checkTools() error {
+	var allTools []string
+	for _, node := range s.nodes {
+		allTools = append(allTools, node.port.MatchedConfig.CheckTools()...)
+	}
+	if s.ctx.CCacheEnabled() {
+		allTools = append(allTools, "ccache")
+	}
+	return buildtools.CheckTools(s.ctx, allTools...)
+}
+
+// prepareNode clones source of node in the scheduler process, so that refs resolved
+// before deploy are honoured, then reports if node is already installed.
+func (s *installScheduler) prepareNode(node *scheduleNode) (string, error) {
+	port := node.port
+
+	if !node.force && node.fromStage == "" {
+		installed, err := port.Installed()
+		if err != nil {
+			return "", err
+		}
+		if installed {
+			return "preinstalled", nil
+		}
+	}
+
+	// Source is cloned here unless it's already present, then worker reuses it as is.
+	if port.Package.Url != "_" && len(port.BuildConfigs) > 0 {
+		ready, err := sourceReady(port)
+		if err != nil {
+			return "", err
+		}
+		if !ready {
+			unlock, err := port.lockSource()
+			if err != nil {
+				return "", err
+			}
+			defer unlock()
+
+			repoRef := expr.If(port.Package.Checksum != "", port.Package.Checksum, port.Package.Ref)
+			if err := port.cloneSource(port.Package.Url, repoRef, port.Package.Archive, port.Package.Depth); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	buildDir := port.MatchedConfig.PortConfig.BuildDir
+	node.logPath = filepath.Join(filepath.Dir(buildDir), filepath.Base(buildDir)+"-celer.log")
+	return "", nil
+}
+
+// sourceReady reports whether source of port is already present in its repo dir,
+// a git repo is ready only when it's checked out at the commit pinned by checksum or resolved ref.
+func sourceReady(port Port) (bool, error) {
+	repoDir := port.MatchedConfig.PortConfig.RepoDir
+	entries, err := os.ReadDir(repoDir)
+	if err != nil || len(entries) == 0 {
+		return false, nil
+	}
+	if !fileio.PathExists(filepath.Join(repoDir, ".git")) {
+		return true, nil
+	}
+
+	commit := port.Package.Checksum
+	if commit == "" {
+		commit = refs.GetResolvedCommit(expr.If(port.DevDep || port.HostDep, port.NameVersion()+"-dev", port.NameVersion()))
+	}
+	if !git.IsFullCommitHash(commit) {
+		return true, nil
+	}
+	currentCommit, err := git.GetCommitHash(repoDir)
+	if err != nil {
+		return false, err
+	}
+	return currentCommit == commit, nil
+}
+
+// buildNode installs node with a worker process and returns where it was installed from.
+func (s *installScheduler) buildNode(node *scheduleNode, jobs int) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate celer executable -> %w", err)
+	}
+
+	// Every worker owns a tmp dir, result file is written by the worker when it succeeds.
+	// Workers of other celer processes in this workspace are kept apart by pid.
+	workerName := strings.NewReplacer(" ", "", "[", "-", "]", "").Replace(node.key)
+	workerTmpDir := filepath.Join(dirs.TmpDir, "workers", strconv.Itoa(os.Getpid()), workerName)
+	if err := os.RemoveAll(workerTmpDir); err != nil {
+		return "", err
+	}
+	if err := fileio.MkdirAll(workerTmpDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workerTmpDir)
+	resultFile := filepath.Join(workerTmpDir, "result")
+	args := s.workerArgs(node, jobs, resultFile)
+
+	if err := fileio.MkdirAll(filepath.Dir(node.logPath), os.ModePerm); err != nil {
+		return "", err
+	}
+	logFile, err := os.Create(node.logPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create log file -> %w", err)
+	}
+	defer logFile.Close()
+
+	command := exec.Command(executable, args...)
+	command.Dir = dirs.WorkspaceDir
+	command.Env = append(os.Environ(), dirs.TmpDirEnv+"="+workerTmpDir)
+	command.Stdout = logFile
+	command.Stderr = logFile
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("worker exited with %w", err)
+	}
+
+	bytes, err := os.ReadFile(resultFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read worker result -> %w", err)
+	}
+
+	// Stats of worker are merged before port is added into report.
+	stats, err := readWorkerStats(resultFile)
+	if err != nil {
+		return "", err
+	}
+	s.report.recordWorkerStats(&node.port, stats)
+
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+// workerArgs returns arguments of worker process installing node, the port is
+// installed with the same features as it's selected in the graph, and with
+// platform and build type of scheduler, which may differ from celer.toml in matrix.
+func (s *installScheduler) workerArgs(node *scheduleNode, jobs int, resultFile string) []string {
+	nameVersion := JoinFeatures(node.port.NameVersion(), node.port.SelectedFeatures)
+	args := []string{"install", nameVersion, fmt.Sprintf("--jobs=%d", jobs), "--result-file=" + resultFile}
+	if platform := s.ctx.Platform().GetName(); platform != "" {
+		args = append(args, "--platform="+platform)
+	}
+	args = append(args, "--build-type="+s.ctx.BuildType())
+	if node.port.DevDep || node.port.HostDep {
+		args = append(args, "--dev")
+	}
+	if node.force {
+		args = append(args, "--force")
+	}
+	if node.fromStage != "" {
+		args = append(args, "--from-stage="+node.fromStage)
+	}
+	return args
+}
+
+// installScheduled installs ports together with all their dependencies in parallel,
+// and returns where each of the ports was installed from.
+func installScheduled(ctx context.Context, ports []Port, options InstallOptions, report *installReport) ([]string, error) {
+	scheduler := newInstallScheduler(ctx, options, report)
+	for _, port := range ports {
+		port.installReport = report
+		if err := scheduler.addRoot(port); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scheduler.checkTools(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Platform().Setup(); err != nil {
+		return nil, err
+	}
+
+	color.Printf(color.Title, "\n[schedule %d ports with %d builds in parallel]\n", len(scheduler.nodes), options.Parallel)
+	if err := scheduler.run(); err != nil {
+		return nil, err
+	}
+
+	installedFroms := make([]string, 0, len(ports))
+	for _, port := range ports {
+		installedFroms = append(installedFroms, scheduler.lookup[port.visitedKey()].installedFrom)
+	}
+	return installedFroms, nil
+}
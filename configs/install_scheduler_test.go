@@ -0,0 +1,342 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/celer-pkg/celer/buildsystems"
+	"github.com/celer-pkg/celer/pkgs/git"
+)
+
+// newTestScheduler builds a scheduler from "name -> deps" without touching port files.
+func newTestScheduler(t *testing.T, jobs int, options InstallOptions, graph map[string][]string, order []string) *installScheduler {
+	t.Helper()
+
+	celer := &Celer{configData: configData{Main: Main{Jobs: jobs}}}
+	scheduler := newInstallScheduler(celer, options, newInstallReport("test"))
+	for _, name := range order {
+		node := &scheduleNode{
+			key: name + "@1.0.0",
+			port: Port{
+				Name:          name,
+				Version:       "1.0.0",
+				MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+			},
+		}
+		scheduler.lookup[node.key] = node
+		scheduler.nodes = append(scheduler.nodes, node)
+	}
+	for _, name := range order {
+		node := scheduler.lookup[name+"@1.0.0"]
+		for _, depName := range graph[name] {
+			dep := scheduler.lookup[depName+"@1.0.0"]
+			node.deps = append(node.deps, dep)
+			dep.dependents = append(dep.dependents, node)
+		}
+		node.pending = len(node.deps)
+	}
+	scheduler.prepare = func(node *scheduleNode) (string, error) {
+		return "", nil
+	}
+	return scheduler
+}
+
+func TestInstallScheduler_DependenciesFirst(t *testing.T) {
+	graph := map[string][]string{
+		"app":    {"libA", "libB"},
+		"libA":   {"zlib"},
+		"libB":   {"zlib"},
+		"zlib":   {},
+		"single": {},
+	}
+	order := []string{"app", "libA", "libB", "zlib", "single"}
+	scheduler := newTestScheduler(t, 8, InstallOptions{Parallel: 3}, graph, order)
+
+	var (
+		mutex    sync.Mutex
+		finished []string
+	)
+	scheduler.build = func(node *scheduleNode, jobs int) (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, dep := range node.deps {
+			if !slices.Contains(finished, dep.port.Name) {
+				return "", fmt.Errorf("%s is built before its dependency %s", node.port.Name, dep.port.Name)
+			}
+		}
+		finished = append(finished, node.port.Name)
+		return "source", nil
+	}
+
+	if err := scheduler.run(); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(finished) != len(order) {
+		t.Fatalf("built %v, want all of %v", finished, order)
+	}
+	for _, node := range scheduler.nodes {
+		if node.state != nodeDone || node.installedFrom != "source" {
+			t.Errorf("%s: state = %v, installedFrom = %q", node.key, node.state, node.installedFrom)
+		}
+	}
+}
+
+func TestInstallScheduler_RespectsParallelAndJobs(t *testing.T) {
+	graph := map[string][]string{}
+	order := []string{"a", "b", "c", "d", "e", "f"}
+	scheduler := newTestScheduler(t, 4, InstallOptions{Parallel: 2}, graph, order)
+
+	var (
+		mutex      sync.Mutex
+		running    int
+		maxRunning int
+		usedJobs   int
+		maxJobs    int
+		overlapped = make(chan struct{})
+		once       sync.Once
+	)
+	scheduler.build = func(node *scheduleNode, jobs int) (string, error) {
+		mutex.Lock()
+		running++
+		usedJobs += jobs
+		maxRunning = max(maxRunning, running)
+		maxJobs = max(maxJobs, usedJobs)
+		if running == 2 {
+			once.Do(func() { close(overlapped) })
+		}
+		mutex.Unlock()
+
+		// Hold build open until two builds are running, and a while longer,
+		// so that a scheduler without limits would start more of them.
+		select {
+		case <-overlapped:
+		case <-time.After(10 * time.Second):
+			return "", fmt.Errorf("%s never overlapped with another build", node.port.Name)
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		usedJobs -= jobs
+		mutex.Unlock()
+		return "source", nil
+	}
+
+	if err := scheduler.run(); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if maxRunning != 2 {
+		t.Errorf("max running builds = %d, want 2 builds overlapped", maxRunning)
+	}
+	if maxJobs > 4 {
+		t.Errorf("max jobs in use = %d, want <= 4", maxJobs)
+	}
+}
+
+func TestSourceReady(t *testing.T) {
+	repoDir := filepath.Join(t.TempDir(), "src")
+	port := Port{
+		Name:          "foo",
+		Version:       "1.0.0",
+		MatchedConfig: &buildsystems.BuildConfig{PortConfig: buildsystems.PortConfig{RepoDir: repoDir}},
+	}
+
+	// Missing or empty source is not ready.
+	if ready, err := sourceReady(port); err != nil || ready {
+		t.Errorf("missing source should not be ready, got %v, %v", ready, err)
+	}
+	if err := os.MkdirAll(repoDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if ready, err := sourceReady(port); err != nil || ready {
+		t.Errorf("empty source should not be ready, got %v, %v", ready, err)
+	}
+
+	// Extracted archive is ready, even it has a checksum.
+	if err := os.WriteFile(filepath.Join(repoDir, "foo.c"), []byte("int foo;\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	port.Package.Checksum = strings.Repeat("a", 64)
+	if ready, err := sourceReady(port); err != nil || !ready {
+		t.Errorf("extracted source should be ready, got %v, %v", ready, err)
+	}
+
+	// Git repo is ready only at the pinned commit.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found.")
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, output)
+		}
+	}
+	commit, err := git.GetCommitHash(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port.Package.Checksum = commit
+	if ready, err := sourceReady(port); err != nil || !ready {
+		t.Errorf("git repo at pinned commit should be ready, got %v, %v", ready, err)
+	}
+	port.Package.Checksum = strings.Repeat("b", 40)
+	if ready, err := sourceReady(port); err != nil || ready {
+		t.Errorf("git repo at another commit should not be ready, got %v, %v", ready, err)
+	}
+}
+
+func TestInstallScheduler_FailFast(t *testing.T) {
+	graph := map[string][]string{
+		"app":  {"bad"},
+		"bad":  {},
+		"next": {"good"},
+		"good": {},
+	}
+	order := []string{"app", "bad", "next", "good"}
+	scheduler := newTestScheduler(t, 1, InstallOptions{Parallel: 1}, graph, order)
+	scheduler.build = func(node *scheduleNode, jobs int) (string, error) {
+		if node.port.Name == "bad" {
+			return "", fmt.Errorf("build failed")
+		}
+		return "source", nil
+	}
+
+	if err := scheduler.run(); err == nil {
+		t.Fatal("run() should fail")
+	}
+	if state := scheduler.lookup["app@1.0.0"].state; state != nodeSkipped {
+		t.Errorf("app state = %v, want skipped", state)
+	}
+	if state := scheduler.lookup["next@1.0.0"].state; state != nodePending {
+		t.Errorf("next state = %v, want pending since fail-fast stops launching", state)
+	}
+}
+
+func TestInstallScheduler_KeepGoing(t *testing.T) {
+	graph := map[string][]string{
+		"app":  {"bad"},
+		"bad":  {},
+		"next": {"good"},
+		"good": {},
+	}
+	order := []string{"app", "bad", "next", "good"}
+	scheduler := newTestScheduler(t, 1, InstallOptions{Parallel: 1, KeepGoing: true}, graph, order)
+	scheduler.build = func(node *scheduleNode, jobs int) (string, error) {
+		if node.port.Name == "bad" {
+			return "", fmt.Errorf("build failed")
+		}
+		return "source", nil
+	}
+
+	if err := scheduler.run(); err == nil {
+		t.Fatal("run() should fail")
+	}
+	if state := scheduler.lookup["app@1.0.0"].state; state != nodeSkipped {
+		t.Errorf("app state = %v, want skipped", state)
+	}
+	if state := scheduler.lookup["next@1.0.0"].state; state != nodeDone {
+		t.Errorf("next state = %v, want done with --keep-going", state)
+	}
+}
+
+func TestInstallScheduler_PreinstalledSkipsBuild(t *testing.T) {
+	graph := map[string][]string{
+		"app":  {"zlib"},
+		"zlib": {},
+	}
+	scheduler := newTestScheduler(t, 2, InstallOptions{Parallel: 2}, graph, []string{"app", "zlib"})
+	scheduler.prepare = func(node *scheduleNode) (string, error) {
+		if node.port.Name == "zlib" {
+			return "preinstalled", nil
+		}
+		return "", nil
+	}
+
+	var built []string
+	scheduler.build = func(node *scheduleNode, jobs int) (string, error) {
+		built = append(built, node.port.Name)
+		return "source", nil
+	}
+
+	if err := scheduler.run(); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !slices.Equal(built, []string{"app"}) {
+		t.Errorf("built = %v, want [app]", built)
+	}
+}
+
+func TestInstallScheduler_WorkerArgs(t *testing.T) {
+	celer := &Celer{configData: configData{Main: Main{Jobs: 4, BuildType: "release"}}}
+	scheduler := newInstallScheduler(celer, InstallOptions{Force: true}, newInstallReport("test"))
+	root := Port{
+		Name:             "opencv",
+		Version:          "4.8.0",
+		SelectedFeatures: []string{"contrib", "cuda"},
+		MatchedConfig:    &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+	if err := scheduler.addRoot(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Features of root are kept, so worker builds the same as serial install.
+	args := scheduler.workerArgs(scheduler.nodes[0], 2, "result")
+	expected := []string{"install", "opencv@4.8.0[contrib,cuda]", "--jobs=2", "--result-file=result", "--build-type=release", "--force"}
+	if !slices.Equal(args, expected) {
+		t.Errorf("workerArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestInstallScheduler_WorkerArgsOfMatrix(t *testing.T) {
+	// Matrix cell differs from platform and build type of celer.toml.
+	celer := &Celer{configData: configData{Main: Main{Jobs: 4, Platform: "x86_64-linux", BuildType: "debug"}}}
+	celer.platform.Name = "aarch64-linux"
+	scheduler := newInstallScheduler(celer, InstallOptions{}, newInstallReport("test"))
+	dep := Port{
+		Name:          "zlib",
+		Version:       "1.3.1",
+		DevDep:        true,
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+	if err := scheduler.addRoot(dep); err != nil {
+		t.Fatal(err)
+	}
+
+	args := scheduler.workerArgs(scheduler.nodes[0], 1, "result")
+	expected := []string{"install", "zlib@1.3.1", "--jobs=1", "--result-file=result", "--platform=aarch64-linux", "--build-type=debug", "--dev"}
+	if !slices.Equal(args, expected) {
+		t.Errorf("workerArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestJobBudget_Take(t *testing.T) {
+	budget := jobBudget{total: 8}
+	if jobs := budget.take(6); jobs != 6 {
+		t.Fatalf("take(6) = %d, want 6", jobs)
+	}
+	if jobs := budget.take(6); jobs != 2 {
+		t.Fatalf("take(6) = %d, want 2", jobs)
+	}
+	if jobs := budget.take(1); jobs != 0 {
+		t.Fatalf("take(1) = %d, want 0 when budget is exhausted", jobs)
+	}
+	budget.release(6)
+	if jobs := budget.take(3); jobs != 3 {
+		t.Fatalf("take(3) = %d, want 3", jobs)
+	}
+
+	empty := jobBudget{total: 0}
+	if jobs := empty.take(4); jobs != 1 {
+		t.Fatalf("take(4) with empty budget = %d, want 1", jobs)
+	}
+}
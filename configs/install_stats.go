@@ -0,0 +1,461 @@
+package configs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/celer-pkg/celer/buildsystems"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	// statsFileSuffix is the suffix of stats history beside install report, one run per line.
+	statsFileSuffix = ".stats.jsonl"
+)
+
+// cloneStats records time of cloning source in current process, keyed by visitedKey,
+// since ports are cloned with other Port instances before they're installed.
+var cloneStats sync.Map
+
+// PortStats is time and resource usage of installing a port.
+type PortStats struct {
+	Port          string                    `json:"port"`
+	DevDep        bool                      `json:"dev"`
+	InstalledFrom string                    `json:"installed_from"`
+	Stages        []buildsystems.StageStats `json:"stages,omitempty"`
+	Cache         string                    `json:"cache,omitempty"` // Result of looking up artifact pkgcache, empty if not looked up.
+	CacheStored   bool                      `json:"cache_stored,omitempty"`
+	ArchiveBytes  int64                     `json:"archive_bytes,omitempty"` // Size of archive restored from or stored into artifact pkgcache.
+	PackageBytes  int64                     `json:"package_bytes,omitempty"`
+}
+
+// WallSeconds returns total wall time of all stages.
+func (p PortStats) WallSeconds() float64 {
+	var total float64
+	for _, stage := range p.Stages {
+		total += stage.WallSeconds
+	}
+	return total
+}
+
+// CPUSeconds returns total CPU time of all stages.
+func (p PortStats) CPUSeconds() float64 {
+	var total float64
+	for _, stage := range p.Stages {
+		total += stage.CPUSeconds
+	}
+	return total
+}
+
+// PeakRSS returns the max peak RSS of all stages.
+func (p PortStats) PeakRSS() int64 {
+	var peak int64
+	for _, stage := range p.Stages {
+		peak = max(peak, stage.PeakRSS)
+	}
+	return peak
+}
+
+// InstallStatsRun is stats of one install or deploy, which is a line in stats history.
+type InstallStatsRun struct {
+	Root        string      `json:"root"`
+	StartedAt   time.Time   `json:"started_at"`
+	WallSeconds float64     `json:"wall_seconds"`
+	Platform    string      `json:"platform"`
+	Project     string      `json:"project"`
+	BuildType   string      `json:"build_type"`
+	Ports       []PortStats `json:"ports"`
+}
+
+// CacheCounts returns how many times artifact pkgcache is hit and missed.
+func (r InstallStatsRun) CacheCounts() (hits, misses int) {
+	for _, port := range r.Ports {
+		switch port.Cache {
+		case CacheHit:
+			hits++
+		case CacheMiss:
+			misses++
+		}
+	}
+	return hits, misses
+}
+
+// cloneSource clones source of port, and records the time when it's really cloned or downloaded.
+func (p Port) cloneSource(url, ref, archive string, depth int) error {
+	if fileio.PathExists(p.MatchedConfig.PortConfig.RepoDir) {
+		return p.MatchedConfig.Clone(url, ref, archive, depth)
+	}
+
+	stats, err := buildsystems.MeasureStage(buildsystems.StageClone, func() error {
+		return p.MatchedConfig.Clone(url, ref, archive, depth)
+	})
+	if err == nil {
+		cloneStats.Store(p.visitedKey(), stats)
+	}
+	return err
+}
+
+func (i *installReport) statsOf(port *Port) *PortStats {
+	key := port.visitedKey()
+	stats, ok := i.stats[key]
+	if !ok {
+		stats = &PortStats{}
+		i.stats[key] = stats
+	}
+	return stats
+}
+
+// recordStages records stages run by buildsystem of port.
+func (i *installReport) recordStages(port *Port, stages []buildsystems.StageStats) {
+	if i == nil || len(stages) == 0 {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	stats := i.statsOf(port)
+	stats.Stages = append(stats.Stages, stages...)
+}
+
+// recordCache records result of looking up artifact pkgcache, archiveBytes is size of the restored archive.
+func (i *installReport) recordCache(port *Port, result string, archiveBytes int64) {
+	if i == nil {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	stats := i.statsOf(port)
+	stats.Cache = result
+	stats.ArchiveBytes = archiveBytes
+}
+
+// recordCacheStored records package of port is stored into artifact pkgcache, archiveBytes
+// is size of the stored archive, it's 0 if the archive was already cached.
+func (i *installReport) recordCacheStored(port *Port, archiveBytes int64) {
+	if i == nil {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	stats := i.statsOf(port)
+	stats.CacheStored = true
+	if archiveBytes > 0 {
+		stats.ArchiveBytes = archiveBytes
+	}
+}
+
+// recordWorkerStats merges stats reported by scheduler worker.
+func (i *installReport) recordWorkerStats(port *Port, workerStats PortStats) {
+	if i == nil {
+		return
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	stats := i.statsOf(port)
+	stats.Stages = append(stats.Stages, workerStats.Stages...)
+	stats.Cache = workerStats.Cache
+	stats.CacheStored = workerStats.CacheStored
+	stats.ArchiveBytes = workerStats.ArchiveBytes
+}
+
+// portStats returns the collected stats of port, it must be called with mutex locked.
+func (i *installReport) portStats(port *Port, installedFrom string) PortStats {
+	stats := PortStats{
+		Port:          port.NameVersion(),
+		DevDep:        port.DevDep || port.HostDep,
+		InstalledFrom: installedFrom,
+	}
+	if collected, ok := i.stats[port.visitedKey()]; ok {
+		stats.Stages = append(stats.Stages, collected.Stages...)
+		stats.Cache = collected.Cache
+		stats.CacheStored = collected.CacheStored
+		stats.ArchiveBytes = collected.ArchiveBytes
+	}
+	if value, ok := cloneStats.Load(port.visitedKey()); ok {
+		stats.Stages = append([]buildsystems.StageStats{value.(buildsystems.StageStats)}, stats.Stages...)
+	}
+	if installedFrom != "preinstalled" && port.PackageDir != "" {
+		stats.PackageBytes = dirSize(port.PackageDir)
+	}
+	return stats
+}
+
+// writeWorkerStats writes stats of port for the scheduler, beside the result file.
+func (i *installReport) writeWorkerStats(port *Port, resultFile string) error {
+	i.mutex.Lock()
+	stats := i.portStats(port, "")
+	i.mutex.Unlock()
+
+	bytes, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(resultFile+".stats", bytes, os.ModePerm)
+}
+
+// readWorkerStats reads stats written by worker, missing stats is not an error.
+func readWorkerStats(resultFile string) (PortStats, error) {
+	var stats PortStats
+	bytes, err := os.ReadFile(resultFile + ".stats")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, err
+	}
+	if err := json.Unmarshal(bytes, &stats); err != nil {
+		return stats, fmt.Errorf("failed to parse worker stats -> %w", err)
+	}
+	return stats, nil
+}
+
+// appendStats appends stats of ports that are not preinstalled into stats history.
+func (i *installReport) appendStats(p *Port, platformName, statisticDir, fileBase string) error {
+	run := InstallStatsRun{
+		Root:        i.rootPort,
+		StartedAt:   i.startedAt,
+		WallSeconds: time.Since(i.startedAt).Seconds(),
+		Platform:    platformName,
+		Project:     p.ctx.Project().GetName(),
+		BuildType:   p.ctx.BuildType(),
+		Ports:       []PortStats{},
+	}
+	for _, entry := range i.orderedEntries() {
+		if entry.InstalledFrom != "preinstalled" {
+			run.Ports = append(run.Ports, entry.Stats)
+		}
+	}
+
+	bytes, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(statisticDir, fileBase+statsFileSuffix),
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(bytes, '\n'))
+	return err
+}
+
+// LoadInstallStats reads stats history of all installs and deploys in workspace, ordered by start time.
+func LoadInstallStats() ([]InstallStatsRun, error) {
+	statisticsDir := filepath.Join(dirs.InstalledDir, "celer", "statistics")
+	if !fileio.PathExists(statisticsDir) {
+		return nil, nil
+	}
+
+	var runs []InstallStatsRun
+	err := filepath.WalkDir(statisticsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), statsFileSuffix) {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var run InstallStatsRun
+			if err := json.Unmarshal([]byte(line), &run); err != nil {
+				return fmt.Errorf("failed to parse %s -> %w", path, err)
+			}
+			runs = append(runs, run)
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(runs, func(a, b int) bool {
+		return runs[a].StartedAt.Before(runs[b].StartedAt)
+	})
+	return runs, nil
+}
+
+// SlowPort is a port built from source with its latest and average build time.
+type SlowPort struct {
+	Port           string  `json:"port"`
+	DevDep         bool    `json:"dev"`
+	Builds         int     `json:"builds"`
+	LatestSeconds  float64 `json:"latest_seconds"`
+	AverageSeconds float64 `json:"average_seconds"`
+	CPUSeconds     float64 `json:"cpu_seconds"`    // CPU time of the latest build.
+	PeakRSS        int64   `json:"peak_rss_bytes"` // Peak RSS of the latest build.
+	Slowest        string  `json:"slowest_stage"`  // Slowest stage of the latest build.
+}
+
+// SlowestPorts returns ports built from source ordered by their latest build time, at most limit ports.
+func SlowestPorts(runs []InstallStatsRun, limit int) []SlowPort {
+	slowPorts := make(map[string]*SlowPort)
+	for _, run := range runs {
+		for _, port := range run.Ports {
+			if len(port.Stages) == 0 {
+				continue
+			}
+
+			key := fmt.Sprintf("%s|%t", port.Port, port.DevDep)
+			slowPort, ok := slowPorts[key]
+			if !ok {
+				slowPort = &SlowPort{Port: port.Port, DevDep: port.DevDep}
+				slowPorts[key] = slowPort
+			}
+
+			// Runs are ordered by time, so the last one is the latest.
+			wallSeconds := port.WallSeconds()
+			slowPort.AverageSeconds = (slowPort.AverageSeconds*float64(slowPort.Builds) + wallSeconds) / float64(slowPort.Builds+1)
+			slowPort.Builds++
+			slowPort.LatestSeconds = wallSeconds
+			slowPort.CPUSeconds = port.CPUSeconds()
+			slowPort.PeakRSS = port.PeakRSS()
+			slowPort.Slowest = slowestStage(port.Stages)
+		}
+	}
+
+	ordered := make([]SlowPort, 0, len(slowPorts))
+	for _, slowPort := range slowPorts {
+		ordered = append(ordered, *slowPort)
+	}
+	sort.SliceStable(ordered, func(a, b int) bool {
+		if ordered[a].LatestSeconds != ordered[b].LatestSeconds {
+			return ordered[a].LatestSeconds > ordered[b].LatestSeconds
+		}
+		return ordered[a].Port < ordered[b].Port
+	})
+	if limit > 0 && len(ordered) > limit {
+		ordered = ordered[:limit]
+	}
+	return ordered
+}
+
+// StatsTrend is summary of one install or deploy in stats history.
+type StatsTrend struct {
+	StartedAt   time.Time `json:"started_at"`
+	Root        string    `json:"root"`
+	Platform    string    `json:"platform"`
+	Built       int       `json:"built"` // Ports built from source.
+	WallSeconds float64   `json:"wall_seconds"`
+	CacheHits   int       `json:"cache_hits"`
+	CacheMisses int       `json:"cache_misses"`
+}
+
+// StatsTrends returns summary of the latest runs, at most limit runs.
+func StatsTrends(runs []InstallStatsRun, limit int) []StatsTrend {
+	if limit > 0 && len(runs) > limit {
+		runs = runs[len(runs)-limit:]
+	}
+
+	trends := make([]StatsTrend, 0, len(runs))
+	for _, run := range runs {
+		trend := StatsTrend{
+			StartedAt:   run.StartedAt,
+			Root:        run.Root,
+			Platform:    run.Platform,
+			WallSeconds: run.WallSeconds,
+		}
+		trend.CacheHits, trend.CacheMisses = run.CacheCounts()
+		for _, port := range run.Ports {
+			if port.InstalledFrom == "source" {
+				trend.Built++
+			}
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// CacheHitRatio returns hits, misses and hit ratio of artifact pkgcache in all runs.
+func CacheHitRatio(runs []InstallStatsRun) (hits, misses int, ratio float64) {
+	for _, run := range runs {
+		runHits, runMisses := run.CacheCounts()
+		hits += runHits
+		misses += runMisses
+	}
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	return hits, misses, ratio
+}
+
+func slowestStage(stages []buildsystems.StageStats) string {
+	var slowest buildsystems.StageStats
+	for _, stage := range stages {
+		if stage.WallSeconds > slowest.WallSeconds {
+			slowest = stage
+		}
+	}
+	return slowest.Stage
+}
+
+// dirSize returns total size of regular files under dir, zero if it doesn't exist.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// FormatSeconds formats seconds like "1h2m3s", "2m3s" or "1.5s".
+func FormatSeconds(seconds float64) string {
+	duration := time.Duration(seconds * float64(time.Second))
+	if duration >= time.Minute {
+		return duration.Round(time.Second).String()
+	}
+	return duration.Round(100 * time.Millisecond).String()
+}
+
+// FormatBytes formats bytes like "12.3 MiB".
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		value /= unit
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return ""
+}
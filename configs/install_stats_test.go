@@ -0,0 +1,128 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/buildsystems"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+func TestInstallStats_Report(t *testing.T) {
+	celer := &Celer{configData: configData{Main: Main{BuildType: "release"}}}
+	celer.platform.Name = "x86_64-linux-ubuntu-22.04-gcc-11.5"
+	celer.project.Name = "test_project"
+
+	originalInstalledDir := dirs.InstalledDir
+	dirs.InstalledDir = t.TempDir()
+	defer func() { dirs.InstalledDir = originalInstalledDir }()
+
+	root := Port{
+		Name:          "app",
+		Version:       "1.0.0",
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+		ctx:           celer,
+	}
+	zlib := Port{
+		Name:          "zlib",
+		Version:       "1.3.1",
+		Parent:        "app@1.0.0",
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+
+	report := newInstallReport(root.NameVersion())
+	report.format = OutputFormatText
+	report.recordCache(&zlib, CacheHit, 2048)
+	report.recordCache(&root, CacheMiss, 0)
+	report.recordStages(&root, []buildsystems.StageStats{
+		{Stage: buildsystems.StageConfigure, WallSeconds: 2, CPUSeconds: 1, PeakRSS: 1024},
+		{Stage: buildsystems.StageBuild, WallSeconds: 10, CPUSeconds: 40, PeakRSS: 4096},
+	})
+	report.recordCacheStored(&root, 3072)
+	report.add(&zlib, "cache")
+	report.add(&root, "source")
+
+	reportPath, err := report.write(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markdown, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"- Cache hit/miss: `1/1` (`50.0%`)",
+		"## Build statistics",
+		"| `app@1.0.0` | source | miss (stored `3.0 KiB`) | `12s` | `41s` | `4.0 KiB` |",
+		"| `zlib@1.3.1` | cache | hit (`2.0 KiB`) |",
+	} {
+		if !strings.Contains(string(markdown), expected) {
+			t.Errorf("report should contain %q, but got:\n%s", expected, markdown)
+		}
+	}
+
+	// Stats are appended into history, and summarized across runs.
+	if _, err := report.write(&root); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := LoadInstallStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || len(runs[0].Ports) != 2 || runs[0].Platform != celer.platform.Name {
+		t.Fatalf("expected 2 runs with 2 ports, but got %+v", runs)
+	}
+
+	slowPorts := SlowestPorts(runs, 10)
+	if len(slowPorts) != 1 || slowPorts[0].Port != "app@1.0.0" || slowPorts[0].Builds != 2 ||
+		slowPorts[0].LatestSeconds != 12 || slowPorts[0].Slowest != buildsystems.StageBuild {
+		t.Fatalf("unexpected slowest ports: %+v", slowPorts)
+	}
+
+	hits, misses, ratio := CacheHitRatio(runs)
+	if hits != 2 || misses != 2 || ratio != 0.5 {
+		t.Fatalf("expected 2 hits and 2 misses, but got %d, %d, %f", hits, misses, ratio)
+	}
+
+	trends := StatsTrends(runs, 1)
+	if len(trends) != 1 || trends[0].Built != 1 || trends[0].CacheHits != 1 {
+		t.Fatalf("unexpected trends: %+v", trends)
+	}
+}
+
+func TestInstallStats_WorkerStats(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "result")
+	port := Port{
+		Name:          "zlib",
+		Version:       "1.3.1",
+		MatchedConfig: &buildsystems.BuildConfig{BuildSystem: "cmake"},
+	}
+
+	// Missing stats is allowed, since worker may be an older celer.
+	stats, err := readWorkerStats(resultFile)
+	if err != nil || len(stats.Stages) != 0 {
+		t.Fatalf("missing stats should be empty, but got %+v, %v", stats, err)
+	}
+
+	worker := newInstallReport(port.NameVersion())
+	worker.recordCache(&port, CacheMiss, 0)
+	worker.recordStages(&port, []buildsystems.StageStats{{Stage: buildsystems.StageBuild, WallSeconds: 3}})
+	if err := worker.writeWorkerStats(&port, resultFile); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = readWorkerStats(resultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := newInstallReport(port.NameVersion())
+	scheduler.recordWorkerStats(&port, stats)
+	scheduler.add(&port, "source")
+
+	entry := scheduler.orderedEntries()[0]
+	if entry.Stats.Cache != CacheMiss || entry.Stats.WallSeconds() != 3 {
+		t.Fatalf("worker stats should be merged, but got %+v", entry.Stats)
+	}
+}
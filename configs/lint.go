@@ -0,0 +1,986 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/celer-pkg/celer/buildsystems"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/version"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// buildSystemFields are fields that only work with some build systems,
+// their system-specific variants like "cmake_generator_windows" are included.
+var buildSystemFields = map[string][]string{
+	"url":                 {"prebuilt"},
+	"cmake_generator":     {"cmake"},
+	"apply_envs":          {"cmake"},
+	"autogen_options":     {"makefiles"},
+	"build_shared_option": {"makefiles"},
+	"build_static_option": {"makefiles"},
+}
+
+// featureNameRegex limits feature names, since they're listed in `name@version[a,b]`.
+var featureNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// LintDiagnostic is a problem found in port, platform or project TOML file.
+type LintDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats diagnostic like "ports/z/zlib/1.3.1/port.toml:5: error: message",
+// which is recognized by editors and CI.
+func (d LintDiagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// Linter checks port, platform and project TOML files without initializing them,
+// so that broken files can be checked as well.
+type Linter struct {
+	systemNames      []string // System names of host and all platforms.
+	systemProcessors []string // System processors of host and all platforms.
+	toolchainNames   []string // Toolchain names of all platforms.
+
+	// Registries that ports of dependencies are looked up in.
+	registries context.Registries
+}
+
+// NewLinter collects system names, processors and toolchains of all platforms,
+// they're used to find build configs that never match, ports are looked up in registries.
+func NewLinter(registries context.Registries) (*Linter, error) {
+	linter := Linter{
+		registries:       registries,
+		systemNames:      []string{"linux", "windows", "darwin"},
+		systemProcessors: []string{"x86_64", "aarch64", "i386", "arm"},
+	}
+
+	platformFiles, err := LintPlatformFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, platformFile := range platformFiles {
+		var platform Platform
+		if _, err := toml.DecodeFile(platformFile, &platform); err != nil || platform.Toolchain == nil {
+			continue
+		}
+
+		linter.addKnown(&linter.systemNames, platform.Toolchain.SystemName)
+		linter.addKnown(&linter.systemProcessors, platform.Toolchain.SystemProcessor)
+		linter.addKnown(&linter.toolchainNames, platform.Toolchain.Name)
+	}
+	return &linter, nil
+}
+
+func (l *Linter) addKnown(values *[]string, value string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value != "" && !slices.Contains(*values, value) {
+		*values = append(*values, value)
+	}
+}
+
+// LintPort checks port.toml.
+func (l Linter) LintPort(portFile string) ([]LintDiagnostic, error) {
+	file, err := openLintFile(portFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var port Port
+	if !file.decode(&port) {
+		return file.diagnostics, nil
+	}
+
+	// Required fields, same as Port.validate.
+	if port.Package.Url == "" {
+		file.report(file.keyLine("package", -1, "url"), LintError, "package.url is empty")
+	} else if port.Package.Checksum == "" && isArchiveUrl(port.Package.Url) {
+		file.report(file.keyLine("package", -1, "url"), LintWarning,
+			"package.checksum is missing for archive url, it's required for verification and caching")
+	}
+	if port.Package.Ref == "" {
+		file.report(file.keyLine("package", -1, "ref"), LintError, "package.ref is empty")
+	}
+
+	for index, config := range port.BuildConfigs {
+		l.lintBuildConfig(file, port, index, config)
+	}
+	l.lintDuplicatedConfigs(file, port.BuildConfigs)
+	for _, name := range port.FeatureNames() {
+		l.lintFeature(file, name, port.Features[name])
+	}
+
+	return file.sortedDiagnostics(), nil
+}
+
+func (l Linter) lintBuildConfig(file *lintFile, port Port, index int, config buildsystems.BuildConfig) {
+	headerLine := file.keyLine("build_configs", index, "")
+	name := fmt.Sprintf("build_configs[%d]", index)
+	keys := file.tableKeys("build_configs", index)
+
+	// Validate config for every system it may match, since system-specific fields
+	// like build_system_windows override the common one.
+	systems := l.matchedSystems(config)
+	var validateErrors []string
+	for _, system := range expr.If(len(systems) == 0, []string{""}, systems) {
+		merged := lintMergedConfig(config, system)
+		merged.PortConfig.PortFile = file.relative
+		if err := merged.Validate(); err != nil && !slices.Contains(validateErrors, err.Error()) {
+			validateErrors = append(validateErrors, err.Error())
+			file.report(file.keyLineOr("build_configs", index, "build_system", headerLine), LintError, "%s: %s", name, err)
+		}
+	}
+
+	// Never matching configs.
+	for _, systemName := range lintSystemNames(config) {
+		if !slices.Contains(l.systemNames, systemName) {
+			file.report(file.keyLineOr("build_configs", index, expr.If(len(config.SystemNames) > 0, "system_names", "system_name"), headerLine),
+				LintWarning, "%s: system_name %q doesn't match host or any platform", name, systemName)
+		}
+	}
+	if len(lintSystemNames(config)) > 0 && len(systems) == 0 {
+		file.report(headerLine, LintWarning, "%s never matches any platform", name)
+	}
+	if processor := strings.ToLower(strings.TrimSpace(config.SystemProcessor)); processor != "" && !slices.Contains(l.systemProcessors, processor) {
+		file.report(file.keyLineOr("build_configs", index, "system_processor", headerLine), LintWarning,
+			"%s: system_processor %q doesn't match host or any platform", name, processor)
+	}
+	if toolchainName := strings.ToLower(strings.TrimSpace(config.ToolchainName)); toolchainName != "" &&
+		len(l.toolchainNames) > 0 && !slices.Contains(l.toolchainNames, toolchainName) {
+		file.report(file.keyLineOr("build_configs", index, "toolchain_name", headerLine), LintWarning,
+			"%s: toolchain_name %q doesn't match any platform", name, toolchainName)
+	}
+
+	// Fields used with wrong build system.
+	buildSystems := lintBuildSystems(config)
+	for _, key := range keys {
+		base := lintBaseKey(key)
+		allowed, ok := buildSystemFields[base]
+		if !ok || len(buildSystems) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(buildSystems, func(buildSystem string) bool {
+			return slices.Contains(allowed, buildSystem)
+		}) {
+			file.report(file.keyLine("build_configs", index, key), LintWarning,
+				"%s: %s only works with build_system %s, but it's %s",
+				name, key, strings.Join(allowed, ", "), strings.Join(buildSystems, ", "))
+		}
+	}
+
+	// System-specific fields for systems that never match this config.
+	for _, key := range keys {
+		suffix := lintSystemSuffix(key)
+		if suffix == "" || len(lintSystemNames(config)) == 0 && config.SystemNameExcept == "" {
+			continue
+		}
+		if !slices.Contains(systems, suffix) {
+			file.report(file.keyLine("build_configs", index, key), LintWarning,
+				"%s: %s is never applied, since %s doesn't match %s", name, key, name, suffix)
+		}
+	}
+
+	// Archive url of prebuilt config requires checksum as well.
+	if config.Url != "" && port.Package.Checksum == "" && isArchiveUrl(config.Url) {
+		file.report(file.keyLine("build_configs", index, "url"), LintWarning,
+			"%s: package.checksum is missing for archive url, it's required for verification and caching", name)
+	}
+
+	// Patches must exist in port dir.
+	portDir := filepath.Dir(file.path)
+	for _, key := range []string{"patches", "patches_windows", "patches_linux", "patches_darwin"} {
+		for _, patch := range lintStringsOf(config, key) {
+			patch = strings.TrimSpace(patch)
+			if patch == "" || strings.Contains(patch, "${") {
+				continue
+			}
+			if !fileio.PathExists(filepath.Join(portDir, patch)) {
+				file.report(file.keyLineOr("build_configs", index, key, headerLine), LintError,
+					"%s: patch %s doesn't exist in port dir", name, patch)
+			}
+		}
+	}
+
+	// Features selected for dependencies must be defined.
+	for _, key := range []string{
+		"dependencies", "dependencies_windows", "dependencies_linux", "dependencies_darwin",
+		"dev_dependencies", "dev_dependencies_windows", "dev_dependencies_linux", "dev_dependencies_darwin",
+	} {
+		l.lintDependencyFeatures(file, file.keyLineOr("build_configs", index, key, headerLine), name, lintStringsOf(config, key))
+	}
+}
+
+// lintFeature checks [features.<name>] of port.
+func (l Linter) lintFeature(file *lintFile, name string, feature PortFeature) {
+	table := "features." + name
+	headerLine := file.keyLine(table, -1, "")
+	if !featureNameRegex.MatchString(name) {
+		file.report(headerLine, LintError, "feature name %q should only contain letters, digits, '_' and '-'", name)
+	}
+	if len(feature.Options)+len(feature.Dependencies)+len(feature.DevDependencies)+len(feature.Patches) == 0 {
+		file.report(headerLine, LintWarning, "%s changes nothing when it's selected", table)
+	}
+
+	// Patches must exist in port dir.
+	portDir := filepath.Dir(file.path)
+	for _, patch := range feature.Patches {
+		patch = strings.TrimSpace(patch)
+		if patch == "" || strings.Contains(patch, "${") {
+			continue
+		}
+		if !fileio.PathExists(filepath.Join(portDir, patch)) {
+			file.report(file.keyLineOr(table, -1, "patches", headerLine), LintError,
+				"%s: patch %s doesn't exist in port dir", table, patch)
+		}
+	}
+
+	l.lintDependencyFeatures(file, file.keyLineOr(table, -1, "dependencies", headerLine), table, feature.Dependencies)
+	l.lintDependencyFeatures(file, file.keyLineOr(table, -1, "dev_dependencies", headerLine), table, feature.DevDependencies)
+}
+
+// lintDependencyFeatures reports features that are not defined by the selected ports,
+// ports with version constraints or not found are skipped.
+func (l Linter) lintDependencyFeatures(file *lintFile, line int, owner string, nameVersions []string) {
+	for _, nameVersion := range nameVersions {
+		nameVersion, features := SplitFeatures(nameVersion)
+		name, spec, ok := strings.Cut(nameVersion, "@")
+		if len(features) == 0 || !ok || version.IsConstraint(spec) {
+			continue
+		}
+
+		defined, found := l.lintPortFeatures(file.projectName(), name, spec)
+		if !found {
+			continue
+		}
+		for _, feature := range features {
+			if _, ok := defined[feature]; !ok {
+				file.report(line, LintError, "%s: feature %s is not defined in %s", owner, feature, nameVersion)
+			}
+		}
+	}
+}
+
+// lintPortFeatures returns features of port, the port of project is preferred.
+func (l Linter) lintPortFeatures(projectName, name, version string) (map[string]PortFeature, bool) {
+	var portFiles []string
+	if projectName != "" {
+		portFiles = append(portFiles,
+			filepath.Join(dirs.ConfProjectsDir, projectName, name, version, "port.toml"),
+			filepath.Join(dirs.ConfProjectsDir, projectName, "ports", name, version, "port.toml"),
+		)
+	}
+	portFiles = append(portFiles, l.registries.FindPortPath(name, version))
+
+	for _, portFile := range portFiles {
+		if !fileio.PathExists(portFile) {
+			continue
+		}
+		var port Port
+		if _, err := toml.DecodeFile(portFile, &port); err != nil {
+			return nil, false
+		}
+		return port.Features, true
+	}
+	return nil, false
+}
+
+// lintDuplicatedConfigs finds build configs with the same selectors,
+// install fails with them since more than one config matches.
+func (l Linter) lintDuplicatedConfigs(file *lintFile, configs []buildsystems.BuildConfig) {
+	selectors := make(map[string]int)
+	for index, config := range configs {
+		names := lintSystemNames(config)
+		sort.Strings(names)
+		selector := strings.Join([]string{
+			strings.Join(names, ","),
+			strings.ToLower(strings.TrimSpace(config.SystemNameExcept)),
+			strings.ToLower(strings.TrimSpace(config.SystemProcessor)),
+			strings.ToLower(strings.TrimSpace(config.ToolchainName)),
+			strings.TrimSpace(config.ToolchainVersion),
+		}, "|")
+
+		if previous, ok := selectors[selector]; ok {
+			file.report(file.keyLine("build_configs", index, ""), LintError,
+				"build_configs[%d] matches the same platforms as build_configs[%d]", index, previous)
+			continue
+		}
+		selectors[selector] = index
+	}
+}
+
+// matchedSystems returns known system names that config may match.
+func (l Linter) matchedSystems(config buildsystems.BuildConfig) []string {
+	var systems []string
+	names := lintSystemNames(config)
+	except := strings.ToLower(strings.TrimSpace(config.SystemNameExcept))
+	for _, systemName := range l.systemNames {
+		switch {
+		case except != "":
+			if systemName != except {
+				systems = append(systems, systemName)
+			}
+		case len(names) == 0 || slices.Contains(names, systemName):
+			systems = append(systems, systemName)
+		}
+	}
+	return systems
+}
+
+// LintPlatform checks platform toml in conf/platforms.
+func (l Linter) LintPlatform(platformFile string) ([]LintDiagnostic, error) {
+	file, err := openLintFile(platformFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var platform Platform
+	if !file.decode(&platform) {
+		return file.diagnostics, nil
+	}
+
+	if toolchain := platform.Toolchain; toolchain != nil {
+		for _, field := range [][2]string{
+			{"url", toolchain.Url},
+			{"name", toolchain.Name},
+			{"system_name", toolchain.SystemName},
+			{"system_processor", toolchain.SystemProcessor},
+		} {
+			if strings.TrimSpace(field[1]) == "" {
+				file.report(file.keyLineOr("toolchain", -1, "", 1), LintError, "toolchain.%s is empty", field[0])
+			}
+		}
+		if isRemoteUrl(toolchain.Url) && toolchain.SHA256 == "" {
+			file.report(file.keyLine("toolchain", -1, "url"), LintWarning,
+				"toolchain.sha256 is missing, it's required for verification and caching")
+		}
+	}
+	if rootfs := platform.RootFS; rootfs != nil {
+		if isRemoteUrl(rootfs.Url) && rootfs.SHA256 == "" {
+			file.report(file.keyLine("rootfs", -1, "url"), LintWarning,
+				"rootfs.sha256 is missing, it's required for verification and caching")
+		}
+	}
+
+	return file.sortedDiagnostics(), nil
+}
+
+// LintProject checks project toml in conf/projects.
+func (l Linter) LintProject(projectFile string) ([]LintDiagnostic, error) {
+	file, err := openLintFile(projectFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if !file.decode(&project) {
+		return file.diagnostics, nil
+	}
+
+	projectName := strings.TrimSuffix(filepath.Base(projectFile), ".toml")
+	portsLine := file.keyLine("", -1, "ports")
+	for _, nameVersion := range project.Ports {
+		plain, _ := SplitFeatures(nameVersion)
+		name, spec, ok := strings.Cut(plain, "@")
+		if !ok || name == "" || spec == "" {
+			file.report(portsLine, LintError, "port %q should be like name@version", nameVersion)
+			continue
+		}
+
+		if version.IsConstraint(spec) {
+			if _, err := version.ParseConstraint(spec); err != nil {
+				file.report(portsLine, LintError, "port %s: %s", nameVersion, err)
+			}
+			continue
+		}
+
+		if !fileio.PathExists(l.registries.FindPortPath(name, spec)) &&
+			!fileio.PathExists(filepath.Join(dirs.ConfProjectsDir, projectName, name, spec, "port.toml")) &&
+			!fileio.PathExists(filepath.Join(dirs.ConfProjectsDir, projectName, "ports", name, spec, "port.toml")) {
+			file.report(portsLine, LintError, "port %s is not found", nameVersion)
+			continue
+		}
+		l.lintDependencyFeatures(file, portsLine, "ports", []string{nameVersion})
+	}
+
+	if project.Bundle != nil {
+		if project.Bundle.Version != "" {
+			if err := ValidateBundleVersion(project.Bundle.Version); err != nil {
+				file.report(file.keyLine("bundle", -1, "version"), LintError, "%s", err)
+			}
+		}
+		for table, rules := range map[string]BundleRules{"bundle.runtime": project.Bundle.Runtime, "bundle.sdk": project.Bundle.SDK} {
+			for key, patterns := range map[string][]string{"include": rules.Include, "exclude": rules.Exclude} {
+				for _, pattern := range patterns {
+					if err := ValidateBundlePattern(pattern); err != nil {
+						file.report(file.keyLine(table, -1, key), LintError, "%s", err)
+					}
+				}
+			}
+		}
+	}
+
+	return file.sortedDiagnostics(), nil
+}
+
+// LintPortFiles returns port.toml files of name@version, including the ones in projects.
+func LintPortFiles(registries context.Registries, nameVersion string) ([]string, error) {
+	name, version, ok := strings.Cut(nameVersion, "@")
+	if !ok || name == "" || version == "" {
+		return nil, fmt.Errorf("port name and version are invalid %s", nameVersion)
+	}
+
+	var portFiles []string
+	if portFile := registries.FindPortPath(name, version); fileio.PathExists(portFile) {
+		portFiles = append(portFiles, portFile)
+	}
+
+	projectPorts, err := lintProjectPortFiles()
+	if err != nil {
+		return nil, err
+	}
+	suffix := string(filepath.Separator) + filepath.Join(name, version, "port.toml")
+	for _, portFile := range projectPorts {
+		if strings.HasSuffix(portFile, suffix) {
+			portFiles = append(portFiles, portFile)
+		}
+	}
+
+	if len(portFiles) == 0 {
+		return nil, fmt.Errorf("port %s is not found", nameVersion)
+	}
+	return portFiles, nil
+}
+
+// LintAllPortFiles returns all port.toml files in registries, ports and projects.
+func LintAllPortFiles(registries context.Registries) ([]string, error) {
+	var portFiles []string
+	for _, registry := range registries {
+		if !fileio.PathExists(registry.Dir) {
+			continue
+		}
+		err := filepath.WalkDir(registry.Dir, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && entry.Name() == "port.toml" {
+				portFiles = append(portFiles, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	projectPorts, err := lintProjectPortFiles()
+	if err != nil {
+		return nil, err
+	}
+	return append(portFiles, projectPorts...), nil
+}
+
+// LintPlatformFiles returns all platform toml files.
+func LintPlatformFiles() ([]string, error) {
+	return lintTomlFiles(dirs.ConfPlatformsDir)
+}
+
+// LintProjectFiles returns all project toml files.
+func LintProjectFiles() ([]string, error) {
+	return lintTomlFiles(dirs.ConfProjectsDir)
+}
+
+func lintTomlFiles(dir string) ([]string, error) {
+	if !fileio.PathExists(dir) {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".toml" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func lintProjectPortFiles() ([]string, error) {
+	if !fileio.PathExists(dirs.ConfProjectsDir) {
+		return nil, nil
+	}
+
+	var portFiles []string
+	err := filepath.WalkDir(dirs.ConfProjectsDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && entry.Name() == "port.toml" {
+			portFiles = append(portFiles, path)
+		}
+		return nil
+	})
+	return portFiles, err
+}
+
+// lintFile is a TOML file being linted, with lines of keys to locate diagnostics.
+type lintFile struct {
+	path        string
+	relative    string
+	entries     []tomlEntry
+	diagnostics []LintDiagnostic
+	content     string
+}
+
+func openLintFile(path string) (*lintFile, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s -> %w", path, err)
+	}
+
+	relative := path
+	if rel, err := filepath.Rel(dirs.WorkspaceDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		relative = filepath.ToSlash(rel)
+	}
+
+	return &lintFile{
+		path:     path,
+		relative: relative,
+		content:  string(bytes),
+		entries:  scanTOML(strings.Split(string(bytes), "\n")),
+	}, nil
+}
+
+// projectName returns name of project if file is in conf/projects, otherwise empty.
+func (f *lintFile) projectName() string {
+	rel, err := filepath.Rel(dirs.ConfProjectsDir, f.path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return strings.TrimSuffix(first, ".toml")
+}
+
+// sortedDiagnostics returns diagnostics ordered by line.
+func (f *lintFile) sortedDiagnostics() []LintDiagnostic {
+	sort.SliceStable(f.diagnostics, func(a, b int) bool {
+		return f.diagnostics[a].Line < f.diagnostics[b].Line
+	})
+	return f.diagnostics
+}
+
+func (f *lintFile) report(line int, severity, format string, args ...any) {
+	f.diagnostics = append(f.diagnostics, LintDiagnostic{
+		File:     f.relative,
+		Line:     max(line, 1),
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// decode decodes file into value, and reports syntax errors and unknown keys.
+func (f *lintFile) decode(value any) bool {
+	metaData, err := toml.Decode(f.content, value)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			f.report(parseErr.Position.Line, LintError, "%s", parseErr.Message)
+		} else {
+			f.report(1, LintError, "%s", err)
+		}
+		return false
+	}
+
+	undecoded := make(map[string]toml.Key)
+	for _, key := range metaData.Undecoded() {
+		undecoded[key.String()] = key
+	}
+	for _, key := range metaData.Undecoded() {
+		// Only report the outermost unknown table.
+		if len(key) > 1 {
+			if _, ok := undecoded[toml.Key(key[:len(key)-1]).String()]; ok {
+				continue
+			}
+		}
+
+		message := fmt.Sprintf("unknown key %q", key.String())
+		if suggestion := suggestKey(reflect.TypeOf(value), key); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+
+		lines := f.keyLines(key)
+		if len(lines) == 0 {
+			lines = []int{1}
+		}
+		for _, line := range lines {
+			if !slices.ContainsFunc(f.diagnostics, func(diagnostic LintDiagnostic) bool {
+				return diagnostic.Line == line && diagnostic.Message == message
+			}) {
+				f.report(line, LintError, "%s", message)
+			}
+		}
+	}
+	return true
+}
+
+// keyLine returns line of key in table, index is the index of array table or -1,
+// and empty key means the table header. Zero is returned if it's not found.
+func (f *lintFile) keyLine(table string, index int, key string) int {
+	for _, entry := range f.entries {
+		if entry.table == table && (index < 0 || entry.index == index) && entry.key == key {
+			return entry.line
+		}
+	}
+	return 0
+}
+
+func (f *lintFile) keyLineOr(table string, index int, key string, fallback int) int {
+	if line := f.keyLine(table, index, key); line > 0 {
+		return line
+	}
+	return fallback
+}
+
+// tableKeys returns keys defined in table.
+func (f *lintFile) tableKeys(table string, index int) []string {
+	var keys []string
+	for _, entry := range f.entries {
+		if entry.table == table && entry.index == index && entry.key != "" {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
+}
+
+// keyLines returns lines where key is defined, a key may be defined in every array table.
+func (f *lintFile) keyLines(key toml.Key) []int {
+	var lines []int
+	for _, entry := range f.entries {
+		var path []string
+		if entry.table != "" {
+			path = strings.Split(entry.table, ".")
+		}
+		if entry.key != "" {
+			path = append(path, strings.Split(entry.key, ".")...)
+		}
+		if slices.Equal(path, key) {
+			lines = append(lines, entry.line)
+		}
+	}
+	return lines
+}
+
+// tomlEntry is a table header or a key found by scanTOML.
+type tomlEntry struct {
+	table string // Dotted path of table, empty for root table.
+	index int    // Index of array table, -1 for normal table.
+	key   string // Key in table, empty for table header.
+	line  int
+}
+
+// scanTOML finds table headers and keys line by line, it's only used to locate
+// diagnostics, since BurntSushi/toml doesn't expose positions of keys.
+func scanTOML(lines []string) []tomlEntry {
+	var (
+		entries      []tomlEntry
+		table        string
+		index        = -1
+		arrayIndexes = make(map[string]int)
+		depth        int  // Depth of multi-line arrays and inline tables.
+		multiline    bool // In multi-line string.
+	)
+
+	for number, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip content of multi-line values.
+		if multiline {
+			if strings.Count(trimmed, `"""`)%2 == 1 || strings.Count(trimmed, `'''`)%2 == 1 {
+				multiline = false
+			}
+			continue
+		}
+		if depth > 0 {
+			depth += bracketDepth(trimmed)
+			continue
+		}
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+
+		case strings.HasPrefix(trimmed, "[["):
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				continue
+			}
+			table = normalizeTomlKey(trimmed[2:end])
+			index = arrayIndexes[table]
+			arrayIndexes[table]++
+			entries = append(entries, tomlEntry{table: table, index: index, line: number + 1})
+
+		case strings.HasPrefix(trimmed, "["):
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			table = normalizeTomlKey(trimmed[1:end])
+			index = -1
+			entries = append(entries, tomlEntry{table: table, index: index, line: number + 1})
+
+		default:
+			key, value, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				continue
+			}
+			entries = append(entries, tomlEntry{table: table, index: index, key: normalizeTomlKey(key), line: number + 1})
+
+			value = strings.TrimSpace(value)
+			if strings.Count(value, `"""`)%2 == 1 || strings.Count(value, `'''`)%2 == 1 {
+				multiline = true
+			} else {
+				depth = bracketDepth(value)
+			}
+		}
+	}
+
+	return entries
+}
+
+// normalizeTomlKey removes spaces and quotes of dotted key, like `a . "b"` to `a.b`.
+func normalizeTomlKey(key string) string {
+	var parts []string
+	for part := range strings.SplitSeq(key, ".") {
+		parts = append(parts, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return strings.Join(parts, ".")
+}
+
+// bracketDepth counts unclosed brackets and braces out of strings and comments.
+func bracketDepth(value string) int {
+	var (
+		depth int
+		quote rune
+	)
+	for _, char := range value {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			quote = char
+		case char == '#':
+			return depth
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// suggestKey suggests the most similar known key for unknown key.
+func suggestKey(root reflect.Type, key toml.Key) string {
+	fieldType := root
+	for _, part := range key[:len(key)-1] {
+		// Names of tables like [features.<name>] are defined by users.
+		if fieldType.Kind() == reflect.Map {
+			fieldType = tomlElem(fieldType.Elem())
+			continue
+		}
+		fieldType = tomlFieldType(fieldType, part)
+		if fieldType == nil {
+			return ""
+		}
+	}
+
+	unknown := key[len(key)-1]
+	var (
+		suggestion string
+		distance   = max(len(unknown)/3, 2) + 1
+	)
+	for _, name := range tomlFieldNames(fieldType) {
+		if current := levenshtein(unknown, name); current < distance {
+			suggestion, distance = name, current
+		}
+	}
+	return suggestion
+}
+
+// tomlFieldType returns type of field named by toml tag, elements of slices and pointers are returned.
+func tomlFieldType(structType reflect.Type, name string) reflect.Type {
+	structType = tomlElem(structType)
+	if structType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for index := range structType.NumField() {
+		field := structType.Field(index)
+		if field.Anonymous && field.Tag.Get("toml") == "" {
+			if found := tomlFieldType(field.Type, name); found != nil {
+				return found
+			}
+			continue
+		}
+		if tagName, _, _ := strings.Cut(field.Tag.Get("toml"), ","); tagName == name {
+			return tomlElem(field.Type)
+		}
+	}
+	return nil
+}
+
+// tomlFieldNames returns toml names of fields in struct.
+func tomlFieldNames(structType reflect.Type) []string {
+	structType = tomlElem(structType)
+	if structType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for index := range structType.NumField() {
+		field := structType.Field(index)
+		tag := field.Tag.Get("toml")
+		if field.Anonymous && tag == "" {
+			names = append(names, tomlFieldNames(field.Type)...)
+			continue
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func tomlElem(fieldType reflect.Type) reflect.Type {
+	for fieldType.Kind() == reflect.Pointer || fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType
+}
+
+func levenshtein(left, right string) int {
+	previous := make([]int, len(right)+1)
+	for index := range previous {
+		previous[index] = index
+	}
+
+	for i := 1; i <= len(left); i++ {
+		current := make([]int, len(right)+1)
+		current[0] = i
+		for j := 1; j <= len(right); j++ {
+			cost := 1
+			if left[i-1] == right[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(right)]
+}
+
+// lintMergedConfig merges fields validated by BuildConfig.Validate for system,
+// like buildsystems does before building.
+func lintMergedConfig(config buildsystems.BuildConfig, system string) buildsystems.BuildConfig {
+	pick := func(base, windows, linux, darwin string) string {
+		value := map[string]string{"windows": windows, "linux": linux, "darwin": darwin}[system]
+		return expr.If(value != "", value, base)
+	}
+	config.BuildSystem = pick(config.BuildSystem, config.BuildSystem_Windows, config.BuildSystem_Linux, config.BuildSystem_Darwin)
+	config.CStandard = pick(config.CStandard, config.CStandard_Windows, config.CStandard_Linux, config.CStandard_Darwin)
+	config.CXXStandard = pick(config.CXXStandard, config.CXXStandard_Windows, config.CXXStandard_Linux, config.CXXStandard_Darwin)
+	return config
+}
+
+// lintSystemNames returns lowercase system names config is restricted to.
+func lintSystemNames(config buildsystems.BuildConfig) []string {
+	names := config.SystemNames
+	if len(names) == 0 && config.SystemName != "" {
+		names = []string{config.SystemName}
+	}
+
+	var systemNames []string
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			systemNames = append(systemNames, name)
+		}
+	}
+	return systemNames
+}
+
+// lintBuildSystems returns names of build systems config may use, without versions.
+func lintBuildSystems(config buildsystems.BuildConfig) []string {
+	var names []string
+	for _, buildSystem := range []string{config.BuildSystem, config.BuildSystem_Windows, config.BuildSystem_Linux, config.BuildSystem_Darwin} {
+		name, _, _ := strings.Cut(strings.TrimSpace(buildSystem), "@")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lintStringsOf returns value of string list field by toml name.
+func lintStringsOf(config buildsystems.BuildConfig, key string) []string {
+	value := reflect.ValueOf(config)
+	for index := range value.NumField() {
+		tagName, _, _ := strings.Cut(value.Type().Field(index).Tag.Get("toml"), ",")
+		if tagName == key {
+			if values, ok := value.Field(index).Interface().([]string); ok {
+				return values
+			}
+		}
+	}
+	return nil
+}
+
+// lintSystemSuffix returns system of key like "options_windows", empty if it's not system-specific.
+func lintSystemSuffix(key string) string {
+	for _, system := range []string{"windows", "linux", "darwin"} {
+		if strings.HasSuffix(key, "_"+system) {
+			return system
+		}
+	}
+	return ""
+}
+
+// lintBaseKey removes system suffix of key.
+func lintBaseKey(key string) string {
+	if suffix := lintSystemSuffix(key); suffix != "" {
+		return strings.TrimSuffix(key, "_"+suffix)
+	}
+	return key
+}
+
+// isArchiveUrl tells whether url is an archive to download, instead of git repo or virtual port.
+func isArchiveUrl(url string) bool {
+	return url != "_" && !strings.HasSuffix(url, ".git") && !strings.HasPrefix(url, "file:///")
+}
+
+func isRemoteUrl(url string) bool {
+	return strings.HasPrefix(url, "http") || strings.HasPrefix(url, "ftp")
+}
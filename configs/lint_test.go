@@ -0,0 +1,212 @@
+package configs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+func lintMessages(diagnostics []LintDiagnostic) string {
+	var lines []string
+	for _, diagnostic := range diagnostics {
+		lines = append(lines, diagnostic.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestLinter_LintPort(t *testing.T) {
+	setupTestWorkspace(t, map[string]string{
+		"conf/platforms/aarch64-linux-gcc-11.5.toml": `[toolchain]
+url = "https://example.com/gcc.tar.gz"
+sha256 = "abc"
+name = "gcc"
+system_name = "Linux"
+system_processor = "aarch64"
+`,
+		"ports/z/zlib/1.3.1/exist.patch": "",
+		"ports/z/zlib/1.3.1/port.toml": `[package]
+url = "https://example.com/zlib-1.3.1.tar.gz"
+ref = "1.3.1"
+
+[[build_configs]]
+system_name = "linux"
+build_sytem = "cmake"
+dependences = ["x264@stable"]
+patches = ["exist.patch", "missing.patch"]
+options = [
+    "-DFOO=ON",
+    "build_system = nothing",
+]
+
+[[build_configs]]
+system_name = "qnx"
+build_system = "makefiles"
+cmake_generator = "Ninja"
+options_windows = ["--enable-foo"]
+
+[[build_configs]]
+system_names = ["linux"]
+build_system = "cmake"
+`,
+	})
+
+	linter, err := NewLinter(NewCeler().Registries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := linter.LintPort(dirs.GetPortPath("zlib", "1.3.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := lintMessages(diagnostics)
+	for _, expected := range []string{
+		`ports/z/zlib/1.3.1/port.toml:2: warning: package.checksum is missing for archive url`,
+		`ports/z/zlib/1.3.1/port.toml:7: error: unknown key "build_configs.build_sytem", did you mean "build_system"?`,
+		`ports/z/zlib/1.3.1/port.toml:8: error: unknown key "build_configs.dependences", did you mean "dependencies"?`,
+		`ports/z/zlib/1.3.1/port.toml:5: error: build_configs[0]: build_system is empty`,
+		`ports/z/zlib/1.3.1/port.toml:9: error: build_configs[0]: patch missing.patch doesn't exist in port dir`,
+		`ports/z/zlib/1.3.1/port.toml:16: warning: build_configs[1]: system_name "qnx" doesn't match host or any platform`,
+		`ports/z/zlib/1.3.1/port.toml:15: warning: build_configs[1] never matches any platform`,
+		`ports/z/zlib/1.3.1/port.toml:18: warning: build_configs[1]: cmake_generator only works with build_system cmake, but it's makefiles`,
+		`ports/z/zlib/1.3.1/port.toml:19: warning: build_configs[1]: options_windows is never applied`,
+		`ports/z/zlib/1.3.1/port.toml:21: error: build_configs[2] matches the same platforms as build_configs[0]`,
+	} {
+		if !strings.Contains(messages, expected) {
+			t.Errorf("diagnostics should contain %q, but got:\n%s", expected, messages)
+		}
+	}
+	if strings.Contains(messages, "patch exist.patch") || strings.Count(messages, "build_sytem") != 1 {
+		t.Errorf("unexpected diagnostics:\n%s", messages)
+	}
+}
+
+func TestLinter_LintPlatformAndProject(t *testing.T) {
+	setupTestWorkspace(t, map[string]string{
+		"conf/platforms/x86_64-linux.toml": `[toolchain]
+url = "https://example.com/gcc.tar.gz"
+name = "gcc"
+system_name = "linux"
+system_processor = "x86_64"
+crosstool_prefx = "x86_64-linux-gnu-"
+`,
+		"conf/projects/test_project.toml": `build_type = "Release"
+ports = ["zlib@1.3.1", "libpng@1.6.43", "x264"]
+marcos = ["FOO"]
+
+[bundle]
+version = "v1.0"
+maintainer = "celer <celer@localhost>"
+
+[bundle.runtime]
+include = ["include/zlib.h"]
+exclude = ["share/[doc/**"]
+`,
+		"ports/z/zlib/1.3.1/port.toml": "",
+		"conf/projects/broken.toml":    "ports = [\n",
+	})
+
+	linter, err := NewLinter(NewCeler().Registries())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics, err := linter.LintPlatform(filepath.Join(dirs.ConfPlatformsDir, "x86_64-linux.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := lintMessages(diagnostics)
+	for _, expected := range []string{
+		`conf/platforms/x86_64-linux.toml:6: error: unknown key "toolchain.crosstool_prefx", did you mean "crosstool_prefix"?`,
+		`conf/platforms/x86_64-linux.toml:2: warning: toolchain.sha256 is missing`,
+	} {
+		if !strings.Contains(messages, expected) {
+			t.Errorf("diagnostics should contain %q, but got:\n%s", expected, messages)
+		}
+	}
+
+	diagnostics, err = linter.LintProject(filepath.Join(dirs.ConfProjectsDir, "test_project.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages = lintMessages(diagnostics)
+	for _, expected := range []string{
+		`conf/projects/test_project.toml:3: error: unknown key "marcos", did you mean "macros"?`,
+		`conf/projects/test_project.toml:2: error: port libpng@1.6.43 is not found`,
+		`conf/projects/test_project.toml:2: error: port "x264" should be like name@version`,
+		`conf/projects/test_project.toml:6: error: bundle version "v1.0" is invalid`,
+		`conf/projects/test_project.toml:11: error: bundle pattern "share/[doc/**" is invalid`,
+	} {
+		if !strings.Contains(messages, expected) {
+			t.Errorf("diagnostics should contain %q, but got:\n%s", expected, messages)
+		}
+	}
+	if strings.Contains(messages, "zlib") {
+		t.Errorf("zlib@1.3.1 exists, but got:\n%s", messages)
+	}
+
+	diagnostics, err = linter.LintProject(filepath.Join(dirs.ConfProjectsDir, "broken.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Severity != LintError || diagnostics[0].Line != 1 {
+		t.Errorf("syntax error should be reported, but got:\n%s", lintMessages(diagnostics))
+	}
+}
+
+func TestLinter_LintFeatures(t *testing.T) {
+	setupTestWorkspace(t, map[string]string{
+		"ports/o/opencv/4.8.0/port.toml": `[package]
+url = "https://example.com/opencv.git"
+ref = "4.8.0"
+
+[[build_configs]]
+build_system = "cmake"
+dependencies = ["zlib@1.3.1[simd]"]
+
+[features.contrib]
+optoins = ["-DWITH_CONTRIB=ON"]
+patches = ["contrib.patch"]
+
+[features."cuda,gpu"]
+options = ["-DWITH_CUDA=ON"]
+`,
+		"ports/z/zlib/1.3.1/port.toml": `[package]
+url = "https://example.com/zlib.git"
+ref = "1.3.1"
+`,
+		"conf/projects/test_project.toml": `ports = ["opencv@4.8.0[contrib,java]"]
+`,
+	})
+
+	linter, err := NewLinter(NewCeler().Registries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := linter.LintPort(dirs.GetPortPath("opencv", "4.8.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := lintMessages(diagnostics)
+	for _, expected := range []string{
+		`ports/o/opencv/4.8.0/port.toml:7: error: build_configs[0]: feature simd is not defined in zlib@1.3.1`,
+		`ports/o/opencv/4.8.0/port.toml:10: error: unknown key "features.contrib.optoins", did you mean "options"?`,
+		`ports/o/opencv/4.8.0/port.toml:11: error: features.contrib: patch contrib.patch doesn't exist in port dir`,
+		`ports/o/opencv/4.8.0/port.toml:13: error: feature name "cuda,gpu" should only contain letters, digits, '_' and '-'`,
+	} {
+		if !strings.Contains(messages, expected) {
+			t.Errorf("diagnostics should contain %q, but got:\n%s", expected, messages)
+		}
+	}
+
+	diagnostics, err = linter.LintProject(filepath.Join(dirs.ConfProjectsDir, "test_project.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages = lintMessages(diagnostics)
+	if expected := `conf/projects/test_project.toml:1: error: ports: feature java is not defined in opencv@4.8.0`; messages != expected {
+		t.Errorf("diagnostics should be %q, but got:\n%s", expected, messages)
+	}
+}
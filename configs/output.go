@@ -0,0 +1,132 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OutputSchemaVersion is the version of JSON documents printed or written by celer.
+// It's bumped only when existing fields are renamed, removed or change meaning,
+// adding new fields keeps the version unchanged.
+const OutputSchemaVersion = 1
+
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
+// ValidateOutputFormat checks value of `--format`.
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputFormatText, OutputFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, available formats: text, json", format)
+	}
+}
+
+// OutputHeader leads every JSON document, consumers should check
+// `schema_version` before reading the rest fields.
+type OutputHeader struct {
+	SchemaVersion int    `json:"schema_version"`
+	Kind          string `json:"kind"`
+}
+
+func NewOutputHeader(kind string) OutputHeader {
+	return OutputHeader{
+		SchemaVersion: OutputSchemaVersion,
+		Kind:          kind,
+	}
+}
+
+// DependencyNode describes a port in dependency graph.
+type DependencyNode struct {
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	Parent        string            `json:"parent,omitempty"`
+	DevDep        bool              `json:"dev_dep"`
+	HostDep       bool              `json:"host_dep"`
+	BuildSystem   string            `json:"build_system,omitempty"`
+	Features      []string          `json:"features,omitempty"`
+	InstalledFrom string            `json:"installed_from,omitempty"`
+	BuildHash     string            `json:"build_hash,omitempty"`
+	Dependencies  []*DependencyNode `json:"dependencies,omitempty"`
+}
+
+// NewDependencyNode splits nameVersion into name and version.
+func NewDependencyNode(nameVersion string) *DependencyNode {
+	name, version, _ := strings.Cut(nameVersion, "@")
+	return &DependencyNode{
+		Name:    name,
+		Version: version,
+	}
+}
+
+// WriteJSON writes value as indented JSON.
+func WriteJSON(writer io.Writer, value any) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode json -> %w", err)
+	}
+	return nil
+}
+
+// PrintJSON prints value as indented JSON to stdout.
+func PrintJSON(value any) error {
+	return WriteJSON(os.Stdout, value)
+}
+
+// InstalledInfo returns where port was installed from and its build hash,
+// both are empty when it's not installed.
+func (p Port) InstalledInfo() (string, string) {
+	if installed, err := p.Installed(); err != nil || !installed {
+		return "", ""
+	}
+	return p.recordedInstalledFrom(), p.installedBuildHash()
+}
+
+// installedFromFile records where port was installed from, it's beside the trace file.
+func (p Port) installedFromFile() string {
+	return strings.TrimSuffix(p.traceFile, ".trace") + ".from"
+}
+
+// writeInstalledFrom records where port was installed from, like "source" or "pkgcache".
+func (p Port) writeInstalledFrom(installedFrom string) error {
+	if err := os.MkdirAll(filepath.Dir(p.installedFromFile()), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create trace dir -> %w", err)
+	}
+	if err := os.WriteFile(p.installedFromFile(), []byte(installedFrom), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to record where %s was installed from -> %w", p.NameVersion(), err)
+	}
+	return nil
+}
+
+// recordedInstalledFrom reads where port was installed from,
+// ports installed before it's recorded are regarded as "preinstalled".
+func (p Port) recordedInstalledFrom() string {
+	data, err := os.ReadFile(p.installedFromFile())
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		return "preinstalled"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// installedBuildHash reads build hash from the meta file in package dir,
+// the meta file is named with the hash of its content.
+func (p Port) installedBuildHash() string {
+	if p.PackageDir == "" {
+		return ""
+	}
+
+	matches, err := filepath.Glob(filepath.Join(p.PackageDir, "*.meta"))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(matches[0]), ".meta")
+}
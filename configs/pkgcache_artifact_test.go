@@ -46,6 +46,9 @@ func (f fakeContext) GenerateToolchainFile() error            { return nil }
 func (f fakeContext) ExprVars() *context.ExprVars             { return nil }
 func (f fakeContext) PythonConfig() context.PythonConfig      { return nil }
 func (f fakeContext) Features() context.Features              { return nil }
+func (f fakeContext) Registries() context.Registries {
+	return context.Registries{{Name: context.DefaultRegistry, Dir: dirs.PortsDir}}
+}
 
 type fakePlatform struct {
 	name string
@@ -115,7 +118,7 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 		if artifactCache == nil {
 			t.Fatal("artifact cache should not be nil")
 		}
-		if err := artifactCache.Store(packageDir, meta); err != nil {
+		if _, err := artifactCache.Store(packageDir, meta); err != nil {
 			t.Fatal(err)
 		}
 
@@ -125,7 +128,7 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 	t.Run("artifact not exist", func(t *testing.T) {
 		artifactCache, nameVersion, _, _, _ := setupArtifactFixture(t)
 		destDir := filepath.Join(tmpWorkspace, "out-not-exist")
-		fromWhere, err := artifactCache.Restore(nameVersion, "not-exist-hash", destDir)
+		fromWhere, _, err := artifactCache.Restore(nameVersion, "not-exist-hash", destDir)
 		if err != nil {
 			t.Fatalf("expected no error, got: %v", err)
 		}
@@ -146,7 +149,7 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 
 		// Restore cache to test_package.
 		packageDir := filepath.Join(tmpWorkspace, "test_package")
-		fromWhere, err := artifactCache.Restore(nameVersion, hash, packageDir)
+		fromWhere, _, err := artifactCache.Restore(nameVersion, hash, packageDir)
 		if err == nil {
 			t.Fatal("expected error when metadata is missing")
 		}
@@ -166,7 +169,7 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 		}
 
 		destDir := filepath.Join(tmpWorkspace, "out-meta-mismatch")
-		fromWhere, err := artifactCache.Restore(nameVersion, hash, destDir)
+		fromWhere, _, err := artifactCache.Restore(nameVersion, hash, destDir)
 		if err == nil {
 			t.Fatal("expected error when metadata checksum mismatches")
 		}
@@ -182,13 +185,16 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 	t.Run("read success", func(t *testing.T) {
 		artifactCache, nameVersion, _, hash, _ := setupArtifactFixture(t)
 		destDir := filepath.Join(tmpWorkspace, "out-success")
-		fromWhere, err := artifactCache.Restore(nameVersion, hash, destDir)
+		fromWhere, archiveBytes, err := artifactCache.Restore(nameVersion, hash, destDir)
 		if err != nil {
 			t.Fatalf("expected no error, got: %v", err)
 		}
 		if fromWhere == "" {
 			t.Fatal("expected install from cache success")
 		}
+		if info, err := os.Stat(fromWhere); err != nil || info.Size() != archiveBytes {
+			t.Fatalf("expected restored archive size %d, got: %v, %v", archiveBytes, info, err)
+		}
 
 		if !fileio.PathExists(filepath.Join(destDir, "a.txt")) {
 			t.Fatal("expected extracted file a.txt in destination")
@@ -203,4 +209,42 @@ func TestArtifactCache_StoreAndFetch(t *testing.T) {
 			t.Fatalf("unexpected extracted content: %q", string(content))
 		}
 	})
+	t.Run("store debug", func(t *testing.T) {
+		artifactCache, nameVersion, _, hash, _ := setupArtifactFixture(t)
+
+		storeDir := filepath.Join(tmpWorkspace, "debug-store")
+		debugFile := filepath.Join(storeDir, ".build-id", "ab", "cdef.debug")
+		if err := os.MkdirAll(filepath.Dir(debugFile), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(debugFile, []byte("debug"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		archivePath, err := archiveDebugFiles(storeDir, []string{debugFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(filepath.Dir(archivePath))
+
+		// Debug archive is not stored without cached package.
+		if err := artifactCache.StoreDebug(nameVersion, "not-exist-hash", archivePath); err != nil {
+			t.Fatal(err)
+		}
+		if fileio.PathExists(filepath.Join(artifactCacheDir, "x86_64-linux", "proj", "release", nameVersion, "debug", "not-exist-hash.tar.gz")) {
+			t.Fatal("expected debug archive not stored without cached package")
+		}
+
+		if err := artifactCache.StoreDebug(nameVersion, hash, archivePath); err != nil {
+			t.Fatal(err)
+		}
+		debugArchive := filepath.Join(artifactCacheDir, "x86_64-linux", "proj", "release", nameVersion, "debug", hash+".tar.gz")
+		extractDir := filepath.Join(tmpWorkspace, "out-debug")
+		if err := fileio.Extract(debugArchive, extractDir); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(filepath.Join(extractDir, ".build-id", "ab", "cdef.debug"))
+		if err != nil || string(content) != "debug" {
+			t.Fatalf("expected debug file keyed by build-id in archive, got %q, %v", content, err)
+		}
+	})
 }
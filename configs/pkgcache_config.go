@@ -1,22 +1,42 @@
 package configs
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/celer-pkg/celer/context"
 	"github.com/celer-pkg/celer/pkgcache"
 	"github.com/celer-pkg/celer/pkgcache/dev"
+	"github.com/celer-pkg/celer/pkgcache/remote"
+	"github.com/celer-pkg/celer/pkgs/errors"
 )
 
 // ================= PkgCacheConfig ================= //
 
+const (
+	PkgCacheBackendNetfs = "netfs"
+	PkgCacheBackendHTTP  = remote.BackendHTTP
+	PkgCacheBackendS3    = remote.BackendS3
+)
+
 type PkgCacheConfig struct {
+	Backend        string `toml:"backend,omitempty"` // netfs (default), http or s3.
 	Dir            string `toml:"dir"`
+	Url            string `toml:"url,omitempty"` // Base url of http or s3 backend, s3 url is path style: <endpoint>/<bucket>.
 	Writable       bool   `toml:"writable"`
 	CacheArtifacts bool   `toml:"cache_artifacts"`
 	CacheDownloads bool   `toml:"cache_downloads"`
 
+	// Auth of http and s3 backend, values support ${ENV} expanding.
+	Headers   map[string]string `toml:"headers,omitempty"`
+	Region    string            `toml:"region,omitempty"`
+	AccessKey string            `toml:"access_key,omitempty"`
+	SecretKey string            `toml:"secret_key,omitempty"`
+	Timeout   int               `toml:"timeout,omitempty"` // Timeout of a request in seconds, 600 by default.
+
 	// Internal field.
 	artifactCache pkgcache.AritifactCache
 	repoCache     pkgcache.RepoCache
@@ -31,22 +51,91 @@ func NewPkgCacheConfig() *PkgCacheConfig {
 	}
 }
 
+// GetDir returns cache dir of netfs backend, or cache url of http and s3 backend.
 func (p PkgCacheConfig) GetDir(dirType pkgcache.PkgCacheDirType) string {
+	join := filepath.Join
+	root := p.Dir
+	if p.IsRemote() {
+		root = strings.TrimSuffix(p.Url, "/")
+		join = func(elem ...string) string { return strings.Join(elem, "/") }
+		if root == "" {
+			return ""
+		}
+	}
+
 	switch dirType {
 	case pkgcache.PkgCacheDirArtifacts:
-		return filepath.Join(p.Dir, "artifacts-"+Version)
+		return join(root, "artifacts-"+Version)
 
 	case pkgcache.PkgCacheDirRepos:
-		return filepath.Join(p.Dir, "repos")
+		return join(root, "repos")
 
 	case pkgcache.PkgCacheDirDownloads:
-		return filepath.Join(p.Dir, "downloads")
+		return join(root, "downloads")
 
 	default:
-		return p.Dir
+		return root
 	}
 }
 
+// IsRemote returns true when cache is stored in http or s3 server.
+func (p PkgCacheConfig) IsRemote() bool {
+	return p.Backend == PkgCacheBackendHTTP || p.Backend == PkgCacheBackendS3
+}
+
+// Validate checks the backend and its dir or url is specified.
+func (p PkgCacheConfig) Validate() error {
+	switch p.Backend {
+	case "", PkgCacheBackendNetfs:
+		if strings.TrimSpace(p.Dir) == "" {
+			return errors.ErrPkgCacheDirEmpty
+		}
+
+	case PkgCacheBackendHTTP, PkgCacheBackendS3:
+		if strings.TrimSpace(p.Url) == "" {
+			return errors.ErrPkgCacheUrlEmpty
+		}
+		if !strings.HasPrefix(p.Url, "http://") && !strings.HasPrefix(p.Url, "https://") {
+			return fmt.Errorf("pkgcache url should start with http:// or https://: %s", p.Url)
+		}
+		if p.Timeout < 0 {
+			return fmt.Errorf("pkgcache timeout should not be negative: %d", p.Timeout)
+		}
+
+	default:
+		return fmt.Errorf("unsupported pkgcache backend %q, available backends: netfs, http, s3", p.Backend)
+	}
+
+	return nil
+}
+
+// remoteClient creates client of http or s3 backend.
+func (p PkgCacheConfig) remoteClient() *remote.Client {
+	options := remote.Options{
+		Backend:   p.Backend,
+		Headers:   p.Headers,
+		Region:    p.Region,
+		AccessKey: os.ExpandEnv(p.AccessKey),
+		SecretKey: os.ExpandEnv(p.SecretKey),
+		Timeout:   time.Duration(p.Timeout) * time.Second,
+	}
+
+	// Fallback to standard aws environment variables.
+	if p.Backend == PkgCacheBackendS3 {
+		if options.Region == "" {
+			options.Region = "us-east-1"
+		}
+		if options.AccessKey == "" {
+			options.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		if options.SecretKey == "" {
+			options.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+	}
+
+	return remote.NewClient(options)
+}
+
 func (p PkgCacheConfig) IsWritable() bool {
 	return p.Writable
 }
@@ -0,0 +1,267 @@
+package configs
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgcache/remote"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+// fakeObjectServer is a stand-in of http or s3 cache server, objects are kept in memory.
+type fakeObjectServer struct {
+	mutex    sync.Mutex
+	objects  map[string][]byte
+	requests []string
+	failures int // Count of requests to fail with 503 before serving.
+	checkReq func(r *http.Request) bool
+}
+
+func newFakeObjectServer(t *testing.T, checkReq func(r *http.Request) bool) (*fakeObjectServer, string) {
+	server := &fakeObjectServer{
+		objects:  make(map[string][]byte),
+		checkReq: checkReq,
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return server, httpServer.URL
+}
+
+func (f *fakeObjectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if f.checkReq != nil && !f.checkReq(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRemoteArtifactCache_StoreAndRestore(t *testing.T) {
+	oldWorkspace := dirs.WorkspaceDir
+	tmpWorkspace := t.TempDir()
+	dirs.Init(tmpWorkspace)
+	t.Cleanup(func() { dirs.Init(oldWorkspace) })
+
+	t.Setenv("TEST_PKGCACHE_TOKEN", "secret-token")
+	t.Setenv("TEST_PKGCACHE_SECRET", "secret-key")
+
+	tests := []struct {
+		name     string
+		config   PkgCacheConfig
+		checkReq func(r *http.Request) bool
+	}{
+		{
+			name: "http",
+			config: PkgCacheConfig{
+				Backend: PkgCacheBackendHTTP,
+				Headers: map[string]string{"Authorization": "Bearer ${TEST_PKGCACHE_TOKEN}"},
+			},
+			checkReq: func(r *http.Request) bool {
+				return r.Header.Get("Authorization") == "Bearer secret-token"
+			},
+		},
+		{
+			name: "s3",
+			config: PkgCacheConfig{
+				Backend:   PkgCacheBackendS3,
+				AccessKey: "access-key",
+				SecretKey: "${TEST_PKGCACHE_SECRET}",
+			},
+			checkReq: func(r *http.Request) bool {
+				// "@" in name@version must be encoded as s3 requires.
+				return strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") &&
+					r.Header.Get("X-Amz-Content-Sha256") != "" &&
+					strings.Contains(r.URL.EscapedPath(), "demo%401.0.0")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, serverUrl := newFakeObjectServer(t, test.checkReq)
+
+			pkgCacheConfig := test.config
+			pkgCacheConfig.Url = serverUrl + "/bucket/"
+			pkgCacheConfig.Writable = true
+			if err := pkgCacheConfig.Validate(); err != nil {
+				t.Fatal(err)
+			}
+
+			fakeCtx := fakeContext{platform: "x86_64-linux", project: "proj", build: "release"}
+			fakeCtx.pkgCacheConfig = &pkgCacheConfig
+			artifactCache := remote.NewArtifactConfig(fakeCtx, pkgCacheConfig.remoteClient())
+
+			packageDir := filepath.Join(tmpWorkspace, "packages", "x86_64-linux", "proj", "release", "demo@1.0.0")
+			if err := os.MkdirAll(packageDir, os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(packageDir, "a.txt"), []byte("hello"), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			meta := "meta-data-for-" + test.name
+			hash := fmt.Sprintf("%x", sha256.Sum256([]byte(meta)))
+			storedBytes, err := artifactCache.Store(packageDir, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Same layout as netfs backend.
+			archiveKey := "/bucket/artifacts-" + Version + "/x86_64-linux/proj/release/demo@1.0.0/" + hash + ".tar.gz"
+			metaKey := "/bucket/artifacts-" + Version + "/x86_64-linux/proj/release/demo@1.0.0/metas/" + hash + ".meta"
+			if _, ok := server.objects[archiveKey]; !ok {
+				t.Fatalf("archive is not uploaded to %s, requests: %v", archiveKey, server.requests)
+			}
+			if string(server.objects[metaKey]) != meta {
+				t.Fatalf("meta is not uploaded to %s", metaKey)
+			}
+			if storedBytes != int64(len(server.objects[archiveKey])) {
+				t.Errorf("Store() = %d bytes, want %d", storedBytes, len(server.objects[archiveKey]))
+			}
+
+			// Nothing is uploaded when already cached.
+			if storedBytes, err := artifactCache.Store(packageDir, meta); err != nil || storedBytes != 0 {
+				t.Errorf("Store() of cached artifact = %d, %v, want 0", storedBytes, err)
+			}
+			if !artifactCache.Exist("demo@1.0.0", hash) {
+				t.Fatal("artifact should exist after stored")
+			}
+
+			// Cache miss is not an error.
+			if fromWhere, _, err := artifactCache.Restore("demo@1.0.0", "not-exist-hash", packageDir); err != nil || fromWhere != "" {
+				t.Fatalf("Restore(not-exist-hash) = %q, %v", fromWhere, err)
+			}
+
+			// Restore with server hiccups, they should be retried.
+			restoreDir := filepath.Join(tmpWorkspace, "restored-"+test.name, "demo@1.0.0")
+			server.failures = 2
+			fromWhere, restoredBytes, err := artifactCache.Restore("demo@1.0.0", hash, restoreDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(fromWhere, hash+".tar.gz") {
+				t.Errorf("Restore() = %q, want archive url", fromWhere)
+			}
+			if restoredBytes != storedBytes {
+				t.Errorf("Restore() = %d bytes, want %d", restoredBytes, storedBytes)
+			}
+			if data, err := os.ReadFile(filepath.Join(restoreDir, "a.txt")); err != nil || string(data) != "hello" {
+				t.Errorf("restored a.txt = %q, %v", data, err)
+			}
+
+			// Tampered meta must be detected.
+			server.objects[metaKey] = []byte("tampered")
+			if _, _, err := artifactCache.Restore("demo@1.0.0", hash, restoreDir); err == nil {
+				t.Error("Restore() should fail when meta is tampered")
+			}
+		})
+	}
+}
+
+func TestRemoteDownloadCache_StoreAndRestore(t *testing.T) {
+	oldWorkspace := dirs.WorkspaceDir
+	tmpWorkspace := t.TempDir()
+	dirs.Init(tmpWorkspace)
+	t.Cleanup(func() { dirs.Init(oldWorkspace) })
+
+	server, serverUrl := newFakeObjectServer(t, nil)
+	pkgCacheConfig := PkgCacheConfig{Backend: PkgCacheBackendHTTP, Url: serverUrl, Writable: true}
+	fakeCtx := fakeContext{pkgCacheConfig: &pkgCacheConfig}
+	downloadCache := remote.NewDownloadConfig(fakeCtx, pkgCacheConfig.remoteClient())
+
+	srcFile := filepath.Join(tmpWorkspace, "cmake-3.30.5.tar.gz")
+	if err := os.WriteFile(srcFile, []byte("cmake"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte("cmake")))
+
+	if _, err := downloadCache.Store("cmake-3.30.5.tar.gz", checksum, srcFile); err != nil {
+		t.Fatal(err)
+	}
+	key := "/downloads/cmake-3.30.5-" + checksum + ".tar.gz"
+	if string(server.objects[key]) != "cmake" {
+		t.Fatalf("file is not uploaded to %s, requests: %v", key, server.requests)
+	}
+
+	cachedFile, err := downloadCache.Restore("cmake-3.30.5.tar.gz", checksum)
+	if err != nil || cachedFile == "" {
+		t.Fatalf("Restore() = %q, %v", cachedFile, err)
+	}
+	if data, _ := os.ReadFile(cachedFile); string(data) != "cmake" {
+		t.Errorf("restored file content = %q", data)
+	}
+
+	// Miss and corrupted content both fall back to download from source.
+	if cachedFile, err := downloadCache.Restore("ninja-1.12.1.zip", checksum); err != nil || cachedFile != "" {
+		t.Errorf("Restore(missing) = %q, %v", cachedFile, err)
+	}
+	server.objects[key] = []byte("corrupted")
+	if cachedFile, err := downloadCache.Restore("cmake-3.30.5.tar.gz", checksum); err != nil || cachedFile != "" {
+		t.Errorf("Restore(corrupted) = %q, %v", cachedFile, err)
+	}
+}
+
+func TestPkgCacheConfig_Validate(t *testing.T) {
+	tests := []struct {
+		config  PkgCacheConfig
+		wantErr bool
+	}{
+		{PkgCacheConfig{Dir: "/mnt/pkgcache"}, false},
+		{PkgCacheConfig{Backend: PkgCacheBackendNetfs}, true},
+		{PkgCacheConfig{Backend: PkgCacheBackendHTTP, Url: "http://cache.local/celer"}, false},
+		{PkgCacheConfig{Backend: PkgCacheBackendS3, Url: "cache.local/bucket"}, true},
+		{PkgCacheConfig{Backend: PkgCacheBackendS3}, true},
+		{PkgCacheConfig{Backend: "ftp", Url: "ftp://cache.local"}, true},
+	}
+	for _, test := range tests {
+		if err := test.config.Validate(); (err != nil) != test.wantErr {
+			t.Errorf("Validate(%+v) = %v, want error: %v", test.config, err, test.wantErr)
+		}
+	}
+
+	remoteConfig := PkgCacheConfig{Backend: PkgCacheBackendHTTP, Url: "http://cache.local/celer/"}
+	if dir := remoteConfig.GetDir(pkgcache.PkgCacheDirRepos); dir != "http://cache.local/celer/repos" {
+		t.Errorf("GetDir(repos) = %s", dir)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/celer-pkg/celer/buildsystems"
 	"github.com/celer-pkg/celer/context"
@@ -19,20 +20,70 @@ import (
 
 var (
 	// preparedTmpDeps tracks deps already prepared for tmp, to avoid redundant Init().
-	preparedTmpDeps = map[string]bool{}
+	preparedTmpDeps = newPortSet()
 
 	// visitedPorts tracks ports visited during dependency-tree traversal so
 	// each port is processed at most once even when it appears under many parents.
-	visitedPorts = map[string]bool{}
+	visitedPorts = newPortSet()
 
 	// clonedPorts tracks which ports have already been cloned during a single
 	// cloneAllRepos invocation, to avoid redundant Init() + Clone() calls.
-	clonedPorts = map[string]bool{}
+	clonedPorts = newPortSet()
 )
 
+// portSet is a set of port keys that is safe for concurrent use, the install
+// scheduler may touch it from several goroutines at the same time.
+type portSet struct {
+	mutex sync.Mutex
+	keys  map[string]bool
+}
+
+func newPortSet() *portSet {
+	return &portSet{keys: make(map[string]bool)}
+}
+
+func (s *portSet) has(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.keys[key]
+}
+
+func (s *portSet) add(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[key] = true
+}
+
+// tryAdd adds key and reports whether it was absent before.
+func (s *portSet) tryAdd(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.keys[key] {
+		return false
+	}
+	s.keys[key] = true
+	return true
+}
+
+func (s *portSet) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys = make(map[string]bool)
+}
+
 type InstallOptions struct {
 	Force     bool
 	Recursive bool
+	Parallel  int    // Number of ports built at the same time, one by one when <= 1.
+	KeepGoing bool   // Keep building independent ports after a failure when Parallel > 1.
+	FromStage string // Rebuild root port from the stage, stages before it are resumed from checkpoint.
+
+	// ReportFormat is the format of install report, markdown is written when it's not "json".
+	ReportFormat string
+
+	// ResultFile receives where the port was installed from instead of
+	// the install report, it's how scheduler workers report back.
+	ResultFile string
 }
 
 type RemoveOptions struct {
@@ -50,21 +101,28 @@ type Package struct {
 	SrcDir          string `toml:"src_dir,omitempty"`
 	IgnoreSubmodule bool   `toml:"ignore_submodule,omitempty"`
 	BuildTool       bool   `toml:"build_tool,omitempty"`
+	License         string `toml:"license,omitempty"`
+	CPE             string `toml:"cpe,omitempty"`
+	Purl            string `toml:"purl,omitempty"`
+	UpdateUrl       string `toml:"update_url,omitempty"`
+	UpdatePattern   string `toml:"update_pattern,omitempty"`
 }
 
 type Port struct {
 	Package      Package                    `toml:"package"`
 	BuildConfigs []buildsystems.BuildConfig `toml:"build_configs"`
+	Features     map[string]PortFeature     `toml:"features,omitempty"`
 
 	// Internal fields.
-	Name          string                    `toml:"-"`
-	Version       string                    `toml:"-"`
-	Parent        string                    `toml:"-"`
-	DevDep        bool                      `toml:"-"` // Whether the port is a dev_dependences.
-	HostDep       bool                      `toml:"-"` // Whether the port is a dependencies of a dev_dependencies.
-	MatchedConfig *buildsystems.BuildConfig `toml:"-"`
-	PackageDir    string                    `toml:"-"`
-	InstalledDir  string                    `toml:"-"`
+	Name             string                    `toml:"-"`
+	Version          string                    `toml:"-"`
+	Parent           string                    `toml:"-"`
+	DevDep           bool                      `toml:"-"` // Whether the port is a dev_dependences.
+	HostDep          bool                      `toml:"-"` // Whether the port is a dependencies of a dev_dependencies.
+	MatchedConfig    *buildsystems.BuildConfig `toml:"-"`
+	PackageDir       string                    `toml:"-"`
+	InstalledDir     string                    `toml:"-"`
+	SelectedFeatures []string                  `toml:"-"` // Features merged into matched config, sorted.
 
 	ctx                        context.Context
 	portFile                   string
@@ -75,12 +133,18 @@ type Port struct {
 	exprVars                   context.ExprVars
 	sourceModified             bool
 	pkgCacheStoreSkippedReason string
+	rawDependencies            bool // Keep version constraints of dependencies unresolved.
 }
 
 func (p Port) NameVersion() string {
 	return p.Name + "@" + p.Version
 }
 
+// PortFile returns the port.toml it was initialized from, it may come from project's ports.
+func (p Port) PortFile() string {
+	return p.portFile
+}
+
 // visitedKey is the key used in visitedPorts to dedupe per-command processing.
 func (p Port) visitedKey() string {
 	if p.DevDep || p.HostDep {
@@ -93,8 +157,9 @@ func (p *Port) Init(ctx context.Context, nameVersion string) error {
 	p.ctx = ctx
 	p.exprVars = ctx.ExprVars().Clone()
 
-	// Validate name and version.
+	// Validate name and version, features like `opencv@4.8.0[contrib]` are optional.
 	nameVersion = strings.ReplaceAll(nameVersion, "`", "")
+	nameVersion, features := SplitFeatures(nameVersion)
 	parts := strings.Split(nameVersion, "@")
 	if len(parts) != 2 {
 		return fmt.Errorf("port name and version are invalid %s", nameVersion)
@@ -134,6 +199,13 @@ func (p *Port) Init(ctx context.Context, nameVersion string) error {
 		p.HostDep = true
 	}
 
+	// Replace version constraints of dependencies with exact versions.
+	if !p.rawDependencies {
+		if err := p.resolveDependencies(); err != nil {
+			return err
+		}
+	}
+
 	// Convert build type to lowercase for all build configs.
 	for index := range p.BuildConfigs {
 		p.BuildConfigs[index].BuildType = strings.ToLower(p.BuildConfigs[index].BuildType)
@@ -169,6 +241,11 @@ func (p *Port) Init(ctx context.Context, nameVersion string) error {
 		return fmt.Errorf("failed to init build config -> %w", err)
 	}
 
+	// Merge selected features into matched config.
+	if err := p.applyFeatures(features); err != nil {
+		return err
+	}
+
 	// Validate port.
 	if err := p.validate(); err != nil {
 		return fmt.Errorf("failed to validate %s -> %w", p.portFile, err)
@@ -241,11 +318,12 @@ func (p Port) Installed() (bool, error) {
 //
 //  1. <ConfProjectsDir>/<project>/<name>/<version>/port.toml         (project top-level)
 //  2. <ConfProjectsDir>/<project>/ports/<name>/<version>/port.toml   (project vendor)
-//  3. <PortsDir>/<first-char>/<name>/<version>/port.toml             (global)
+//  3. <registry>/<first-char>/<name>/<version>/port.toml             (registries of celer.toml)
+//  4. <PortsDir>/<first-char>/<name>/<version>/port.toml             (global)
 //
 // Returns the found path, or:
 //   - ErrAmbiguousProjectPort if both (1) and (2) exist
-//   - ErrPortNotFound if none of the three exists
+//   - ErrPortNotFound if none of them exists
 func (p Port) resolveProjectPort(project, name, version string) (string, error) {
 	topLevelPort := filepath.Join(dirs.ConfProjectsDir, project, name, version, "port.toml")
 	vendorPort := filepath.Join(dirs.ConfProjectsDir, project, "ports", name, version, "port.toml")
@@ -265,8 +343,8 @@ func (p Port) resolveProjectPort(project, name, version string) (string, error)
 		return vendorPort, nil
 	}
 
-	// Fall back to the global ports/ collection.
-	publicPort := dirs.GetPortPath(name, version)
+	// Fall back to registries and then the global ports/ collection.
+	publicPort := p.ctx.Registries().FindPortPath(name, version)
 	if fileio.PathExists(publicPort) {
 		return publicPort, nil
 	}
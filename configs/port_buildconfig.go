@@ -88,7 +88,7 @@ func (p *Port) initBuildConfig(nameVersion string) error {
 	if len(p.BuildConfigs) > 0 {
 		for index := range p.BuildConfigs {
 			// Merge ports defined in project if exists.
-			publicPort := dirs.GetPortPath(p.Name, p.Version)
+			publicPort := p.ctx.Registries().FindPortPath(p.Name, p.Version)
 			projectPort := p.portFile != "" && p.portFile != publicPort
 			if fileio.PathExists(publicPort) && projectPort {
 				bytes, err := os.ReadFile(p.portFile)
@@ -0,0 +1,183 @@
+package configs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+)
+
+// requestedFeatures collects features selected for ports while initializing their
+// parents, so that a port is always built with the same features in a project.
+var requestedFeatures = struct {
+	mutex    sync.Mutex
+	features map[string]map[solveKey][]string
+}{features: make(map[string]map[solveKey][]string)}
+
+// PortFeature is an optional component of port, it's built only when it's
+// selected like `opencv@4.8.0[contrib,cuda]`.
+type PortFeature struct {
+	Description     string   `toml:"description,omitempty"`
+	Options         []string `toml:"options,omitempty"`
+	Dependencies    []string `toml:"dependencies,omitempty"`
+	DevDependencies []string `toml:"dev_dependencies,omitempty"`
+	Patches         []string `toml:"patches,omitempty"`
+}
+
+// SplitFeatures splits `name@version[a,b]` into `name@version` and its features.
+func SplitFeatures(nameVersion string) (string, []string) {
+	index := strings.LastIndex(nameVersion, "[")
+	if index < 0 || !strings.HasSuffix(nameVersion, "]") {
+		return nameVersion, nil
+	}
+
+	var features []string
+	for feature := range strings.SplitSeq(nameVersion[index+1:len(nameVersion)-1], ",") {
+		feature = strings.TrimSpace(feature)
+		if feature != "" && !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+	return strings.TrimSpace(nameVersion[:index]), features
+}
+
+// JoinFeatures is the reverse of SplitFeatures.
+func JoinFeatures(nameVersion string, features []string) string {
+	if len(features) == 0 {
+		return nameVersion
+	}
+	return nameVersion + "[" + strings.Join(features, ",") + "]"
+}
+
+// unionFeatures returns sorted union of features, and whether others added any.
+func unionFeatures(features []string, others ...string) ([]string, bool) {
+	var changed bool
+	for _, other := range others {
+		if !slices.Contains(features, other) {
+			features = append(features, other)
+			changed = true
+		}
+	}
+	slices.Sort(features)
+	return features, changed
+}
+
+// FeatureNames returns names of features defined in port in order.
+func (p Port) FeatureNames() []string {
+	var names []string
+	for name := range p.Features {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (p Port) solveKey() solveKey {
+	return solveKey{name: p.Name, host: p.DevDep || p.HostDep}
+}
+
+// applyFeatures merges options, dependencies and patches of selected features into
+// matched config. A port is installed only once in a project, so its features are
+// the union of selections from the project and all ports depending on it.
+func (p *Port) applyFeatures(requested []string) error {
+	selected, _ := unionFeatures(slices.Clone(requested))
+	if !p.rawDependencies && len(p.Features) > 0 {
+		selected, _ = unionFeatures(selected, registeredFeatures(p.ctx, p.solveKey())...)
+		selected, _ = unionFeatures(selected, solvedFeatures(p.ctx)[p.solveKey()]...)
+	}
+
+	host := p.DevDep || p.HostDep
+	config := p.MatchedConfig
+	for _, name := range selected {
+		feature, ok := p.Features[name]
+		if !ok {
+			available := expr.If(len(p.Features) == 0, "none", strings.Join(p.FeatureNames(), ", "))
+			return fmt.Errorf("feature %s is not defined in %s, available features: %s", name, p.NameVersion(), available)
+		}
+
+		dependencies := slices.Clone(feature.Dependencies)
+		devDependencies := slices.Clone(feature.DevDependencies)
+		if !p.rawDependencies {
+			if err := p.resolveConstraints(dependencies, host); err != nil {
+				return err
+			}
+			if err := p.resolveConstraints(devDependencies, true); err != nil {
+				return err
+			}
+		}
+
+		config.Options = append(config.Options, feature.Options...)
+		config.Patches = append(config.Patches, feature.Patches...)
+		for _, dependency := range dependencies {
+			if !slices.Contains(config.Dependencies, dependency) {
+				config.Dependencies = append(config.Dependencies, dependency)
+			}
+		}
+		for _, dependency := range devDependencies {
+			if !slices.Contains(config.DevDependencies, dependency) {
+				config.DevDependencies = append(config.DevDependencies, dependency)
+			}
+		}
+	}
+	p.SelectedFeatures = selected
+
+	// Features of dependencies are handed over by registering them, since
+	// dependencies are referred by name@version everywhere else.
+	if p.rawDependencies {
+		return nil
+	}
+	registerFeatures(p.ctx, p.solveKey(), selected)
+	for index := range p.BuildConfigs {
+		matched := &p.BuildConfigs[index] == config
+		p.BuildConfigs[index].Dependencies = p.stripFeatures(p.BuildConfigs[index].Dependencies, host, matched)
+		p.BuildConfigs[index].DevDependencies = p.stripFeatures(p.BuildConfigs[index].DevDependencies, true, matched)
+	}
+	return nil
+}
+
+func (p *Port) stripFeatures(nameVersions []string, host, register bool) []string {
+	for index, nameVersion := range nameVersions {
+		nameVersion, features := SplitFeatures(nameVersion)
+		if len(features) == 0 {
+			continue
+		}
+		nameVersions[index] = nameVersion
+		if register {
+			name, _, _ := strings.Cut(nameVersion, "@")
+			registerFeatures(p.ctx, solveKey{name: name, host: host}, features)
+		}
+	}
+	return nameVersions
+}
+
+func registerFeatures(ctx context.Context, key solveKey, features []string) {
+	if len(features) == 0 {
+		return
+	}
+
+	requestedFeatures.mutex.Lock()
+	defer requestedFeatures.mutex.Unlock()
+
+	projectKey := projectSolveKey(ctx)
+	registered, ok := requestedFeatures.features[projectKey]
+	if !ok {
+		registered = make(map[solveKey][]string)
+		requestedFeatures.features[projectKey] = registered
+	}
+	registered[key], _ = unionFeatures(registered[key], features...)
+}
+
+func registeredFeatures(ctx context.Context, key solveKey) []string {
+	requestedFeatures.mutex.Lock()
+	defer requestedFeatures.mutex.Unlock()
+	return slices.Clone(requestedFeatures.features[projectSolveKey(ctx)][key])
+}
+
+// projectSolveKey identifies the graph of ports of project for current platform and build type.
+func projectSolveKey(ctx context.Context) string {
+	return strings.Join([]string{dirs.WorkspaceDir, ctx.Project().GetName(), ctx.Platform().GetName(), ctx.BuildType()}, "|")
+}
@@ -0,0 +1,139 @@
+package configs
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/configs/toolchains"
+	"github.com/celer-pkg/celer/context"
+)
+
+// featureContext is a context of project with ports, which is required to solve features.
+type featureContext struct {
+	fakeContext
+	ports []string
+}
+
+func (f featureContext) Platform() context.Platform {
+	return featurePlatform{fakePlatform: fakePlatform{name: f.platform}}
+}
+func (f featureContext) Project() context.Project {
+	return featureProject{fakeProject: fakeProject{name: f.project}, ports: f.ports}
+}
+func (f featureContext) ExprVars() *context.ExprVars { return &context.ExprVars{} }
+
+type featurePlatform struct {
+	fakePlatform
+}
+
+func (f featurePlatform) GetToolchain() context.Toolchain {
+	return &Toolchain{Infos: toolchains.Infos{SystemName: "linux", SystemProcessor: "x86_64"}}
+}
+
+type featureProject struct {
+	fakeProject
+	ports []string
+}
+
+func (f featureProject) GetPorts() []string { return f.ports }
+
+func TestSplitFeatures(t *testing.T) {
+	for _, test := range []struct {
+		nameVersion string
+		expected    string
+		features    []string
+	}{
+		{"opencv@4.8.0", "opencv@4.8.0", nil},
+		{"opencv@4.8.0[contrib]", "opencv@4.8.0", []string{"contrib"}},
+		{"opencv@4.8.0[contrib, cuda,contrib]", "opencv@4.8.0", []string{"contrib", "cuda"}},
+		{"opencv@>=4.8,<5[cuda]", "opencv@>=4.8,<5", []string{"cuda"}},
+		{"opencv@4.8.0[]", "opencv@4.8.0", nil},
+	} {
+		nameVersion, features := SplitFeatures(test.nameVersion)
+		if nameVersion != test.expected || !slices.Equal(features, test.features) {
+			t.Errorf("%s should be split into %s %v, but got %s %v",
+				test.nameVersion, test.expected, test.features, nameVersion, features)
+		}
+	}
+}
+
+func TestPort_Features(t *testing.T) {
+	setupTestWorkspace(t, map[string]string{
+		"ports/a/app/1.0.0/port.toml": `[package]
+url = "https://example.com/app.git"
+ref = "1.0.0"
+
+[[build_configs]]
+build_system = "cmake"
+dependencies = ["opencv@4.8.0[contrib]"]
+`,
+		"ports/o/opencv/4.8.0/port.toml": `[package]
+url = "https://example.com/opencv.git"
+ref = "4.8.0"
+
+[[build_configs]]
+build_system = "cmake"
+options = ["-DBUILD_TESTS=OFF"]
+
+[features.contrib]
+description = "Extra modules"
+options = ["-DWITH_CONTRIB=ON"]
+dependencies = ["zlib@>=1.2"]
+patches = ["contrib.patch"]
+
+[features.cuda]
+options = ["-DWITH_CUDA=ON"]
+`,
+		"ports/z/zlib/1.3.1/port.toml": `[package]
+url = "https://example.com/zlib.git"
+ref = "1.3.1"
+
+[[build_configs]]
+build_system = "cmake"
+`,
+	})
+
+	ctx := featureContext{
+		fakeContext: fakeContext{platform: "x86_64-linux", project: "features", build: "release"},
+		ports:       []string{"app@1.0.0", "opencv@4.8.0[cuda]"},
+	}
+
+	// Features selected by project and app are unioned.
+	var opencv Port
+	if err := opencv.Init(ctx, "opencv@4.8.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(opencv.SelectedFeatures, []string{"contrib", "cuda"}) {
+		t.Fatalf("features should be unioned across the graph, but got %v", opencv.SelectedFeatures)
+	}
+	config := opencv.MatchedConfig
+	if !slices.Equal(config.Options, []string{"-DBUILD_TESTS=OFF", "-DWITH_CONTRIB=ON", "-DWITH_CUDA=ON"}) {
+		t.Errorf("options of features should be appended, but got %v", config.Options)
+	}
+	if !slices.Equal(config.Dependencies, []string{"zlib@1.3.1"}) || !slices.Equal(config.Patches, []string{"contrib.patch"}) {
+		t.Errorf("dependencies and patches of features should be appended, but got %v %v", config.Dependencies, config.Patches)
+	}
+
+	// Dependencies are referred without features after init.
+	var app Port
+	if err := app.Init(ctx, "app@1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(app.MatchedConfig.Dependencies, []string{"opencv@4.8.0"}) {
+		t.Errorf("features of dependencies should be stripped, but got %v", app.MatchedConfig.Dependencies)
+	}
+
+	// Selected features are part of the build metadata.
+	buildConfig := opencv.toPkgCacheBuildConfig(opencv.MatchedConfig, opencv.portFile, opencv.SelectedFeatures)
+	if !slices.Equal(buildConfig.Features, []string{"contrib", "cuda"}) {
+		t.Errorf("features should be in build config of meta, but got %v", buildConfig.Features)
+	}
+
+	// Undefined feature.
+	var undefined Port
+	if err := undefined.Init(ctx, "zlib@1.3.1[gpu]"); err == nil ||
+		!strings.Contains(err.Error(), "feature gpu is not defined in zlib@1.3.1") {
+		t.Errorf("undefined feature should fail, but got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/celer-pkg/celer/buildsystems"
 	"github.com/celer-pkg/celer/buildtools"
 	"github.com/celer-pkg/celer/pkgcache"
 	"github.com/celer-pkg/celer/pkgs/color"
@@ -20,11 +21,32 @@ import (
 
 // Install install a port and tell me where it was installed from.
 func (p *Port) Install(options InstallOptions) (installedFrom string, retErr error) {
+	// Hand the whole dependency graph over to the scheduler to build in parallel.
+	if p.Parent == "" && options.Parallel > 1 {
+		return p.installInParallel(options)
+	}
+
+	// Another celer process may be installing the same port in this workspace.
+	unlock, err := p.lockPort()
+	if err != nil {
+		return "", fmt.Errorf("failed to lock %s -> %w", p.NameVersion(), err)
+	}
+	defer unlock()
+
 	// At the top-level entry, reset the installReport.
 	if p.Parent == "" {
 		p.installReport = newInstallReport(p.NameVersion())
+		p.installReport.format = options.ReportFormat
 	}
 	defer func() {
+		// Record where it's installed from, so that it can be told after install.
+		if retErr == nil && installedFrom != "" && installedFrom != "preinstalled" {
+			if err := p.writeInstalledFrom(installedFrom); err != nil {
+				retErr = err
+				return
+			}
+		}
+
 		if retErr != nil || p.installReport == nil {
 			return
 		}
@@ -35,6 +57,18 @@ func (p *Port) Install(options InstallOptions) (installedFrom string, retErr err
 		}
 		p.installReport.add(p, finalFrom)
 
+		// Scheduler worker reports back through result file.
+		if p.Parent == "" && options.ResultFile != "" {
+			if err := os.WriteFile(options.ResultFile, []byte(finalFrom), os.ModePerm); err != nil {
+				retErr = fmt.Errorf("failed to write install result -> %w", err)
+				return
+			}
+			if err := p.installReport.writeWorkerStats(p, options.ResultFile); err != nil {
+				retErr = fmt.Errorf("failed to write install stats -> %w", err)
+			}
+			return
+		}
+
 		// Only top-level port writes report files.
 		if p.Parent == "" {
 			reportPath, err := p.installReport.write(p)
@@ -66,6 +100,16 @@ func (p *Port) Install(options InstallOptions) (installedFrom string, retErr err
 		}
 	}
 
+	// Restart from a stage means to rebuild root port from source with its build cache,
+	// the installed files are removed but package dir is kept for resuming.
+	restart := p.Parent == "" && options.FromStage != "" && !options.Force
+	if restart && installed {
+		if err := p.Remove(RemoveOptions{}); err != nil {
+			return "", fmt.Errorf("failed to remove installed package -> %w", err)
+		}
+		installed = false
+	}
+
 	// Remvoe installed port when repo source changed.
 	if p.sourceModified {
 		options := RemoveOptions{
@@ -137,6 +181,17 @@ func (p *Port) Install(options InstallOptions) (installedFrom string, retErr err
 		return
 	}
 
+	// Restart from a stage skips package and caches.
+	if restart {
+		if err := p.InstallFromSource(options); err != nil {
+			return "", err
+		}
+
+		installedFrom = "source"
+		retErr = nil
+		return
+	}
+
 	// 1. Try to install from package.
 	if installed, err := p.InstallFromPackage(options); err != nil {
 		return "", err
@@ -176,6 +231,26 @@ func (p *Port) Install(options InstallOptions) (installedFrom string, retErr err
 	return
 }
 
+// installInParallel installs the port and its dependencies with the install scheduler.
+func (p *Port) installInParallel(options InstallOptions) (string, error) {
+	p.installReport = newInstallReport(p.NameVersion())
+	p.installReport.format = options.ReportFormat
+	installedFroms, err := installScheduled(p.ctx, []Port{*p}, options, p.installReport)
+	if err != nil {
+		return "", err
+	}
+
+	reportPath, err := p.installReport.write(p)
+	if err != nil {
+		color.PrintWarning("failed to write install report for %s -> %s", p.NameVersion(), err)
+	} else {
+		color.PrintPass("%s's install report is generated", p.NameVersion())
+		color.PrintHint("Location: %s\n", reportPath)
+	}
+
+	return installedFroms[0], nil
+}
+
 // shouldSkipArtifactPkgCache reports whether the artifact pkgcache must be
 // bypassed for both restore and store. Dev/host builds use the local
 // toolchain, which differs per machine; locally modified sources mean the
@@ -220,11 +295,9 @@ func (p *Port) pkgCacheStoreSkipReason() (string, error) {
 
 func (p Port) Clone() error {
 	// Skip if this port was already cloned in the current cloneAllRepos run.
-	key := p.visitedKey()
-	if clonedPorts[key] {
+	if !clonedPorts.tryAdd(p.visitedKey()) {
 		return nil
 	}
-	clonedPorts[key] = true
 
 	for _, nameVersion := range p.MatchedConfig.DevDependencies {
 		var port = Port{
@@ -238,7 +311,7 @@ func (p Port) Clone() error {
 		// Ports with a checksum are expected to be restored from the artifact
 		// pkgcache during install (no source build needed).
 		if port.Package.Checksum == "" {
-			if err := port.MatchedConfig.Clone(
+			if err := port.cloneSource(
 				port.Package.Url,
 				port.Package.Ref,
 				port.Package.Archive,
@@ -262,7 +335,7 @@ func (p Port) Clone() error {
 		// Ports with a checksum are expected to be restored from the artifact
 		// pkgcache during install (no source build needed).
 		if port.Package.Checksum == "" {
-			if err := port.MatchedConfig.Clone(
+			if err := port.cloneSource(
 				port.Package.Url,
 				port.Package.Ref,
 				port.Package.Archive,
@@ -278,7 +351,7 @@ func (p Port) Clone() error {
 		// Repo may archived in pkgcache/repos with filename of commit hash,
 		// we prefer clone with commit, so that repo can restore from pkgcache/repos.
 		repoRef := expr.If(p.Package.Checksum != "", p.Package.Checksum, p.Package.Ref)
-		if err := p.MatchedConfig.Clone(p.Package.Url, repoRef, p.Package.Archive, p.Package.Depth); err != nil {
+		if err := p.cloneSource(p.Package.Url, repoRef, p.Package.Archive, p.Package.Depth); err != nil {
 			return err
 		}
 	}
@@ -291,7 +364,7 @@ func (p Port) doInstallFromPkgCache(options InstallOptions) (bool, error) {
 	for _, nameVersion := range p.MatchedConfig.Dependencies {
 		// Skip Init() for already-processed ports.
 		key := expr.If(p.DevDep || p.HostDep, nameVersion+"[dev]", nameVersion)
-		if visitedPorts[key] {
+		if visitedPorts.has(key) {
 			continue
 		}
 
@@ -306,7 +379,7 @@ func (p Port) doInstallFromPkgCache(options InstallOptions) (bool, error) {
 		if _, err := port.Install(options); err != nil {
 			return false, err
 		}
-		visitedPorts[key] = true
+		visitedPorts.add(key)
 	}
 
 	// Calculate buildhash.
@@ -318,11 +391,13 @@ func (p Port) doInstallFromPkgCache(options InstallOptions) (bool, error) {
 	// Read cache file and extract them to package dir.
 	artifactCache := p.ctx.PkgCacheConfig().GetArtifactCache()
 	if artifactCache != nil {
-		if fromWhere, err := artifactCache.Restore(p.NameVersion(), buildhash, p.PackageDir); err != nil {
+		if fromWhere, archiveBytes, err := artifactCache.Restore(p.NameVersion(), buildhash, p.PackageDir); err != nil {
 			return false, fmt.Errorf("read cache with buildhash: %s", err)
 		} else if fromWhere != "" {
+			p.installReport.recordCache(&p, CacheHit, archiveBytes)
 			return true, nil
 		}
+		p.installReport.recordCache(&p, CacheMiss, 0)
 	}
 
 	return false, nil
@@ -492,9 +567,10 @@ func (p *Port) InstallFromDevCache(options InstallOptions) (bool, error) {
 }
 
 func (p *Port) InstallFromSource(options InstallOptions) error {
-	// Reset at top-level entry.
+	// Reset at top-level entry, only root port is restarted from the given stage.
 	if p.Parent == "" {
-		visitedPorts = map[string]bool{}
+		visitedPorts.reset()
+		p.MatchedConfig.FromStage = options.FromStage
 	}
 
 	// Clone or download source of all repos.
@@ -531,6 +607,13 @@ func (p *Port) InstallFromSource(options InstallOptions) error {
 		return err
 	}
 
+	// tmp/deps is shared by all builds of this workspace, hold it until installed.
+	unlock, err := lockTmpDeps()
+	if err != nil {
+		return fmt.Errorf("failed to lock tmp deps dir -> %w", err)
+	}
+	defer unlock()
+
 	// Clean tmp/deps before prepareTmpDeps to prevent sibling dependency pollution.
 	if err := fileio.CleanDir(dirs.TmpDepsDir); err != nil {
 		return err
@@ -538,14 +621,21 @@ func (p *Port) InstallFromSource(options InstallOptions) error {
 
 	// Prepare dependencies to tmp/deps before build it.
 	haveDependencies := len(p.MatchedConfig.Dependencies) > 0 || len(p.MatchedConfig.DevDependencies) > 0
-	if haveDependencies && (options.Force || !p.MatchedConfig.Configured()) {
+	if haveDependencies && (options.Force || options.FromStage != "" || !p.MatchedConfig.Configured()) {
 		color.Printf(color.Title, "\n[prepare dependencies: %s]\n", p.NameVersion())
-		preparedTmpDeps = map[string]bool{}
+		preparedTmpDeps.reset()
 		if err := p.prepareTmpDeps(); err != nil {
 			return err
 		}
 	}
 
+	// Source is shared by builds of other platforms, projects and build types.
+	unlockSource, err := p.lockSource()
+	if err != nil {
+		return fmt.Errorf("failed to lock source of %s -> %w", p.NameVersion(), err)
+	}
+	defer unlockSource()
+
 	// Firstly, install to package dir.
 	if err := p.doInstallFromSource(); err != nil {
 		return err
@@ -582,6 +672,13 @@ func (p Port) doInstallFromPackage(destDir string) error {
 		return err
 	}
 
+	// Files installed into venv are managed by pip, only files in installed dir are checked.
+	if destDir == p.InstalledDir {
+		if err := p.checkFileCollisions(files); err != nil {
+			return err
+		}
+	}
+
 	// Copy files from package to installed dir.
 	for _, file := range files {
 		if p.DevDep || p.HostDep {
@@ -616,7 +713,7 @@ func (p *Port) doInstallFromDevCache(options InstallOptions) (bool, error) {
 	for _, nameVersion := range p.MatchedConfig.Dependencies {
 		// Skip Init() for already-processed ports.
 		key := expr.If(p.DevDep || p.HostDep, nameVersion+"[dev]", nameVersion)
-		if visitedPorts[key] {
+		if visitedPorts.has(key) {
 			continue
 		}
 
@@ -632,7 +729,7 @@ func (p *Port) doInstallFromDevCache(options InstallOptions) (bool, error) {
 		if _, err := port.Install(options); err != nil {
 			return false, err
 		}
-		visitedPorts[key] = true
+		visitedPorts.add(key)
 	}
 
 	// No repo means cannot computer meta.
@@ -658,20 +755,38 @@ func (p *Port) doInstallFromDevCache(options InstallOptions) (bool, error) {
 }
 
 func (p *Port) doInstallFromSource() error {
+	// Build of ports with source is resumable, stages are recorded with buildhash.
+	if p.MatchedConfig.BuildSystem != "nobuild" && p.Package.Url != "_" {
+		buildhash, err := p.buildhash()
+		if err != nil {
+			return fmt.Errorf("failed to calculate buildhash of %s -> %w", p.NameVersion(), err)
+		}
+		p.MatchedConfig.BuildHash = buildhash
+	}
+
 	var installFailed bool
 	defer func() {
-		// Remove package dir if install failed.
+		// Remove package dir if install failed, unless it's kept for resuming.
 		if installFailed {
+			if installed, _ := p.MatchedConfig.StageCompleted(buildsystems.StageInstall); installed {
+				return
+			}
 			if err := os.RemoveAll(p.PackageDir); err != nil {
 				fmt.Printf("remove broken package dir %s: %s\n", p.PackageDir, err)
 			}
 		}
 	}()
 
-	// Clean package directory.
-	if err := os.RemoveAll(p.PackageDir); err != nil {
-		installFailed = true
-		return fmt.Errorf("failed to clean package dir %s -> %w", p.PackageDir, err)
+	// Clean package directory, unless it's installed by last build that is resumed.
+	installed, err := p.MatchedConfig.StageCompleted(buildsystems.StageInstall)
+	if err != nil {
+		return err
+	}
+	if !installed {
+		if err := os.RemoveAll(p.PackageDir); err != nil {
+			installFailed = true
+			return fmt.Errorf("failed to clean package dir %s -> %w", p.PackageDir, err)
+		}
 	}
 
 	// Check if need to store pkgcache and remember the skip reason.
@@ -682,11 +797,18 @@ func (p *Port) doInstallFromSource() error {
 	p.pkgCacheStoreSkippedReason = skipReason
 
 	// Call matched buildsystem to configure, build and install.
-	if err := p.MatchedConfig.Install(p.Package.Url, p.Package.Ref, p.Package.Archive); err != nil {
+	err = p.MatchedConfig.Install(p.Package.Url, p.Package.Ref, p.Package.Archive)
+	p.installReport.recordStages(p, p.MatchedConfig.StageStats)
+	if err != nil {
 		installFailed = true
 		return err
 	}
 
+	// Packages with absolute workspace paths are not reusable on other machines.
+	if p.pkgCacheStoreSkippedReason == "" && p.MatchedConfig.NonRelocatable != "" {
+		p.pkgCacheStoreSkippedReason = p.MatchedConfig.NonRelocatable
+	}
+
 	// Generate meta file and store cache.
 	buildSystem := p.MatchedConfig.BuildSystem
 	if buildSystem != "nobuild" {
@@ -719,9 +841,11 @@ func (p *Port) doInstallFromSource() error {
 			if p.pkgCacheStoreSkippedReason == "" && !p.shouldSkipArtifactPkgCache() {
 				artifactCache := pkgCache.GetArtifactCache()
 				if artifactCache != nil {
-					if err := artifactCache.Store(p.MatchedConfig.PortConfig.PackageDir, metaData); err != nil {
+					archiveBytes, err := artifactCache.Store(p.MatchedConfig.PortConfig.PackageDir, metaData)
+					if err != nil {
 						return err
 					}
+					p.installReport.recordCacheStored(p, archiveBytes)
 				}
 			}
 		}
@@ -740,12 +864,12 @@ func (p *Port) doInstallFromSource() error {
 
 func (p Port) cloneAllRepos() error {
 	buildConfig := p.MatchedConfig
-	clonedPorts = map[string]bool{}
+	clonedPorts.reset()
 
 	for _, nameVersion := range buildConfig.DevDependencies {
 		// Skip Init() for already-cloned ports.
 		key := nameVersion + "[dev]"
-		if clonedPorts[key] {
+		if clonedPorts.has(key) {
 			continue
 		}
 
@@ -764,12 +888,12 @@ func (p Port) cloneAllRepos() error {
 				return err
 			}
 		}
-		clonedPorts[key] = true
+		clonedPorts.add(key)
 	}
 	for _, nameVersion := range buildConfig.Dependencies {
 		// Skip Init() for already-cloned ports.
 		key := nameVersion
-		if clonedPorts[key] {
+		if clonedPorts.has(key) {
 			continue
 		}
 
@@ -789,7 +913,7 @@ func (p Port) cloneAllRepos() error {
 				return err
 			}
 		}
-		clonedPorts[key] = true
+		clonedPorts.add(key)
 	}
 	if err := p.Clone(); err != nil {
 		return err
@@ -985,7 +1109,7 @@ func (p Port) installDependencies(options InstallOptions) error {
 
 		// Compute key early to skip Init() for already-processed ports.
 		key := expr.If(p.DevDep, nameVersion+"[dev]", nameVersion)
-		if _, alreadyProcessed := visitedPorts[key]; alreadyProcessed {
+		if visitedPorts.has(key) {
 			continue
 		}
 
@@ -1019,9 +1143,9 @@ func (p Port) installDependencies(options InstallOptions) error {
 				return err
 			}
 
-			visitedPorts[key] = true
+			visitedPorts.add(key)
 		} else {
-			visitedPorts[key] = true
+			visitedPorts.add(key)
 			if p.installReport != nil {
 				p.installReport.add(&port, "preinstalled")
 				if err := port.collectInstalledDepsForReport(); err != nil {
@@ -1043,7 +1167,7 @@ func (p Port) installDevDependencies(options InstallOptions) error {
 
 		// Compute key early to skip Init() for already-processed ports.
 		key := nameVersion + "[dev]"
-		if _, alreadyProcessed := visitedPorts[key]; alreadyProcessed {
+		if visitedPorts.has(key) {
 			continue
 		}
 
@@ -1078,9 +1202,9 @@ func (p Port) installDevDependencies(options InstallOptions) error {
 				return err
 			}
 
-			visitedPorts[key] = true
+			visitedPorts.add(key)
 		} else {
-			visitedPorts[key] = true
+			visitedPorts.add(key)
 			if p.installReport != nil {
 				p.installReport.add(&port, "preinstalled")
 				if err := port.collectInstalledDepsForReport(); err != nil {
@@ -1109,10 +1233,10 @@ func (p Port) collectInstalledDepsForReport() error {
 
 		// Skip Init() for already-visited ports.
 		key := nameVersion + "[dev]"
-		if p.installReport.visitedPorts[key] {
+		if p.installReport.visitedPorts.has(key) {
 			continue
 		}
-		p.installReport.visitedPorts[key] = true
+		p.installReport.visitedPorts.add(key)
 
 		port := Port{
 			DevDep:        true,
@@ -1139,10 +1263,10 @@ func (p Port) collectInstalledDepsForReport() error {
 
 		// Skip Init() for already-visited ports.
 		key := nameVersion
-		if p.installReport.visitedPorts[key] {
+		if p.installReport.visitedPorts.has(key) {
 			continue
 		}
-		p.installReport.visitedPorts[key] = true
+		p.installReport.visitedPorts.add(key)
 
 		port := Port{
 			DevDep:        p.DevDep,
@@ -1170,7 +1294,7 @@ func (p Port) prepareTmpDeps() error {
 		}
 
 		// Ignore duplicated.
-		if preparedTmpDeps[nameVersion+"[dev]"] {
+		if preparedTmpDeps.has(nameVersion + "[dev]") {
 			continue
 		}
 
@@ -1194,7 +1318,7 @@ func (p Port) prepareTmpDeps() error {
 		}
 
 		// Provider tmp deps recursively.
-		preparedTmpDeps[nameVersion+"[dev]"] = true
+		preparedTmpDeps.add(nameVersion + "[dev]")
 		if err := port.prepareTmpDeps(); err != nil {
 			return err
 		}
@@ -1210,7 +1334,7 @@ func (p Port) prepareTmpDeps() error {
 
 		// Ignore duplicated.
 		devSuffix := expr.If(p.DevDep || p.HostDep, "[dev]", "")
-		if preparedTmpDeps[nameVersion+devSuffix] {
+		if preparedTmpDeps.has(nameVersion + devSuffix) {
 			continue
 		}
 
@@ -1235,7 +1359,7 @@ func (p Port) prepareTmpDeps() error {
 
 		// Provider tmp deps recursively.
 		devSuffix = expr.If(p.DevDep || p.HostDep, "[dev]", "")
-		preparedTmpDeps[nameVersion+devSuffix] = true
+		preparedTmpDeps.add(nameVersion + devSuffix)
 		if err := port.prepareTmpDeps(); err != nil {
 			return err
 		}
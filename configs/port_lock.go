@@ -0,0 +1,79 @@
+package configs
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/filelock"
+)
+
+// workspaceLocks are file locks held by current process. Ports install their
+// dependencies recursively in one process, so a lock held by current process is re-entered.
+var workspaceLocks = struct {
+	mutex sync.Mutex
+	held  map[string]*heldLock
+}{held: make(map[string]*heldLock)}
+
+type heldLock struct {
+	lock  *filelock.Lock
+	count int
+}
+
+// lockWorkspace locks path for current process and returns the func to unlock it.
+func lockWorkspace(path string) (func(), error) {
+	workspaceLocks.mutex.Lock()
+	if held, ok := workspaceLocks.held[path]; ok {
+		held.count++
+		workspaceLocks.mutex.Unlock()
+		return func() { unlockWorkspace(path) }, nil
+	}
+	workspaceLocks.mutex.Unlock()
+
+	lock, err := filelock.Acquire(path)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaceLocks.mutex.Lock()
+	workspaceLocks.held[path] = &heldLock{lock: lock, count: 1}
+	workspaceLocks.mutex.Unlock()
+	return func() { unlockWorkspace(path) }, nil
+}
+
+func unlockWorkspace(path string) {
+	workspaceLocks.mutex.Lock()
+	defer workspaceLocks.mutex.Unlock()
+
+	held, ok := workspaceLocks.held[path]
+	if !ok {
+		return
+	}
+	held.count--
+	if held.count == 0 {
+		delete(workspaceLocks.held, path)
+		held.lock.Release()
+	}
+}
+
+// lockPort locks package and installed files of port, they're kept per platform/project/build type,
+// so the lock is keyed the same as package dir, like "packages/<platform>/<project>/<build type>/<name@version>".
+func (p Port) lockPort() (func(), error) {
+	packageDir, err := filepath.Rel(dirs.PackagesDir, p.PackageDir)
+	if err != nil || !filepath.IsLocal(packageDir) {
+		return nil, fmt.Errorf("package dir %s is not in %s", p.PackageDir, dirs.PackagesDir)
+	}
+	return lockWorkspace(filepath.Join(dirs.LocksDir, "packages", packageDir+".lock"))
+}
+
+// lockSource locks source of port in buildtrees, it's shared by all platforms, projects and build types.
+func (p Port) lockSource() (func(), error) {
+	return lockWorkspace(filepath.Join(dirs.LocksDir, "sources", p.NameVersion()+".lock"))
+}
+
+// lockTmpDeps locks tmp/deps, it's cleaned and filled by every build from source.
+// Workers of the install scheduler own their tmp dirs, so they never wait for each other.
+func lockTmpDeps() (func(), error) {
+	return lockWorkspace(filepath.Join(dirs.TmpDir, "deps.lock"))
+}
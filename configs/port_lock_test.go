@@ -0,0 +1,49 @@
+package configs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+func TestPort_LockPort(t *testing.T) {
+	setupTestWorkspace(t, nil)
+
+	// Same port of two projects sharing one platform.
+	newPort := func(project string) Port {
+		return Port{
+			Name:       "zlib",
+			Version:    "1.3.1",
+			PackageDir: filepath.Join(dirs.PackagesDir, "x86_64-linux", project, "release", "zlib@1.3.1"),
+		}
+	}
+	for _, project := range []string{"project_a", "project_b"} {
+		unlock, err := newPort(project).lockPort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unlock()
+
+		lockFile := filepath.Join(dirs.LocksDir, "packages", "x86_64-linux", project, "release", "zlib@1.3.1.lock")
+		if !fileio.PathExists(lockFile) {
+			t.Errorf("port of %s should be locked with %s", project, lockFile)
+		}
+	}
+
+	// Source is shared by both projects.
+	unlock, err := newPort("project_a").lockSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	if lockFile := filepath.Join(dirs.LocksDir, "sources", "zlib@1.3.1.lock"); !fileio.PathExists(lockFile) {
+		t.Errorf("source should be locked with %s", lockFile)
+	}
+
+	// Package dir out of workspace is never locked.
+	if _, err := (Port{Name: "zlib", Version: "1.3.1"}).lockPort(); err == nil {
+		t.Error("port without package dir should fail to lock")
+	}
+}
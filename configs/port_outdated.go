@@ -0,0 +1,441 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/git"
+	"github.com/celer-pkg/celer/pkgs/version"
+)
+
+const (
+	UpdateSourceTags    = "tags"
+	UpdateSourceArchive = "archive"
+)
+
+var (
+	semverLikeRegex  = regexp.MustCompile(`^[0-9]+([._][0-9]+)+$`)
+	githubRepoRegex  = regexp.MustCompile(`^(https://github\.com/[^/]+/[^/]+)/(archive|releases/download)/`)
+	packageLineRegex = regexp.MustCompile(`^(\s*)(url|ref|checksum)(\s*=\s*)("[^"]*"|'[^']*')(.*)$`)
+)
+
+// OutdatedPort describes newer upstream versions of a port.
+type OutdatedPort struct {
+	Port      string   `json:"port"`
+	Current   string   `json:"current"`
+	Latest    string   `json:"latest,omitempty"`
+	LatestRef string   `json:"latest_ref,omitempty"`
+	Newer     []string `json:"newer,omitempty"`
+	Source    string   `json:"source,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// PortUpdate is the result of creating a port like an existing one.
+type PortUpdate struct {
+	PortFile      string
+	Url           string
+	Ref           string
+	Checksum      string
+	BrokenPatches []string
+}
+
+// OutdatedPortNames returns ports to check, they're the ports of current project if
+// project is true, otherwise the latest version of every port in ports dir.
+func (c *Celer) OutdatedPortNames(project bool) ([]string, error) {
+	if project {
+		var nameVersions []string
+		for _, nameVersion := range c.Project().GetPorts() {
+			nameVersion, _ := SplitFeatures(nameVersion)
+			name, spec, ok := strings.Cut(nameVersion, "@")
+			if !ok {
+				return nil, fmt.Errorf("port %q should be like name@version", nameVersion)
+			}
+			if version.IsConstraint(spec) {
+				resolved, err := c.resolveSpec(name, spec)
+				if err != nil {
+					return nil, err
+				}
+				spec = resolved
+			}
+			nameVersions = append(nameVersions, name+"@"+spec)
+		}
+		return nameVersions, nil
+	}
+
+	latest := make(map[string]string)
+	for _, registry := range c.Registries() {
+		portFiles, err := filepath.Glob(filepath.Join(registry.Dir, "*", "*", "*", "port.toml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, portFile := range portFiles {
+			versionDir := filepath.Dir(portFile)
+			name, portVersion := filepath.Base(filepath.Dir(versionDir)), filepath.Base(versionDir)
+			if !registry.Provides(name) {
+				continue
+			}
+			if current, ok := latest[name]; !ok || version.Compare(portVersion, current) > 0 {
+				latest[name] = portVersion
+			}
+		}
+	}
+
+	var nameVersions []string
+	for name, portVersion := range latest {
+		nameVersions = append(nameVersions, name+"@"+portVersion)
+	}
+	slices.Sort(nameVersions)
+	return nameVersions, nil
+}
+
+// resolveSpec resolves version constraint to the highest available version.
+func (c *Celer) resolveSpec(name, spec string) (string, error) {
+	constraint, err := version.ParseConstraint(spec)
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range availableVersions(c, name) {
+		if constraint.Check(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no version of %s satisfies %s", name, spec)
+}
+
+// CheckOutdated checks newer upstream versions of ports, failure of one port
+// is recorded in its Error instead of aborting others.
+func (c *Celer) CheckOutdated(nameVersions []string) []OutdatedPort {
+	var outdated []OutdatedPort
+	for _, nameVersion := range nameVersions {
+		result := OutdatedPort{Port: nameVersion}
+		if err := c.checkOutdated(nameVersion, &result); err != nil {
+			result.Error = err.Error()
+		}
+		outdated = append(outdated, result)
+	}
+	return outdated
+}
+
+func (c *Celer) checkOutdated(nameVersion string, result *OutdatedPort) error {
+	name, current, ok := strings.Cut(nameVersion, "@")
+	if !ok || name == "" || current == "" {
+		return fmt.Errorf("port name and version are invalid %s", nameVersion)
+	}
+	result.Current = current
+
+	if c.Offline() {
+		return fmt.Errorf("offline mode forbids checking upstream")
+	}
+
+	portFile, err := Port{ctx: c}.resolveProjectPort(c.Project().GetName(), name, current)
+	if err != nil {
+		return err
+	}
+	var port Port
+	if _, err := toml.DecodeFile(portFile, &port); err != nil {
+		return fmt.Errorf("failed to decode %s -> %w", portFile, err)
+	}
+
+	// Collect candidate versions mapping to their refs.
+	var candidates map[string]string
+	switch repoUrl := port.Package.Url; {
+	case repoUrl == "" || repoUrl == "_":
+		return fmt.Errorf("port has no upstream url")
+
+	case strings.HasSuffix(repoUrl, ".git"):
+		result.Source = UpdateSourceTags
+		if candidates, err = remoteTagVersions(repoUrl); err != nil {
+			return err
+		}
+
+	case port.Package.UpdateUrl == "" && port.Package.UpdatePattern == "" && githubRepoRegex.MatchString(repoUrl):
+		// Releases on github are always tagged, it's cheaper than reading release pages.
+		result.Source = UpdateSourceTags
+		repoUrl = githubRepoRegex.FindStringSubmatch(repoUrl)[1] + ".git"
+		if candidates, err = remoteTagVersions(repoUrl); err != nil {
+			return err
+		}
+
+	default:
+		result.Source = UpdateSourceArchive
+		if candidates, err = c.archiveVersions(port.Package, current); err != nil {
+			return err
+		}
+	}
+
+	for candidate := range candidates {
+		if version.Compare(candidate, current) > 0 {
+			result.Newer = append(result.Newer, candidate)
+		}
+	}
+	slices.SortFunc(result.Newer, func(a, b string) int {
+		return version.Compare(b, a)
+	})
+	if len(result.Newer) > 0 {
+		result.Latest = result.Newer[0]
+		result.LatestRef = candidates[result.Latest]
+	}
+	return nil
+}
+
+// remoteTagVersions returns semver-like versions of remote tags, mapping to their tags.
+func remoteTagVersions(repoUrl string) (map[string]string, error) {
+	tags, err := git.ListRemoteTags(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, tag := range tags {
+		if tagVersion := tagVersion(tag); tagVersion != "" {
+			versions[tagVersion] = tag
+		}
+	}
+	return versions, nil
+}
+
+// tagVersion extracts semver-like version from tag, such as "v1.2.3", "zlib-1.3.1"
+// and "curl-8_5_0", it returns empty for tags like "nightly" and "1.0-rc1".
+func tagVersion(tag string) string {
+	index := strings.IndexFunc(tag, func(r rune) bool { return r >= '0' && r <= '9' })
+	if index < 0 {
+		return ""
+	}
+
+	// Prefix is expected to be like "v", "release-" or "name-", without digits.
+	prefix, remain := tag[:index], tag[index:]
+	if prefix != "" && !strings.HasSuffix(prefix, "v") && !strings.HasSuffix(prefix, "V") &&
+		!strings.HasSuffix(prefix, "-") && !strings.HasSuffix(prefix, "_") {
+		return ""
+	}
+	if !semverLikeRegex.MatchString(remain) {
+		return ""
+	}
+	return strings.ReplaceAll(remain, "_", ".")
+}
+
+// archiveVersions reads update_url, which is the parent dir of url by default, and
+// finds versions by update_pattern, which is derived from archive name by default.
+func (c *Celer) archiveVersions(pkg Package, current string) (map[string]string, error) {
+	pageUrl := pkg.UpdateUrl
+	if pageUrl == "" {
+		pageUrl = pkg.Url[:strings.LastIndex(pkg.Url, "/")+1]
+	}
+
+	pattern := pkg.UpdatePattern
+	if pattern == "" {
+		archive := expr.If(pkg.Archive != "", pkg.Archive, filepath.Base(pkg.Url))
+		if !strings.Contains(archive, current) {
+			return nil, fmt.Errorf("version is not found in archive name %s, please set update_pattern", archive)
+		}
+		parts := strings.Split(archive, current)
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		pattern = strings.Join(parts, `([0-9]+(?:[._][0-9]+)+)`)
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("update_pattern is invalid -> %w", err)
+	}
+	if regex.NumSubexp() < 1 {
+		return nil, fmt.Errorf("update_pattern should capture version with a group")
+	}
+
+	host, port := c.ProxyHostPort()
+	content, err := fileio.ReadRemoteText(host, port, pageUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, match := range regex.FindAllStringSubmatch(content, -1) {
+		if semverLikeRegex.MatchString(match[1]) {
+			versions[strings.ReplaceAll(match[1], "_", ".")] = match[1]
+		}
+	}
+	return versions, nil
+}
+
+// CreatePortLike creates nameVersion by copying port dir of likeNameVersion, url, ref
+// and checksum are updated for new version, and patches that no longer apply are reported.
+func (c *Celer) CreatePortLike(nameVersion, likeNameVersion string) (*PortUpdate, error) {
+	name, newVersion, ok := strings.Cut(nameVersion, "@")
+	if !ok || name == "" || newVersion == "" {
+		return nil, fmt.Errorf("invalid port name version")
+	}
+	likeName, oldVersion, ok := strings.Cut(likeNameVersion, "@")
+	if !ok || likeName == "" || oldVersion == "" {
+		return nil, fmt.Errorf("invalid port name version %s", likeNameVersion)
+	}
+
+	oldPortFile, err := Port{ctx: c}.resolveProjectPort(c.Project().GetName(), likeName, oldVersion)
+	if err != nil {
+		return nil, err
+	}
+	var oldPort Port
+	if _, err := toml.DecodeFile(oldPortFile, &oldPort); err != nil {
+		return nil, fmt.Errorf("failed to decode %s -> %w", oldPortFile, err)
+	}
+
+	// New port is placed beside the old one, unless it's in registries or global ports.
+	oldPortDir := filepath.Dir(oldPortFile)
+	newPortDir := filepath.Join(filepath.Dir(filepath.Dir(oldPortDir)), name, newVersion)
+	if registry, ok := c.Registries().RegistryOf(oldPortFile); ok {
+		newPortDir = registry.PortDir(name, newVersion)
+	}
+	newPortFile := filepath.Join(newPortDir, "port.toml")
+	if fileio.PathExists(newPortDir) {
+		return nil, fmt.Errorf("%s is already exists", newPortDir)
+	}
+
+	update := PortUpdate{
+		PortFile: newPortFile,
+		Url:      replaceVersion(oldPort.Package.Url, oldVersion, newVersion),
+		Ref:      replaceVersion(oldPort.Package.Ref, oldVersion, newVersion),
+	}
+
+	// Fetch new source to compute checksum and check patches.
+	if err := os.MkdirAll(dirs.TmpDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	srcDir, err := os.MkdirTemp(dirs.TmpDir, "update-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir -> %w", err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	tags := []string{newVersion, "v" + newVersion}
+	if update.Ref != oldPort.Package.Ref {
+		tags = slices.Insert(tags, 0, update.Ref)
+	}
+	ref, checksum, err := c.fetchPortSource(nameVersion, update.Url, tags, srcDir)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(update.Url, ".git") {
+		update.Ref = ref
+
+		// Keep pinning to commit if the old port pins.
+		if oldPort.Package.Checksum != "" {
+			if update.Checksum, err = git.GetCommitHash(srcDir); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		update.Checksum = checksum
+	}
+
+	// Copy port dir and update package of port.toml, other contents are kept as they are.
+	if err := fileio.CopyDir(oldPortDir, newPortDir); err != nil {
+		return nil, fmt.Errorf("failed to copy %s -> %w", oldPortDir, err)
+	}
+	if err := update.rewritePackage(newPortFile); err != nil {
+		return nil, err
+	}
+
+	// Patches are checked against a git repo, archive source is tracked as local repo.
+	if !fileio.PathExists(filepath.Join(srcDir, ".git")) {
+		if err := git.InitAsLocalRepo(srcDir, `"init for checking patches"`); err != nil {
+			return nil, err
+		}
+	}
+	var patches []string
+	target := c.buildTarget()
+	for _, buildConfig := range oldPort.BuildConfigs {
+		for _, patch := range buildConfig.PatchesOf(target) {
+			if !slices.Contains(patches, patch) {
+				patches = append(patches, patch)
+			}
+		}
+	}
+	for _, patch := range patches {
+		if err := git.CheckPatch(nameVersion, srcDir, filepath.Join(newPortDir, patch)); err != nil {
+			color.PrintWarning("%s", err)
+			update.BrokenPatches = append(update.BrokenPatches, patch)
+		}
+	}
+
+	return &update, nil
+}
+
+// buildTarget returns system that ports are built for, patches of the system are checked.
+func (c *Celer) buildTarget() string {
+	if toolchain := c.platform.Toolchain; toolchain != nil {
+		systemName := strings.ToLower(toolchain.GetSystemName())
+		if slices.Contains([]string{"windows", "linux", "darwin"}, systemName) {
+			return systemName
+		}
+	}
+	return runtime.GOOS
+}
+
+// rewritePackage updates url, ref and checksum of [package] in place, to keep comments
+// and layout of the copied port.toml.
+func (u PortUpdate) rewritePackage(portFile string) error {
+	content, err := os.ReadFile(portFile)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{"url": u.Url, "ref": u.Ref, "checksum": u.Checksum}
+	var (
+		lines     = strings.Split(string(content), "\n")
+		inPackage bool
+		refLine   = -1
+		checksum  bool
+	)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inPackage = trimmed == "[package]"
+			continue
+		}
+		if !inPackage {
+			continue
+		}
+
+		match := packageLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		switch match[2] {
+		case "ref":
+			refLine = i
+		case "checksum":
+			checksum = true
+		}
+		lines[i] = match[1] + match[2] + match[3] + strconv.Quote(values[match[2]]) + match[5]
+	}
+
+	// Old port may have no checksum, but archive should have one.
+	if !checksum && u.Checksum != "" && refLine >= 0 {
+		lines = slices.Insert(lines, refLine+1, "checksum = "+strconv.Quote(u.Checksum))
+	}
+
+	return os.WriteFile(portFile, []byte(strings.Join(lines, "\n")), os.ModePerm)
+}
+
+// replaceVersion replaces version in text, versions separated by underscore are replaced as well,
+// for example, "boost_1_84_0" in url.
+func replaceVersion(text, oldVersion, newVersion string) string {
+	text = strings.ReplaceAll(text, oldVersion, newVersion)
+	if strings.Contains(oldVersion, ".") {
+		text = strings.ReplaceAll(text,
+			strings.ReplaceAll(oldVersion, ".", "_"),
+			strings.ReplaceAll(newVersion, ".", "_"))
+	}
+	return text
+}
@@ -0,0 +1,163 @@
+package configs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+func TestTagVersion(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"v1.2.3":         "1.2.3",
+		"1.3":            "1.3",
+		"zlib-1.3.1":     "1.3.1",
+		"curl-8_5_0":     "8.5.0",
+		"release-2.0.0":  "2.0.0",
+		"nightly":        "",
+		"1.0-rc1":        "",
+		"v2":             "",
+		"x264-snapshot1": "",
+	} {
+		if actual := tagVersion(tag); actual != expected {
+			t.Errorf("tag %q should be %q, but got %q", tag, expected, actual)
+		}
+	}
+}
+
+func TestCheckOutdated_Archive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<a href="foo-1.0.0.tar.gz">foo-1.0.0.tar.gz</a>
+<a href="foo-1.2.0.tar.gz">foo-1.2.0.tar.gz</a>
+<a href="foo-1.10.0.tar.gz">foo-1.10.0.tar.gz</a>
+<a href="foo-0.9.tar.gz">foo-0.9.tar.gz</a>
+<a href="foo-2.0-beta.tar.gz">foo-2.0-beta.tar.gz</a>`))
+	}))
+	defer server.Close()
+
+	setupTestWorkspace(t, map[string]string{
+		"ports/f/foo/1.0.0/port.toml": "[package]\nurl = \"" + server.URL + "/files/foo-1.0.0.tar.gz\"\nref = \"1.0.0\"\n",
+		"ports/f/foo/0.9/port.toml":   "[package]\nurl = \"" + server.URL + "/files/foo-0.9.tar.gz\"\nref = \"0.9\"\n",
+		"ports/b/bar/1.0.0/port.toml": "[package]\nurl = \"" + server.URL + "/files/bar.tar.gz\"\nref = \"1.0.0\"\n",
+	})
+
+	celer := &Celer{}
+	nameVersions, err := celer.OutdatedPortNames(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(nameVersions, []string{"bar@1.0.0", "foo@1.0.0"}) {
+		t.Fatalf("latest version of each port should be checked, but got %v", nameVersions)
+	}
+
+	outdated := celer.CheckOutdated(nameVersions)
+	if !strings.Contains(outdated[0].Error, "please set update_pattern") {
+		t.Errorf("archive name without version should require update_pattern, but got %+v", outdated[0])
+	}
+	foo := outdated[1]
+	if foo.Error != "" || foo.Source != UpdateSourceArchive || foo.Latest != "1.10.0" ||
+		!slices.Equal(foo.Newer, []string{"1.10.0", "1.2.0"}) {
+		t.Errorf("unexpected outdated result: %+v", foo)
+	}
+}
+
+func TestCreatePortLike(t *testing.T) {
+	// Prepare archive of new version, which has changed the line patched by broken.patch.
+	sourceDir := filepath.Join(t.TempDir(), "foo-1.1.0")
+	if err := os.MkdirAll(sourceDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.txt"), []byte("hello\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "b.txt"), []byte("changed upstream\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "foo-1.1.0.tar.gz")
+	if err := fileio.Targz(archive, sourceDir, true); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir(archive))))
+	defer server.Close()
+
+	setupTestWorkspace(t, map[string]string{
+		"ports/f/foo/1.0.0/port.toml": `[package]
+url = "` + server.URL + `/foo-1.0.0.tar.gz" # upstream release
+ref = "1.0.0"
+
+[[build_configs]]
+build_system = "cmake"
+patches = ["good.patch", "broken.patch"]
+
+[[build_configs]]
+build_system = "cmake"
+patches = ["good.patch"]
+patches_` + runtime.GOOS + ` = ["good.patch", "os.patch"]
+`,
+		"ports/f/foo/1.0.0/good.patch": `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-hello
++hello world
+`,
+		"ports/f/foo/1.0.0/os.patch": `diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -1 +1 @@
+-original
++patched for os
+`,
+		"ports/f/foo/1.0.0/broken.patch": `diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -1 +1 @@
+-original
++patched
+`,
+	})
+
+	celer := &Celer{}
+	update, err := celer.CreatePortLike("foo@1.1.0", "foo@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(update.BrokenPatches, []string{"broken.patch", "os.patch"}) {
+		t.Errorf("broken.patch and os.patch of current system should be reported, but got %v", update.BrokenPatches)
+	}
+
+	checksum, err := fileio.SHA256Sum(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(dirs.GetPortPath("foo", "1.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `/foo-1.1.0.tar.gz" # upstream release`) {
+		t.Errorf("url should be updated in place, but got:\n%s", content)
+	}
+
+	var port Port
+	if _, err := toml.Decode(string(content), &port); err != nil {
+		t.Fatal(err)
+	}
+	if port.Package.Ref != "1.1.0" || port.Package.Checksum != checksum || len(port.BuildConfigs[0].Patches) != 2 {
+		t.Errorf("unexpected port: %+v", port)
+	}
+	if !fileio.PathExists(filepath.Join(dirs.GetPortDir("foo", "1.1.0"), "good.patch")) {
+		t.Errorf("patches should be copied")
+	}
+
+	if _, err := celer.CreatePortLike("foo@1.1.0", "foo@1.0.0"); err == nil {
+		t.Errorf("existing port should not be overwritten")
+	}
+}
@@ -0,0 +1,151 @@
+package configs
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// FileOwner is an installed port that owns a file.
+type FileOwner struct {
+	NameVersion string
+	LibraryDir  string // Like "x86_64-linux-dev" or "x86_64-linux/project/release".
+	traceFile   string
+}
+
+func (f FileOwner) String() string {
+	return fmt.Sprintf("%s (%s)", f.NameVersion, filepath.ToSlash(f.LibraryDir))
+}
+
+// FileOwners indexes installed files by ports that installed them, key of the index is
+// the line in trace file, which is relative to installed dir, like "x86_64-linux/project/release/include/zlib.h".
+type FileOwners map[string][]FileOwner
+
+// LoadFileOwners builds the index from trace files in installed/celer/traces,
+// since they record Port.PackageFiles of every installed port.
+func LoadFileOwners() (FileOwners, error) {
+	owners := make(FileOwners)
+
+	tracesDir := filepath.Join(dirs.InstalledDir, "celer", "traces")
+	if !fileio.PathExists(tracesDir) {
+		return owners, nil
+	}
+
+	if err := filepath.WalkDir(tracesDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".trace") {
+			return nil
+		}
+
+		libraryDir, err := filepath.Rel(tracesDir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		owner := FileOwner{
+			NameVersion: strings.TrimSuffix(entry.Name(), ".trace"),
+			LibraryDir:  libraryDir,
+			traceFile:   path,
+		}
+		return owners.add(owner)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to load trace files -> %w", err)
+	}
+
+	return owners, nil
+}
+
+func (f FileOwners) add(owner FileOwner) error {
+	file, err := os.Open(owner.traceFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasSuffix(line, ".meta") {
+			continue
+		}
+		f[line] = append(f[line], owner)
+	}
+	return scanner.Err()
+}
+
+// Owners returns ports that own the file, file is a line of trace file.
+func (f FileOwners) Owners(file string) []FileOwner {
+	return f[filepath.Clean(file)]
+}
+
+// Search returns installed files that equal to path or end with it.
+func (f FileOwners) Search(path string) []string {
+	path = filepath.Clean(path)
+	if _, ok := f[path]; ok {
+		return []string{path}
+	}
+
+	var files []string
+	suffix := string(os.PathSeparator) + path
+	for file := range f {
+		if strings.HasSuffix(file, suffix) {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// othersOf returns owners of file except the port itself.
+func (f FileOwners) othersOf(file, traceFile string) []FileOwner {
+	var others []FileOwner
+	for _, owner := range f.Owners(file) {
+		if owner.traceFile != traceFile {
+			others = append(others, owner)
+		}
+	}
+	return others
+}
+
+// checkFileCollisions checks whether files of port would overwrite files installed by other ports.
+// Overwriting them silently breaks the other port, and removing any of them later deletes files
+// the other still owns, so it fails unless feature `allow_file_collisions` is enabled.
+func (p Port) checkFileCollisions(files []string) error {
+	owners, err := LoadFileOwners()
+	if err != nil {
+		return err
+	}
+
+	var collisions []string
+	for _, file := range files {
+		if strings.HasSuffix(file, ".meta") {
+			continue
+		}
+		for _, owner := range owners.othersOf(file, p.traceFile) {
+			collisions = append(collisions, fmt.Sprintf("%s is owned by %s", filepath.ToSlash(file), owner.NameVersion))
+		}
+	}
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	features := p.ctx.Features()
+	if features != nil && features.ShouldAllowFileCollisions() {
+		color.PrintWarning("%s overwrites files installed by other ports:\n  - %s",
+			p.NameVersion(), strings.Join(collisions, "\n  - "))
+		return nil
+	}
+
+	return fmt.Errorf("%s would overwrite files installed by other ports:\n  - %s\n"+
+		"please remove the conflicting port first, or enable `allow_file_collisions` in [features] of celer.toml",
+		p.NameVersion(), strings.Join(collisions, "\n  - "))
+}
@@ -0,0 +1,88 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+func writeTestTrace(t *testing.T, libraryDir, nameVersion string, files ...string) string {
+	t.Helper()
+
+	traceFile := filepath.Join(dirs.InstalledDir, "celer", "traces", libraryDir, nameVersion+".trace")
+	if err := os.MkdirAll(filepath.Dir(traceFile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for index, file := range files {
+		files[index] = filepath.Join(libraryDir, file)
+	}
+	if err := os.WriteFile(traceFile, []byte(strings.Join(files, "\n")), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	return traceFile
+}
+
+func TestFileOwners_Load(t *testing.T) {
+	dirs.Init(t.TempDir())
+
+	libraryDir := filepath.Join("x86_64-linux", "project_001", "release")
+	writeTestTrace(t, libraryDir, "zlib@1.3.1", "include/zlib.h", "lib/libz.a", "zlib@1.3.1.meta")
+	writeTestTrace(t, libraryDir, "minizip@1.3.1", "include/zlib.h", "include/unzip.h")
+	writeTestTrace(t, "x86_64-linux-dev", "nasm@2.16.03", "bin/nasm")
+
+	owners, err := LoadFileOwners()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nameVersions []string
+	for _, owner := range owners.Owners(filepath.Join(libraryDir, "include", "zlib.h")) {
+		nameVersions = append(nameVersions, owner.NameVersion)
+	}
+	slices.Sort(nameVersions)
+	if !slices.Equal(nameVersions, []string{"minizip@1.3.1", "zlib@1.3.1"}) {
+		t.Fatalf("include/zlib.h should be owned by minizip and zlib, but got %v", nameVersions)
+	}
+
+	if len(owners.Owners(filepath.Join(libraryDir, "zlib@1.3.1.meta"))) != 0 {
+		t.Fatal("meta file should not be indexed")
+	}
+
+	found := owners.Search("nasm")
+	if !slices.Equal(found, []string{filepath.Join("x86_64-linux-dev", "bin", "nasm")}) {
+		t.Fatalf("nasm should be found in dev dir, but got %v", found)
+	}
+}
+
+func TestFileOwners_Collision(t *testing.T) {
+	dirs.Init(t.TempDir())
+
+	ctx := fakeContext{platform: "x86_64-linux", project: "project_001", build: "release"}
+	libraryDir := filepath.Join("x86_64-linux", "project_001", "release")
+	writeTestTrace(t, libraryDir, "zlib@1.3.1", "include/zlib.h", "lib/libz.a")
+	selfTrace := writeTestTrace(t, libraryDir, "minizip@1.3.1", "include/unzip.h")
+
+	port := Port{ctx: ctx, traceFile: selfTrace, Name: "minizip", Version: "1.3.1"}
+
+	// Files installed by port itself before are not collisions.
+	if err := port.checkFileCollisions([]string{filepath.Join(libraryDir, "include", "unzip.h")}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := port.checkFileCollisions([]string{
+		filepath.Join(libraryDir, "include", "unzip.h"),
+		filepath.Join(libraryDir, "include", "zlib.h"),
+	})
+	if err == nil {
+		t.Fatal("overwriting include/zlib.h of zlib should be reported.")
+	}
+	for _, expected := range []string{"minizip@1.3.1", "zlib@1.3.1", "include/zlib.h"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("error should contain %q, but got:\n%s", expected, err)
+		}
+	}
+}
@@ -60,6 +60,9 @@ func ResetMetaCache() {
 		return true
 	})
 
+	// Versions solved for project depend on port.toml files as well.
+	resetSolvedVersions()
+
 	// Also clear the pkgcache-level buildMeta cache (the recursive one inside
 	// metadata.go that caches per nameVersion|native).
 	meta.ResetMetaCache()
@@ -74,6 +77,11 @@ func (p Port) buildhash() (string, error) {
 	return p.meta2hash(metaData), nil
 }
 
+// BuildMeta returns the meta that buildhash is computed from, it's used to explain cache misses.
+func (p Port) BuildMeta() (string, error) {
+	return p.buildMeta()
+}
+
 func (p Port) meta2hash(metaData string) string {
 	checksum := sha256.Sum256([]byte(metaData))
 	return fmt.Sprintf("%x", checksum)
@@ -95,7 +103,7 @@ func (p Port) buildMeta() (string, error) {
 		Project:     p.ctx.Project().GetName(),
 		DevDep:      p.DevDep,
 		HostDev:     p.HostDep,
-		BuildConfig: p.toPkgCacheBuildConfig(p.MatchedConfig, p.portFile),
+		BuildConfig: p.toPkgCacheBuildConfig(p.MatchedConfig, p.portFile, p.SelectedFeatures),
 		Callbacks:   p,
 	}
 
@@ -173,6 +181,18 @@ func (p Port) GenPortTomlString(nameVersion string, devDep bool) (string, error)
 	port.Package.Checksum = ""
 	port.Package.Depth = 0
 
+	// Identifiers for sbom and audit never affect the build.
+	port.Package.License = ""
+	port.Package.CPE = ""
+	port.Package.Purl = ""
+
+	// Fields of checking updates are only used by `celer outdated`.
+	port.Package.UpdateUrl = ""
+	port.Package.UpdatePattern = ""
+
+	// Selected features are merged into matched config already, and others never affect the build.
+	port.Features = nil
+
 	// Only export the matched build config for current platform.
 	bytes, err := toml.Marshal(port)
 	if err != nil {
@@ -270,7 +290,7 @@ func (p Port) GetBuildConfig(nameVersion string, native bool) (*meta.BuildConfig
 		return nil, err
 	}
 
-	config := p.toPkgCacheBuildConfig(port.MatchedConfig, port.portFile)
+	config := p.toPkgCacheBuildConfig(port.MatchedConfig, port.portFile, port.SelectedFeatures)
 	buildConfigCache.Store(key, &config)
 	return &config, nil
 }
@@ -291,12 +311,13 @@ func (p Port) CheckHostSupported(nameVersion string) bool {
 	return supported
 }
 
-func (p Port) toPkgCacheBuildConfig(buildConfig *buildsystems.BuildConfig, portFile string) meta.BuildConfig {
+func (p Port) toPkgCacheBuildConfig(buildConfig *buildsystems.BuildConfig, portFile string, features []string) meta.BuildConfig {
 	return meta.BuildConfig{
 		Patches:         append([]string{}, buildConfig.Patches...),
 		Dependencies:    append([]string{}, buildConfig.Dependencies...),
 		DevDependencies: append([]string{}, buildConfig.DevDependencies...),
 		BuildTools:      buildConfig.CheckTools(),
+		Features:        append([]string{}, features...),
 		PortFile:        portFile,
 	}
 }
@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenPortTomlString_IgnoreUpdateFields(t *testing.T) {
+	const portToml = `[package]
+url = "https://example.com/foo-1.0.0.tar.gz"
+ref = "1.0.0"
+
+[[build_configs]]
+build_system = "cmake"
+`
+	workspaceDir := setupTestWorkspace(t, map[string]string{
+		"ports/f/foo/1.0.0/port.toml": portToml,
+	})
+	t.Cleanup(ResetMetaCache)
+
+	ctx := featureContext{
+		fakeContext: fakeContext{platform: "x86_64-linux", project: "buildhash", build: "release"},
+	}
+	caller := Port{ctx: ctx, Package: Package{Checksum: "0123456789abcdef"}}
+
+	ResetMetaCache()
+	expected, err := caller.GenPortTomlString("foo@1.0.0", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields of checking updates never affect the build.
+	portFile := filepath.Join(workspaceDir, "ports/f/foo/1.0.0/port.toml")
+	updated := strings.Replace(portToml, `ref = "1.0.0"`, `ref = "1.0.0"
+update_url = "https://example.com/releases"
+update_pattern = "foo-(\\d+\\.\\d+\\.\\d+)\\.tar\\.gz"`, 1)
+	if err := os.WriteFile(portFile, []byte(updated), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ResetMetaCache()
+	actual, err := caller.GenPortTomlString("foo@1.0.0", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != expected {
+		t.Errorf("buildhash should not change with update_url and update_pattern, got:\n%s\nwant:\n%s", actual, expected)
+	}
+}
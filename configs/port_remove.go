@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/celer-pkg/celer/buildsystems"
 	"github.com/celer-pkg/celer/pkgs/color"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/expr"
@@ -39,7 +40,7 @@ func (p Port) Remove(options RemoveOptions) error {
 			// top-level command. Cascade-removing them here would force a
 			// rebuild later when prepareTmpDeps needs the package — every
 			// shared dep would otherwise be rebuilt once per parent.
-			if _, alreadyVisited := visitedPorts[port.visitedKey()]; alreadyVisited {
+			if visitedPorts.has(port.visitedKey()) {
 				return nil
 			}
 
@@ -86,6 +87,9 @@ func (p Port) Remove(options RemoveOptions) error {
 		if err := p.RemoveLogs(); err != nil {
 			return fmt.Errorf("failed to remove logs -> %w", err)
 		}
+		if err := buildsystems.RemoveCheckpoint(matchedConfig.PortConfig.BuildDir); err != nil {
+			return fmt.Errorf("failed to remove build checkpoint -> %w", err)
+		}
 	}
 
 	return nil
@@ -107,6 +111,13 @@ func (p Port) doRemovePort() error {
 		return nil
 	}
 
+	// Files installed by other ports as well should be kept.
+	owners, err := LoadFileOwners()
+	if err != nil {
+		noError = false
+		return err
+	}
+
 	// Open install info file.
 	file, err := os.OpenFile(p.traceFile, os.O_RDONLY, os.ModePerm)
 	if err != nil {
@@ -125,6 +136,10 @@ func (p Port) doRemovePort() error {
 		if !fileio.PathExists(line) {
 			fileToRemove = filepath.Join(dirs.WorkspaceDir, "installed", line)
 		}
+		if others := owners.othersOf(line, p.traceFile); len(others) > 0 {
+			fmt.Printf("-- keep: %s, it's still owned by %s\n", fileToRemove, others[0].NameVersion)
+			continue
+		}
 		if err := p.removeFiles(fileToRemove); err != nil {
 			noError = false
 			return fmt.Errorf("cannot remove file -> %w", err)
@@ -161,6 +176,10 @@ func (p Port) doRemovePort() error {
 		noError = false
 		return fmt.Errorf("cannot remove info file: %s", err)
 	}
+	if err := os.Remove(p.installedFromFile()); err != nil && !os.IsNotExist(err) {
+		noError = false
+		return fmt.Errorf("cannot remove installed from file: %s", err)
+	}
 	traceDir := filepath.Join(dirs.WorkspaceDir, "installed", "celer", "traces", libraryDir)
 	if err := fileio.RemoveFolderRecursively(traceDir); err != nil {
 		noError = false
@@ -0,0 +1,392 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/errors"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/version"
+)
+
+// maxSolveRounds bounds how many times the dependency graph is walked again
+// after a chosen version is replaced.
+const maxSolveRounds = 32
+
+// solvedVersions caches versions solved for the dependency graph of a project.
+var solvedVersions = struct {
+	mutex   sync.Mutex
+	results map[string]solveResult
+}{results: make(map[string]solveResult)}
+
+// resetSolvedVersions clears versions and features solved before, so that
+// edits of port.toml and constraints are solved again.
+func resetSolvedVersions() {
+	solvedVersions.mutex.Lock()
+	solvedVersions.results = make(map[string]solveResult)
+	solvedVersions.mutex.Unlock()
+
+	requestedFeatures.mutex.Lock()
+	requestedFeatures.features = make(map[string]map[solveKey][]string)
+	requestedFeatures.mutex.Unlock()
+}
+
+type solveResult struct {
+	versions map[solveKey]string
+	features map[solveKey][]string
+	err      error
+}
+
+// solveKey identifies a port in the graph, ports built for host and target
+// are installed separately, so they're solved separately.
+type solveKey struct {
+	name string
+	host bool
+}
+
+// requirement is a version constraint on a port and where it comes from.
+type requirement struct {
+	constraint version.Constraint
+	path       []string // From project to the port that requires it.
+	pinned     bool     // Required by ports of project.
+}
+
+func (r requirement) String() string {
+	if r.pinned {
+		return fmt.Sprintf("%s pinned by project %s", r.constraint, r.path[0])
+	}
+	return fmt.Sprintf("%s required by %s", r.constraint, strings.Join(r.path, " -> "))
+}
+
+// resolveDependencies replaces version constraints like `zlib@>=1.2.13,<1.4`
+// in dependencies with the exact version chosen for the project.
+func (p *Port) resolveDependencies() error {
+	host := p.DevDep || p.HostDep
+	for index := range p.BuildConfigs {
+		config := &p.BuildConfigs[index]
+		if err := p.resolveConstraints(config.Dependencies, host); err != nil {
+			return err
+		}
+		if err := p.resolveConstraints(config.DevDependencies, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Port) resolveConstraints(nameVersions []string, host bool) error {
+	for index, nameVersion := range nameVersions {
+		nameVersion, features := SplitFeatures(nameVersion)
+		name, spec, ok := strings.Cut(nameVersion, "@")
+		if !ok || !version.IsConstraint(spec) {
+			continue
+		}
+
+		resolved, err := p.resolveConstraint(name, spec, host)
+		if err != nil {
+			return err
+		}
+		nameVersions[index] = JoinFeatures(name+"@"+resolved, features)
+	}
+	return nil
+}
+
+// resolveConstraint prefers the version solved for the project, so that only one
+// version of a port is in the graph. Ports out of the graph of project, for example
+// installed by `celer install` alone, fall back to the highest available version.
+func (p *Port) resolveConstraint(name, spec string, host bool) (string, error) {
+	constraint, err := version.ParseConstraint(spec)
+	if err != nil {
+		return "", fmt.Errorf("invalid dependency %s@%s of %s -> %w", name, spec, p.NameVersion(), err)
+	}
+
+	solved, err := solveProject(p.ctx)
+	if err != nil {
+		return "", err
+	}
+	if solvedVersion, ok := solved[solveKey{name: name, host: host}]; ok && constraint.Check(solvedVersion) {
+		return solvedVersion, nil
+	}
+
+	available := availableVersions(p.ctx, name)
+	for _, availableVersion := range available {
+		if constraint.Check(availableVersion) {
+			return availableVersion, nil
+		}
+	}
+	return "", fmt.Errorf("no version of %s satisfies %s required by %s, available versions: %s",
+		name, spec, p.NameVersion(), formatVersions(available))
+}
+
+// solveProject solves versions of all ports in the dependency graph of project once.
+func solveProject(ctx context.Context) (map[solveKey]string, error) {
+	result := solveProjectResult(ctx)
+	return result.versions, result.err
+}
+
+// solvedFeatures returns features of ports unioned across the graph of project.
+func solvedFeatures(ctx context.Context) map[solveKey][]string {
+	return solveProjectResult(ctx).features
+}
+
+func solveProjectResult(ctx context.Context) solveResult {
+	key := projectSolveKey(ctx)
+
+	solvedVersions.mutex.Lock()
+	defer solvedVersions.mutex.Unlock()
+
+	result, ok := solvedVersions.results[key]
+	if !ok {
+		solver := newDependencySolver(ctx)
+		versions, err := solver.solve(ctx.Project().GetPorts())
+		result = solveResult{versions: versions, features: solver.features, err: err}
+		solvedVersions.results[key] = result
+	}
+	return result
+}
+
+type dependencySolver struct {
+	ctx          context.Context
+	available    map[string][]string
+	chosen       map[solveKey]string
+	requirements map[solveKey][]requirement
+	visited      map[solveKey]bool
+	features     map[solveKey][]string // Union of selected features, kept across rounds.
+	changed      bool
+}
+
+func newDependencySolver(ctx context.Context) *dependencySolver {
+	return &dependencySolver{
+		ctx:       ctx,
+		available: make(map[string][]string),
+		chosen:    make(map[solveKey]string),
+		features:  make(map[solveKey][]string),
+	}
+}
+
+// solve walks the graph from ports of project and chooses the highest version that
+// meets all requirements of every port. When a chosen version has to be replaced,
+// its dependencies may change as well, so the graph is walked again until no
+// version is replaced.
+func (s *dependencySolver) solve(projectPorts []string) (map[solveKey]string, error) {
+	projectName := s.ctx.Project().GetName()
+
+	for range maxSolveRounds {
+		s.requirements = make(map[solveKey][]requirement)
+		s.visited = make(map[solveKey]bool)
+		s.changed = false
+
+		// Ports of project are pinned.
+		for _, nameVersion := range projectPorts {
+			nameVersion, features := SplitFeatures(nameVersion)
+			name, pinned, _ := strings.Cut(nameVersion, "@")
+			constraint, err := version.ParseConstraint("=" + pinned)
+			if err != nil {
+				return nil, err
+			}
+			key := solveKey{name: name}
+			s.features[key], _ = unionFeatures(s.features[key], features...)
+			s.requirements[key] = append(s.requirements[key], requirement{
+				constraint: constraint,
+				path:       []string{projectName},
+				pinned:     true,
+			})
+		}
+
+		for _, nameVersion := range projectPorts {
+			name, _, _ := strings.Cut(nameVersion, "@")
+			if err := s.require(name, "", Port{}, []string{projectName}); err != nil {
+				return nil, err
+			}
+		}
+
+		if !s.changed {
+			return s.chosen, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to solve versions of %s in %d rounds, please pin versions in project", projectName, maxSolveRounds)
+}
+
+// require adds a requirement of spec on port, and visits it with the chosen version.
+// Empty spec means the port has been required already, and spec may end with
+// selected features like `>=4.8[contrib]`.
+func (s *dependencySolver) require(name, spec string, flags Port, path []string) error {
+	key := solveKey{name: name, host: flags.DevDep || flags.HostDep}
+
+	// Port visited already has to be visited again with new features in next round.
+	spec, features := SplitFeatures(spec)
+	if merged, changed := unionFeatures(s.features[key], features...); changed {
+		s.features[key] = merged
+		if s.visited[key] {
+			s.changed = true
+		}
+	}
+
+	if spec != "" {
+		constraint, err := version.ParseConstraint(spec)
+		if err != nil {
+			return fmt.Errorf("invalid dependency %s@%s of %s -> %w", name, spec, path[len(path)-1], err)
+		}
+		s.requirements[key] = append(s.requirements[key], requirement{constraint: constraint, path: path})
+	}
+
+	current, ok := s.chosen[key]
+	if !ok || !s.satisfied(key, current) {
+		selected, err := s.choose(key)
+		if err != nil {
+			return err
+		}
+		if ok && s.visited[key] {
+			s.changed = true
+		}
+		s.chosen[key] = selected
+	}
+
+	return s.visit(key, flags, path)
+}
+
+func (s *dependencySolver) visit(key solveKey, flags Port, path []string) error {
+	if s.visited[key] {
+		return nil
+	}
+	s.visited[key] = true
+
+	nameVersion := key.name + "@" + s.chosen[key]
+	port := Port{DevDep: flags.DevDep, HostDep: flags.HostDep, rawDependencies: true}
+	if err := port.Init(s.ctx, JoinFeatures(nameVersion, s.features[key])); err != nil {
+		if errors.Is(err, errors.ErrNoMatchedConfigFound) {
+			return nil
+		}
+		return err
+	}
+
+	path = append(slices.Clone(path), nameVersion)
+	host := port.DevDep || port.HostDep
+	for _, dependency := range port.MatchedConfig.DevDependencies {
+		name, spec, _ := strings.Cut(dependency, "@")
+
+		// Same name, version as parent and they are booth build with native toolchain, so skip.
+		if plain, _ := SplitFeatures(dependency); host && plain == nameVersion {
+			continue
+		}
+		if err := s.require(name, spec, Port{DevDep: true, HostDep: true}, path); err != nil {
+			return err
+		}
+	}
+	for _, dependency := range port.MatchedConfig.Dependencies {
+		name, spec, _ := strings.Cut(dependency, "@")
+		if err := s.require(name, spec, Port{HostDep: host}, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *dependencySolver) satisfied(key solveKey, candidate string) bool {
+	for _, requirement := range s.requirements[key] {
+		if !requirement.constraint.Check(candidate) {
+			return false
+		}
+	}
+	return true
+}
+
+// choose returns the highest version that meets all requirements of port,
+// otherwise explains which requirements conflict.
+func (s *dependencySolver) choose(key solveKey) (string, error) {
+	available, ok := s.available[key.name]
+	if !ok {
+		available = availableVersions(s.ctx, key.name)
+		s.available[key.name] = available
+	}
+
+	for _, candidate := range available {
+		if s.satisfied(key, candidate) {
+			return candidate, nil
+		}
+	}
+
+	requirements := s.requirements[key]
+	meets := func(requirements ...requirement) bool {
+		return slices.ContainsFunc(available, func(candidate string) bool {
+			for _, requirement := range requirements {
+				if !requirement.constraint.Check(candidate) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	// Point out the requirement that no version meets, or two requirements that conflict.
+	conflicts := requirements
+search:
+	for i, first := range requirements {
+		if !meets(first) {
+			conflicts = []requirement{first}
+			break
+		}
+		for _, second := range requirements[i+1:] {
+			if !meets(first, second) {
+				conflicts = []requirement{first, second}
+				break search
+			}
+		}
+	}
+
+	var details []string
+	for _, conflict := range conflicts {
+		details = append(details, key.name+"@"+conflict.String())
+	}
+	return "", fmt.Errorf("no version of %s satisfies all requirements:\n  - %s\n  available versions: %s",
+		key.name, strings.Join(details, "\n  - "), formatVersions(available))
+}
+
+// availableVersions returns versions of port from project, registries and global ports, from high to low.
+func availableVersions(ctx context.Context, name string) []string {
+	projectDir := filepath.Join(dirs.ConfProjectsDir, ctx.Project().GetName())
+	searchDirs := []string{
+		filepath.Join(projectDir, name),
+		filepath.Join(projectDir, "ports", name),
+	}
+	for _, registry := range ctx.Registries() {
+		if registry.Provides(name) {
+			searchDirs = append(searchDirs, filepath.Dir(registry.PortDir(name, "_")))
+		}
+	}
+
+	var versions []string
+	for _, searchDir := range searchDirs {
+		entries, err := os.ReadDir(searchDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || slices.Contains(versions, entry.Name()) {
+				continue
+			}
+			if fileio.PathExists(filepath.Join(searchDir, entry.Name(), "port.toml")) {
+				versions = append(versions, entry.Name())
+			}
+		}
+	}
+
+	slices.SortFunc(versions, func(a, b string) int {
+		return version.Compare(b, a)
+	})
+	return versions
+}
+
+func formatVersions(versions []string) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+	return strings.Join(versions, ", ")
+}
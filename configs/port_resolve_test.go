@@ -0,0 +1,52 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResetMetaCache_SolvedVersions(t *testing.T) {
+	workspaceDir := setupTestWorkspace(t, map[string]string{
+		"ports/a/app/1.0.0/port.toml": `[package]
+url = "https://example.com/app.git"
+ref = "1.0.0"
+
+[[build_configs]]
+build_system = "cmake"
+dependencies = ["zlib@>=1.2"]
+`,
+		"ports/z/zlib/1.3.1/port.toml": "[package]\n",
+	})
+
+	ctx := featureContext{
+		fakeContext: fakeContext{platform: "x86_64-linux", project: "resolve", build: "release"},
+		ports:       []string{"app@1.0.0"},
+	}
+	zlib := solveKey{name: "zlib"}
+
+	solved, err := solveProject(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solved[zlib] != "1.3.1" {
+		t.Fatalf("zlib should be solved as 1.3.1, but got %q", solved[zlib])
+	}
+
+	// A newer version is added after solved.
+	portFile := filepath.Join(workspaceDir, "ports/z/zlib/1.4.0/port.toml")
+	if err := os.MkdirAll(filepath.Dir(portFile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(portFile, []byte("[package]\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ResetMetaCache()
+	if solved, err = solveProject(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if solved[zlib] != "1.4.0" {
+		t.Errorf("zlib should be solved again as 1.4.0 after cache is reset, but got %q", solved[zlib])
+	}
+}
@@ -0,0 +1,370 @@
+package configs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/git"
+)
+
+// buildSystemMarkers are files that identify build systems, in order of priority.
+var buildSystemMarkers = []struct {
+	buildSystem string
+	patterns    []string
+}{
+	{"cmake", []string{"CMakeLists.txt"}},
+	{"meson", []string{"meson.build"}},
+	{"makefiles", []string{"configure", "configure.ac", "configure.in"}},
+	{"bazel", []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel", "BUILD", "BUILD.bazel"}},
+	{"qmake", []string{"*.pro"}},
+	{"b2", []string{"Jamroot", "Jamroot.jam", "jamroot.jam"}},
+	{"gyp", []string{"*.gyp"}},
+}
+
+var (
+	cmakeOptionRegex      = regexp.MustCompile(`(?im)^\s*option\s*\(\s*([A-Za-z0-9_]+)\s+"([^"]*)"\s*([^\s)]*)\s*\)`)
+	cmakeFindPackageRegex = regexp.MustCompile(`(?i)find_package\s*\(\s*([A-Za-z0-9_.+-]+)`)
+	mesonOptionRegex      = regexp.MustCompile(`(?s)option\s*\(\s*'([^']+)'(.*?)\)\s*(?:\n|$)`)
+	mesonValueRegex       = regexp.MustCompile(`value\s*:\s*('[^']*'|\[[^\]]*\]|[A-Za-z0-9_.-]+)`)
+	mesonDescRegex        = regexp.MustCompile(`description\s*:\s*'([^']*)'`)
+	mesonDependencyRegex  = regexp.MustCompile(`dependency\s*\(\s*'([^']+)'`)
+)
+
+// ScaffoldOption is a configurable option found in upstream build files.
+type ScaffoldOption struct {
+	Name        string
+	Default     string
+	Description string
+}
+
+// PortScaffold is the draft port.toml generated from upstream source.
+type PortScaffold struct {
+	Url          string
+	Ref          string
+	Checksum     string
+	BuildSystem  string
+	Options      []ScaffoldOption
+	Dependencies []string
+}
+
+// CreatePortFrom creates port.toml of nameVersion by fetching and inspecting upstream source.
+func (c *Celer) CreatePortFrom(nameVersion, from string) (*PortScaffold, error) {
+	name, version, ok := strings.Cut(nameVersion, "@")
+	if !ok || name == "" || version == "" {
+		return nil, fmt.Errorf("invalid port name version")
+	}
+
+	if portPath := c.Registries().FindPortPath(name, version); fileio.PathExists(portPath) {
+		return nil, fmt.Errorf("%s is already exists", portPath)
+	}
+	portPath := dirs.GetPortPath(name, version)
+
+	if err := os.MkdirAll(dirs.TmpDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	srcDir, err := os.MkdirTemp(dirs.TmpDir, "scaffold-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir -> %w", err)
+	}
+	defer os.RemoveAll(srcDir)
+
+	// For archive, ref is version and checksum is sha-256 of archive.
+	ref, checksum, err := c.fetchPortSource(nameVersion, from, []string{version, "v" + version}, srcDir)
+	if err != nil {
+		return nil, err
+	}
+	scaffold := PortScaffold{
+		Url:      from,
+		Ref:      expr.If(ref == "", version, ref),
+		Checksum: checksum,
+	}
+
+	if err := scaffold.inspect(srcDir, name, c.scaffoldPortNames()); err != nil {
+		return nil, err
+	}
+	if scaffold.BuildSystem == "" {
+		color.PrintWarning("build system of %s is not detected, please fill it manually.", nameVersion)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(portPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(portPath, []byte(scaffold.Render()), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &scaffold, nil
+}
+
+// fetchPortSource clones git repo or downloads archive into srcDir. For git repo,
+// the first existing tag of tags is cloned, or default branch if none exists, and
+// the cloned ref is returned. For archive, sha-256 of archive is returned as checksum.
+func (c *Celer) fetchPortSource(nameVersion, url string, tags []string, srcDir string) (ref, checksum string, err error) {
+	if c.Offline() {
+		return "", "", fmt.Errorf("offline mode forbids fetching %s", url)
+	}
+
+	if strings.HasSuffix(url, ".git") {
+		for _, tag := range tags {
+			isTag, err := git.CheckIfRemoteTag(nameVersion, url, tag)
+			if err != nil {
+				return "", "", err
+			}
+			if isTag {
+				ref = tag
+				break
+			}
+		}
+
+		title := fmt.Sprintf("[clone %s]", nameVersion)
+		if err := git.CloneRepo(title, nameVersion, url, ref, 1, srcDir); err != nil {
+			return "", "", err
+		}
+		if ref == "" {
+			if ref, err = git.GetCurrentBranch(srcDir); err != nil {
+				return "", "", err
+			}
+			color.PrintWarning("none of tags %s exists, ref falls back to branch %s.", strings.Join(tags, ", "), ref)
+		}
+		return ref, "", nil
+	}
+
+	color.Printf(color.Title, "\n[fetch repo %s]", nameVersion)
+	archive := filepath.Base(url)
+	repair := fileio.NewRepair(url, c.Downloads(), archive, ".", srcDir, "")
+	if err := repair.CheckAndRepair(c); err != nil {
+		return "", "", err
+	}
+	if err := fileio.FlattenNestedDir(srcDir); err != nil {
+		return "", "", err
+	}
+
+	checksum, err = fileio.SHA256Sum(filepath.Join(c.Downloads(), archive))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to compute checksum of %s -> %w", archive, err)
+	}
+	return "", checksum, nil
+}
+
+// scaffoldPortNames returns existing port names keyed by lower case name.
+func (c *Celer) scaffoldPortNames() map[string]string {
+	searchDirs := []string{}
+	for _, registry := range c.Registries() {
+		if entries, err := os.ReadDir(registry.Dir); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					searchDirs = append(searchDirs, filepath.Join(registry.Dir, entry.Name()))
+				}
+			}
+		}
+	}
+	if projectName := c.Project().GetName(); projectName != "" {
+		projectDir := filepath.Join(dirs.ConfProjectsDir, projectName)
+		searchDirs = append(searchDirs, projectDir, filepath.Join(projectDir, "ports"))
+	}
+
+	names := make(map[string]string)
+	for _, searchDir := range searchDirs {
+		entries, err := os.ReadDir(searchDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if versions := availableVersions(c, entry.Name()); len(versions) > 0 {
+				names[strings.ToLower(entry.Name())] = entry.Name() + "@" + versions[0]
+			}
+		}
+	}
+	return names
+}
+
+// inspect detects build system, options and dependencies from source,
+// ports maps lower case port names to name@version of existing ports.
+func (s *PortScaffold) inspect(srcDir, portName string, ports map[string]string) error {
+	buildSystem, err := detectBuildSystem(srcDir)
+	if err != nil {
+		return err
+	}
+	s.BuildSystem = buildSystem
+
+	switch buildSystem {
+	case "cmake":
+		content, err := os.ReadFile(filepath.Join(srcDir, "CMakeLists.txt"))
+		if err != nil {
+			return err
+		}
+		s.Options = parseCMakeOptions(string(content))
+	case "meson":
+		for _, file := range []string{"meson.options", "meson_options.txt"} {
+			content, err := os.ReadFile(filepath.Join(srcDir, file))
+			if err == nil {
+				s.Options = parseMesonOptions(string(content))
+				break
+			}
+		}
+	}
+
+	packages, err := findRequiredPackages(srcDir)
+	if err != nil {
+		return err
+	}
+	s.Dependencies = matchScaffoldDependencies(packages, portName, ports)
+	return nil
+}
+
+// Render renders scaffold as port.toml, options are listed as commented candidates.
+func (s PortScaffold) Render() string {
+	var buffer strings.Builder
+	buffer.WriteString("[package]\n")
+	fmt.Fprintf(&buffer, "url = %s\n", strconv.Quote(s.Url))
+	fmt.Fprintf(&buffer, "ref = %s\n", strconv.Quote(s.Ref))
+	if s.Checksum != "" {
+		fmt.Fprintf(&buffer, "checksum = %s\n", strconv.Quote(s.Checksum))
+	}
+
+	buffer.WriteString("\n[[build_configs]]\n")
+	fmt.Fprintf(&buffer, "build_system = %s\n", strconv.Quote(s.BuildSystem))
+
+	var quoted []string
+	for _, dependency := range s.Dependencies {
+		quoted = append(quoted, strconv.Quote(dependency))
+	}
+	fmt.Fprintf(&buffer, "dependencies = [%s]\n", strings.Join(quoted, ", "))
+
+	if len(s.Options) == 0 {
+		buffer.WriteString("options = []\n")
+		return buffer.String()
+	}
+
+	buffer.WriteString("options = [\n")
+	buffer.WriteString("    # Options found in upstream build files, uncomment to override defaults.\n")
+	for _, option := range s.Options {
+		line := fmt.Sprintf("    # %s,", strconv.Quote(fmt.Sprintf("-D%s=%s", option.Name, option.Default)))
+		if option.Description != "" {
+			line += " # " + option.Description
+		}
+		buffer.WriteString(line + "\n")
+	}
+	buffer.WriteString("]\n")
+	return buffer.String()
+}
+
+// detectBuildSystem returns build system of source by its marker files.
+func detectBuildSystem(srcDir string) (string, error) {
+	for _, marker := range buildSystemMarkers {
+		for _, pattern := range marker.patterns {
+			matches, err := filepath.Glob(filepath.Join(srcDir, pattern))
+			if err != nil {
+				return "", err
+			}
+			for _, match := range matches {
+				if isDir, err := fileio.IsDirectory(match); err == nil && !isDir {
+					return marker.buildSystem, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// parseCMakeOptions parses option(NAME "description" DEFAULT) of CMakeLists.txt.
+func parseCMakeOptions(content string) []ScaffoldOption {
+	var options []ScaffoldOption
+	for _, match := range cmakeOptionRegex.FindAllStringSubmatch(content, -1) {
+		options = append(options, ScaffoldOption{
+			Name:        match[1],
+			Default:     expr.If(match[3] == "", "OFF", strings.ToUpper(match[3])),
+			Description: match[2],
+		})
+	}
+	return options
+}
+
+// parseMesonOptions parses option('name', ...) of meson_options.txt or meson.options.
+func parseMesonOptions(content string) []ScaffoldOption {
+	var options []ScaffoldOption
+	for _, match := range mesonOptionRegex.FindAllStringSubmatch(content, -1) {
+		option := ScaffoldOption{Name: match[1]}
+		if value := mesonValueRegex.FindStringSubmatch(match[2]); value != nil {
+			option.Default = strings.Trim(value[1], "'")
+		}
+		if desc := mesonDescRegex.FindStringSubmatch(match[2]); desc != nil {
+			option.Description = desc[1]
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// findRequiredPackages collects packages of find_package() and dependency() calls.
+func findRequiredPackages(srcDir string) ([]string, error) {
+	var packages []string
+	err := filepath.WalkDir(srcDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var regex *regexp.Regexp
+		switch name := entry.Name(); {
+		case name == "CMakeLists.txt" || strings.HasSuffix(name, ".cmake"):
+			regex = cmakeFindPackageRegex
+		case name == "meson.build":
+			regex = mesonDependencyRegex
+		default:
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range regex.FindAllStringSubmatch(string(content), -1) {
+			if !slices.Contains(packages, match[1]) {
+				packages = append(packages, match[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan required packages -> %w", err)
+	}
+	return packages, nil
+}
+
+// matchScaffoldDependencies matches packages against existing ports, such as ZLIB -> zlib, PNG -> libpng.
+func matchScaffoldDependencies(packages []string, portName string, ports map[string]string) []string {
+	var dependencies []string
+	for _, pkg := range packages {
+		lower := strings.ToLower(pkg)
+		for _, candidate := range []string{lower, "lib" + lower, strings.TrimPrefix(lower, "lib")} {
+			nameVersion, ok := ports[candidate]
+			if !ok || strings.EqualFold(candidate, portName) {
+				continue
+			}
+			if !slices.Contains(dependencies, nameVersion) {
+				dependencies = append(dependencies, nameVersion)
+			}
+			break
+		}
+	}
+	slices.Sort(dependencies)
+	return dependencies
+}
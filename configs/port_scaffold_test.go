@@ -0,0 +1,145 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func writeScaffoldFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	srcDir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(srcDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srcDir
+}
+
+func TestPortScaffold_DetectBuildSystem(t *testing.T) {
+	for _, test := range []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"CMakeLists.txt", "configure"}, "cmake"},
+		{[]string{"meson.build"}, "meson"},
+		{[]string{"configure.ac"}, "makefiles"},
+		{[]string{"WORKSPACE"}, "bazel"},
+		{[]string{"app.pro"}, "qmake"},
+		{[]string{"Jamroot"}, "b2"},
+		{[]string{"lib.gyp"}, "gyp"},
+		{[]string{"README.md"}, ""},
+	} {
+		files := make(map[string]string)
+		for _, file := range test.files {
+			files[file] = ""
+		}
+		buildSystem, err := detectBuildSystem(writeScaffoldFiles(t, files))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buildSystem != test.expected {
+			t.Errorf("%v should be detected as %q, but got %q", test.files, test.expected, buildSystem)
+		}
+	}
+}
+
+func TestPortScaffold_InspectCMake(t *testing.T) {
+	srcDir := writeScaffoldFiles(t, map[string]string{
+		"CMakeLists.txt": `cmake_minimum_required(VERSION 3.16)
+project(foo)
+option(FOO_BUILD_TESTS "Build tests" ON)
+option(FOO_WITH_PNG "Enable png support")
+find_package(ZLIB REQUIRED)
+find_package(PNG)
+find_package(Threads)
+add_subdirectory(src)
+`,
+		"src/CMakeLists.txt":  "find_package(Foo)\n",
+		"cmake/deps.cmake":    "find_package(OpenSSL)\n",
+		".git/CMakeLists.txt": "find_package(curl)\n",
+	})
+
+	var scaffold PortScaffold
+	ports := map[string]string{
+		"zlib":    "zlib@1.3.1",
+		"libpng":  "libpng@1.6.43",
+		"openssl": "openssl@3.0.13",
+		"foo":     "foo@1.0.0",
+		"curl":    "curl@8.5.0",
+	}
+	if err := scaffold.inspect(srcDir, "foo", ports); err != nil {
+		t.Fatal(err)
+	}
+
+	if scaffold.BuildSystem != "cmake" {
+		t.Fatalf("expected cmake, but got %q", scaffold.BuildSystem)
+	}
+	expectedOptions := []ScaffoldOption{
+		{Name: "FOO_BUILD_TESTS", Default: "ON", Description: "Build tests"},
+		{Name: "FOO_WITH_PNG", Default: "OFF", Description: "Enable png support"},
+	}
+	if !slices.Equal(scaffold.Options, expectedOptions) {
+		t.Fatalf("expected options %+v, but got %+v", expectedOptions, scaffold.Options)
+	}
+	expectedDependencies := []string{"libpng@1.6.43", "openssl@3.0.13", "zlib@1.3.1"}
+	if !slices.Equal(scaffold.Dependencies, expectedDependencies) {
+		t.Fatalf("expected dependencies %v, but got %v", expectedDependencies, scaffold.Dependencies)
+	}
+
+	// Rendered port.toml should be valid, and options are commented candidates.
+	scaffold.Url = "https://example.com/foo-1.0.0.tar.gz"
+	scaffold.Ref = "1.0.0"
+	scaffold.Checksum = "abc"
+	content := scaffold.Render()
+	if !strings.Contains(content, `    # "-DFOO_BUILD_TESTS=ON", # Build tests`) {
+		t.Errorf("options should be listed as comments, but got:\n%s", content)
+	}
+
+	var port Port
+	if _, err := toml.Decode(content, &port); err != nil {
+		t.Fatalf("rendered port.toml is invalid -> %v:\n%s", err, content)
+	}
+	if port.Package.Checksum != "abc" || port.BuildConfigs[0].BuildSystem != "cmake" ||
+		len(port.BuildConfigs[0].Options) != 0 || len(port.BuildConfigs[0].Dependencies) != 3 {
+		t.Fatalf("unexpected decoded port: %+v", port)
+	}
+}
+
+func TestPortScaffold_InspectMeson(t *testing.T) {
+	srcDir := writeScaffoldFiles(t, map[string]string{
+		"meson.build": "project('bar', 'c')\nzlib_dep = dependency('zlib')\nglib_dep = dependency('glib-2.0')\n",
+		"meson_options.txt": `option('tests', type : 'boolean', value : true, description : 'Build tests')
+option('backend',
+  type : 'combo',
+  choices : ['a', 'b'],
+  value : 'a')
+`,
+	})
+
+	var scaffold PortScaffold
+	if err := scaffold.inspect(srcDir, "bar", map[string]string{"zlib": "zlib@1.3.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOptions := []ScaffoldOption{
+		{Name: "tests", Default: "true", Description: "Build tests"},
+		{Name: "backend", Default: "a"},
+	}
+	if scaffold.BuildSystem != "meson" || !slices.Equal(scaffold.Options, expectedOptions) {
+		t.Fatalf("unexpected scaffold: %+v", scaffold)
+	}
+	if !slices.Equal(scaffold.Dependencies, []string{"zlib@1.3.1"}) {
+		t.Fatalf("expected zlib@1.3.1, but got %v", scaffold.Dependencies)
+	}
+}
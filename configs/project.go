@@ -1,15 +1,22 @@
 package configs
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgcache"
 	"github.com/celer-pkg/celer/pkgs/cmd"
 	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/debuginfo"
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/errors"
 	"github.com/celer-pkg/celer/pkgs/fileio"
@@ -24,12 +31,55 @@ type Project struct {
 	Vars           []string `toml:"vars"`
 	Envs           []string `toml:"envs"`
 	Macros         []string `toml:"macros"`
+	Bundle         *Bundle  `toml:"bundle,omitempty"`
 
 	// Internal fields.
 	Name string `toml:"-"`
 	ctx  context.Context
 }
 
+// Bundle customizes `celer bundle` of project, files are selected by default
+// rules of profile, then custom rules are applied with the same precedence.
+type Bundle struct {
+	Version     string      `toml:"version,omitempty"`
+	Maintainer  string      `toml:"maintainer,omitempty"`
+	Description string      `toml:"description,omitempty"`
+	Prefix      string      `toml:"prefix,omitempty"`
+	Runtime     BundleRules `toml:"runtime,omitempty"`
+	SDK         BundleRules `toml:"sdk,omitempty"`
+}
+
+// BundleRules are glob patterns of files relative to installed dir, pattern
+// without "/" matches file name in any dir, and "**" matches any dirs.
+// Excluded files are bundled again when they match include.
+type BundleRules struct {
+	Include []string `toml:"include,omitempty"`
+	Exclude []string `toml:"exclude,omitempty"`
+}
+
+var bundleVersionRegex = regexp.MustCompile(`^[0-9][0-9A-Za-z.+~]*$`)
+
+// ValidateBundleVersion checks version of bundle, which must be accepted by both deb and rpm.
+func ValidateBundleVersion(version string) error {
+	if !bundleVersionRegex.MatchString(version) {
+		return fmt.Errorf("bundle version %q is invalid, it should start with digit and contain only alphanumerics and .+~", version)
+	}
+	return nil
+}
+
+// ValidateBundlePattern checks syntax of include and exclude pattern of bundle.
+func ValidateBundlePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("bundle pattern is empty")
+	}
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("bundle pattern %q is invalid -> %w", pattern, err)
+		}
+	}
+	return nil
+}
+
 func (p *Project) Init(ctx context.Context, projectName string) error {
 	p.ctx = ctx
 
@@ -117,29 +167,44 @@ func (p Project) GetVars() []string {
 	return p.Vars
 }
 
-func (p Project) deploy(force, strip bool) error {
-	options := InstallOptions{
-		Force:     force,
-		Recursive: true,
+func (p Project) deploy(options DeployOptions) error {
+	installOptions := InstallOptions{
+		Force:        options.Force,
+		Recursive:    true,
+		Parallel:     options.Parallel,
+		KeepGoing:    options.KeepGoing,
+		ReportFormat: options.ReportFormat,
 	}
 
 	// Collect a single deploy-wide report that includes all project ports.
 	deployReport := newInstallReport(p.GetName())
+	deployReport.format = options.ReportFormat
 
-	for _, nameVersion := range p.Ports {
-		var port Port
-		if err := port.Init(p.ctx, nameVersion); err != nil {
-			return fmt.Errorf("failed to init %s -> %w", nameVersion, err)
+	if options.Parallel > 1 {
+		if err := p.deployInParallel(installOptions, deployReport); err != nil {
+			return err
 		}
-
-		port.installReport = deployReport
-		if _, err := port.Install(options); err != nil {
-			return fmt.Errorf("failed to install %s -> %w", nameVersion, err)
+	} else {
+		for _, nameVersion := range p.Ports {
+			var port Port
+			if err := port.Init(p.ctx, nameVersion); err != nil {
+				return fmt.Errorf("failed to init %s -> %w", nameVersion, err)
+			}
+
+			port.installReport = deployReport
+			if _, err := port.Install(installOptions); err != nil {
+				return fmt.Errorf("failed to install %s -> %w", nameVersion, err)
+			}
 		}
 	}
 
-	// Strip ELF binaries and shared libraries to deduce the file size.
-	if strip {
+	// Strip ELF binaries and shared libraries to deduce the file size,
+	// their debug symbols are kept in symbol store when split.
+	if options.SplitDebug {
+		if err := p.splitDeployedDebug(); err != nil {
+			return fmt.Errorf("failed to split debug symbols of deployed binaries -> %w", err)
+		}
+	} else if options.Strip {
 		if err := p.stripDeployed(); err != nil {
 			return fmt.Errorf("failed to strip deployed binaries -> %w", err)
 		}
@@ -148,6 +213,35 @@ func (p Project) deploy(force, strip bool) error {
 	return nil
 }
 
+// deployInParallel installs all project ports as one dependency graph, so
+// that ports shared by several project ports are scheduled only once.
+func (p Project) deployInParallel(options InstallOptions, deployReport *installReport) error {
+	var ports []Port
+	for _, nameVersion := range p.Ports {
+		var port Port
+		if err := port.Init(p.ctx, nameVersion); err != nil {
+			return fmt.Errorf("failed to init %s -> %w", nameVersion, err)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+
+	if _, err := installScheduled(p.ctx, ports, options, deployReport); err != nil {
+		return err
+	}
+
+	reportPath, err := deployReport.write(&ports[0])
+	if err != nil {
+		color.PrintWarning("failed to write install report for %s -> %s", p.GetName(), err)
+		return nil
+	}
+	color.PrintPass("%s's install report is generated", p.GetName())
+	color.PrintHint("Location: %s\n", reportPath)
+	return nil
+}
+
 // stripDeployed walks the per-platform installed tree and runs strip on every
 // ELF file found. Static archives (.a) are intentionally skipped — stripping
 // them removes symbols downstream linking against this deploy still needs.
@@ -197,3 +291,148 @@ func (p Project) stripDeployed() error {
 
 	return nil
 }
+
+// splitDeployedDebug works like stripDeployed, but debug sections of every ELF
+// file are extracted into build-id keyed symbol store before it's stripped:
+// installed/celer/debug/<platform>/<project>/<buildType>/.build-id/xx/yyyy.debug.
+func (p Project) splitDeployedDebug() error {
+	// Check if objcopy executable file has been configured.
+	toolchain := p.ctx.Platform().GetToolchain()
+	objcopy := toolchain.GetOBJCOPY()
+	if objcopy == "" {
+		return fmt.Errorf("objcopy executable file path is not configured in platform: %s.toml", p.ctx.Platform().GetName())
+	}
+
+	installedDir := p.ctx.InstalledDir()
+	if !fileio.PathExists(installedDir) {
+		return nil
+	}
+
+	storeDir := filepath.Join(dirs.InstalledDir, "celer", "debug", p.ctx.LibraryFolder())
+	color.Printf(color.Title, "\n[split debug symbols of deployed binaries: %s]\n", installedDir)
+
+	// Debug files are indexed by lines of trace file, to find the ports owning them.
+	debugFiles := make(map[string]string)
+	err := filepath.WalkDir(installedDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if strings.HasSuffix(path, ".a") || !fileio.IsELFFile(path) {
+			return nil
+		}
+
+		debugFile, err := debuginfo.Split(objcopy, path, storeDir)
+		if err != nil {
+			color.PrintWarning("%s is not split -> %s", path, err)
+			return nil
+		}
+		if debugFile == "" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dirs.InstalledDir, path)
+		if err != nil {
+			return err
+		}
+		debugFiles[relPath] = debugFile
+		color.PrintHint("✔ split %s", path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(debugFiles) > 0 {
+		color.PrintHint("Debug files: %s", storeDir)
+	}
+	return p.storeDebugArchives(storeDir, debugFiles)
+}
+
+// storeDebugArchives archives debug files of every port, and stores them next to
+// its artifact cache entry, so that every cached build has matching symbols.
+func (p Project) storeDebugArchives(storeDir string, debugFiles map[string]string) error {
+	pkgCache := p.ctx.PkgCacheConfig()
+	if len(debugFiles) == 0 || pkgCache == nil || pkgCache.GetDir(pkgcache.PkgCacheDirRoot) == "" || !pkgCache.IsWritable() {
+		return nil
+	}
+	artifactCache := pkgCache.GetArtifactCache()
+	if artifactCache == nil {
+		return nil
+	}
+
+	owners, err := LoadFileOwners()
+	if err != nil {
+		return err
+	}
+	libraryDir := p.ctx.LibraryFolder()
+	portDebugFiles := make(map[string][]string)
+	for file, debugFile := range debugFiles {
+		for _, owner := range owners.Owners(file) {
+			if owner.LibraryDir == libraryDir {
+				portDebugFiles[owner.NameVersion] = append(portDebugFiles[owner.NameVersion], debugFile)
+			}
+		}
+	}
+
+	for _, nameVersion := range slices.Sorted(maps.Keys(portDebugFiles)) {
+		// Build hash is the checksum of installed meta file, ports without it
+		// are not built by celer, and they're never cached.
+		metaFile := filepath.Join(dirs.InstalledDir, "celer", "metas", libraryDir, nameVersion+".meta")
+		metaBytes, err := os.ReadFile(metaFile)
+		if err != nil {
+			continue
+		}
+		buildHash := fmt.Sprintf("%x", sha256.Sum256(metaBytes))
+
+		archivePath, err := archiveDebugFiles(storeDir, portDebugFiles[nameVersion])
+		if err != nil {
+			return fmt.Errorf("failed to archive debug files of %s -> %w", nameVersion, err)
+		}
+		err = artifactCache.StoreDebug(nameVersion, buildHash, archivePath)
+		os.RemoveAll(filepath.Dir(archivePath))
+		if err != nil {
+			return fmt.Errorf("failed to store debug files of %s -> %w", nameVersion, err)
+		}
+	}
+	return nil
+}
+
+// archiveDebugFiles compresses debug files with their paths in symbol store,
+// the archive is created in a temp dir, which should be removed by caller.
+func archiveDebugFiles(storeDir string, debugFiles []string) (string, error) {
+	if err := os.MkdirAll(dirs.TmpFilesDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	tempDir, err := os.MkdirTemp(dirs.TmpFilesDir, "debug-archive-*")
+	if err != nil {
+		return "", err
+	}
+
+	stagingDir := filepath.Join(tempDir, "debug")
+	for _, debugFile := range debugFiles {
+		relPath, err := filepath.Rel(storeDir, debugFile)
+		if err != nil {
+			os.RemoveAll(tempDir)
+			return "", err
+		}
+		dest := filepath.Join(stagingDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			os.RemoveAll(tempDir)
+			return "", err
+		}
+		if err := fileio.CopyFile(debugFile, dest); err != nil {
+			os.RemoveAll(tempDir)
+			return "", err
+		}
+	}
+
+	archivePath := filepath.Join(tempDir, "debug.tar.gz")
+	if err := fileio.Targz(archivePath, stagingDir, false); err != nil {
+		os.RemoveAll(tempDir)
+		return "", err
+	}
+	return archivePath, nil
+}
@@ -0,0 +1,228 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/celer-pkg/celer/buildtools"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/git"
+)
+
+// ProjectRegistry is the registry name of ports in project dir of conf repo.
+const ProjectRegistry = "project"
+
+var registryNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Registry is a ports repo declared in celer.toml, it's cloned into "registries/<name>".
+// Ports are looked up in registries by the order of celer.toml, and then in the default ports repo.
+type Registry struct {
+	Name   string   `toml:"name"`
+	Url    string   `toml:"url"`
+	Branch string   `toml:"branch,omitempty"`
+	Commit string   `toml:"commit,omitempty"`
+	Ports  []string `toml:"ports,omitempty"` // Name patterns of ports looked up in it, like "openssl" or "qt*".
+}
+
+// Dir returns the dir that registry is cloned into.
+func (r Registry) Dir() string {
+	return filepath.Join(dirs.RegistriesDir, r.Name)
+}
+
+// Ref returns the pinned commit, or the branch when it's not pinned.
+func (r Registry) Ref() string {
+	if r.Commit != "" {
+		return r.Commit
+	}
+	return r.Branch
+}
+
+func (r Registry) Validate() error {
+	if !registryNameRegex.MatchString(r.Name) {
+		return fmt.Errorf("registry name %q should only contain letters, digits, '.', '_' and '-'", r.Name)
+	}
+	if r.Name == context.DefaultRegistry || r.Name == ProjectRegistry {
+		return fmt.Errorf("registry name %q is reserved", r.Name)
+	}
+	if r.Url == "" {
+		return fmt.Errorf("url of registry %s is empty", r.Name)
+	}
+	if r.Branch != "" && r.Commit != "" {
+		return fmt.Errorf("registry %s can only have one of branch and commit", r.Name)
+	}
+	if r.Commit != "" && !git.CheckIsCommitHash(r.Commit) {
+		return fmt.Errorf("commit %q of registry %s is not a commit hash", r.Commit, r.Name)
+	}
+	for _, pattern := range r.Ports {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("port pattern %q of registry %s is invalid", pattern, r.Name)
+		}
+	}
+	return nil
+}
+
+// PortRegistries returns registries of celer.toml in priority order.
+func (c *Celer) PortRegistries() []Registry {
+	return c.configData.Registries
+}
+
+// Registries returns registries that ports are looked up in by priority,
+// the default ports repo is the last one.
+func (c *Celer) Registries() context.Registries {
+	var registries context.Registries
+	for _, registry := range c.configData.Registries {
+		registries = append(registries, context.Registry{
+			Name:  registry.Name,
+			Dir:   registry.Dir(),
+			Ports: registry.Ports,
+		})
+	}
+	return append(registries, context.Registry{Name: context.DefaultRegistry, Dir: dirs.PortsDir})
+}
+
+// validateRegistries validates registries of celer.toml, their names must be unique.
+func (c *Celer) validateRegistries() error {
+	visited := make(map[string]bool)
+	for _, registry := range c.configData.Registries {
+		if err := registry.Validate(); err != nil {
+			return err
+		}
+		if visited[registry.Name] {
+			return fmt.Errorf("registry %s is declared more than once", registry.Name)
+		}
+		visited[registry.Name] = true
+	}
+	return nil
+}
+
+// Cloned reports whether registry is cloned into its dir.
+func (r Registry) Cloned() bool {
+	entities, err := os.ReadDir(r.Dir())
+	return err == nil && len(entities) > 0
+}
+
+// checkedOut reports whether the cloned registry is checked out from url and
+// to ref of celer.toml, the changed one is returned as the reason if not.
+func (r Registry) checkedOut() (bool, string, error) {
+	repoUrl, err := git.GetRepoUrl(r.Dir())
+	if err != nil {
+		return false, "", err
+	}
+	if strings.TrimSuffix(repoUrl, "/") != strings.TrimSuffix(r.Url, "/") {
+		return false, "url", nil
+	}
+
+	switch {
+	case r.Commit != "":
+		commit, err := git.GetCommitHash(r.Dir())
+		if err != nil {
+			return false, "", err
+		}
+		if !strings.HasPrefix(strings.ToLower(commit), strings.ToLower(r.Commit)) {
+			return false, "commit", nil
+		}
+
+	default:
+		// HEAD is detached when it's checked out to a commit or tag.
+		branch, err := git.GetCurrentBranch(r.Dir())
+		if err != nil {
+			return false, "", err
+		}
+		if r.Branch == "" && branch == "HEAD" {
+			return false, "branch", nil
+		}
+		if r.Branch != "" && branch != r.Branch {
+			if tag, err := git.GetCurrentTag(r.Dir()); err != nil || tag != r.Branch {
+				return false, "branch", nil
+			}
+		}
+	}
+	return true, "", nil
+}
+
+// cloneRegistries clones registries that are not cloned yet, and checks out the cloned
+// ones again when their url, branch or commit is changed in celer.toml.
+func (c *Celer) cloneRegistries() error {
+	for _, registry := range c.configData.Registries {
+		if !registry.Cloned() {
+			if err := c.CloneRegistry(registry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Registry that is not a git repo is taken as provided by user.
+		if !fileio.PathExists(filepath.Join(registry.Dir(), ".git")) {
+			continue
+		}
+
+		matched, changed, err := registry.checkedOut()
+		if err != nil {
+			return fmt.Errorf("failed to check registry %s -> %w", registry.Name, err)
+		}
+		if matched {
+			continue
+		}
+
+		if c.Main.Offline {
+			color.PrintWarning("%s of registry %s is changed in celer.toml, but offline is on, it's kept as is.", changed, registry.Name)
+			continue
+		}
+
+		// Local modifications are never discarded implicitly.
+		if modified, err := git.IsModified(registry.Dir()); err != nil {
+			return err
+		} else if modified {
+			color.PrintWarning("%s of registry %s is changed in celer.toml, but it has local modifications, "+
+				"please update it with `celer update --ports-repo --force`.", changed, registry.Name)
+			continue
+		}
+
+		color.PrintHint("%s of registry %s is changed in celer.toml, it'll be checked out again.", changed, registry.Name)
+		if changed == "url" {
+			if err := c.CloneRegistry(registry); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := git.UpdateRepo("registry "+registry.Name, registry.Ref(), registry.Dir(), false); err != nil {
+			return fmt.Errorf("failed to check out registry %s -> %w", registry.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// CloneRegistry clones registry into its dir, the existing dir is removed before clone.
+func (c *Celer) CloneRegistry(registry Registry) error {
+	// Remove registry dir before clone it.
+	repoDir := registry.Dir()
+	if err := os.RemoveAll(repoDir); err != nil {
+		return err
+	}
+
+	if c.Main.Offline {
+		return fmt.Errorf("offline is on, cloning registry %s is aborted", registry.Name)
+	}
+	if err := fileio.CheckAccessible(registry.Url); err != nil {
+		return fmt.Errorf("%s is not accessible, cloning registry %s is aborted", registry.Url, registry.Name)
+	}
+
+	// Make sure git available.
+	if err := buildtools.CheckTools(c, "git"); err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("[clone registry %s]", registry.Name)
+	if err := git.CloneRepo(title, "registry "+registry.Name, registry.Url, registry.Ref(), 0, repoDir); err != nil {
+		return fmt.Errorf("failed to clone registry %s -> %w", registry.Name, err)
+	}
+	return nil
+}
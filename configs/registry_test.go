@@ -0,0 +1,177 @@
+package configs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Validate(t *testing.T) {
+	for _, test := range []struct {
+		registry Registry
+		err      string
+	}{
+		{Registry{Name: "company", Url: "https://example.com/ports.git", Branch: "main", Ports: []string{"qt*"}}, ""},
+		{Registry{Name: "team", Url: "https://example.com/ports.git", Commit: "3f2a9c1"}, ""},
+		{Registry{Name: "my team", Url: "https://example.com/ports.git"}, "should only contain"},
+		{Registry{Name: "default", Url: "https://example.com/ports.git"}, "is reserved"},
+		{Registry{Name: "team"}, "url of registry team is empty"},
+		{Registry{Name: "team", Url: "https://example.com/ports.git", Branch: "main", Commit: "3f2a9c1"}, "only have one of branch and commit"},
+		{Registry{Name: "team", Url: "https://example.com/ports.git", Commit: "main"}, "is not a commit hash"},
+		{Registry{Name: "team", Url: "https://example.com/ports.git", Ports: []string{"qt["}}, "is invalid"},
+	} {
+		err := test.registry.Validate()
+		if test.err == "" && err != nil {
+			t.Errorf("%+v should be valid, but got %v", test.registry, err)
+		}
+		if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%+v should fail with %q, but got %v", test.registry, test.err, err)
+		}
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	workspaceDir := setupTestWorkspace(t, map[string]string{
+		"registries/company/z/zlib/1.3.1/port.toml":    "[package]\n",
+		"registries/company/o/openssl/3.5.0/port.toml": "[package]\n",
+		"registries/team/z/zlib/1.3.1/port.toml":       "[package]\n",
+		"registries/team/z/zlib/1.4.0/port.toml":       "[package]\n",
+		"ports/z/zlib/1.2.0/port.toml":                 "[package]\n",
+		"ports/z/zlib/1.3.1/port.toml":                 "[package]\n",
+		"ports/o/openssl/3.5.0/port.toml":              "[package]\n",
+	})
+
+	celer := &Celer{}
+	celer.configData.Registries = []Registry{
+		{Name: "company", Url: "https://example.com/company.git", Ports: []string{"z*"}},
+		{Name: "team", Url: "https://example.com/team.git"},
+	}
+	if err := celer.validateRegistries(); err != nil {
+		t.Fatal(err)
+	}
+
+	for nameVersion, expected := range map[string]string{
+		"zlib@1.3.1":    "registries/company/z/zlib/1.3.1/port.toml", // Registry with higher priority wins.
+		"zlib@1.4.0":    "registries/team/z/zlib/1.4.0/port.toml",    // Registry with lower priority has it only.
+		"zlib@1.2.0":    "ports/z/zlib/1.2.0/port.toml",              // Default ports repo is the last one.
+		"openssl@3.5.0": "ports/o/openssl/3.5.0/port.toml",           // Filtered out by company registry.
+	} {
+		name, version, _ := strings.Cut(nameVersion, "@")
+		portFile, err := Port{ctx: celer}.resolveProjectPort("", name, version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if portFile != filepath.Join(workspaceDir, expected) {
+			t.Errorf("%s should be looked up in %s, but got %s", nameVersion, expected, portFile)
+		}
+	}
+
+	if versions := availableVersions(celer, "zlib"); !slices.Equal(versions, []string{"1.4.0", "1.3.1", "1.2.0"}) {
+		t.Errorf("versions of all registries should be available, but got %v", versions)
+	}
+	if registry, ok := celer.Registries().RegistryOf(filepath.Join(workspaceDir, "registries/team/z/zlib/1.4.0/port.toml")); !ok || registry.Name != "team" {
+		t.Errorf("port file should belong to team registry, but got %+v", registry)
+	}
+
+	// Registry name must be unique.
+	celer.configData.Registries = append(celer.configData.Registries, Registry{Name: "team", Url: "https://example.com/other.git"})
+	if err := celer.validateRegistries(); err == nil || !strings.Contains(err.Error(), "declared more than once") {
+		t.Errorf("duplicated registry should fail, but got %v", err)
+	}
+}
+
+func TestRegistry_CheckoutChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not found.")
+	}
+	runGit := func(dir string, args ...string) string {
+		t.Helper()
+		command := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		command.Env = append(os.Environ(), "GIT_AUTHOR_NAME=celer", "GIT_AUTHOR_EMAIL=celer@example.com",
+			"GIT_COMMITTER_NAME=celer", "GIT_COMMITTER_EMAIL=celer@example.com")
+		output, err := command.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %s", args, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	// Upstream registry has main and dev branch.
+	upstream := t.TempDir()
+	runGit(upstream, "init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(upstream, "README.md"), []byte("main\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	runGit(upstream, "add", "-A")
+	runGit(upstream, "commit", "-q", "-m", "main")
+	mainCommit := runGit(upstream, "rev-parse", "HEAD")
+	runGit(upstream, "checkout", "-q", "-b", "dev")
+	if err := os.WriteFile(filepath.Join(upstream, "README.md"), []byte("dev\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	runGit(upstream, "commit", "-q", "-am", "dev")
+	runGit(upstream, "checkout", "-q", "main")
+
+	workspaceDir := setupTestWorkspace(t, nil)
+	repoDir := filepath.Join(workspaceDir, "registries", "company")
+	if output, err := exec.Command("git", "clone", "-q", "-b", "main", upstream, repoDir).CombinedOutput(); err != nil {
+		t.Fatalf("failed to clone registry: %s", output)
+	}
+
+	registry := Registry{Name: "company", Url: upstream, Branch: "main"}
+	if !registry.Cloned() {
+		t.Fatal("registry should be cloned")
+	}
+	for _, test := range []struct {
+		registry Registry
+		changed  string
+	}{
+		{registry, ""},
+		{Registry{Name: "company", Url: upstream}, ""},
+		{Registry{Name: "company", Url: upstream, Commit: mainCommit[:7]}, ""},
+		{Registry{Name: "company", Url: upstream, Branch: "dev"}, "branch"},
+		{Registry{Name: "company", Url: upstream + "-moved", Branch: "main"}, "url"},
+	} {
+		matched, changed, err := test.registry.checkedOut()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != (test.changed == "") || changed != test.changed {
+			t.Errorf("checkedOut() of %+v = %v, %q, want %q", test.registry, matched, changed, test.changed)
+		}
+	}
+
+	// Branch changed in celer.toml is checked out.
+	celer := &Celer{}
+	celer.configData.Registries = []Registry{{Name: "company", Url: upstream, Branch: "dev"}}
+	if err := celer.cloneRegistries(); err != nil {
+		t.Fatal(err)
+	}
+	if branch := runGit(repoDir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "dev" {
+		t.Errorf("registry should be checked out to dev, but got %s", branch)
+	}
+
+	// Registry with local modifications is kept as is.
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("modified\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	celer.configData.Registries = []Registry{{Name: "company", Url: upstream, Commit: mainCommit}}
+	if err := celer.cloneRegistries(); err != nil {
+		t.Fatal(err)
+	}
+	if branch := runGit(repoDir, "rev-parse", "--abbrev-ref", "HEAD"); branch != "dev" {
+		t.Errorf("registry with local modifications should be kept, but got %s", branch)
+	}
+
+	// Pinned commit is checked out.
+	runGit(repoDir, "checkout", "-q", "--", "README.md")
+	if err := celer.cloneRegistries(); err != nil {
+		t.Fatal(err)
+	}
+	if commit := runGit(repoDir, "rev-parse", "HEAD"); commit != mainCommit {
+		t.Errorf("registry should be checked out to %s, but got %s", mainCommit, commit)
+	}
+}
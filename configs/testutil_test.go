@@ -0,0 +1,30 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+// setupTestWorkspace creates a workspace with files, and restores dirs after test.
+func setupTestWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	workspaceDir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(workspaceDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalWorkspaceDir := dirs.WorkspaceDir
+	dirs.Init(workspaceDir)
+	t.Cleanup(func() { dirs.Init(originalWorkspaceDir) })
+	return workspaceDir
+}
@@ -105,9 +105,9 @@ func (c *Celer) GenerateToolchainFile() error {
 	c.appendEnvs(&builder)
 	c.appendMacros(&builder)
 
-	// Write toolchain file.
+	// Write toolchain file, it's renamed into place since builds of other celer processes may be reading it.
 	toolchainPath := filepath.Join(dirs.WorkspaceDir, "toolchain_file.cmake")
-	if err := os.WriteFile(toolchainPath, []byte(builder.String()), os.ModePerm); err != nil {
+	if err := fileio.WriteFileAtomic(toolchainPath, []byte(builder.String()), os.ModePerm); err != nil {
 		return err
 	}
 
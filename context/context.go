@@ -26,6 +26,7 @@ type Context interface {
 	ExprVars() *ExprVars
 	PythonConfig() PythonConfig
 	Features() Features
+	Registries() Registries
 }
 
 // PythonConfig exposes the Python interpreter setup for building python ports.
@@ -39,4 +40,8 @@ type PythonConfig interface {
 // Features features during development, can be configure temportary.
 type Features interface {
 	ShouldIgnoreCheckCMakeAbsPath() bool
+	ShouldAllowFileCollisions() bool
+	ShouldFixELFRPath() bool
+	ShouldIgnoreCheckArch() bool
+	ShouldBuildHermetic() bool
 }
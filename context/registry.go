@@ -0,0 +1,83 @@
+package context
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+// DefaultRegistry is the name of ports repo cloned into "ports", it's always
+// the last registry, so registries of celer.toml can override its ports.
+const DefaultRegistry = "default"
+
+// Registry is a ports repo in workspace, it has the same layout as "ports".
+type Registry struct {
+	Name  string   // Name of registry.
+	Dir   string   // Root dir of ports in registry.
+	Ports []string // Name patterns of ports it provides, all ports when it's empty.
+}
+
+// Provides reports whether port of name can be looked up in registry.
+func (r Registry) Provides(name string) bool {
+	if len(r.Ports) == 0 {
+		return true
+	}
+	for _, pattern := range r.Ports {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// PortDir returns the port directory path in registry with first-letter classification.
+func (r Registry) PortDir(name, version string) string {
+	if name == "" {
+		return ""
+	}
+
+	firstChar := strings.ToLower(string([]rune(name)[0]))
+	return filepath.Join(r.Dir, firstChar, name, version)
+}
+
+// Registries are registries of celer.toml in priority order, the default ports repo is the last one.
+type Registries []Registry
+
+// FindPortDir returns port dir of the first registry that provides and contains the port,
+// and the name of registry. Port dir in default ports repo is returned when it's not found.
+func (r Registries) FindPortDir(name, version string) (string, string) {
+	for _, registry := range r {
+		if !registry.Provides(name) {
+			continue
+		}
+		if portDir := registry.PortDir(name, version); portFileExists(portDir) {
+			return portDir, registry.Name
+		}
+	}
+	return dirs.GetPortDir(name, version), DefaultRegistry
+}
+
+// FindPortPath returns port.toml path like FindPortDir.
+func (r Registries) FindPortPath(name, version string) string {
+	portDir, _ := r.FindPortDir(name, version)
+	return filepath.Join(portDir, "port.toml")
+}
+
+// RegistryOf returns the registry that port file belongs to,
+// it returns false when the file is not in any registry, like ports of project.
+func (r Registries) RegistryOf(portFile string) (Registry, bool) {
+	for _, registry := range r {
+		if rel, err := filepath.Rel(registry.Dir, portFile); err == nil && filepath.IsLocal(rel) {
+			return registry, true
+		}
+	}
+	return Registry{}, false
+}
+
+func portFileExists(portDir string) bool {
+	_, err := os.Stat(filepath.Join(portDir, "port.toml"))
+	return err == nil
+}
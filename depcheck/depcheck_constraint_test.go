@@ -0,0 +1,104 @@
+package depcheck
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+func setupConstraintProject(t *testing.T, projectName string) *configs.Celer {
+	t.Helper()
+
+	// Set test workspace dir.
+	currentDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs.Init(filepath.Join(currentDir, "testdata/depcheck/constraint"))
+
+	celer := configs.NewCeler()
+	if err := celer.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := celer.SetProject(projectName); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Remove(filepath.Join(dirs.WorkspaceDir, "celer.toml"))
+	})
+	return celer
+}
+
+func initConstraintPorts(celer *configs.Celer) ([]configs.Port, error) {
+	var ports []configs.Port
+	for _, nameVersion := range celer.Project().GetPorts() {
+		var port configs.Port
+		if err := port.Init(celer, nameVersion); err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+func TestDepCheck_Constraint_OneVersion(t *testing.T) {
+	celer := setupConstraintProject(t, "project_001")
+
+	// bbb accepts zzz 1.2 and 1.3, ccc accepts zzz 1.2 only, so 1.2.0 is the only choice.
+	var bbb configs.Port
+	if err := bbb.Init(celer, "bbb@1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bbb.MatchedConfig.Dependencies, []string{"zzz@1.2.0"}) {
+		t.Fatalf("dependencies of bbb should be resolved to zzz@1.2.0, but got %v", bbb.MatchedConfig.Dependencies)
+	}
+
+	ports, err := initConstraintPorts(celer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewDepCheck().CheckConflict(celer, ports...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDepCheck_Constraint_Pinned(t *testing.T) {
+	celer := setupConstraintProject(t, "project_003")
+
+	// zzz@1.3.0 is pinned by project, though 1.2.0 also satisfies bbb.
+	var bbb configs.Port
+	if err := bbb.Init(celer, "bbb@1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bbb.MatchedConfig.Dependencies, []string{"zzz@1.3.0"}) {
+		t.Fatalf("dependencies of bbb should be resolved to pinned zzz@1.3.0, but got %v", bbb.MatchedConfig.Dependencies)
+	}
+}
+
+func TestDepCheck_Constraint_Unsatisfiable(t *testing.T) {
+	celer := setupConstraintProject(t, "project_002")
+
+	ports, err := initConstraintPorts(celer)
+	if err == nil {
+		err = NewDepCheck().CheckConflict(celer, ports...)
+	}
+	if err == nil {
+		t.Fatal("unsatisfiable constraints should be reported.")
+	}
+
+	// Both dependency paths should be explained.
+	for _, expected := range []string{
+		"no version of zzz satisfies all requirements",
+		"zzz@>=1.2,<1.4 required by project_002 -> ddd@1.0.0 -> bbb@1.0.0",
+		"zzz@>=1.4 required by project_002 -> ddd@1.0.0 -> eee@1.0.0",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("error should contain %q, but got:\n%s", expected, err)
+		}
+	}
+}
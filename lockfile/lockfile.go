@@ -0,0 +1,292 @@
+package lockfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/refs"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Version is the format version of lock file.
+const Version = 1
+
+// Lock records exactly what sources a project was built with.
+type Lock struct {
+	Version int          `toml:"version"`
+	Project string       `toml:"project"`
+	Ports   []LockedPort `toml:"ports"`
+}
+
+// LockedPort records the resolved source and port file of a single port.
+type LockedPort struct {
+	NameVersion string `toml:"name_version"`
+	Source      string `toml:"source"` // git, archive or virtual.
+	Url         string `toml:"url,omitempty"`
+	Ref         string `toml:"ref,omitempty"`
+	Commit      string `toml:"commit,omitempty"` // Resolved commit of git source.
+	SHA256      string `toml:"sha256,omitempty"` // SHA-256 of archive source.
+	PortHash    string `toml:"port_hash"`        // SHA-256 of port.toml.
+}
+
+// Path returns the lock file path of project, it's next to the project file.
+func Path(projectName string) string {
+	return filepath.Join(dirs.ConfProjectsDir, projectName+".lock.toml")
+}
+
+// Load reads lock file of project.
+func Load(projectName string) (*Lock, error) {
+	lockPath := Path(projectName)
+	if !fileio.PathExists(lockPath) {
+		return nil, fmt.Errorf("lock file %s is not found, please run `celer lock` first", lockPath)
+	}
+
+	var lock Lock
+	if _, err := toml.DecodeFile(lockPath, &lock); err != nil {
+		return nil, fmt.Errorf("failed to decode %s -> %w", lockPath, err)
+	}
+	if lock.Version != Version {
+		return nil, fmt.Errorf("lock file version %d is not supported, please run `celer lock` again", lock.Version)
+	}
+
+	return &lock, nil
+}
+
+// Save writes lock file of project.
+func (l Lock) Save() error {
+	var buffer bytes.Buffer
+	buffer.WriteString("# Generated by `celer lock`, please don't edit it manually.\n\n")
+	if err := toml.NewEncoder(&buffer).Encode(l); err != nil {
+		return fmt.Errorf("failed to encode lock file -> %w", err)
+	}
+
+	lockPath := Path(l.Project)
+	if err := os.WriteFile(lockPath, buffer.Bytes(), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write %s -> %w", lockPath, err)
+	}
+
+	return nil
+}
+
+// Find returns the locked port of nameVersion.
+func (l Lock) Find(nameVersion string) (LockedPort, bool) {
+	for _, port := range l.Ports {
+		if port.NameVersion == nameVersion {
+			return port, true
+		}
+	}
+	return LockedPort{}, false
+}
+
+// Commits returns locked commits of git ports, they're used to pin sources when clone.
+func (l Lock) Commits() map[string]string {
+	commits := make(map[string]string)
+	for _, port := range l.Ports {
+		if port.Commit != "" {
+			commits[port.NameVersion] = port.Commit
+		}
+	}
+	return commits
+}
+
+// CollectPorts collects ports and all their dependencies with matched build config.
+func CollectPorts(ctx context.Context, nameVersions []string) ([]configs.Port, error) {
+	var (
+		ports     []configs.Port
+		collected = make(map[string]bool)
+		collect   func(nameVersion string) error
+	)
+
+	collect = func(nameVersion string) error {
+		key, _ := configs.SplitFeatures(nameVersion)
+		if collected[key] {
+			return nil
+		}
+		collected[key] = true
+
+		var port configs.Port
+		if err := port.Init(ctx, nameVersion); err != nil {
+			return err
+		}
+		ports = append(ports, port)
+
+		for _, dep := range port.MatchedConfig.Dependencies {
+			if err := collect(dep); err != nil {
+				return err
+			}
+		}
+		for _, dep := range port.MatchedConfig.DevDependencies {
+			if err := collect(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, nameVersion := range nameVersions {
+		if err := collect(nameVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(ports, func(a, b configs.Port) int {
+		return strings.Compare(a.NameVersion(), b.NameVersion())
+	})
+	return ports, nil
+}
+
+// PortInfos converts ports to what refs.ResolvePorts requires.
+func PortInfos(ports []configs.Port) []refs.PortInfo {
+	var portInfos []refs.PortInfo
+	for _, port := range ports {
+		portInfos = append(portInfos, refs.PortInfo{
+			NameVersion: port.NameVersion(),
+			Url:         port.Package.Url,
+			Ref:         port.Package.Ref,
+			Checksum:    port.Package.Checksum,
+		})
+	}
+	return portInfos
+}
+
+// Generate generates lock of project with ports and their resolved refs.
+func Generate(ctx context.Context, ports []configs.Port, resolvedRefs []refs.ResolvedRef) (*Lock, error) {
+	lock := Lock{
+		Version: Version,
+		Project: ctx.Project().GetName(),
+	}
+
+	for _, port := range ports {
+		lockedPort, err := lockPort(ctx, port, resolvedRefs)
+		if err != nil {
+			return nil, err
+		}
+		lock.Ports = append(lock.Ports, lockedPort)
+	}
+
+	return &lock, nil
+}
+
+// Verify checks ports against the lock, it fails when a port is not locked,
+// its port file is changed or its source has drifted from the lock.
+func (l Lock) Verify(ctx context.Context, ports []configs.Port, resolvedRefs []refs.ResolvedRef) error {
+	var drifts []string
+	for _, port := range ports {
+		locked, ok := l.Find(port.NameVersion())
+		if !ok {
+			drifts = append(drifts, fmt.Sprintf("%s is not locked", port.NameVersion()))
+			continue
+		}
+
+		current, err := lockPort(ctx, port, resolvedRefs)
+		if err != nil {
+			return err
+		}
+
+		if drift := compare(locked, current); drift != "" {
+			drifts = append(drifts, drift)
+		}
+	}
+
+	if len(drifts) > 0 {
+		return fmt.Errorf("%s is out of date, run `celer lock` to update it:\n  - %s",
+			Path(l.Project), strings.Join(drifts, "\n  - "))
+	}
+	return nil
+}
+
+// compare describes how current port drifted from the locked one, empty means no drift.
+func compare(locked, current LockedPort) string {
+	switch {
+	case current.PortHash != locked.PortHash:
+		return fmt.Sprintf("%s's port file is changed", current.NameVersion)
+	case current.Url != locked.Url || current.Ref != locked.Ref:
+		return fmt.Sprintf("%s's source is changed from %s@%s to %s@%s",
+			current.NameVersion, locked.Url, locked.Ref, current.Url, current.Ref)
+	case current.Commit != locked.Commit:
+		return fmt.Sprintf("%s's ref %s has drifted from %s to %s",
+			current.NameVersion, current.Ref, locked.Commit, current.Commit)
+	case current.SHA256 != locked.SHA256:
+		return fmt.Sprintf("%s's archive sha-256 is changed from %s to %s",
+			current.NameVersion, locked.SHA256, current.SHA256)
+	default:
+		return ""
+	}
+}
+
+func lockPort(ctx context.Context, port configs.Port, resolvedRefs []refs.ResolvedRef) (LockedPort, error) {
+	portHash, err := fileio.SHA256Sum(port.PortFile())
+	if err != nil {
+		return LockedPort{}, fmt.Errorf("failed to compute hash of %s -> %w", port.PortFile(), err)
+	}
+
+	lockedPort := LockedPort{
+		NameVersion: port.NameVersion(),
+		Url:         port.Package.Url,
+		Ref:         port.Package.Ref,
+		PortHash:    portHash,
+	}
+
+	index := slices.IndexFunc(resolvedRefs, func(ref refs.ResolvedRef) bool {
+		return ref.NameVersion == port.NameVersion()
+	})
+	if index < 0 {
+		return LockedPort{}, fmt.Errorf("ref of %s is not resolved", port.NameVersion())
+	}
+	resolved := resolvedRefs[index]
+	if resolved.Error != "" {
+		return LockedPort{}, fmt.Errorf("failed to resolve ref of %s -> %s", port.NameVersion(), resolved.Error)
+	}
+	lockedPort.Source = string(resolved.SourceType)
+
+	switch resolved.SourceType {
+	case refs.SourceVirtual:
+		lockedPort.Url = ""
+		lockedPort.Ref = ""
+
+	case refs.SourceGit:
+		lockedPort.Commit = resolved.ResolvedCommit
+
+	case refs.SourceArchive:
+		sha256, err := archiveSHA256(ctx, port)
+		if err != nil {
+			return LockedPort{}, err
+		}
+		lockedPort.SHA256 = sha256
+	}
+
+	return lockedPort, nil
+}
+
+// archiveSHA256 prefers checksum declared in port.toml, otherwise computes it
+// from the downloaded archive, and the archive would be downloaded when missing.
+func archiveSHA256(ctx context.Context, port configs.Port) (string, error) {
+	if port.Package.Checksum != "" {
+		return port.Package.Checksum, nil
+	}
+
+	archive := expr.If(port.Package.Archive != "", port.Package.Archive, filepath.Base(port.Package.Url))
+	archivePath := filepath.Join(ctx.Downloads(), archive)
+	if !fileio.PathExists(archivePath) {
+		repair := fileio.NewRepair(port.Package.Url, ctx.Downloads(), archive, "", "", "")
+		if err := repair.CheckAndRepair(ctx); err != nil {
+			return "", fmt.Errorf("failed to download archive of %s -> %w", port.NameVersion(), err)
+		}
+	}
+
+	sha256, err := fileio.SHA256Sum(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute sha-256 of %s -> %w", archivePath, err)
+	}
+	return sha256, nil
+}
@@ -0,0 +1,116 @@
+package lockfile
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/celer-pkg/celer/pkgs/dirs"
+)
+
+func TestLock_SaveAndLoad(t *testing.T) {
+	original := dirs.ConfProjectsDir
+	dirs.ConfProjectsDir = t.TempDir()
+	defer func() { dirs.ConfProjectsDir = original }()
+
+	lock := Lock{
+		Version: Version,
+		Project: "test_project",
+		Ports: []LockedPort{
+			{
+				NameVersion: "zlib@1.3.1",
+				Source:      "git",
+				Url:         "https://github.com/madler/zlib.git",
+				Ref:         "v1.3.1",
+				Commit:      "51b7f2abdade71cd9bb0e7a373ef2610ec6f9daf",
+				PortHash:    "aaa",
+			},
+			{
+				NameVersion: "nasm@2.16.03",
+				Source:      "archive",
+				Url:         "https://www.nasm.us/pub/nasm/releasebuilds/2.16.03/nasm-2.16.03.tar.gz",
+				Ref:         "2.16.03",
+				SHA256:      "bbb",
+				PortHash:    "ccc",
+			},
+		},
+	}
+	if err := lock.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load("test_project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Ports) != 2 {
+		t.Fatalf("loaded %d ports, want 2", len(loaded.Ports))
+	}
+
+	port, ok := loaded.Find("nasm@2.16.03")
+	if !ok || port.SHA256 != "bbb" || port.Commit != "" {
+		t.Errorf("Find(nasm@2.16.03) = %+v, %v", port, ok)
+	}
+	if _, ok := loaded.Find("nasm@2.16.01"); ok {
+		t.Error("Find(nasm@2.16.01) should not be found")
+	}
+
+	commits := loaded.Commits()
+	if len(commits) != 1 || commits["zlib@1.3.1"] != "51b7f2abdade71cd9bb0e7a373ef2610ec6f9daf" {
+		t.Errorf("Commits() = %v", commits)
+	}
+}
+
+func TestLock_Load_Errors(t *testing.T) {
+	original := dirs.ConfProjectsDir
+	dirs.ConfProjectsDir = t.TempDir()
+	defer func() { dirs.ConfProjectsDir = original }()
+
+	if _, err := Load("missing"); err == nil || !strings.Contains(err.Error(), "celer lock") {
+		t.Errorf("Load(missing) error = %v, want hint of `celer lock`", err)
+	}
+
+	if err := os.WriteFile(Path("future"), []byte("version = 99\nproject = \"future\"\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load("future"); err == nil {
+		t.Error("Load(future) should fail with unsupported version")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	locked := LockedPort{
+		NameVersion: "zlib@1.3.1",
+		Source:      "git",
+		Url:         "https://github.com/madler/zlib.git",
+		Ref:         "develop",
+		Commit:      "1111111111111111111111111111111111111111",
+		PortHash:    "aaa",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(port *LockedPort)
+		keyword string
+	}{
+		{"unchanged", func(port *LockedPort) {}, ""},
+		{"port file changed", func(port *LockedPort) { port.PortHash = "bbb" }, "port file is changed"},
+		{"ref changed", func(port *LockedPort) { port.Ref = "master" }, "source is changed"},
+		{"branch drifted", func(port *LockedPort) { port.Commit = "2222222222222222222222222222222222222222" }, "has drifted"},
+		{"archive changed", func(port *LockedPort) { port.SHA256 = "ccc" }, "sha-256 is changed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current := locked
+			test.modify(&current)
+
+			drift := compare(locked, current)
+			if test.keyword == "" && drift != "" {
+				t.Errorf("compare() = %q, want no drift", drift)
+			}
+			if test.keyword != "" && !strings.Contains(drift, test.keyword) {
+				t.Errorf("compare() = %q, want it contains %q", drift, test.keyword)
+			}
+		})
+	}
+}
@@ -25,9 +25,12 @@ type PkgCacheConfig interface {
 }
 
 // AritifactCache stores/restores a port's built package, keyed by name@version + build hash.
+// Restore and Store return size of the archive transferred, it's 0 when nothing is transferred.
+// StoreDebug stores split debug files of a cached package, so they match its binaries.
 type AritifactCache interface {
-	Restore(nameVersion, buildhash, packageDir string) (string, error)
-	Store(packageDir, metadata string) error
+	Restore(nameVersion, buildhash, packageDir string) (string, int64, error)
+	Store(packageDir, metadata string) (int64, error)
+	StoreDebug(nameVersion, buildhash, archivePath string) error
 }
 
 // RepoCache stores/restores a port's source tree, keyed by name@version + checksum.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/celer-pkg/celer/pkgs/dirs"
 	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/filelock"
 )
 
 type DevArtifactCache struct {
@@ -93,6 +94,27 @@ func (d DevArtifactCache) Store(packageDir, meta string) error {
 		return fmt.Errorf("invalid package dir: %s", packageDir)
 	}
 
+	destDir := filepath.Join(d.cacheDir, nameVersion)
+	metaDir := filepath.Join(destDir, "metas")
+
+	// Calculate checksum of metadata (this would be the cache key).
+	data := sha256.Sum256([]byte(meta))
+	hash := fmt.Sprintf("%x", data)
+
+	// Lock the cache key, other processes may be storing the same artifact.
+	lock, err := filelock.Acquire(filepath.Join(d.cacheDir, ".locks", nameVersion, hash+".lock"))
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// Skip if already cached — rebuild with same metadata produces identical output.
+	archivePath := filepath.Join(destDir, hash+".tar.gz")
+	metaPath := filepath.Join(metaDir, hash+".meta")
+	if fileio.PathExists(archivePath) && fileio.PathExists(metaPath) {
+		return nil
+	}
+
 	// Extract tar.gz to a tmp dir.
 	archiveName := fmt.Sprintf("%s.tar.gz", nameVersion)
 	if err := dirs.CleanTmpFilesDir(); err != nil {
@@ -110,13 +132,6 @@ func (d DevArtifactCache) Store(packageDir, meta string) error {
 		return err
 	}
 
-	destDir := filepath.Join(d.cacheDir, nameVersion)
-	metaDir := filepath.Join(destDir, "metas")
-
-	// Calculate checksum of metadata (this would be the cache key).
-	data := sha256.Sum256([]byte(meta))
-	hash := fmt.Sprintf("%x", data)
-
 	// Create dirs.
 	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
 		return err
@@ -125,15 +140,13 @@ func (d DevArtifactCache) Store(packageDir, meta string) error {
 		return err
 	}
 
-	// Copy archive directly to final path.
-	archivePath := filepath.Join(destDir, hash+".tar.gz")
-	if err := fileio.CopyFile(tempArchivePath, archivePath); err != nil {
+	// Write meta file first, an archive is taken as cached only when its meta exists.
+	if err := fileio.WriteFileAtomic(metaPath, []byte(meta), os.ModePerm); err != nil {
 		return err
 	}
 
-	// Write meta file directly to final path.
-	metaPath := filepath.Join(metaDir, hash+".meta")
-	if err := os.WriteFile(metaPath, []byte(meta), os.ModePerm); err != nil {
+	// Copy archive beside final path and rename it, so that it's never read partially.
+	if err := fileio.CopyFileAtomic(tempArchivePath, archivePath, "", os.ModePerm); err != nil {
 		return err
 	}
 
@@ -0,0 +1,7 @@
+package manage
+
+import "syscall"
+
+func statAtime(stat *syscall.Stat_t) (int64, int64) {
+	return stat.Atimespec.Sec, stat.Atimespec.Nsec
+}
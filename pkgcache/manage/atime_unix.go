@@ -0,0 +1,24 @@
+//go:build darwin || linux
+
+package manage
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime returns last access time of file, it falls back to modification
+// time when it's not available, for example the fs is mounted with noatime.
+func accessTime(info os.FileInfo) time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+
+	atime := time.Unix(statAtime(stat))
+	if atime.Before(info.ModTime()) {
+		return info.ModTime()
+	}
+	return atime
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package manage
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime returns last access time of file, it falls back to modification time when it's not available.
+func accessTime(info os.FileInfo) time.Time {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return info.ModTime()
+	}
+
+	atime := time.Unix(0, data.LastAccessTime.Nanoseconds())
+	if atime.Before(info.ModTime()) {
+		return info.ModTime()
+	}
+	return atime
+}
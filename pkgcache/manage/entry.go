@@ -0,0 +1,282 @@
+package manage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// Kind is the kind of cache entry.
+type Kind string
+
+const (
+	KindArtifacts Kind = "artifacts"
+	KindRepos     Kind = "repos"
+	KindDownloads Kind = "downloads"
+	KindDev       Kind = "dev"
+)
+
+// AllKinds are all kinds of cache, they're in the order of being displayed.
+var AllKinds = []Kind{KindArtifacts, KindRepos, KindDownloads, KindDev}
+
+// ParseKinds parses kinds from values of `--kind`, empty values means all kinds.
+func ParseKinds(values []string) ([]Kind, error) {
+	if len(values) == 0 {
+		return AllKinds, nil
+	}
+
+	var kinds []Kind
+	for _, value := range values {
+		kind := Kind(strings.TrimSpace(value))
+		if !slices.Contains(AllKinds, kind) {
+			return nil, fmt.Errorf("unsupported cache kind %q, available kinds: artifacts, repos, downloads, dev", value)
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// Dirs are root dirs of each kind of cache, empty dir means the kind of cache is not available.
+type Dirs struct {
+	Artifacts string
+	Repos     string
+	Downloads string
+	Dev       string
+}
+
+// Get returns root dir of the kind of cache.
+func (d Dirs) Get(kind Kind) string {
+	switch kind {
+	case KindArtifacts:
+		return d.Artifacts
+	case KindRepos:
+		return d.Repos
+	case KindDownloads:
+		return d.Downloads
+	case KindDev:
+		return d.Dev
+	default:
+		return ""
+	}
+}
+
+// Entry is a cached archive, for artifacts it also owns the meta file.
+type Entry struct {
+	Kind       Kind      `json:"kind"`
+	Platform   string    `json:"platform,omitempty"`   // Only for artifacts.
+	Project    string    `json:"project,omitempty"`    // Only for artifacts.
+	BuildType  string    `json:"build_type,omitempty"` // Only for artifacts.
+	Name       string    `json:"name"`                 // name@version, or file name for downloads.
+	Hash       string    `json:"hash"`                 // Build hash, git commit or sha-256.
+	Path       string    `json:"path"`
+	MetaPath   string    `json:"meta_path,omitempty"`
+	DebugPath  string    `json:"debug_path,omitempty"` // Split debug files, only for artifacts.
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	AccessTime time.Time `json:"access_time"`
+}
+
+// Workspace returns <platform>/<project>/<buildType> of artifact.
+func (e Entry) Workspace() string {
+	if e.Kind != KindArtifacts {
+		return ""
+	}
+	return e.Platform + "/" + e.Project + "/" + e.BuildType
+}
+
+// downloadRegex matches cached download files named as {base}-{sha256}{ext}.
+var downloadRegex = regexp.MustCompile(`^(.+)-([0-9a-f]{64})(\..+)?$`)
+
+// Scan collects entries of kinds, the kinds whose dir is empty or missing are skipped.
+func Scan(dirs Dirs, kinds []Kind) ([]Entry, error) {
+	var entries []Entry
+	for _, kind := range kinds {
+		rootDir := dirs.Get(kind)
+		if rootDir == "" || !fileio.PathExists(rootDir) {
+			continue
+		}
+
+		var (
+			found []Entry
+			err   error
+		)
+		switch kind {
+		case KindArtifacts:
+			found, err = scanArchives(kind, rootDir, "*", "*", "*", "*", "*.tar.gz")
+		case KindDev:
+			found, err = scanArchives(kind, rootDir, "*", "*.tar.gz")
+		case KindRepos:
+			found, err = scanRepos(rootDir)
+		case KindDownloads:
+			found, err = scanDownloads(rootDir)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s cache in %s -> %w", kind, rootDir, err)
+		}
+		entries = append(entries, found...)
+	}
+
+	return entries, nil
+}
+
+// scanArchives scans archives and their metas that stored as:
+// <...>/<name@version>/<hash>.tar.gz and <...>/<name@version>/metas/<hash>.meta,
+// split debug files of artifact are stored as <...>/<name@version>/debug/<hash>.tar.gz.
+func scanArchives(kind Kind, rootDir string, pattern ...string) ([]Entry, error) {
+	matches, err := filepath.Glob(filepath.Join(append([]string{rootDir}, pattern...)...))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, archivePath := range matches {
+		hash := strings.TrimSuffix(filepath.Base(archivePath), ".tar.gz")
+		entry, err := newEntry(kind, archivePath, filepath.Base(filepath.Dir(archivePath)), hash)
+		if err != nil {
+			return nil, err
+		}
+
+		metaPath := filepath.Join(filepath.Dir(archivePath), "metas", hash+".meta")
+		if info, err := os.Stat(metaPath); err == nil {
+			entry.MetaPath = metaPath
+			entry.Size += info.Size()
+		}
+		debugPath := filepath.Join(filepath.Dir(archivePath), "debug", hash+".tar.gz")
+		if info, err := os.Stat(debugPath); err == nil {
+			entry.DebugPath = debugPath
+			entry.Size += info.Size()
+		}
+
+		if kind == KindArtifacts {
+			relPath, err := filepath.Rel(rootDir, archivePath)
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.Split(filepath.ToSlash(relPath), "/")
+			entry.Platform, entry.Project, entry.BuildType = parts[0], parts[1], parts[2]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// scanRepos scans repo archives stored as <name@version>/<commit or sha-256><ext>.
+func scanRepos(rootDir string) ([]Entry, error) {
+	repoDirs, err := os.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, repoDir := range repoDirs {
+		if !repoDir.IsDir() {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(rootDir, repoDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+
+			archivePath := filepath.Join(rootDir, repoDir.Name(), file.Name())
+			hash := strings.TrimSuffix(file.Name(), fileio.Ext(file.Name()))
+			entry, err := newEntry(KindRepos, archivePath, repoDir.Name(), hash)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// scanDownloads scans downloaded files stored as {base}-{sha256}{ext}.
+func scanDownloads(rootDir string) ([]Entry, error) {
+	files, err := os.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		// Files not named by celer are ignored.
+		parts := downloadRegex.FindStringSubmatch(file.Name())
+		if parts == nil {
+			continue
+		}
+
+		entry, err := newEntry(KindDownloads, filepath.Join(rootDir, file.Name()), parts[1]+parts[3], parts[2])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newEntry(kind Kind, path, name, hash string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return Entry{
+		Kind:       kind,
+		Name:       name,
+		Hash:       hash,
+		Path:       path,
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		AccessTime: accessTime(info),
+	}, nil
+}
+
+// Summary is the statistics of a kind of cache.
+type Summary struct {
+	Kind   Kind      `json:"kind"`
+	Dir    string    `json:"dir"`
+	Count  int       `json:"count"`
+	Size   int64     `json:"size"`
+	Oldest time.Time `json:"oldest,omitzero"`
+	Newest time.Time `json:"newest,omitzero"`
+}
+
+// Summarize summarizes entries by kind.
+func Summarize(dirs Dirs, kinds []Kind, entries []Entry) []Summary {
+	var summaries []Summary
+	for _, kind := range kinds {
+		summary := Summary{Kind: kind, Dir: dirs.Get(kind)}
+		for _, entry := range entries {
+			if entry.Kind != kind {
+				continue
+			}
+
+			summary.Count++
+			summary.Size += entry.Size
+			if summary.Oldest.IsZero() || entry.ModTime.Before(summary.Oldest) {
+				summary.Oldest = entry.ModTime
+			}
+			if entry.ModTime.After(summary.Newest) {
+				summary.Newest = entry.ModTime
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
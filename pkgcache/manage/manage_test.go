@@ -0,0 +1,218 @@
+package manage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sha256Of(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+// setupCache creates caches with both valid and broken entries.
+func setupCache(t *testing.T) Dirs {
+	root := t.TempDir()
+	dirs := Dirs{
+		Artifacts: filepath.Join(root, "artifacts-v1"),
+		Repos:     filepath.Join(root, "repos"),
+		Downloads: filepath.Join(root, "downloads"),
+		Dev:       filepath.Join(root, "dev"),
+	}
+
+	// Valid artifact.
+	artifactDir := filepath.Join(dirs.Artifacts, "x86_64-linux", "proj", "release", "zlib@1.3.1")
+	writeFile(t, filepath.Join(artifactDir, sha256Of("meta-zlib")+".tar.gz"), "archive")
+	writeFile(t, filepath.Join(artifactDir, "metas", sha256Of("meta-zlib")+".meta"), "meta-zlib")
+	writeFile(t, filepath.Join(artifactDir, "debug", sha256Of("meta-zlib")+".tar.gz"), "debug")
+
+	// Tampered meta.
+	writeFile(t, filepath.Join(artifactDir, sha256Of("meta-old")+".tar.gz"), "archive")
+	writeFile(t, filepath.Join(artifactDir, "metas", sha256Of("meta-old")+".meta"), "meta-new")
+
+	// Orphan archive and orphan meta.
+	writeFile(t, filepath.Join(artifactDir, "aaaa.tar.gz"), "archive")
+	writeFile(t, filepath.Join(artifactDir, "metas", "bbbb.meta"), "meta")
+
+	// Repos of git source and archive source, the latter is corrupted.
+	writeFile(t, filepath.Join(dirs.Repos, "x264@stable", strings.Repeat("1", 40)+".tar.gz"), "git repo")
+	writeFile(t, filepath.Join(dirs.Repos, "nasm@2.16.03", sha256Of("nasm")+".tar.xz"), "corrupted")
+
+	// Download files, unknown files are ignored.
+	writeFile(t, filepath.Join(dirs.Downloads, "cmake-3.30.5-"+sha256Of("cmake")+".tar.gz"), "cmake")
+	writeFile(t, filepath.Join(dirs.Downloads, "README.txt"), "readme")
+
+	// Dev artifact.
+	writeFile(t, filepath.Join(dirs.Dev, "nasm@2.16.03", sha256Of("meta-nasm")+".tar.gz"), "archive")
+	writeFile(t, filepath.Join(dirs.Dev, "nasm@2.16.03", "metas", sha256Of("meta-nasm")+".meta"), "meta-nasm")
+
+	return dirs
+}
+
+func TestScan(t *testing.T) {
+	dirs := setupCache(t)
+
+	entries, err := Scan(dirs, AllKinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[Kind]int)
+	for _, entry := range entries {
+		counts[entry.Kind]++
+	}
+	want := map[Kind]int{KindArtifacts: 3, KindRepos: 2, KindDownloads: 1, KindDev: 1}
+	for kind, count := range want {
+		if counts[kind] != count {
+			t.Errorf("%s entries = %d, want %d", kind, counts[kind], count)
+		}
+	}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case KindArtifacts:
+			if entry.Workspace() != "x86_64-linux/proj/release" || entry.Name != "zlib@1.3.1" {
+				t.Errorf("artifact = %+v", entry)
+			}
+			if entry.Hash == sha256Of("meta-zlib") {
+				if entry.DebugPath == "" || entry.Size != int64(len("archive")+len("meta-zlib")+len("debug")) {
+					t.Errorf("artifact with debug archive = %+v", entry)
+				}
+				if err := Remove(entry); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := os.Stat(filepath.Dir(entry.DebugPath)); !os.IsNotExist(err) {
+					t.Errorf("debug dir should be removed with its archive, but got %v", err)
+				}
+			}
+		case KindDownloads:
+			if entry.Name != "cmake-3.30.5.tar.gz" || entry.Hash != sha256Of("cmake") {
+				t.Errorf("download = %+v", entry)
+			}
+		}
+	}
+
+	summaries := Summarize(dirs, []Kind{KindDev}, entries)
+	if len(summaries) != 1 || summaries[0].Count != 1 || summaries[0].Size != int64(len("archive")+len("meta-nasm")) {
+		t.Errorf("Summarize() = %+v", summaries)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dirs := setupCache(t)
+
+	problems, err := Verify(dirs, AllKinds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reasons := make(map[string]string)
+	for _, problem := range problems {
+		reasons[filepath.Base(problem.Path)] = problem.Reason
+	}
+	if len(problems) != 4 {
+		t.Errorf("Verify() found %d problems, want 4: %v", len(problems), reasons)
+	}
+	for name, reason := range map[string]string{
+		sha256Of("meta-old") + ".tar.gz": "meta checksum mismatch",
+		"aaaa.tar.gz":                    "orphan archive",
+		"bbbb.meta":                      "orphan meta",
+		sha256Of("nasm") + ".tar.xz":     "sha-256 mismatch",
+	} {
+		if !strings.Contains(reasons[name], reason) {
+			t.Errorf("problem of %s = %q, want %q", name, reasons[name], reason)
+		}
+	}
+
+	// Broken entries are gone after fixed.
+	for _, problem := range problems {
+		if err := RemoveFiles(problem.Files); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if problems, err := Verify(dirs, AllKinds); err != nil || len(problems) != 0 {
+		t.Errorf("Verify() after fixed = %v, %v", problems, err)
+	}
+
+	// Repo dir left empty is removed too.
+	if _, err := os.Stat(filepath.Join(dirs.Repos, "nasm@2.16.03")); !os.IsNotExist(err) {
+		t.Errorf("empty repo dir should be removed, got %v", err)
+	}
+}
+
+func TestSelectPrune(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	days := func(count int) time.Time { return now.Add(-time.Duration(count) * 24 * time.Hour) }
+	entries := []Entry{
+		{Name: "old@1", Size: 10, ModTime: days(100), AccessTime: days(1)},
+		{Name: "unused@1", Size: 10, ModTime: days(20), AccessTime: days(40)},
+		{Name: "cold@1", Size: 30, ModTime: days(10), AccessTime: days(9)},
+		{Name: "hot@1", Size: 30, ModTime: days(10), AccessTime: days(1)},
+	}
+
+	names := func(entries []Entry) string {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name    string
+		options PruneOptions
+		want    string
+	}{
+		{"older than", PruneOptions{OlderThan: 90 * 24 * time.Hour}, "old@1"},
+		{"unused for", PruneOptions{UnusedFor: 30 * 24 * time.Hour}, "unused@1"},
+		{"max size", PruneOptions{MaxSize: 40}, "unused@1,cold@1"},
+		{"combined", PruneOptions{OlderThan: 90 * 24 * time.Hour, MaxSize: 60}, "old@1,unused@1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := names(SelectPrune(entries, test.options, now)); got != test.want {
+				t.Errorf("SelectPrune() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseAgeAndSize(t *testing.T) {
+	ages := map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "12h": 12 * time.Hour}
+	for value, want := range ages {
+		if got, err := ParseAge(value); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "d", "-1d", "abc"} {
+		if _, err := ParseAge(value); err == nil {
+			t.Errorf("ParseAge(%q) should fail", value)
+		}
+	}
+
+	sizes := map[string]int64{"1024": 1024, "500M": 500 << 20, "20G": 20 << 30, "1.5gb": 3 << 29}
+	for value, want := range sizes {
+		if got, err := ParseSize(value); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "G", "-1G", "ten"} {
+		if _, err := ParseSize(value); err == nil {
+			t.Errorf("ParseSize(%q) should fail", value)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package manage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PruneOptions decides which entries are evicted, entries matching any of them are evicted.
+type PruneOptions struct {
+	OlderThan time.Duration // Evict entries stored before now - OlderThan.
+	UnusedFor time.Duration // Evict entries not accessed since now - UnusedFor.
+	MaxSize   int64         // Evict least recently accessed entries until total size is not greater than it.
+}
+
+// Empty returns true when no option is specified.
+func (p PruneOptions) Empty() bool {
+	return p.OlderThan == 0 && p.UnusedFor == 0 && p.MaxSize == 0
+}
+
+// SelectPrune selects entries to evict.
+func SelectPrune(entries []Entry, options PruneOptions, now time.Time) []Entry {
+	var (
+		evicted  []Entry
+		retained []Entry
+	)
+
+	for _, entry := range entries {
+		switch {
+		case options.OlderThan > 0 && now.Sub(entry.ModTime) > options.OlderThan:
+			evicted = append(evicted, entry)
+		case options.UnusedFor > 0 && now.Sub(entry.AccessTime) > options.UnusedFor:
+			evicted = append(evicted, entry)
+		default:
+			retained = append(retained, entry)
+		}
+	}
+
+	if options.MaxSize > 0 {
+		var totalSize int64
+		for _, entry := range retained {
+			totalSize += entry.Size
+		}
+
+		// Least recently accessed entries are evicted first.
+		slices.SortStableFunc(retained, func(a, b Entry) int {
+			return a.AccessTime.Compare(b.AccessTime)
+		})
+		for _, entry := range retained {
+			if totalSize <= options.MaxSize {
+				break
+			}
+			evicted = append(evicted, entry)
+			totalSize -= entry.Size
+		}
+	}
+
+	return evicted
+}
+
+// FilterByName returns entries of name@version.
+func FilterByName(entries []Entry, nameVersion string) []Entry {
+	var matched []Entry
+	for _, entry := range entries {
+		if entry.Name == nameVersion {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Remove removes archive, meta and debug archive of entry, and the dirs left empty.
+func Remove(entry Entry) error {
+	files := []string{entry.Path}
+	if entry.MetaPath != "" {
+		files = append(files, entry.MetaPath)
+	}
+	if entry.DebugPath != "" {
+		files = append(files, entry.DebugPath)
+	}
+	return RemoveFiles(files)
+}
+
+// RemoveFiles removes files, and their parent dirs if they're left empty.
+func RemoveFiles(files []string) error {
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s -> %w", file, err)
+		}
+
+		// Remove empty "metas" or "debug" dir and then empty name@version dir.
+		dir := filepath.Dir(file)
+		for range 2 {
+			if os.Remove(dir) != nil {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	return nil
+}
+
+// ParseAge parses age like "30d", "2w" or any value accepted by time.ParseDuration, such as "12h".
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(number)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid age %q, it should be like 12h, 30d or 2w", value)
+	}
+	return duration, nil
+}
+
+// ParseSize parses size like "500M", "20G" or "20GB", units are in 1024.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	value = strings.TrimSuffix(value, "B")
+
+	unit := int64(1)
+	for index, suffix := range []string{"K", "M", "G", "T"} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			value = number
+			unit = int64(1) << (10 * (index + 1))
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid size %q, it should be like 500M or 20G", size)
+	}
+	return int64(number * float64(unit)), nil
+}
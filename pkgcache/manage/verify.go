@@ -0,0 +1,126 @@
+package manage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// Problem is a broken cache entry found by Verify.
+type Problem struct {
+	Kind   Kind     `json:"kind"`
+	Path   string   `json:"path"`
+	Reason string   `json:"reason"`
+	Files  []string `json:"files"` // Files to remove to fix the problem.
+}
+
+// Verify checks cache entries of kinds:
+//   - artifacts and dev: meta is re-hashed against archive name, orphan archives and metas are reported.
+//   - repos: archives of archive source are re-hashed against their name.
+//   - downloads: files are re-hashed against the sha-256 in their name.
+func Verify(dirs Dirs, kinds []Kind) ([]Problem, error) {
+	entries, err := Scan(dirs, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, entry := range entries {
+		problem, err := verifyEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		if problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+
+	// Metas left without archive.
+	for _, kind := range kinds {
+		rootDir := dirs.Get(kind)
+		if rootDir == "" || !fileio.PathExists(rootDir) {
+			continue
+		}
+
+		var pattern []string
+		switch kind {
+		case KindArtifacts:
+			pattern = []string{rootDir, "*", "*", "*", "*", "metas", "*.meta"}
+		case KindDev:
+			pattern = []string{rootDir, "*", "metas", "*.meta"}
+		default:
+			continue
+		}
+
+		metas, err := filepath.Glob(filepath.Join(pattern...))
+		if err != nil {
+			return nil, err
+		}
+		for _, metaPath := range metas {
+			hash := strings.TrimSuffix(filepath.Base(metaPath), ".meta")
+			archivePath := filepath.Join(filepath.Dir(filepath.Dir(metaPath)), hash+".tar.gz")
+			if !fileio.PathExists(archivePath) {
+				problems = append(problems, Problem{
+					Kind:   kind,
+					Path:   metaPath,
+					Reason: "orphan meta, archive is missing",
+					Files:  []string{metaPath},
+				})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+func verifyEntry(entry Entry) (*Problem, error) {
+	switch entry.Kind {
+	case KindArtifacts, KindDev:
+		if entry.MetaPath == "" {
+			return &Problem{
+				Kind:   entry.Kind,
+				Path:   entry.Path,
+				Reason: "orphan archive, meta is missing",
+				Files:  []string{entry.Path},
+			}, nil
+		}
+
+		metaBytes, err := os.ReadFile(entry.MetaPath)
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprintf("%x", sha256.Sum256(metaBytes)) != entry.Hash {
+			return &Problem{
+				Kind:   entry.Kind,
+				Path:   entry.Path,
+				Reason: "meta checksum mismatch",
+				Files:  []string{entry.Path, entry.MetaPath},
+			}, nil
+		}
+
+	case KindRepos, KindDownloads:
+		// Git repos are named with commit, they can only be verified after extracted.
+		if len(entry.Hash) != sha256.Size*2 {
+			return nil, nil
+		}
+
+		checksum, err := fileio.SHA256Sum(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != entry.Hash {
+			return &Problem{
+				Kind:   entry.Kind,
+				Path:   entry.Path,
+				Reason: fmt.Sprintf("sha-256 mismatch, got %s", checksum),
+				Files:  []string{entry.Path},
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
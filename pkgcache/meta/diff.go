@@ -0,0 +1,212 @@
+package meta
+
+import (
+	"regexp"
+	"strings"
+)
+
+// dividerRegex matches divider lines written by `newDivider`.
+var dividerRegex = regexp.MustCompile(`^# -------- (.*) --------$`)
+
+// Section is a part of meta led by a divider, such as platform, port, patch,
+// dependency and build tools versions.
+type Section struct {
+	Title   string
+	Content string
+}
+
+// ParseSections splits meta into sections by dividers.
+func ParseSections(meta string) []Section {
+	var (
+		sections []Section
+		current  *Section
+		lines    []string
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Content = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+			sections = append(sections, *current)
+		}
+		lines = nil
+	}
+
+	for line := range strings.SplitSeq(meta, "\n") {
+		if match := dividerRegex.FindStringSubmatch(line); match != nil {
+			flush()
+			current = &Section{Title: match[1]}
+			continue
+		}
+
+		// Content before the first divider is kept in an untitled section.
+		if current == nil {
+			current = &Section{}
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return sections
+}
+
+const (
+	SectionAdded   = "added"
+	SectionRemoved = "removed"
+	SectionChanged = "changed"
+)
+
+// SectionDiff describes how a section differs between stored and current meta.
+type SectionDiff struct {
+	Title  string   `json:"title"`
+	Status string   `json:"status"`          // added, removed or changed.
+	Lines  []string `json:"lines,omitempty"` // Lines prefixed with "-" or "+".
+}
+
+// Diff compares sections of stored and current meta, sections with the same
+// title are compared line by line, unchanged sections are not returned.
+func Diff(stored, current string) []SectionDiff {
+	storedSections := ParseSections(stored)
+	currentSections := ParseSections(current)
+
+	storedIndex := make(map[string]Section)
+	for _, section := range storedSections {
+		storedIndex[section.Title] = section
+	}
+	currentIndex := make(map[string]Section)
+	for _, section := range currentSections {
+		currentIndex[section.Title] = section
+	}
+
+	var diffs []SectionDiff
+	for _, section := range currentSections {
+		storedSection, ok := storedIndex[section.Title]
+		switch {
+		case !ok:
+			diffs = append(diffs, SectionDiff{
+				Title:  section.Title,
+				Status: SectionAdded,
+				Lines:  prefixLines("+", section.Content),
+			})
+		case storedSection.Content != section.Content:
+			diffs = append(diffs, SectionDiff{
+				Title:  section.Title,
+				Status: SectionChanged,
+				Lines:  diffLines(storedSection.Content, section.Content),
+			})
+		}
+	}
+	for _, section := range storedSections {
+		if _, ok := currentIndex[section.Title]; !ok {
+			diffs = append(diffs, SectionDiff{
+				Title:  section.Title,
+				Status: SectionRemoved,
+				Lines:  prefixLines("-", section.Content),
+			})
+		}
+	}
+
+	return diffs
+}
+
+// Distance measures how far stored meta is from current meta, it's the count
+// of differing lines, it's used to find the closest stored meta.
+func Distance(stored, current string) int {
+	var distance int
+	for _, diff := range Diff(stored, current) {
+		distance += max(len(diff.Lines), 1)
+	}
+	return distance
+}
+
+func prefixLines(prefix, content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(content, "\n") {
+		lines = append(lines, prefix+" "+line)
+	}
+	return lines
+}
+
+// maxLcsCells limits memory of line diff, larger sections fall back to set diff.
+const maxLcsCells = 4 << 20
+
+// diffLines returns removed and added lines between two contents,
+// with longest common subsequence to keep the order of lines.
+func diffLines(oldContent, newContent string) []string {
+	oldLines := strings.Split(oldContent, "\n")
+	newLines := strings.Split(newContent, "\n")
+
+	if len(oldLines)*len(newLines) > maxLcsCells {
+		return diffLineSets(oldLines, newLines)
+	}
+
+	// lcs[i][j] is the length of LCS of oldLines[i:] and newLines[j:].
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+oldLines[i])
+			i++
+		default:
+			lines = append(lines, "+ "+newLines[j])
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		lines = append(lines, "- "+oldLines[i])
+	}
+	for ; j < len(newLines); j++ {
+		lines = append(lines, "+ "+newLines[j])
+	}
+	return lines
+}
+
+func diffLineSets(oldLines, newLines []string) []string {
+	counts := make(map[string]int)
+	for _, line := range newLines {
+		counts[line]++
+	}
+
+	var lines []string
+	for _, line := range oldLines {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			lines = append(lines, "- "+line)
+		}
+	}
+
+	counts = make(map[string]int)
+	for _, line := range oldLines {
+		counts[line]++
+	}
+	for _, line := range newLines {
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			lines = append(lines, "+ "+line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,76 @@
+package meta
+
+import (
+	"strings"
+	"testing"
+)
+
+func buildTestMeta(sections ...[2]string) string {
+	var builder strings.Builder
+	for _, section := range sections {
+		builder.WriteString(newDivider(nil, "zlib@1.3.1", section[0]))
+		builder.WriteString(section[1] + "\n")
+	}
+	return builder.String()
+}
+
+func TestParseSections(t *testing.T) {
+	metaData := buildTestMeta(
+		[2]string{"platform", "arch = x86_64"},
+		[2]string{"port", "url = a\nref = b"},
+	)
+
+	sections := ParseSections(metaData)
+	if len(sections) != 2 {
+		t.Fatalf("ParseSections() = %d sections, want 2", len(sections))
+	}
+	if sections[0].Title != "zlib@1.3.1 <<< platform" || sections[0].Content != "arch = x86_64" {
+		t.Errorf("sections[0] = %+v", sections[0])
+	}
+	if sections[1].Title != "zlib@1.3.1 <<< port" || sections[1].Content != "url = a\nref = b" {
+		t.Errorf("sections[1] = %+v", sections[1])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	stored := buildTestMeta(
+		[2]string{"build tools versions", "cmake 3.30.5"},
+		[2]string{"platform", "arch = x86_64"},
+		[2]string{"port", "url = a\nref = b\nbuild_system = cmake"},
+		[2]string{"patch: old.patch", "diff old"},
+	)
+	current := buildTestMeta(
+		[2]string{"build tools versions", "cmake 3.30.5"},
+		[2]string{"platform", "arch = x86_64"},
+		[2]string{"port", "url = a\nref = c\nbuild_system = cmake"},
+		[2]string{"patch: new.patch", "diff new"},
+	)
+
+	diffs := Diff(stored, current)
+	got := make(map[string]SectionDiff)
+	for _, diff := range diffs {
+		got[diff.Title] = diff
+	}
+	if len(diffs) != 3 {
+		t.Fatalf("Diff() = %+v, want 3 sections", diffs)
+	}
+
+	port := got["zlib@1.3.1 <<< port"]
+	if port.Status != SectionChanged || strings.Join(port.Lines, "|") != "- ref = b|+ ref = c" {
+		t.Errorf("port diff = %+v", port)
+	}
+	if got["zlib@1.3.1 <<< patch: new.patch"].Status != SectionAdded {
+		t.Errorf("new patch diff = %+v", got["zlib@1.3.1 <<< patch: new.patch"])
+	}
+	if got["zlib@1.3.1 <<< patch: old.patch"].Status != SectionRemoved {
+		t.Errorf("old patch diff = %+v", got["zlib@1.3.1 <<< patch: old.patch"])
+	}
+
+	if distance := Distance(current, current); distance != 0 {
+		t.Errorf("Distance() of same meta = %d, want 0", distance)
+	}
+	farther := buildTestMeta([2]string{"port", "url = b\nref = d\nbuild_system = cmake"})
+	if Distance(stored, current) >= Distance(farther, current) {
+		t.Errorf("Distance() should be smaller for closer meta")
+	}
+}
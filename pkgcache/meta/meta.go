@@ -51,6 +51,7 @@ type BuildConfig struct {
 	Dependencies    []string
 	DevDependencies []string
 	BuildTools      []string
+	Features        []string
 	PortFile        string
 }
 
@@ -127,6 +128,12 @@ func (p Port) buildMeta() (string, error) {
 	p.writeSectionTitle(&buffer, p.Parents, p.NameVersion, "port")
 	fmt.Fprintf(&buffer, "%s\n", content)
 
+	// Write selected features, only when any is selected to keep meta of other ports unchanged.
+	if len(p.BuildConfig.Features) > 0 {
+		p.writeSectionTitle(&buffer, p.Parents, p.NameVersion, "features")
+		fmt.Fprintf(&buffer, "%s\n\n", strings.Join(p.BuildConfig.Features, ","))
+	}
+
 	// Write content of patches.
 	for _, patch := range p.BuildConfig.Patches {
 		content, err := p.readPatch(patch)
@@ -37,10 +37,10 @@ func NewArtifactConfig(ctx context.Context) *ArtifactConfig {
 
 // Restore restores the cached package to package directory if cache hit, and return the archive path.
 // If cache miss, just return empty string without error.
-func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (string, error) {
+func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (string, int64, error) {
 	// skip restore cache when offline.
 	if a.ctx.Offline() {
-		return "", nil
+		return "", 0, nil
 	}
 
 	platformName := a.ctx.Platform().GetName()
@@ -52,7 +52,7 @@ func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (stri
 	archivePath := filepath.Join(archiveDir, buildHash+".tar.gz")
 	if !fileio.PathExists(archivePath) {
 		color.PrintWarning("======== no artifact found for %s and it'll build from source ========", nameVersion)
-		return "", nil // not an error even not exist.
+		return "", 0, nil // not an error even not exist.
 	}
 
 	// The meta file hash should be the same as hash that calcuated dynamically.
@@ -60,22 +60,22 @@ func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (stri
 	metaBytes, err := os.ReadFile(metaPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("cache archive exists but metadata is missing: %s", metaPath)
+			return "", 0, fmt.Errorf("cache archive exists but metadata is missing: %s", metaPath)
 		}
-		return "", err
+		return "", 0, err
 	}
 	metaHash := sha256.Sum256(metaBytes)
 	if fmt.Sprintf("%x", metaHash) != buildHash {
-		return "", fmt.Errorf("cache metadata checksum mismatch for %s", nameVersion)
+		return "", 0, fmt.Errorf("cache metadata checksum mismatch for %s", nameVersion)
 	}
 
 	// Create tmp dir for extracting inside.
 	if err := dirs.CleanTmpFilesDir(); err != nil {
-		return "", fmt.Errorf("failed to clean tmp files dir -> %w", err)
+		return "", 0, fmt.Errorf("failed to clean tmp files dir -> %w", err)
 	}
 	tempDir, err := os.MkdirTemp(dirs.TmpFilesDir, "pkgcache-extract-*")
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	defer os.RemoveAll(tempDir)
 
@@ -92,38 +92,42 @@ func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (stri
 		}
 	}
 	if restoreErr != nil {
-		return "", restoreErr
+		return "", 0, restoreErr
+	}
+	archiveInfo, err := os.Stat(archivePath)
+	if err != nil {
+		return "", 0, err
 	}
 	if err := os.RemoveAll(packageDir); err != nil {
-		return "", err
+		return "", 0, err
 	}
 	if err := os.MkdirAll(filepath.Dir(packageDir), os.ModePerm); err != nil {
-		return "", err
+		return "", 0, err
 	}
 	if err := os.Rename(tempDir, packageDir); err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	return archivePath, nil
+	return archivePath, archiveInfo.Size(), nil
 }
 
 // Store compresses the package dir and store in cache,
 // the meta is expected to be a string and would be used to calculate the hash key for cache.
-func (a ArtifactConfig) Store(packageDir, meta string) error {
+func (a ArtifactConfig) Store(packageDir, meta string) (int64, error) {
 	// skip storing cache when offline.
 	if a.ctx.Offline() {
-		return nil
+		return 0, nil
 	}
 
 	if !fileio.PathExists(packageDir) {
-		return fmt.Errorf("package dir does not exist: %s", packageDir)
+		return 0, fmt.Errorf("package dir does not exist: %s", packageDir)
 	}
 
 	// Validate packageDir format and extract metadata.
 	// Path format: packages/platform/project/buildType/nameVersion
 	parts := strings.Split(filepath.ToSlash(packageDir), "/")
 	if len(parts) < 5 {
-		return fmt.Errorf("invalid package dir: %s", packageDir)
+		return 0, fmt.Errorf("invalid package dir: %s", packageDir)
 	}
 
 	// Extract from path components.
@@ -135,7 +139,7 @@ func (a ArtifactConfig) Store(packageDir, meta string) error {
 	// Validate nameVersion format (should be name@version)
 	versionParts := strings.Split(nameVersion, "@")
 	if len(versionParts) != 2 {
-		return fmt.Errorf("invalid package dir: %s", packageDir)
+		return 0, fmt.Errorf("invalid package dir: %s", packageDir)
 	}
 
 	var (
@@ -153,59 +157,111 @@ func (a ArtifactConfig) Store(packageDir, meta string) error {
 	hash := fmt.Sprintf("%x", data)
 	archivePath := filepath.Join(destDir, hash+".tar.gz")
 
+	// Lock the cache key, other processes may be storing the same artifact.
+	rootDir := a.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRoot)
+	lock, err := lockEntry(rootDir, "artifacts", platformName, projectName, buildType, nameVersion, hash)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Release()
+
 	// Skip if already cached — rebuild with same metadata produces identical output.
 	if fileio.PathExists(archivePath) {
-		return nil
+		return 0, nil
 	}
 
 	// Compress package dir to a temp archive.
 	archiveName := fmt.Sprintf("%s@%s.tar.gz", libName, libVersion)
 	if err := dirs.CleanTmpFilesDir(); err != nil {
-		return fmt.Errorf("failed to clean tmp files dir -> %w", err)
+		return 0, fmt.Errorf("failed to clean tmp files dir -> %w", err)
 	}
 	tempArchive, err := os.CreateTemp(dirs.TmpFilesDir, archiveName+".*")
 	if err != nil {
-		return err
+		return 0, err
 	}
 	tempArchivePath := tempArchive.Name()
 	tempArchive.Close()
 	defer os.Remove(tempArchivePath)
 
 	if err := fileio.Targz(tempArchivePath, packageDir, false); err != nil {
-		return err
+		return 0, err
+	}
+	archiveInfo, err := os.Stat(tempArchivePath)
+	if err != nil {
+		return 0, err
 	}
 
 	// Create dirs and write to cache (with retry for NFS transient issues).
 	if err := os.MkdirAll(destDir, fileio.CacheDirPerm); err != nil {
-		return err
+		return 0, err
 	}
 	if err := os.MkdirAll(metaDir, fileio.CacheDirPerm); err != nil {
-		return err
+		return 0, err
 	}
 
-	// Copy the compressed archive to cache. Retry on transient IO failures.
+	// Write meta file first, an archive is taken as cached only when its meta exists.
+	// Meta may be left by an interrupted store, it's named by its checksum, so it's reusable.
+	metaPath := filepath.Join(metaDir, hash+".meta")
+	if !fileio.PathExists(metaPath) {
+		tempMetaPath := tempArchivePath + ".meta"
+		if err := os.WriteFile(tempMetaPath, []byte(meta), fileio.CacheFilePerm); err != nil {
+			return 0, err
+		}
+		defer os.Remove(tempMetaPath)
+		if err := publishFile(rootDir, tempMetaPath, metaPath); err != nil {
+			return 0, err
+		}
+	}
+
+	// Publish the compressed archive to cache. Retry on transient IO failures.
 	var storeErr error
 	for attempt := 1; attempt <= a.maxRetries; attempt++ {
-		storeErr = fileio.CopyFile(tempArchivePath, archivePath)
+		storeErr = publishFile(rootDir, tempArchivePath, archivePath)
 		if storeErr == nil {
 			break
 		}
 		if attempt < a.maxRetries {
-			color.Printf(color.Warning, "Store pkgcache failed (attempt %d/%d): %v\n", attempt, a.maxRetries, err)
+			color.Printf(color.Warning, "Store pkgcache failed (attempt %d/%d): %v\n", attempt, a.maxRetries, storeErr)
 			time.Sleep(time.Duration(attempt) * time.Second)
 		}
 	}
 	if storeErr != nil {
-		return err
+		return 0, storeErr
 	}
 
-	// Write meta file.
-	metaPath := filepath.Join(metaDir, hash+".meta")
-	if err := os.WriteFile(metaPath, []byte(meta), fileio.CacheFilePerm); err != nil {
+	return archiveInfo.Size(), nil
+}
+
+// StoreDebug stores archive of split debug files as debug/<hash>.tar.gz next to
+// the cached package, it's skipped when the package is not cached or already has it.
+func (a ArtifactConfig) StoreDebug(nameVersion, buildHash, archivePath string) error {
+	// skip storing cache when offline.
+	if a.ctx.Offline() || !a.Exist(nameVersion, buildHash) {
+		return nil
+	}
+
+	platformName := a.ctx.Platform().GetName()
+	projectName := a.ctx.Project().GetName()
+	buildType := a.ctx.BuildType()
+	artifactCacheDir := a.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirArtifacts)
+	debugDir := filepath.Join(artifactCacheDir, platformName, projectName, buildType, nameVersion, "debug")
+	debugPath := filepath.Join(debugDir, buildHash+".tar.gz")
+
+	// Lock the cache key, other processes may be storing the same debug archive.
+	rootDir := a.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRoot)
+	lock, err := lockEntry(rootDir, "artifacts", platformName, projectName, buildType, nameVersion, buildHash+".debug")
+	if err != nil {
 		return err
 	}
+	defer lock.Release()
 
-	return nil
+	if fileio.PathExists(debugPath) {
+		return nil
+	}
+	if err := os.MkdirAll(debugDir, fileio.CacheDirPerm); err != nil {
+		return err
+	}
+	return publishFile(rootDir, archivePath, debugPath)
 }
 
 // Remove removes the cache for the specified platform, project, build type and name version.
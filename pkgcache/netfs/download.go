@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/celer-pkg/celer/context"
 	"github.com/celer-pkg/celer/pkgcache"
@@ -87,6 +86,14 @@ func (d DownloadConfig) Store(fileName, sha256, srcFile string) (string, error)
 	cachedFileName := fmt.Sprintf("%s-%s%s", fileio.Base(fileName), sha256, fileio.Ext(fileName))
 	cachedFilePath := filepath.Join(d.cacheDir, cachedFileName)
 
+	// Lock the cached file, other processes may be storing it.
+	rootDir := filepath.Dir(d.cacheDir)
+	lock, err := lockEntry(rootDir, "downloads", cachedFileName)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
 	// If cache file exists and SHA256 matches, return it directly.
 	if fileio.PathExists(cachedFilePath) {
 		if fileio.VerifyFileSHA256(cachedFilePath, sha256) {
@@ -94,25 +101,16 @@ func (d DownloadConfig) Store(fileName, sha256, srcFile string) (string, error)
 		}
 	}
 
-	// Write to NFS tmp dir first (excluded from chattr +a),
-	// then atomically rename into final location to avoid partial reads.
-	nfsTmpDir := filepath.Join(filepath.Dir(d.cacheDir), "tmp")
-	nfsTmpFileName := fmt.Sprintf("%s-%d%s", fileio.Base(fileName), time.Now().UnixNano(), fileio.Ext(fileName))
-	nfsTmpPath := filepath.Join(nfsTmpDir, nfsTmpFileName)
-	if err := os.MkdirAll(nfsTmpDir, fileio.CacheDirPerm); err != nil {
-		return "", fmt.Errorf("failed to create tmp dir -> %w", err)
-	}
-	if err := fileio.CopyFile(srcFile, nfsTmpPath); err != nil {
-		return "", fmt.Errorf("failed to copy file to cache -> %w", err)
+	// Executable permission of downloaded file is kept.
+	info, err := os.Stat(srcFile)
+	if err != nil {
+		return "", err
 	}
-	defer os.Remove(nfsTmpPath)
-
-	if err := os.Rename(nfsTmpPath, cachedFilePath); err != nil {
-		// Dest may have appeared between our check and rename (another user won the race).
-		if fileio.VerifyFileSHA256(cachedFilePath, sha256) {
-			return cachedFilePath, nil
-		}
+	perm := info.Mode().Perm() | fileio.CacheFilePerm
 
+	// Write to NFS tmp dir first (excluded from chattr +a),
+	// then atomically rename into final location to avoid partial reads.
+	if err := fileio.CopyFileAtomic(srcFile, cachedFilePath, stagingDir(rootDir), perm); err != nil {
 		// Rename failed — likely chattr +a dir with existing corrupt dest.
 		// Fall back to in-place overwrite (O_TRUNC).
 		if err := fileio.CopyFile(srcFile, cachedFilePath); err != nil {
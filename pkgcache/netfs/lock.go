@@ -0,0 +1,26 @@
+package netfs
+
+import (
+	"path/filepath"
+
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/filelock"
+)
+
+// stagingDir is the "tmp" dir of pkgcache, it's excluded from chattr +a.
+// Files are written into it first and then renamed into cache dirs, so that
+// readers never see partial files, lock files are kept in it since they must
+// be removable.
+func stagingDir(rootDir string) string {
+	return filepath.Join(rootDir, "tmp")
+}
+
+// lockEntry locks a cache entry, so that only one process stores it at a time.
+func lockEntry(rootDir string, parts ...string) (*filelock.Lock, error) {
+	return filelock.Acquire(filepath.Join(stagingDir(rootDir), "locks", filepath.Join(parts...)+".lock"))
+}
+
+// publishFile copies src into cache atomically.
+func publishFile(rootDir, src, dest string) error {
+	return fileio.CopyFileAtomic(src, dest, stagingDir(rootDir), fileio.CacheFilePerm)
+}
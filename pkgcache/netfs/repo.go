@@ -62,6 +62,14 @@ func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (st
 			return "", fmt.Errorf("read current commit -> %w", err)
 		}
 
+		// Lock the repo archive, other processes may be storing it.
+		rootDir := r.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRoot)
+		lock, err := lockEntry(rootDir, "repos", nameVersion, commit)
+		if err != nil {
+			return "", err
+		}
+		defer lock.Release()
+
 		// Ignore when repo archive is stored before.
 		// Archive name will be like: x264@stable/472338e072b6a83fd47825cc91cef81dc848e564.tar.gz
 		archivePath := filepath.Join(cacheRepoDir, nameVersion, commit+".tar.gz")
@@ -74,8 +82,8 @@ func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (st
 			return "", err
 		}
 
-		// Compress to temp dir first (outside cache), then copy to final path.
-		// chattr +a allows creating new files but not renaming.
+		// Compress to temp dir first (outside cache), then publish to final path
+		// through pkgcache's tmp dir, chattr +a allows renaming into cache dirs but not inside them.
 		if err := dirs.CleanTmpFilesDir(); err != nil {
 			return "", fmt.Errorf("failed to clean tmp files dir -> %w", err)
 		}
@@ -84,7 +92,7 @@ func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (st
 			return "", err
 		}
 		defer os.Remove(tempArchivePath)
-		if err := fileio.CopyFile(tempArchivePath, archivePath); err != nil {
+		if err := publishFile(rootDir, tempArchivePath, archivePath); err != nil {
 			return "", err
 		}
 
@@ -100,6 +108,14 @@ func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (st
 			return "", err
 		}
 
+		// Lock the repo archive, other processes may be storing it.
+		rootDir := r.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRoot)
+		lock, err := lockEntry(rootDir, "repos", nameVersion, checksum)
+		if err != nil {
+			return "", err
+		}
+		defer lock.Release()
+
 		// Preserve original archive extension so Extract dispatches correctly.
 		ext := fileio.Ext(filepath.Base(archiveFile))
 		repoCacheDir := r.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRepos)
@@ -116,7 +132,7 @@ func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (st
 		}
 
 		// Copy original archive to repo cache dir.
-		if err := fileio.CopyFile(archiveFile, archivePath); err != nil {
+		if err := publishFile(rootDir, archiveFile, archivePath); err != nil {
 			return "", err
 		}
 
@@ -211,16 +227,19 @@ func (r RepoConfig) Restore(nameVersion, repoUrl, repoDir, checksum string) (str
 	return archivePath, nil
 }
 
-// shouldCacheRepo default we cache all third-party library repos that defined in ports dir.
+// shouldCacheRepo default we cache all third-party library repos that defined in registries and ports dir.
 func (r RepoConfig) shouldCacheRepo(nameVersion string) bool {
 	parts := strings.Split(nameVersion, "@")
 	if len(parts) != 2 {
 		panic("invalid nameVersion: " + nameVersion)
 	}
 
-	// Only cache third-party repos that defined in ports dir.
+	// Only cache third-party repos that defined in registries and ports dir.
 	portName := parts[0]
-	groupChar := strings.ToLower(string([]rune(portName)[0]))
-	portPath := filepath.Join(dirs.PortsDir, groupChar, portName)
-	return fileio.PathExists(portPath)
+	for _, registry := range r.ctx.Registries() {
+		if registry.Provides(portName) && fileio.PathExists(filepath.Dir(registry.PortDir(portName, "_"))) {
+			return true
+		}
+	}
+	return false
 }
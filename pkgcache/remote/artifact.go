@@ -0,0 +1,223 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+type ArtifactConfig struct {
+	ctx      context.Context
+	client   *Client
+	writable bool
+}
+
+func NewArtifactConfig(ctx context.Context, client *Client) *ArtifactConfig {
+	pkgCacheConfig := ctx.PkgCacheConfig()
+	if pkgCacheConfig == nil || pkgCacheConfig.GetDir(pkgcache.PkgCacheDirArtifacts) == "" {
+		return nil
+	}
+
+	return &ArtifactConfig{
+		ctx:      ctx,
+		client:   client,
+		writable: pkgCacheConfig.IsWritable(),
+	}
+}
+
+// Restore downloads the cached package and extracts it to package directory if cache hit,
+// and return the archive url. If cache miss, just return empty string without error.
+func (a ArtifactConfig) Restore(nameVersion, buildHash, packageDir string) (string, int64, error) {
+	// skip restore cache when offline.
+	if a.ctx.Offline() {
+		return "", 0, nil
+	}
+
+	archiveDir := a.archiveDir(a.ctx.Platform().GetName(), a.ctx.Project().GetName(), a.ctx.BuildType(), nameVersion)
+	archiveUrl := joinUrl(archiveDir, buildHash+".tar.gz")
+	exist, err := a.client.Exist(archiveUrl)
+	if err != nil {
+		return "", 0, err
+	}
+	if !exist {
+		color.PrintWarning("======== no artifact found for %s and it'll build from source ========", nameVersion)
+		return "", 0, nil // not an error even not exist.
+	}
+
+	// The meta file hash should be the same as hash that calcuated dynamically.
+	metaUrl := joinUrl(archiveDir, "metas", buildHash+".meta")
+	metaBytes, found, err := a.client.Read(metaUrl)
+	if err != nil {
+		return "", 0, err
+	}
+	if !found {
+		return "", 0, fmt.Errorf("cache archive exists but metadata is missing: %s", metaUrl)
+	}
+	metaHash := sha256.Sum256(metaBytes)
+	if fmt.Sprintf("%x", metaHash) != buildHash {
+		return "", 0, fmt.Errorf("cache metadata checksum mismatch for %s", nameVersion)
+	}
+
+	// Create tmp dir for downloading and extracting inside.
+	if err := dirs.CleanTmpFilesDir(); err != nil {
+		return "", 0, fmt.Errorf("failed to clean tmp files dir -> %w", err)
+	}
+	tempDir, err := os.MkdirTemp(dirs.TmpFilesDir, "pkgcache-extract-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	archivePath := filepath.Join(dirs.TmpFilesDir, filepath.Base(tempDir)+".tar.gz")
+	defer os.Remove(archivePath)
+	if found, err := a.client.Download(archiveUrl, archivePath); err != nil {
+		return "", 0, err
+	} else if !found {
+		return "", 0, fmt.Errorf("cache archive disappeared while downloading: %s", archiveUrl)
+	}
+	archiveInfo, err := os.Stat(archivePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := fileio.Extract(archivePath, tempDir); err != nil {
+		return "", 0, err
+	}
+	if err := os.RemoveAll(packageDir); err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(packageDir), os.ModePerm); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tempDir, packageDir); err != nil {
+		return "", 0, err
+	}
+
+	return archiveUrl, archiveInfo.Size(), nil
+}
+
+// Store compresses the package dir and uploads to cache,
+// the meta is expected to be a string and would be used to calculate the hash key for cache.
+func (a ArtifactConfig) Store(packageDir, meta string) (int64, error) {
+	// skip storing cache when offline.
+	if a.ctx.Offline() {
+		return 0, nil
+	}
+
+	if !fileio.PathExists(packageDir) {
+		return 0, fmt.Errorf("package dir does not exist: %s", packageDir)
+	}
+
+	// Validate packageDir format and extract metadata.
+	// Path format: packages/platform/project/buildType/nameVersion
+	parts := strings.Split(filepath.ToSlash(packageDir), "/")
+	if len(parts) < 5 {
+		return 0, fmt.Errorf("invalid package dir: %s", packageDir)
+	}
+
+	// Extract from path components.
+	nameVersion := parts[len(parts)-1]
+	buildType := parts[len(parts)-2]
+	projectName := parts[len(parts)-3]
+	platformName := parts[len(parts)-4]
+
+	// Validate nameVersion format (should be name@version)
+	if len(strings.Split(nameVersion, "@")) != 2 {
+		return 0, fmt.Errorf("invalid package dir: %s", packageDir)
+	}
+
+	// Calculate checksum of metadata，this would be the cache key.
+	data := sha256.Sum256([]byte(meta))
+	hash := fmt.Sprintf("%x", data)
+	archiveDir := a.archiveDir(platformName, projectName, buildType, nameVersion)
+	archiveUrl := joinUrl(archiveDir, hash+".tar.gz")
+
+	// Skip if already cached — rebuild with same metadata produces identical output.
+	exist, err := a.client.Exist(archiveUrl)
+	if err != nil {
+		return 0, err
+	}
+	if exist {
+		return 0, nil
+	}
+
+	// Compress package dir to a temp archive.
+	if err := dirs.CleanTmpFilesDir(); err != nil {
+		return 0, fmt.Errorf("failed to clean tmp files dir -> %w", err)
+	}
+	tempArchive, err := os.CreateTemp(dirs.TmpFilesDir, nameVersion+".tar.gz.*")
+	if err != nil {
+		return 0, err
+	}
+	tempArchivePath := tempArchive.Name()
+	tempArchive.Close()
+	defer os.Remove(tempArchivePath)
+
+	if err := fileio.Targz(tempArchivePath, packageDir, false); err != nil {
+		return 0, err
+	}
+	archiveInfo, err := os.Stat(tempArchivePath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Upload archive first and then meta, meta is only visible when archive is complete.
+	if err := a.client.UploadFile(archiveUrl, tempArchivePath); err != nil {
+		return 0, err
+	}
+	if err := a.client.Upload(joinUrl(archiveDir, "metas", hash+".meta"), []byte(meta)); err != nil {
+		return 0, err
+	}
+
+	return archiveInfo.Size(), nil
+}
+
+// StoreDebug uploads archive of split debug files as debug/<hash>.tar.gz next to
+// the cached package, it's skipped when the package is not cached or already has it.
+func (a ArtifactConfig) StoreDebug(nameVersion, buildHash, archivePath string) error {
+	// skip storing cache when offline.
+	if a.ctx.Offline() || !a.Exist(nameVersion, buildHash) {
+		return nil
+	}
+
+	archiveDir := a.archiveDir(a.ctx.Platform().GetName(), a.ctx.Project().GetName(), a.ctx.BuildType(), nameVersion)
+	debugUrl := joinUrl(archiveDir, "debug", buildHash+".tar.gz")
+	exist, err := a.client.Exist(debugUrl)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return nil
+	}
+	return a.client.UploadFile(debugUrl, archivePath)
+}
+
+// Exist check both archive file and meta file exist.
+func (a ArtifactConfig) Exist(nameVersion, hash string) bool {
+	archiveDir := a.archiveDir(a.ctx.Platform().GetName(), a.ctx.Project().GetName(), a.ctx.BuildType(), nameVersion)
+	archiveExist, err := a.client.Exist(joinUrl(archiveDir, hash+".tar.gz"))
+	if err != nil || !archiveExist {
+		return false
+	}
+	metaExist, err := a.client.Exist(joinUrl(archiveDir, "metas", hash+".meta"))
+	return err == nil && metaExist
+}
+
+func (a ArtifactConfig) archiveDir(platformName, projectName, buildType, nameVersion string) string {
+	artifactCacheUrl := a.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirArtifacts)
+	return joinUrl(artifactCacheUrl, platformName, projectName, buildType, nameVersion)
+}
+
+// joinUrl joins base url and path elements with "/".
+func joinUrl(baseUrl string, elems ...string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/" + path.Join(elems...)
+}
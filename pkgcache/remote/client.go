@@ -0,0 +1,289 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+const (
+	BackendHTTP = "http"
+	BackendS3   = "s3"
+)
+
+// DefaultTimeout limits a whole request, including transferring its body.
+const DefaultTimeout = 10 * time.Minute
+
+// connectTimeout limits connecting to server and waiting for its response,
+// so that an unreachable server fails quickly even if large transfers are allowed.
+const connectTimeout = 30 * time.Second
+
+// errObjectNotFound stops writing downloaded file when object does not exist.
+var errObjectNotFound = errors.New("object not found")
+
+// Options describes how to talk with the remote cache server.
+type Options struct {
+	Backend   string            // http or s3.
+	Headers   map[string]string // Extra headers sent with every request, such as Authorization.
+	Region    string            // Region used to sign s3 requests.
+	AccessKey string            // Access key used to sign s3 requests.
+	SecretKey string            // Secret key used to sign s3 requests.
+	Timeout   time.Duration     // Timeout of a whole request, DefaultTimeout when it's zero.
+}
+
+// Client is a minimal object storage client, objects are read with GET,
+// checked with HEAD and written with PUT, it's compatible with plain
+// HTTP file servers (nginx with dav, artifactory and so on) and s3.
+type Client struct {
+	options    Options
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	now        func() time.Time
+}
+
+func NewClient(options Options) *Client {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = connectTimeout
+
+	return &Client{
+		options: options,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   options.Timeout,
+		},
+		maxRetries: 3,
+		retryDelay: time.Second,
+		now:        time.Now,
+	}
+}
+
+// Exist checks if object exists.
+func (c Client) Exist(url string) (bool, error) {
+	resp, err := c.do(http.MethodHead, url, nil, emptyPayloadHash)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	default:
+		return false, fmt.Errorf("HEAD %s: unexpected status: %s", url, resp.Status)
+	}
+}
+
+// Read reads content of object, false is returned when object not exist.
+func (c Client) Read(url string) ([]byte, bool, error) {
+	var buffer bytes.Buffer
+	found, err := c.get(url, &buffer)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return buffer.Bytes(), true, nil
+}
+
+// Download downloads object to local file, false is returned when object not exist.
+// The file is written to a temp file and renamed when it's complete, so a broken
+// download never leaves a truncated archive behind.
+func (c Client) Download(url, destFile string) (bool, error) {
+	err := fileio.WriteAtomic(destFile, os.ModePerm, func(file *os.File) error {
+		found, err := c.get(url, file)
+		if err == nil && !found {
+			return errObjectNotFound
+		}
+		return err
+	})
+	if errors.Is(err, errObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Upload uploads content as object.
+func (c Client) Upload(url string, data []byte) error {
+	payloadHash := fmt.Sprintf("%x", sha256.Sum256(data))
+	open := func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+	}
+	return c.put(url, open, payloadHash)
+}
+
+// UploadFile uploads local file as object.
+func (c Client) UploadFile(url, srcFile string) error {
+	payloadHash, err := fileio.SHA256Sum(srcFile)
+	if err != nil {
+		return err
+	}
+	open := func() (io.ReadCloser, int64, error) {
+		file, err := os.Open(srcFile)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, 0, err
+		}
+		return file, info.Size(), nil
+	}
+	return c.put(url, open, payloadHash)
+}
+
+func (c Client) get(url string, writer io.Writer) (bool, error) {
+	var found bool
+	err := c.retry(http.MethodGet, url, func() (bool, error) {
+		resp, retryable, err := c.send(http.MethodGet, url, nil, emptyPayloadHash)
+		if err != nil {
+			return retryable, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			found = false
+			return false, nil
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return false, fmt.Errorf("GET %s: unexpected status: %s", url, resp.Status)
+		}
+
+		// Body may be broken in the middle, it's retried only when writer is rewound.
+		if _, err := io.Copy(writer, resp.Body); err != nil {
+			return rewind(writer), err
+		}
+		found = true
+		return false, nil
+	})
+	return found, err
+}
+
+func (c Client) put(url string, open func() (io.ReadCloser, int64, error), payloadHash string) error {
+	resp, err := c.do(http.MethodPut, url, open, payloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: unexpected status: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// do sends request with auth headers, it retries on network errors and server errors.
+func (c Client) do(method, url string, open func() (io.ReadCloser, int64, error), payloadHash string) (*http.Response, error) {
+	var resp *http.Response
+	err := c.retry(method, url, func() (bool, error) {
+		var (
+			retryable bool
+			err       error
+		)
+		resp, retryable, err = c.send(method, url, open, payloadHash)
+		return retryable, err
+	})
+	return resp, err
+}
+
+// send sends request once, network errors and server errors are retryable.
+func (c Client) send(method, url string, open func() (io.ReadCloser, int64, error), payloadHash string) (*http.Response, bool, error) {
+	req, err := c.newRequest(method, url, open, payloadHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, true, fmt.Errorf("%s %s: unexpected status: %s", method, url, resp.Status)
+	}
+	return resp, false, nil
+}
+
+// retry runs attempt until it succeeds, fails with error not retryable, or runs out of attempts,
+// it's the only layer of retries, so a request is sent at most maxRetries times.
+func (c Client) retry(method, url string, attempt func() (retryable bool, err error)) error {
+	var lastErr error
+	for count := 1; count <= c.maxRetries; count++ {
+		retryable, err := attempt()
+		if err == nil {
+			return nil
+		}
+		if !retryable {
+			return err
+		}
+
+		lastErr = err
+		if count < c.maxRetries {
+			c.waitRetry(method, url, count, err)
+		}
+	}
+	return fmt.Errorf("%s %s failed after %d attempts -> %w", method, url, c.maxRetries, lastErr)
+}
+
+func (c Client) newRequest(method, url string, open func() (io.ReadCloser, int64, error), payloadHash string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request -> %w", err)
+	}
+
+	if open != nil {
+		body, size, err := open()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+		req.ContentLength = size
+	}
+
+	for key, value := range c.options.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	if c.options.Backend == BackendS3 {
+		signV4(req, payloadHash, c.options.Region, c.options.AccessKey, c.options.SecretKey, c.now())
+	}
+
+	return req, nil
+}
+
+func (c Client) waitRetry(method, url string, attempt int, err error) {
+	color.Printf(color.Warning, "%s %s failed (attempt %d/%d): %v\n", method, url, attempt, c.maxRetries, err)
+	time.Sleep(time.Duration(attempt) * c.retryDelay)
+}
+
+// rewind resets writer before retrying a broken download.
+func rewind(writer io.Writer) bool {
+	switch writer := writer.(type) {
+	case *bytes.Buffer:
+		writer.Reset()
+		return true
+	case *os.File:
+		if _, err := writer.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+		return writer.Truncate(0) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Timeout(t *testing.T) {
+	// Server accepts requests but never responds in time.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(Options{Backend: BackendHTTP, Timeout: 100 * time.Millisecond})
+	client.maxRetries = 1
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Exist(server.URL + "/artifact.tar.gz")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("request to unresponsive server should fail")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("request to unresponsive server should time out")
+	}
+}
+
+func TestNewClient_DefaultTimeout(t *testing.T) {
+	if client := NewClient(Options{Backend: BackendHTTP}); client.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("timeout = %s, want %s", client.httpClient.Timeout, DefaultTimeout)
+	}
+}
+
+func TestClient_RetryOnce(t *testing.T) {
+	// Server always fails, every request should be sent at most maxRetries times.
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{Backend: BackendHTTP})
+	client.retryDelay = 0
+	if _, _, err := client.Read(server.URL + "/artifact.meta"); err == nil {
+		t.Fatal("request to failing server should fail")
+	}
+	if count := requests.Load(); count != int32(client.maxRetries) {
+		t.Errorf("request is sent %d times, want %d", count, client.maxRetries)
+	}
+}
+
+func TestClient_DownloadBroken(t *testing.T) {
+	// Server declares more content than it sends, so the body is always broken.
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Length", "1024")
+		w.Write([]byte("truncated"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Options{Backend: BackendHTTP})
+	client.retryDelay = 0
+	destFile := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	if _, err := client.Download(server.URL+"/artifact.tar.gz", destFile); err == nil {
+		t.Fatal("broken download should fail")
+	}
+	if count := requests.Load(); count != int32(client.maxRetries) {
+		t.Errorf("request is sent %d times, want %d", count, client.maxRetries)
+	}
+
+	// Neither truncated archive nor temp file is left.
+	entities, err := os.ReadDir(filepath.Dir(destFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) > 0 {
+		t.Errorf("nothing should be left after broken download, but got %s", entities[0].Name())
+	}
+
+	// Missing object is not an error, and no file is created.
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	if found, err := client.Download(notFound.URL+"/artifact.tar.gz", destFile); err != nil || found {
+		t.Errorf("Download() of missing object = %v, %v", found, err)
+	}
+	if _, err := os.Stat(destFile); !os.IsNotExist(err) {
+		t.Errorf("no file should be created for missing object")
+	}
+}
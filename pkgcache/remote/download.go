@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgs/color"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+)
+
+// DownloadConfig implements pkgcache.DownloadCache for storing/restoring
+// downloaded files (tools, archives) in a remote cache server.
+type DownloadConfig struct {
+	client   *Client
+	cacheUrl string
+	writable bool
+}
+
+// NewDownloadConfig creates a DownloadConfig backed by the cache url from ctx.
+func NewDownloadConfig(ctx context.Context, client *Client) *DownloadConfig {
+	pkgCacheConfig := ctx.PkgCacheConfig()
+	if pkgCacheConfig == nil || pkgCacheConfig.GetDir(pkgcache.PkgCacheDirDownloads) == "" {
+		return nil
+	}
+
+	return &DownloadConfig{
+		client:   client,
+		cacheUrl: pkgCacheConfig.GetDir(pkgcache.PkgCacheDirDownloads),
+		writable: pkgCacheConfig.IsWritable(),
+	}
+}
+
+// Restore downloads the cached file matching the given SHA256 into tmp dir and returns its path.
+// Returns empty string if not found.
+func (d DownloadConfig) Restore(fileName, sha256 string) (string, error) {
+	if sha256 == "" {
+		panic(fmt.Sprintf("no sha256 hash provided for %s/%s", d.cacheUrl, fileName))
+	}
+
+	// Build cached filename directly: {name}-{sha256}.{ext}
+	cachedFileName := fmt.Sprintf("%s-%s%s", fileio.Base(fileName), sha256, fileio.Ext(fileName))
+	if err := os.MkdirAll(dirs.TmpFilesDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("cannot create tmp files dir -> %w", err)
+	}
+	cachedFilePath := filepath.Join(dirs.TmpFilesDir, cachedFileName)
+	found, err := d.client.Download(joinUrl(d.cacheUrl, cachedFileName), cachedFilePath)
+	if err != nil || !found {
+		return "", err
+	}
+
+	color.Printf(color.Title, "\n%s\n", fmt.Sprintf("[validating file cache: %s]", fileName))
+	color.PrintInline(color.Hint, "- validating with sha256: %s", sha256)
+
+	// Verify file's sha256.
+	computedHash, err := fileio.SHA256Sum(cachedFilePath)
+	if err != nil {
+		color.PrintInline(color.Hint, "✘ validate with sha256: %s\n", sha256)
+		return "", fmt.Errorf("failed to compute sha-256 for cached file -> %w", err)
+	}
+	if computedHash == sha256 {
+		color.PrintInline(color.Hint, "✔ validate with sha256: %s\n", sha256)
+		return cachedFilePath, nil
+	}
+
+	color.PrintInline(color.Hint, "✘ validate with sha256: %s\n", sha256)
+	os.Remove(cachedFilePath)
+	return "", nil
+}
+
+// Store uploads a downloaded file to the cache using SHA256 in the filename,
+// the source file is returned since there's no local copy in cache.
+func (d DownloadConfig) Store(fileName, sha256, srcFile string) (string, error) {
+	if sha256 == "" {
+		panic(fmt.Sprintf("no sha-256 provided when caching file to pkgcache for %s", fileName))
+	}
+
+	cachedFileName := fmt.Sprintf("%s-%s%s", fileio.Base(fileName), sha256, fileio.Ext(fileName))
+	cachedFileUrl := joinUrl(d.cacheUrl, cachedFileName)
+
+	// Objects are keyed by content, so existing one is identical.
+	exist, err := d.client.Exist(cachedFileUrl)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		if err := d.client.UploadFile(cachedFileUrl, srcFile); err != nil {
+			return "", fmt.Errorf("failed to upload file to cache -> %w", err)
+		}
+	}
+
+	return srcFile, nil
+}
@@ -0,0 +1,223 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/pkgcache"
+	"github.com/celer-pkg/celer/pkgs/dirs"
+	"github.com/celer-pkg/celer/pkgs/fileio"
+	"github.com/celer-pkg/celer/pkgs/git"
+)
+
+type RepoConfig struct {
+	ctx      context.Context
+	client   *Client
+	writable bool
+}
+
+func NewRepoConfig(ctx context.Context, client *Client) *RepoConfig {
+	pkgCacheConfig := ctx.PkgCacheConfig()
+	if pkgCacheConfig == nil || pkgCacheConfig.GetDir(pkgcache.PkgCacheDirRoot) == "" {
+		return nil
+	}
+
+	return &RepoConfig{
+		ctx:      ctx,
+		client:   client,
+		writable: pkgCacheConfig.IsWritable(),
+	}
+}
+
+// Store packs a source tree and uploads it into repo cache.
+// - for git sources, repoDir is the cloned repo and archive name is its commit.
+// - for archive source, the archiveFile is the path to the original archive file.
+func (r RepoConfig) Store(nameVersion, repoUrl, repoDir, archiveFile string) (string, error) {
+	// skip storing cache when offline.
+	if r.ctx.Offline() {
+		return "", nil
+	}
+
+	// skip when pkgcache is not writable.
+	if !r.writable {
+		return "", nil
+	}
+
+	// Only third-party libraries can be cached.
+	if !r.shouldCacheRepo(nameVersion) {
+		return "", nil
+	}
+
+	reposCacheUrl := r.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRepos)
+	if strings.HasSuffix(repoUrl, ".git") {
+		commit, err := git.GetCommitHash(repoDir)
+		if err != nil {
+			return "", fmt.Errorf("read current commit -> %w", err)
+		}
+
+		// Ignore when repo archive is stored before.
+		// Archive name will be like: x264@stable/472338e072b6a83fd47825cc91cef81dc848e564.tar.gz
+		archiveUrl := joinUrl(reposCacheUrl, nameVersion, commit+".tar.gz")
+		if exist, err := r.client.Exist(archiveUrl); err != nil {
+			return "", err
+		} else if exist {
+			return "", nil
+		}
+
+		// Compress to temp dir first, then upload.
+		if err := dirs.CleanTmpFilesDir(); err != nil {
+			return "", fmt.Errorf("failed to clean tmp files dir -> %w", err)
+		}
+		tempArchivePath := filepath.Join(dirs.TmpFilesDir, fmt.Sprintf("%s-%d.tar.gz", nameVersion, time.Now().UnixMilli()))
+		if err := fileio.Targz(tempArchivePath, repoDir, false); err != nil {
+			return "", err
+		}
+		defer os.Remove(tempArchivePath)
+		if err := r.client.UploadFile(archiveUrl, tempArchivePath); err != nil {
+			return "", err
+		}
+
+		return archiveUrl, nil
+	} else {
+		// Skip when original archive is not available (e.g. file:/// URLs).
+		if !fileio.PathExists(archiveFile) {
+			return "", nil
+		}
+
+		checksum, err := fileio.SHA256Sum(archiveFile)
+		if err != nil {
+			return "", err
+		}
+
+		// Preserve original archive extension so Extract dispatches correctly.
+		ext := fileio.Ext(filepath.Base(archiveFile))
+		archiveUrl := joinUrl(reposCacheUrl, nameVersion, checksum+ext)
+
+		// Skip if already cached.
+		if exist, err := r.client.Exist(archiveUrl); err != nil {
+			return "", err
+		} else if exist {
+			return "", nil
+		}
+
+		// Upload original archive to repo cache.
+		if err := r.client.UploadFile(archiveUrl, archiveFile); err != nil {
+			return "", err
+		}
+
+		return archiveUrl, nil
+	}
+}
+
+// Restore downloads cached archive, extracts it to destination and return the archive url that restored from.
+// the checksum maybe sha256 of a file or git commit hash.
+func (r RepoConfig) Restore(nameVersion, repoUrl, repoDir, checksum string) (string, error) {
+	// skip restore cache when offline.
+	if r.ctx.Offline() {
+		return "", nil
+	}
+
+	// Ignore when repoRef is empty.
+	if strings.TrimSpace(checksum) == "" {
+		return "", nil
+	}
+
+	// Only third-party libraries can be cached.
+	if !r.shouldCacheRepo(nameVersion) {
+		return "", nil
+	}
+
+	// For git source repo, the storage archive archiveExt is ".tar.gz",
+	// For archive source repo, the storage archive archiveExt is same as original archive.
+	archiveExt := ".tar.gz"
+	if !strings.HasSuffix(repoUrl, ".git") {
+		archiveExt = fileio.Ext(filepath.Base(repoUrl))
+	}
+
+	// Download cached archive by checksum.
+	if err := os.MkdirAll(dirs.TmpFilesDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	reposCacheUrl := r.ctx.PkgCacheConfig().GetDir(pkgcache.PkgCacheDirRepos)
+	archiveUrl := joinUrl(reposCacheUrl, nameVersion, checksum+archiveExt)
+	archivePath := filepath.Join(dirs.TmpFilesDir, fmt.Sprintf("%s-%d%s", nameVersion, time.Now().UnixNano(), archiveExt))
+	defer os.Remove(archivePath)
+	if found, err := r.client.Download(archiveUrl, archivePath); err != nil {
+		return "", err
+	} else if !found {
+		return "", nil
+	}
+
+	// Create a clean repo dir.
+	if err := os.RemoveAll(repoDir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(repoDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	// Extract archive to repo dir.
+	if err := fileio.Extract(archivePath, repoDir); err != nil {
+		return "", err
+	}
+
+	// Flatten nested directory, many source archives contain a single wrapping dir like ffmpeg-4.4/.
+	if !strings.HasSuffix(repoUrl, ".git") {
+		if err := fileio.FlattenNestedDir(repoDir); err != nil {
+			_ = os.RemoveAll(repoDir)
+			return "", err
+		}
+	}
+
+	// Verify cached archive integrity.
+	var localChecksum string
+	if strings.HasSuffix(repoUrl, ".git") {
+		commitHash, err := git.GetCommitHash(repoDir)
+		if err != nil {
+			_ = os.RemoveAll(repoDir)
+			return "", fmt.Errorf("invalid cached repo, read commit failed -> %w", err)
+		}
+		localChecksum = commitHash
+	} else {
+		cachedChecksum, err := fileio.SHA256Sum(archivePath)
+		if err != nil {
+			_ = os.RemoveAll(repoDir)
+			return "", fmt.Errorf("invalid cached repo, verify checksum failed -> %w", err)
+		}
+		localChecksum = cachedChecksum
+
+		// Initialize archive source as local git repo, so they won't be treated as user local modifications.
+		if err := git.InitAsLocalRepo(repoDir, `"init for tracking file change"`); err != nil {
+			return "", err
+		}
+	}
+
+	// Check if stored repo was modified.
+	if localChecksum != checksum {
+		_ = os.RemoveAll(repoDir)
+		return "", fmt.Errorf("cached repo checksum mismatch, expect %s, got %s", checksum, localChecksum)
+	}
+
+	return archiveUrl, nil
+}
+
+// shouldCacheRepo default we cache all third-party library repos that defined in registries and ports dir.
+func (r RepoConfig) shouldCacheRepo(nameVersion string) bool {
+	parts := strings.Split(nameVersion, "@")
+	if len(parts) != 2 {
+		panic("invalid nameVersion: " + nameVersion)
+	}
+
+	// Only cache third-party repos that defined in registries and ports dir.
+	portName := parts[0]
+	for _, registry := range r.ctx.Registries() {
+		if registry.Provides(portName) && fileio.PathExists(filepath.Dir(registry.PortDir(portName, "_"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package arch
+
+import (
+	"debug/elf"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Float ABI of 32-bit ARM.
+const (
+	FloatABIHard = "hard"
+	FloatABISoft = "soft"
+)
+
+// Flags of float ABI in header of ARM EABI v5 ELF.
+const (
+	efARMEABIMask     = 0xFF000000
+	efARMEABIVer5     = 0x05000000
+	efARMABIFloatSoft = 0x200
+	efARMABIFloatHard = 0x400
+)
+
+// Machine types of PE/COFF.
+const (
+	peMachineUnknown   = 0x0
+	peMachineI386      = 0x14c
+	peMachineAMD64     = 0x8664
+	peMachineARM64     = 0xaa64
+	peMachineARM64EC   = 0xa641
+	peMachineARMNT     = 0x1c4
+	peMachineRISCV64   = 0x5064
+	peMachineLoongArch = 0x6264
+)
+
+// machine describes how binaries of a processor look like.
+type machine struct {
+	name       string
+	elfMachine elf.Machine
+	elfClass   elf.Class
+	peMachines []uint16
+}
+
+var machines = []machine{
+	{"x86_64", elf.EM_X86_64, elf.ELFCLASS64, []uint16{peMachineAMD64}},
+	{"x86", elf.EM_386, elf.ELFCLASS32, []uint16{peMachineI386}},
+	{"aarch64", elf.EM_AARCH64, elf.ELFCLASS64, []uint16{peMachineARM64, peMachineARM64EC}},
+	{"arm", elf.EM_ARM, elf.ELFCLASS32, []uint16{peMachineARMNT}},
+	{"riscv64", elf.EM_RISCV, elf.ELFCLASS64, []uint16{peMachineRISCV64}},
+	{"riscv32", elf.EM_RISCV, elf.ELFCLASS32, nil},
+	{"loongarch64", elf.EM_LOONGARCH, elf.ELFCLASS64, []uint16{peMachineLoongArch}},
+	{"mips64", elf.EM_MIPS, elf.ELFCLASS64, nil},
+	{"mips", elf.EM_MIPS, elf.ELFCLASS32, nil},
+	{"ppc64", elf.EM_PPC64, elf.ELFCLASS64, nil},
+	{"ppc", elf.EM_PPC, elf.ELFCLASS32, nil},
+	{"s390x", elf.EM_S390, elf.ELFCLASS64, nil},
+}
+
+// Target is the architecture that binaries in package are expected to be built for.
+type Target struct {
+	Processor string // Normalized processor, like "x86_64", "aarch64" or "arm".
+	FloatABI  string // Float ABI of 32-bit ARM, empty means not to check it.
+}
+
+func (t Target) String() string {
+	if t.FloatABI != "" {
+		return fmt.Sprintf("%s (%s-float)", t.Processor, t.FloatABI)
+	}
+	return t.Processor
+}
+
+// NewTarget creates target from system_processor, host and cflags of toolchain,
+// float ABI is taken from -mfloat-abi in cflags first, then the host triple.
+func NewTarget(processor, host string, cflags []string) Target {
+	target := Target{Processor: Normalize(processor)}
+	if target.Processor != "arm" {
+		return target
+	}
+
+	for _, flag := range cflags {
+		for field := range strings.FieldsSeq(flag) {
+			switch field {
+			case "-mfloat-abi=hard":
+				target.FloatABI = FloatABIHard
+			case "-mfloat-abi=soft", "-mfloat-abi=softfp":
+				target.FloatABI = FloatABISoft
+			}
+		}
+	}
+	if target.FloatABI == "" {
+		switch {
+		case strings.HasSuffix(host, "eabihf"):
+			target.FloatABI = FloatABIHard
+		case strings.HasSuffix(host, "eabi"):
+			target.FloatABI = FloatABISoft
+		}
+	}
+	return target
+}
+
+// HostTarget returns the target of current machine, float ABI is not checked for host.
+func HostTarget() Target {
+	return Target{Processor: Normalize(runtime.GOARCH)}
+}
+
+// Normalize converts aliases of processor to the names in machines,
+// for example "AMD64" to "x86_64", "arm64" to "aarch64" and "armv7-a" to "arm".
+func Normalize(processor string) string {
+	processor = strings.ToLower(strings.TrimSpace(processor))
+	switch {
+	case slices.Contains([]string{"amd64", "x64", "x86-64", "em64t"}, processor):
+		return "x86_64"
+	case slices.Contains([]string{"386", "i386", "i486", "i586", "i686", "x86", "win32"}, processor):
+		return "x86"
+	case slices.Contains([]string{"arm64", "armv8", "armv8-a", "aarch64"}, processor):
+		return "aarch64"
+	case strings.HasPrefix(processor, "arm"):
+		return "arm"
+	case processor == "mips64el":
+		return "mips64"
+	case processor == "mipsel":
+		return "mips"
+	case processor == "ppc64le" || processor == "powerpc64" || processor == "powerpc64le":
+		return "ppc64"
+	case processor == "powerpc":
+		return "ppc"
+	case processor == "loong64":
+		return "loongarch64"
+	default:
+		return processor
+	}
+}
+
+func (t Target) machine() (machine, bool) {
+	for _, machine := range machines {
+		if machine.name == t.Processor {
+			return machine, true
+		}
+	}
+	return machine{}, false
+}
+
+// matchELF returns description of the ELF header if it doesn't match target.
+func (t Target) matchELF(expected machine, header elf.FileHeader, flags uint32) (string, bool) {
+	if header.Machine != expected.elfMachine || header.Class != expected.elfClass {
+		return describeELF(header), false
+	}
+
+	if header.Machine == elf.EM_ARM && t.FloatABI != "" && flags&efARMEABIMask == efARMEABIVer5 {
+		switch {
+		case t.FloatABI == FloatABIHard && flags&efARMABIFloatSoft != 0:
+			return "arm (soft-float)", false
+		case t.FloatABI == FloatABISoft && flags&efARMABIFloatHard != 0:
+			return "arm (hard-float)", false
+		}
+	}
+	return "", true
+}
+
+// matchPE returns description of the PE/COFF machine if it doesn't match target.
+func (t Target) matchPE(expected machine, peMachine uint16) (string, bool) {
+	if peMachine == peMachineUnknown || slices.Contains(expected.peMachines, peMachine) {
+		return "", true
+	}
+	return describePE(peMachine), false
+}
+
+func describeELF(header elf.FileHeader) string {
+	for _, machine := range machines {
+		if machine.elfMachine == header.Machine && machine.elfClass == header.Class {
+			return machine.name
+		}
+	}
+	return fmt.Sprintf("%s (%s)", header.Machine, header.Class)
+}
+
+func describePE(peMachine uint16) string {
+	for _, machine := range machines {
+		if slices.Contains(machine.peMachines, peMachine) {
+			return machine.name
+		}
+	}
+	return fmt.Sprintf("PE machine 0x%x", peMachine)
+}
+
+func isKnownPEMachine(peMachine uint16) bool {
+	for _, machine := range machines {
+		if slices.Contains(machine.peMachines, peMachine) {
+			return true
+		}
+	}
+	return false
+}
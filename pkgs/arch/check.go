@@ -0,0 +1,228 @@
+package arch
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	elfMagic     = "\x7fELF"
+	archiveMagic = "!<arch>\n"
+	peMagic      = "MZ"
+)
+
+// Mismatch is a binary in package that is built for another architecture.
+type Mismatch struct {
+	File   string // Relative to package dir, members of archive are like "lib/libz.a(adler32.o)".
+	Actual string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: %s", filepath.ToSlash(m.File), m.Actual)
+}
+
+// Check inspects ELF files, PE files and objects in static libraries under packageDir,
+// and returns the ones whose machine type, bitness or float ABI don't match target.
+// Unknown processor of target is not checked.
+func Check(packageDir string, target Target) ([]Mismatch, error) {
+	expected, ok := target.machine()
+	if !ok {
+		return nil, nil
+	}
+	if _, err := os.Stat(packageDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var mismatches []Mismatch
+	err := filepath.WalkDir(packageDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(packageDir, path)
+		mismatch, err := checkFile(path, target, expected)
+		if err != nil {
+			return fmt.Errorf("failed to check %s -> %w", relPath, err)
+		}
+		if mismatch != nil {
+			mismatch.File = relPath + mismatch.File
+			mismatches = append(mismatches, *mismatch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mismatches, nil
+}
+
+func checkFile(path string, target Target, expected machine) (*Mismatch, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(archiveMagic))
+	count, _ := io.ReadFull(file, magic)
+	magic = magic[:count]
+
+	switch {
+	case bytes.HasPrefix(magic, []byte(elfMagic)):
+		return checkELF(file, target, expected), nil
+	case bytes.HasPrefix(magic, []byte(archiveMagic)):
+		return checkArchive(file, target, expected)
+	case bytes.HasPrefix(magic, []byte(peMagic)):
+		return checkPE(file, target, expected), nil
+	default:
+		return nil, nil
+	}
+}
+
+func checkELF(reader io.ReaderAt, target Target, expected machine) *Mismatch {
+	file, err := elf.NewFile(reader)
+	if err != nil {
+		// Not a valid ELF although it has ELF magic, ignore it.
+		return nil
+	}
+	defer file.Close()
+
+	// e_flags is not exposed by debug/elf, it follows e_ident, e_type, e_machine,
+	// e_version, e_entry, e_phoff and e_shoff in header.
+	flagsOffset := int64(36)
+	if file.Class == elf.ELFCLASS64 {
+		flagsOffset = 48
+	}
+	flags := make([]byte, 4)
+	if _, err := reader.ReadAt(flags, flagsOffset); err != nil {
+		return nil
+	}
+
+	if actual, ok := target.matchELF(expected, file.FileHeader, file.ByteOrder.Uint32(flags)); !ok {
+		return &Mismatch{Actual: actual}
+	}
+	return nil
+}
+
+func checkPE(reader io.ReaderAt, target Target, expected machine) *Mismatch {
+	file, err := pe.NewFile(reader)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	if actual, ok := target.matchPE(expected, file.FileHeader.Machine); !ok {
+		return &Mismatch{Actual: actual}
+	}
+	return nil
+}
+
+// checkArchive checks objects in static library, which is an ar archive of ELF or COFF objects,
+// also the import library of MSVC. Only the first mismatched object is reported.
+func checkArchive(file *os.File, target Target, expected machine) (*Mismatch, error) {
+	offset := int64(len(archiveMagic))
+	header := make([]byte, 60)
+	var longNames []byte
+	for {
+		if _, err := file.ReadAt(header, offset); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		name := strings.TrimSpace(string(header[0:16]))
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive member size of %q", name)
+		}
+		dataOffset := offset + int64(len(header))
+		dataSize := size
+
+		// BSD archives put long names at the beginning of data.
+		if length, ok := strings.CutPrefix(name, "#1/"); ok {
+			nameLength, err := strconv.ParseInt(length, 10, 64)
+			if err == nil && nameLength <= size {
+				dataOffset += nameLength
+				dataSize -= nameLength
+			}
+		}
+
+		member := io.NewSectionReader(file, dataOffset, dataSize)
+		switch {
+		case name == "//":
+			// GNU archives put long names in a table, and members are named like "/123".
+			longNames = make([]byte, dataSize)
+			if _, err := member.ReadAt(longNames, 0); err != nil {
+				return nil, err
+			}
+
+		case !isSpecialMember(name):
+			if mismatch := checkMember(member, target, expected); mismatch != nil {
+				mismatch.File = fmt.Sprintf("(%s)", memberName(name, longNames))
+				return mismatch, nil
+			}
+		}
+
+		// Members are aligned to 2 bytes.
+		offset = dataOffset + dataSize + (size % 2)
+	}
+}
+
+func memberName(name string, longNames []byte) string {
+	if index, err := strconv.Atoi(strings.TrimPrefix(name, "/")); err == nil && strings.HasPrefix(name, "/") && index < len(longNames) {
+		longName := longNames[index:]
+		if end := bytes.IndexByte(longName, '\n'); end >= 0 {
+			longName = longName[:end]
+		}
+		return strings.TrimSuffix(string(longName), "/")
+	}
+	return strings.TrimSuffix(name, "/")
+}
+
+func isSpecialMember(name string) bool {
+	return name == "/" || name == "//" || name == "/SYM64/" ||
+		strings.HasPrefix(name, "__.SYMDEF") || strings.HasPrefix(name, "#1/__.SYMDEF")
+}
+
+func checkMember(member *io.SectionReader, target Target, expected machine) *Mismatch {
+	magic := make([]byte, 4)
+	if _, err := member.ReadAt(magic, 0); err != nil {
+		return nil
+	}
+	if string(magic) == elfMagic {
+		return checkELF(member, target, expected)
+	}
+
+	// Short import object of import library starts with 0x0000, 0xFFFF, then version and machine.
+	peMachine := binary.LittleEndian.Uint16(magic[0:2])
+	if peMachine == 0 && binary.LittleEndian.Uint16(magic[2:4]) == 0xFFFF {
+		machine := make([]byte, 2)
+		if _, err := member.ReadAt(machine, 6); err != nil {
+			return nil
+		}
+		peMachine = binary.LittleEndian.Uint16(machine)
+	}
+
+	// Members like LLVM bitcode are not COFF objects, skip them.
+	if !isKnownPEMachine(peMachine) {
+		return nil
+	}
+	if actual, ok := target.matchPE(expected, peMachine); !ok {
+		return &Mismatch{Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package arch
+
+import (
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestArch_NewTarget(t *testing.T) {
+	tests := []struct {
+		processor string
+		host      string
+		cflags    []string
+		expected  Target
+	}{
+		{"AMD64", "", nil, Target{Processor: "x86_64"}},
+		{"i686", "i686-linux-gnu", nil, Target{Processor: "x86"}},
+		{"arm64", "aarch64-linux-gnu", nil, Target{Processor: "aarch64"}},
+		{"arm", "arm-linux-gnueabihf", nil, Target{Processor: "arm", FloatABI: FloatABIHard}},
+		{"armv7-a", "arm-linux-gnueabi", nil, Target{Processor: "arm", FloatABI: FloatABISoft}},
+		{"arm", "arm-none-eabi", []string{"-mcpu=cortex-m4 -mfloat-abi=hard"}, Target{Processor: "arm", FloatABI: FloatABIHard}},
+		{"arm", "arm-linux-musleabi", nil, Target{Processor: "arm", FloatABI: FloatABISoft}},
+	}
+
+	for _, test := range tests {
+		actual := NewTarget(test.processor, test.host, test.cflags)
+		if actual != test.expected {
+			t.Errorf("NewTarget(%s, %s, %v) = %v, want %v", test.processor, test.host, test.cflags, actual, test.expected)
+		}
+	}
+}
+
+// writeARMHeader writes a minimal 32-bit ARM ELF header with flags.
+func writeARMHeader(t *testing.T, path string, flags uint32) {
+	t.Helper()
+
+	header := make([]byte, 52)
+	copy(header, []byte{0x7f, 'E', 'L', 'F', 1, 1, 1})
+	binary.LittleEndian.PutUint16(header[16:], 3)  // e_type: ET_DYN
+	binary.LittleEndian.PutUint16(header[18:], 40) // e_machine: EM_ARM
+	binary.LittleEndian.PutUint32(header[20:], 1)  // e_version
+	binary.LittleEndian.PutUint32(header[36:], flags)
+	binary.LittleEndian.PutUint16(header[40:], 52) // e_ehsize
+	if err := os.WriteFile(path, header, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArch_FloatABI(t *testing.T) {
+	packageDir := t.TempDir()
+	writeARMHeader(t, filepath.Join(packageDir, "libhard.so"), efARMEABIVer5|efARMABIFloatHard)
+	writeARMHeader(t, filepath.Join(packageDir, "libsoft.so"), efARMEABIVer5|efARMABIFloatSoft)
+
+	mismatches, err := Check(packageDir, NewTarget("arm", "arm-linux-gnueabihf", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || mismatches[0].String() != "libsoft.so: arm (soft-float)" {
+		t.Fatalf("only libsoft.so should mismatch hard-float target, but got %v", mismatches)
+	}
+
+	mismatches, err = Check(packageDir, NewTarget("aarch64", "aarch64-linux-gnu", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 2 {
+		t.Fatalf("both libraries should mismatch aarch64 target, but got %v", mismatches)
+	}
+}
+
+func TestArch_HostBinaries(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("test binaries are built for x86_64 linux.")
+	}
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is not found.")
+	}
+	ar, err := exec.LookPath("ar")
+	if err != nil {
+		t.Skip("ar is not found.")
+	}
+
+	workDir := t.TempDir()
+	packageDir := filepath.Join(workDir, "package")
+	libDir := filepath.Join(packageDir, "lib")
+	if err := os.MkdirAll(libDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	source := filepath.Join(workDir, "a_very_long_object_name_for_gnu_ar.c")
+	if err := os.WriteFile(source, []byte("int foo(void) { return 1; }"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	object := strings.TrimSuffix(source, ".c") + ".o"
+	for _, command := range [][]string{
+		{gcc, "-c", "-fPIC", "-o", object, source},
+		{gcc, "-shared", "-o", filepath.Join(libDir, "libfoo.so"), object},
+		{ar, "rcs", filepath.Join(libDir, "libfoo.a"), object},
+	} {
+		if output, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err != nil {
+			t.Skipf("failed to build test binaries: %s", output)
+		}
+	}
+
+	mismatches, err := Check(packageDir, HostTarget())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("binaries built for host should match host target, but got %v", mismatches)
+	}
+
+	mismatches, err = Check(packageDir, NewTarget("aarch64", "aarch64-linux-gnu", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, mismatch := range mismatches {
+		actual = append(actual, mismatch.String())
+	}
+	expected := []string{
+		"lib/libfoo.a(a_very_long_object_name_for_gnu_ar.o): x86_64",
+		"lib/libfoo.so: x86_64",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("x86_64 binaries should mismatch aarch64 target:\nexpected: %v\nactual: %v", expected, actual)
+	}
+}
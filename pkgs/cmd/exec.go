@@ -24,6 +24,7 @@ type executor struct {
 	workDir          string   // Working directory for command execution
 	logPath          string   // File path for execution logs
 	retryMaxAttempts int      // 0 = no retry (default)
+	sandbox          *Sandbox // Sandbox to run command in, nil = no sandbox (Linux only)
 }
 
 // NewExecutor creates a new Executor with the given title, command, and arguments.
@@ -64,6 +65,12 @@ func (e *executor) SetLogPath(logPath string) *executor {
 	return e
 }
 
+// SetSandbox runs command in sandbox, nil means no sandbox.
+func (e *executor) SetSandbox(sandbox *Sandbox) *executor {
+	e.sandbox = sandbox
+	return e
+}
+
 // WithRetry enables automatic retry on command failure.
 // maxAttempts controls how many times the command will be executed total (1 initial + N retries).
 // A retry sleep of attempt * time.Second is applied between attempts.
@@ -48,20 +48,46 @@ func (e *executor) doExecute(output io.Writer) error {
 	cmd.Env = os.Environ()
 	cmd.Stdin = os.Stdin
 
+	// Isolate command when it's run in sandbox.
+	current := e.sandbox
+	var droppedEnvs []string
+	if current != nil {
+		dropped, err := current.apply(cmd)
+		if err != nil {
+			return err
+		}
+		droppedEnvs = dropped
+	}
+
 	// Create and configure log file.
 	logFile, err := e.createLogFile(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to setup logging -> %w", err)
 	}
+	var outputStart int64
 	if logFile != nil {
 		defer logFile.Close()
+
+		if current != nil {
+			if err := current.writeHeader(logFile, droppedEnvs); err != nil {
+				return fmt.Errorf("failed to write sandbox to log -> %w", err)
+			}
+			if outputStart, err = logFile.Seek(0, io.SeekCurrent); err != nil {
+				return fmt.Errorf("failed to locate output in log -> %w", err)
+			}
+		}
 	}
 
 	// Route output to appropriate destinations.
 	e.configureOutputs(cmd, logFile, output)
 
 	// Execute command and return result.
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	recordUsage(cmd.ProcessState)
+	if err != nil {
+		if current != nil {
+			return current.explain(err, logFile, outputStart)
+		}
 		return err
 	}
 
@@ -58,3 +58,24 @@ func TestExecutor_ExecuteFailingCommand(t *testing.T) {
 		t.Fatal("expected error for failing command")
 	}
 }
+
+func TestExecutor_TrackUsage(t *testing.T) {
+	outer := TrackUsage()
+	inner := TrackUsage()
+	if err := NewExecutor("", "head -c 10000000 /dev/zero | sha256sum").Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	innerUsage := inner.Stop()
+
+	if err := NewExecutor("", "true").Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outerUsage := outer.Stop()
+
+	if innerUsage.PeakRSS <= 0 {
+		t.Errorf("peak rss should be recorded, but got %d", innerUsage.PeakRSS)
+	}
+	if outerUsage.CPUTime < innerUsage.CPUTime || outerUsage.PeakRSS < innerUsage.PeakRSS {
+		t.Errorf("outer tracker %+v should include usage of inner tracker %+v", outerUsage, innerUsage)
+	}
+}
@@ -59,7 +59,9 @@ func (e *executor) doExecute(output io.Writer) error {
 	e.configureOutputs(cmd, logFile, output)
 
 	// Execute command and return result.
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	recordUsage(cmd.ProcessState)
+	if err != nil {
 		return err
 	}
 
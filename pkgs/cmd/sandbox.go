@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sandboxPathRegex matches absolute paths in log of failed command.
+var sandboxPathRegex = regexp.MustCompile(`/[^\s:'"\x60()\[\],;]+`)
+
+// missingHeaderRegex matches headers not found by compiler, like "fatal error: zlib.h: No such file or directory".
+var missingHeaderRegex = regexp.MustCompile(`fatal error: ([^:]+): No such file or directory`)
+
+// networkErrors are messages of commands that failed to access network.
+var networkErrors = []string{
+	"Network is unreachable",
+	"Could not resolve host",
+	"Temporary failure in name resolution",
+	"Name or service not known",
+	"Failed to connect to",
+}
+
+// Sandbox isolates commands from network and environment of host, it's only supported on Linux.
+type Sandbox struct {
+	AllowEnv     func(key string) bool // Environment variables not allowed are not passed to command.
+	ReadOnlyDirs []string              // Dirs that are bind mounted as read-only, like rootfs and toolchain.
+	HiddenDirs   []string              // Dirs of host that are covered by empty tmpfs, like /usr/include.
+}
+
+// filterEnvs keeps allowed environment variables, and returns names of the dropped ones.
+func (s Sandbox) filterEnvs(envs []string) (kept, dropped []string) {
+	for _, env := range envs {
+		key, _, _ := strings.Cut(env, "=")
+		if s.AllowEnv == nil || s.AllowEnv(key) {
+			kept = append(kept, env)
+		} else if !slices.Contains(dropped, key) {
+			dropped = append(dropped, key)
+		}
+	}
+	return kept, dropped
+}
+
+// writeHeader describes sandbox in log, so that violations like unreachable network,
+// missing environment variables and writing to read-only dirs can be traced.
+func (s Sandbox) writeHeader(writer io.Writer, dropped []string) error {
+	var buffer strings.Builder
+	buffer.WriteString("Sandbox: hermetic\n")
+	buffer.WriteString("  network: disabled\n")
+	if len(s.ReadOnlyDirs) > 0 {
+		fmt.Fprintf(&buffer, "  read-only: %s\n", strings.Join(s.ReadOnlyDirs, ", "))
+	}
+	if len(s.HiddenDirs) > 0 {
+		fmt.Fprintf(&buffer, "  hidden: %s\n", strings.Join(s.HiddenDirs, ", "))
+	}
+	if len(dropped) > 0 {
+		fmt.Fprintf(&buffer, "  dropped envs: %s\n", strings.Join(dropped, ", "))
+	}
+	buffer.WriteString("\n")
+
+	_, err := io.WriteString(writer, buffer.String())
+	return err
+}
+
+// explain tells whether the command failed to start sandbox or failed inside it,
+// and the offending path or host found in output of command when it's caused by sandbox,
+// the output starts at outputStart of log file.
+func (s Sandbox) explain(err error, logFile *os.File, outputStart int64) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to start sandbox, please check unprivileged user namespaces are enabled -> %w", err)
+	}
+	if logFile == nil {
+		return fmt.Errorf("failed in hermetic sandbox -> %w", err)
+	}
+
+	var violation string
+	if content, readErr := os.ReadFile(logFile.Name()); readErr == nil && outputStart <= int64(len(content)) {
+		violation = s.violation(string(content[outputStart:]))
+	}
+	if violation == "" {
+		io.WriteString(logFile, "\nSandbox: command failed, it may be caused by accessing network, "+
+			"missing dropped envs, including hidden host dirs or writing read-only dirs.\n")
+		return fmt.Errorf("failed in hermetic sandbox -> %w", err)
+	}
+
+	io.WriteString(logFile, "\nSandbox: command failed by "+violation+".\n")
+	return fmt.Errorf("failed in hermetic sandbox by %s -> %w", violation, err)
+}
+
+// violation finds what's forbidden by sandbox in log, like writing read-only dirs,
+// accessing network and including hidden host dirs.
+func (s Sandbox) violation(content string) string {
+	for line := range strings.SplitSeq(content, "\n") {
+		switch {
+		case strings.Contains(line, "Read-only file system"):
+			if path := s.pathUnder(line, s.ReadOnlyDirs); path != "" {
+				return "writing read-only path " + path
+			}
+			return "writing read-only file system: " + strings.TrimSpace(line)
+
+		case slices.ContainsFunc(networkErrors, func(message string) bool { return strings.Contains(line, message) }):
+			return "accessing network: " + strings.TrimSpace(line)
+		}
+
+		if path := s.pathUnder(line, s.HiddenDirs); path != "" {
+			return "accessing hidden host path " + path
+		}
+		if match := missingHeaderRegex.FindStringSubmatch(line); match != nil && len(s.HiddenDirs) > 0 {
+			return fmt.Sprintf("missing header %s, host headers in %s are hidden", match[1], strings.Join(s.HiddenDirs, ", "))
+		}
+	}
+	return ""
+}
+
+// pathUnder returns the first path in line that is under one of dirs.
+func (s Sandbox) pathUnder(line string, dirs []string) string {
+	for _, path := range sandboxPathRegex.FindAllString(line, -1) {
+		for _, dir := range dirs {
+			if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsLocal(rel) {
+				return path
+			}
+		}
+	}
+	return ""
+}
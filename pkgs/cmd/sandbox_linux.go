@@ -0,0 +1,53 @@
+//go:build linux
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// apply runs command in new user and network namespaces, that only have a loopback device,
+// read-only dirs are bind mounted and hidden dirs are covered by empty tmpfs in new mount
+// namespace before running command.
+func (s Sandbox) apply(cmd *exec.Cmd) ([]string, error) {
+	kept, dropped := s.filterEnvs(cmd.Env)
+	cmd.Env = kept
+
+	cloneflags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET)
+	if len(s.ReadOnlyDirs) > 0 || len(s.HiddenDirs) > 0 {
+		cloneflags |= syscall.CLONE_NEWNS
+
+		// Mounts can only be done inside the namespaces, so wrap the command with a bash prelude.
+		bash, err := exec.LookPath("bash")
+		if err != nil {
+			return nil, fmt.Errorf("bash is required to mount dirs in sandbox -> %w", err)
+		}
+		var script strings.Builder
+		for _, dir := range s.ReadOnlyDirs {
+			fmt.Fprintf(&script, "mount --bind %[1]s %[1]s && mount -o remount,bind,ro %[1]s || exit 126\n", shellQuote(dir))
+		}
+		for _, dir := range s.HiddenDirs {
+			fmt.Fprintf(&script, "[ ! -d %[1]s ] || mount -t tmpfs -o ro,mode=755 tmpfs %[1]s || exit 126\n", shellQuote(dir))
+		}
+		script.WriteString(`exec "$@"`)
+		cmd.Args = append([]string{"bash", "-c", script.String(), "sandbox", cmd.Path}, cmd.Args[1:]...)
+		cmd.Path = bash
+	}
+
+	// Map current user to itself, so that files created by command are owned by current user.
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:                 cloneflags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+	}
+	return dropped, nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,104 @@
+//go:build linux
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkTestSandbox skips test if user namespaces are not available.
+func checkTestSandbox(t *testing.T, sandbox *Sandbox) {
+	t.Helper()
+
+	if _, err := NewExecutor("", "true").SetSandbox(sandbox).ExecuteOutput(); err != nil {
+		t.Skipf("sandbox is not available: %s", err)
+	}
+}
+
+func TestSandbox_NetworkAndEnvs(t *testing.T) {
+	t.Setenv("CELER_SANDBOX_ALLOWED", "yes")
+	t.Setenv("CELER_SANDBOX_DROPPED", "yes")
+	sandbox := &Sandbox{
+		AllowEnv: func(key string) bool {
+			return key == "PATH" || key == "CELER_SANDBOX_ALLOWED"
+		},
+	}
+	checkTestSandbox(t, sandbox)
+
+	output, err := NewExecutor("", "env").SetSandbox(sandbox).ExecuteOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "CELER_SANDBOX_ALLOWED=yes") || strings.Contains(output, "CELER_SANDBOX_DROPPED") {
+		t.Fatalf("only allowed envs should be passed, but got:\n%s", output)
+	}
+
+	// Only loopback device is in the new network namespace.
+	output, err = NewExecutor("", "tail -n +3 /proc/net/dev | cut -d: -f1").SetSandbox(sandbox).ExecuteOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(output) != "lo" {
+		t.Fatalf("network should be disabled, but got devices:\n%s", output)
+	}
+
+	// Commands without sandbox are not affected.
+	output, err = NewExecutor("", "env").ExecuteOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "CELER_SANDBOX_DROPPED=yes") {
+		t.Fatalf("envs of command without sandbox should be kept, but got:\n%s", output)
+	}
+}
+
+func TestSandbox_ReadOnlyDirs(t *testing.T) {
+	readOnlyDir := t.TempDir()
+	sandbox := &Sandbox{ReadOnlyDirs: []string{readOnlyDir}}
+	checkTestSandbox(t, sandbox)
+
+	logPath := filepath.Join(t.TempDir(), "build.log")
+	filePath := filepath.Join(readOnlyDir, "file")
+	err := NewExecutor("", "touch "+filePath).SetSandbox(sandbox).SetLogPath(logPath).Execute()
+	if err == nil {
+		t.Fatal("writing to read-only dir should fail")
+	}
+	if !strings.Contains(err.Error(), "writing read-only path "+filePath) {
+		t.Fatalf("offending path should be reported, but got: %s", err)
+	}
+
+	bytes, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), "read-only: "+readOnlyDir) || !strings.Contains(string(bytes), "Sandbox: command failed") {
+		t.Fatalf("violation should be written into log, but got:\n%s", bytes)
+	}
+}
+
+func TestSandbox_HiddenDirs(t *testing.T) {
+	hiddenDir := t.TempDir()
+	headerPath := filepath.Join(hiddenDir, "zlib.h")
+	if err := os.WriteFile(headerPath, []byte("// zlib"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	sandbox := &Sandbox{HiddenDirs: []string{hiddenDir}}
+	checkTestSandbox(t, sandbox)
+
+	logPath := filepath.Join(t.TempDir(), "build.log")
+	err := NewExecutor("", "cat "+headerPath).SetSandbox(sandbox).SetLogPath(logPath).Execute()
+	if err == nil {
+		t.Fatal("file in hidden dir should not be readable")
+	}
+	if !strings.Contains(err.Error(), "accessing hidden host path "+headerPath) {
+		t.Fatalf("offending path should be reported, but got: %s", err)
+	}
+
+	// Hidden dir is only covered inside sandbox.
+	if _, err := os.Stat(headerPath); err != nil {
+		t.Fatalf("hidden dir should be kept on host -> %s", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+func (s Sandbox) apply(cmd *exec.Cmd) ([]string, error) {
+	return nil, fmt.Errorf("sandbox is not supported on %s", runtime.GOOS)
+}
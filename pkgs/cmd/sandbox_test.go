@@ -0,0 +1,46 @@
+package cmd
+
+import "testing"
+
+func TestSandbox_Violation(t *testing.T) {
+	sandbox := Sandbox{
+		ReadOnlyDirs: []string{"/opt/rootfs"},
+		HiddenDirs:   []string{"/usr/include", "/usr/local/lib"},
+	}
+
+	tests := []struct {
+		log      string
+		expected string
+	}{
+		{
+			log:      "install: cannot create regular file '/opt/rootfs/usr/lib/libz.so': Read-only file system",
+			expected: "writing read-only path /opt/rootfs/usr/lib/libz.so",
+		},
+		{
+			log:      "curl: (6) Could not resolve host: github.com",
+			expected: "accessing network: curl: (6) Could not resolve host: github.com",
+		},
+		{
+			log:      "/usr/bin/ld: cannot find /usr/local/lib/libfoo.so: No such file or directory",
+			expected: "accessing hidden host path /usr/local/lib/libfoo.so",
+		},
+		{
+			log:      "main.c:1:10: fatal error: zlib.h: No such file or directory",
+			expected: "missing header zlib.h, host headers in /usr/include, /usr/local/lib are hidden",
+		},
+		{
+			log:      "main.c:3:1: error: expected ';' before '}' token",
+			expected: "",
+		},
+		{
+			log:      "-- Installing: /home/user/packages/zlib@1.3.1/include/zlib.h",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := sandbox.violation("make: entering dir\n" + test.log + "\nmake: *** [all] Error 1"); actual != test.expected {
+			t.Errorf("violation(%q) = %q, want %q", test.log, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Usage is the resource usage of commands executed during tracking.
+type Usage struct {
+	CPUTime time.Duration // User and system time of commands and their children.
+	PeakRSS int64         // Max resident set size in bytes of a single command, zero if not supported.
+}
+
+// UsageTracker accumulates resource usage of commands, trackers can be nested.
+type UsageTracker struct {
+	usage Usage
+}
+
+var (
+	trackersMutex sync.Mutex
+	trackers      []*UsageTracker
+)
+
+// TrackUsage starts to accumulate resource usage of following commands.
+func TrackUsage() *UsageTracker {
+	tracker := &UsageTracker{}
+
+	trackersMutex.Lock()
+	defer trackersMutex.Unlock()
+	trackers = append(trackers, tracker)
+	return tracker
+}
+
+// Stop stops tracking and returns the accumulated usage.
+func (t *UsageTracker) Stop() Usage {
+	trackersMutex.Lock()
+	defer trackersMutex.Unlock()
+
+	for index, tracker := range trackers {
+		if tracker == t {
+			trackers = append(trackers[:index], trackers[index+1:]...)
+			break
+		}
+	}
+	return t.usage
+}
+
+// recordUsage adds usage of an exited command to all trackers.
+func recordUsage(state *os.ProcessState) {
+	if state == nil {
+		return
+	}
+
+	trackersMutex.Lock()
+	defer trackersMutex.Unlock()
+
+	peakRSS := peakRSS(state)
+	for _, tracker := range trackers {
+		tracker.usage.CPUTime += state.UserTime() + state.SystemTime()
+		tracker.usage.PeakRSS = max(tracker.usage.PeakRSS, peakRSS)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build darwin || linux
+
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func peakRSS(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || rusage == nil {
+		return 0
+	}
+
+	// Maxrss is in bytes on darwin, but in kilobytes on linux.
+	if runtime.GOOS == "darwin" {
+		return int64(rusage.Maxrss)
+	}
+	return int64(rusage.Maxrss) * 1024
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cmd
+
+import "os"
+
+// peakRSS is not provided by process state on windows.
+func peakRSS(state *os.ProcessState) int64 {
+	return 0
+}
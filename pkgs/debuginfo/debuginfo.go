@@ -0,0 +1,144 @@
+package debuginfo
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/celer-pkg/celer/pkgs/cmd"
+)
+
+// ErrNoBuildID is returned when ELF file has no build-id note, it cannot be
+// found in symbol store, so it's left unsplit.
+var ErrNoBuildID = errors.New("no build-id is found, please link it with -Wl,--build-id")
+
+// ntGNUBuildID is the note type of build-id in ".note.gnu.build-id".
+const ntGNUBuildID = 3
+
+// Info is build-id and debug sections of ELF file.
+type Info struct {
+	BuildID   string // Hex build-id, empty if there's no build-id note.
+	HasDebug  bool   // Whether it has DWARF sections.
+	DebugLink string // File name in ".gnu_debuglink", empty if it's not split yet.
+}
+
+// Read reads build-id and debug sections of ELF file.
+func Read(filePath string) (Info, error) {
+	file, err := elf.Open(filePath)
+	if err != nil {
+		return Info{}, err
+	}
+	defer file.Close()
+
+	var info Info
+	for _, section := range file.Sections {
+		switch {
+		case section.Type == elf.SHT_NOTE && info.BuildID == "":
+			data, err := section.Data()
+			if err != nil {
+				return Info{}, fmt.Errorf("failed to read %s of %s -> %w", section.Name, filePath, err)
+			}
+			info.BuildID = buildIDOf(data, file.ByteOrder)
+
+		case slices.Contains([]string{".debug_info", ".zdebug_info"}, section.Name) && section.Type != elf.SHT_NOBITS:
+			info.HasDebug = true
+
+		case section.Name == ".gnu_debuglink":
+			data, err := section.Data()
+			if err != nil {
+				return Info{}, fmt.Errorf("failed to read %s of %s -> %w", section.Name, filePath, err)
+			}
+			if index := slices.Index(data, 0); index > 0 {
+				info.DebugLink = string(data[:index])
+			}
+		}
+	}
+	return info, nil
+}
+
+// buildIDOf finds build-id in notes, every note is namesz, descsz, type, and
+// then name and desc, which are padded to 4 bytes.
+func buildIDOf(data []byte, order binary.ByteOrder) string {
+	for len(data) >= 12 {
+		nameSize := int(order.Uint32(data[0:]))
+		descSize := int(order.Uint32(data[4:]))
+		noteType := order.Uint32(data[8:])
+		nameEnd := 12 + align4(nameSize)
+		descEnd := nameEnd + align4(descSize)
+		if nameSize < 0 || descSize < 0 || descEnd > len(data) {
+			return ""
+		}
+		if noteType == ntGNUBuildID && string(data[12:12+nameSize]) == "GNU\x00" {
+			return hex.EncodeToString(data[nameEnd : nameEnd+descSize])
+		}
+		data = data[descEnd:]
+	}
+	return ""
+}
+
+func align4(size int) int {
+	return (size + 3) &^ 3
+}
+
+// StorePath returns path of debug file in symbol store, which is keyed by
+// build-id, like ".build-id/ab/cdef0123.debug", it's where gdb and lldb
+// look up debug files in debug-file-directory.
+func StorePath(buildID string) string {
+	return filepath.Join(".build-id", buildID[:2], buildID[2:]+".debug")
+}
+
+// Split extracts debug sections of ELF file into symbol store with objcopy,
+// then strips the file and links it to the debug file with ".gnu_debuglink".
+// The returned path is empty when the file has no debug sections to split,
+// it's still stripped unless it's split already.
+func Split(objcopy, filePath, storeDir string) (string, error) {
+	info, err := Read(filePath)
+	if err != nil {
+		return "", err
+	}
+	if !info.HasDebug {
+		if info.DebugLink != "" {
+			return "", nil
+		}
+		if _, err := cmd.NewExecutor("", objcopy, "--strip-all", filePath).ExecuteOutput(); err != nil {
+			return "", fmt.Errorf("failed to strip %s -> %w", filePath, err)
+		}
+		return "", nil
+	}
+	if len(info.BuildID) < 4 {
+		return "", ErrNoBuildID
+	}
+
+	debugPath := filepath.Join(storeDir, StorePath(info.BuildID))
+	if err := os.MkdirAll(filepath.Dir(debugPath), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	// Debug link is the base name of the file written by --only-keep-debug,
+	// so it's extracted as <name>.debug and then moved into store.
+	tempDir, err := os.MkdirTemp(filepath.Dir(debugPath), ".split-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+	tempPath := filepath.Join(tempDir, filepath.Base(filePath)+".debug")
+
+	if _, err := cmd.NewExecutor("", objcopy, "--only-keep-debug", filePath, tempPath).ExecuteOutput(); err != nil {
+		return "", fmt.Errorf("failed to extract debug file of %s -> %w", filePath, err)
+	}
+	if _, err := cmd.NewExecutor("", objcopy, "--strip-all", "--add-gnu-debuglink="+tempPath, filePath).ExecuteOutput(); err != nil {
+		return "", fmt.Errorf("failed to strip %s -> %w", filePath, err)
+	}
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tempPath, debugPath); err != nil {
+		return "", err
+	}
+	return debugPath, nil
+}
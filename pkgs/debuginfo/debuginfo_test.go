@@ -0,0 +1,130 @@
+package debuginfo
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildIDOf(t *testing.T) {
+	note := func(name string, noteType uint32, desc []byte) []byte {
+		data := binary.LittleEndian.AppendUint32(nil, uint32(len(name)))
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(desc)))
+		data = binary.LittleEndian.AppendUint32(data, noteType)
+		data = append(data, make([]byte, align4(len(name)))...)
+		copy(data[12:], name)
+		return append(data, append(desc, make([]byte, align4(len(desc))-len(desc))...)...)
+	}
+
+	data := append(note("GNU\x00", 1, []byte{0, 0, 0, 0}), note("GNU\x00", ntGNUBuildID, []byte{0xab, 0xcd, 0xef, 0x01, 0x23})...)
+	if buildID := buildIDOf(data, binary.LittleEndian); buildID != "abcdef0123" {
+		t.Errorf("build-id should be abcdef0123, but got %q", buildID)
+	}
+	if buildID := buildIDOf(data[:20], binary.LittleEndian); buildID != "" {
+		t.Errorf("truncated note should have no build-id, but got %q", buildID)
+	}
+	if path := filepath.ToSlash(StorePath("abcdef0123")); path != ".build-id/ab/cdef0123.debug" {
+		t.Errorf("store path should be keyed by build-id, but got %s", path)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ELF is only built on linux.")
+	}
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is not found.")
+	}
+	objcopy, err := exec.LookPath("objcopy")
+	if err != nil {
+		t.Skip("objcopy is not found.")
+	}
+
+	workDir := t.TempDir()
+	source := filepath.Join(workDir, "foo.c")
+	if err := os.WriteFile(source, []byte("int foo(int value) { return value * 2; }\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	library := filepath.Join(workDir, "lib", "libfoo.so")
+	if err := os.MkdirAll(filepath.Dir(library), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command(gcc, "-g", "-shared", "-fPIC", "-Wl,--build-id", "-o", library, source).CombinedOutput(); err != nil {
+		t.Fatalf("failed to build libfoo.so: %s", output)
+	}
+
+	before, err := Read(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.BuildID == "" || !before.HasDebug || before.DebugLink != "" {
+		t.Fatalf("libfoo.so should have build-id and debug sections, but got %+v", before)
+	}
+
+	storeDir := filepath.Join(workDir, "debug")
+	debugPath, err := Split(objcopy, library, storeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if debugPath != filepath.Join(storeDir, StorePath(before.BuildID)) {
+		t.Errorf("debug file should be keyed by build-id, but got %s", debugPath)
+	}
+
+	after, err := Read(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.HasDebug || after.BuildID != before.BuildID || after.DebugLink != "libfoo.so.debug" {
+		t.Errorf("libfoo.so should be stripped and linked to libfoo.so.debug, but got %+v", after)
+	}
+	debug, err := Read(debugPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !debug.HasDebug || debug.BuildID != before.BuildID {
+		t.Errorf("debug file should keep debug sections of libfoo.so, but got %+v", debug)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(debugPath))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".split-") {
+			t.Errorf("temp dir %s should be removed", entry.Name())
+		}
+	}
+
+	// Split file is skipped.
+	if debugPath, err := Split(objcopy, library, storeDir); err != nil || debugPath != "" {
+		t.Errorf("split file should be skipped, but got %q, %v", debugPath, err)
+	}
+
+	// File without debug sections is stripped only.
+	noDebug := filepath.Join(workDir, "lib", "libbaz.so")
+	if output, err := exec.Command(gcc, "-shared", "-fPIC", "-o", noDebug, source).CombinedOutput(); err != nil {
+		t.Fatalf("failed to build libbaz.so: %s", output)
+	}
+	if debugPath, err := Split(objcopy, noDebug, storeDir); err != nil || debugPath != "" {
+		t.Errorf("file without debug sections should not be split, but got %q, %v", debugPath, err)
+	}
+	file, err := elf.Open(noDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if file.Section(".symtab") != nil {
+		t.Errorf("file without debug sections should be stripped")
+	}
+
+	// File without build-id is left unsplit.
+	noBuildID := filepath.Join(workDir, "lib", "libbar.so")
+	if output, err := exec.Command(gcc, "-g", "-shared", "-fPIC", "-Wl,--build-id=none", "-o", noBuildID, source).CombinedOutput(); err != nil {
+		t.Fatalf("failed to build libbar.so: %s", output)
+	}
+	if _, err := Split(objcopy, noBuildID, storeDir); err != ErrNoBuildID {
+		t.Errorf("file without build-id should fail with ErrNoBuildID, but got %v", err)
+	}
+}
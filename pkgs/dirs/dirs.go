@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/celer-pkg/celer/pkgs/filelock"
 )
 
 var (
@@ -12,18 +15,24 @@ var (
 	ConfDir          string // "conf"
 	ConfPlatformsDir string // "conf/platforms"
 	ConfProjectsDir  string // "conf/projects"
+	AdvisoriesDir    string // "conf/advisories"
 	PortsDir         string // "ports"
+	RegistriesDir    string // "registries"
 	PackagesDir      string // "packages"
 	InstalledDir     string // "installed"
 	BuildtreesDir    string // "buildtrees"
 	DownloadsDir     string // "downloads"
 	PythonUserBase   string // "PYTHONUSERBASE"
 	TmpDir           string // "tmp"
-	TmpFilesDir      string // "tmp/files"
+	TmpFilesDir      string // "tmp/files/<pid>"
 	TmpDepsDir       string // "tmp/deps"
+	LocksDir         string // "tmp/locks"
 	TestPkgCacheDir  string // "pkg-cache"
 )
 
+// TmpDirEnv overrides the default "tmp" dir when it's set.
+const TmpDirEnv = "CELER_TMP_DIR"
+
 func init() {
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -59,15 +68,28 @@ func Init(workspaceDir string) {
 	ConfDir = filepath.Join(WorkspaceDir, "conf")
 	ConfPlatformsDir = filepath.Join(WorkspaceDir, "conf", "platforms")
 	ConfProjectsDir = filepath.Join(WorkspaceDir, "conf", "projects")
+	AdvisoriesDir = filepath.Join(WorkspaceDir, "conf", "advisories")
 	PortsDir = filepath.Join(WorkspaceDir, "ports")
+	RegistriesDir = filepath.Join(WorkspaceDir, "registries")
 	PackagesDir = filepath.Join(WorkspaceDir, "packages")
 	InstalledDir = filepath.Join(WorkspaceDir, "installed")
 	BuildtreesDir = filepath.Join(WorkspaceDir, "buildtrees")
 	DownloadsDir = filepath.Join(WorkspaceDir, "downloads")
 	PythonUserBase = filepath.Join(WorkspaceDir, ".venv")
 	TmpDir = filepath.Join(WorkspaceDir, "tmp")
-	TmpFilesDir = filepath.Join(WorkspaceDir, "tmp", "files")
-	TmpDepsDir = filepath.Join(WorkspaceDir, "tmp", "deps")
+
+	// Worker processes spawned by the install scheduler get their own tmp dir,
+	// so concurrent builds never clean each other's tmp/deps.
+	if tmpDir := os.Getenv(TmpDirEnv); tmpDir != "" {
+		TmpDir = tmpDir
+	}
+
+	// Every process owns a tmp files dir, so cleaning it never deletes temp files of others.
+	TmpFilesDir = filepath.Join(TmpDir, "files", strconv.Itoa(os.Getpid()))
+	TmpDepsDir = filepath.Join(TmpDir, "deps")
+
+	// Locks are shared by all processes of the workspace, including workers.
+	LocksDir = filepath.Join(WorkspaceDir, "tmp", "locks")
 	TestPkgCacheDir = filepath.Join(WorkspaceDir, "pkg-cache")
 }
 
@@ -101,8 +123,11 @@ func ParentDir(path string, levels int) string {
 	return path
 }
 
-// CleanTmpFilesDir remove tmp dir and create new one.
+// CleanTmpFilesDir remove tmp dir and create new one,
+// tmp files dirs left by dead processes are removed too.
 func CleanTmpFilesDir() error {
+	removeDeadTmpFilesDirs()
+
 	if err := os.RemoveAll(TmpFilesDir); err != nil {
 		return fmt.Errorf("cannot remove tmp dir -> %w", err)
 	}
@@ -114,6 +139,25 @@ func CleanTmpFilesDir() error {
 	return nil
 }
 
+func removeDeadTmpFilesDirs() {
+	filesDir := filepath.Join(TmpDir, "files")
+	if filepath.Dir(TmpFilesDir) != filesDir {
+		return
+	}
+
+	entries, err := os.ReadDir(filesDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() || filelock.ProcessAlive(pid) {
+			continue
+		}
+		os.RemoveAll(filepath.Join(filesDir, entry.Name()))
+	}
+}
+
 func RemoveAllForTest() {
 	os.RemoveAll(filepath.Join(WorkspaceDir, "celer.toml"))
 	os.RemoveAll(TmpDir)
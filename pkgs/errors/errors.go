@@ -16,6 +16,7 @@ var (
 	ErrInvalidBuildType         = errors.New("invalid build type, must be Release, Debug, RelWithDebInfo or MinSizeRel")
 	ErrPkgCacheDirEmpty         = errors.New("pkgcache dir is invalid")
 	ErrPkgCacheDirNotExist      = errors.New("pkgcache dir not exist")
+	ErrPkgCacheUrlEmpty         = errors.New("pkgcache url is invalid")
 	ErrPkgCacheArtifactNotFound = errors.New("artifact cache missing with commit")
 	ErrNotGitDir                = errors.New("refuse to run git commands in non-repo dir")
 	ErrDirNotExist              = errors.New("dir not exist")
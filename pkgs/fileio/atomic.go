@@ -0,0 +1,72 @@
+package fileio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFileAtomic writes data to a temp file beside path and renames it to path,
+// so readers never see a partially written file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return publish(path, "", perm, func(file *os.File) error {
+		_, err := file.Write(data)
+		return err
+	})
+}
+
+// WriteAtomic writes with write to a temp file beside path and renames it to path,
+// so an interrupted write never leaves a truncated file at path.
+func WriteAtomic(path string, perm os.FileMode, write func(file *os.File) error) error {
+	return publish(path, "", perm, write)
+}
+
+// CopyFileAtomic copies src to a temp file in stagingDir and renames it to dest,
+// so readers never see a partially copied file. stagingDir must be on the same
+// file system as dest, temp file is created beside dest when it's empty.
+func CopyFileAtomic(src, dest, stagingDir string, perm os.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	return publish(dest, stagingDir, perm, func(file *os.File) error {
+		_, err := io.Copy(file, srcFile)
+		return err
+	})
+}
+
+func publish(dest, stagingDir string, perm os.FileMode, write func(file *os.File) error) error {
+	// Temp file is hidden and never ends with the ext of dest, so that it's not
+	// taken as a cache entry when it's left by a crashed process.
+	if stagingDir == "" {
+		stagingDir = filepath.Dir(dest)
+	}
+	if err := os.MkdirAll(stagingDir, os.ModePerm); err != nil {
+		return err
+	}
+	tempName := fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(dest), os.Getpid(), time.Now().UnixNano())
+	tempFile, err := os.OpenFile(filepath.Join(stagingDir, tempName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath)
+
+	if err := write(tempFile); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to write %s -> %w", tempPath, err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, dest)
+}
@@ -114,3 +114,50 @@ func assertFileContent(t *testing.T, path, want string) {
 		t.Fatalf("file content = %q, want %q", string(got), want)
 	}
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "toolchain_file.cmake")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		assertFileContent(t, path, content)
+	}
+
+	// No temp file is left.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("dir should only contain the written file, got %d entries", len(entries))
+	}
+}
+
+func TestCopyFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.tar.gz")
+	if err := os.WriteFile(src, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stagingDir := filepath.Join(dir, "tmp")
+	dest := filepath.Join(dir, "cache", "dest.tar.gz")
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyFileAtomic(src, dest, stagingDir, 0644); err != nil {
+		t.Fatal(err)
+	}
+	assertFileContent(t, dest, "archive")
+
+	entries, err := os.ReadDir(stagingDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("staging dir should be empty, got %d entries", len(entries))
+	}
+}
@@ -3,6 +3,7 @@ package fileio
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -60,6 +61,26 @@ func FileSize(httpClient *http.Client, downloadUrl string) (int64, error) {
 	return 0, lastErr
 }
 
+// ReadRemoteText reads text of the given URL, such as a release page or a file listing.
+func ReadRemoteText(host string, port int, remoteUrl string) (string, error) {
+	resp, err := httpClient(host, port).Get(remoteUrl)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to read %s -> %s", remoteUrl, resp.Status)
+	}
+
+	// Pages are expected to be small, limit size to avoid reading huge files by mistake.
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
 func httpClient(host string, port int) *http.Client {
 	if host == "" || port == 0 {
 		return http.DefaultClient
@@ -0,0 +1,263 @@
+package filelock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/color"
+)
+
+var (
+	// StaleAfter is how long a lock can go without refreshing before it's
+	// considered stale, holders refresh their lock every StaleAfter/10.
+	StaleAfter = 5 * time.Minute
+
+	// PollInterval is how often a waiting process retries to acquire the lock.
+	PollInterval = 200 * time.Millisecond
+)
+
+// Lock is an advisory lock backed by a lock file, it works across processes and
+// hosts sharing the same file system, including NFS, since it only relies on
+// exclusive file creation.
+type Lock struct {
+	path   string
+	token  string
+	mutex  *sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// owner is written into lock file to tell who is holding the lock.
+type owner struct {
+	Pid      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Token    string    `json:"token"`
+	Acquired time.Time `json:"acquired"`
+}
+
+func (o owner) String() string {
+	return fmt.Sprintf("pid %d on %s since %s", o.Pid, o.Host, o.Acquired.Format(time.DateTime))
+}
+
+var (
+	mutexesLock sync.Mutex
+	mutexes     = make(map[string]*sync.Mutex)
+)
+
+// processMutex serializes goroutines of current process on the same lock file,
+// since lock files can only tell processes apart.
+func processMutex(path string) *sync.Mutex {
+	mutexesLock.Lock()
+	defer mutexesLock.Unlock()
+
+	mutex, ok := mutexes[path]
+	if !ok {
+		mutex = new(sync.Mutex)
+		mutexes[path] = mutex
+	}
+	return mutex
+}
+
+// Acquire blocks until the lock file at path is created by current process.
+// Locks left by dead processes or not refreshed for StaleAfter are broken.
+func Acquire(path string) (*Lock, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir -> %w", err)
+	}
+
+	mutex := processMutex(absPath)
+	mutex.Lock()
+
+	lock, err := acquire(absPath)
+	if err != nil {
+		mutex.Unlock()
+		return nil, err
+	}
+	lock.mutex = mutex
+	lock.stop = make(chan struct{})
+	lock.done = make(chan struct{})
+	go lock.refresh()
+	return lock, nil
+}
+
+func acquire(path string) (*Lock, error) {
+	hostName, _ := os.Hostname()
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(owner{
+		Pid:      os.Getpid(),
+		Host:     hostName,
+		Token:    token,
+		Acquired: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var notified bool
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.ModePerm)
+		if err == nil {
+			_, writeErr := file.Write(data)
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file %s -> %w", path, errors.Join(writeErr, closeErr))
+			}
+			return &Lock{path: path, token: token}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %s -> %w", path, err)
+		}
+
+		// Break the lock when its holder is gone.
+		holder, stale := inspect(path)
+		if stale {
+			if err := breakLock(path, holder); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !notified {
+			notified = true
+			if holder != nil {
+				color.PrintHint("waiting for lock %s held by %s", path, holder)
+			} else {
+				color.PrintHint("waiting for lock %s", path)
+			}
+		}
+		time.Sleep(PollInterval)
+	}
+}
+
+// Release removes the lock file if it's still owned by current process.
+func (l *Lock) Release() error {
+	if l == nil || l.closed {
+		return nil
+	}
+	l.closed = true
+
+	close(l.stop)
+	<-l.done
+	defer l.mutex.Unlock()
+
+	holder, err := readOwner(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read lock file %s -> %w", l.path, err)
+	}
+
+	// The lock was broken as stale and taken by others.
+	if holder.Token != l.token {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file %s -> %w", l.path, err)
+	}
+	return nil
+}
+
+// refresh touches lock file periodically to tell waiters that holder is alive.
+func (l *Lock) refresh() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(max(StaleAfter/10, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// inspect returns holder of the lock and whether the lock is stale.
+func inspect(path string) (*owner, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Released just now, retry immediately.
+		return nil, false
+	}
+	expired := time.Since(info.ModTime()) > StaleAfter
+
+	holder, err := readOwner(path)
+	if err != nil {
+		// Lock file may be created but not written yet.
+		return nil, expired
+	}
+
+	hostName, _ := os.Hostname()
+	if holder.Host == hostName && holder.Pid != os.Getpid() && !ProcessAlive(holder.Pid) {
+		return holder, true
+	}
+	return holder, expired
+}
+
+// breakLock removes a stale lock, rename is atomic so that only one waiter can break it.
+func breakLock(path string, holder *owner) error {
+	stalePath := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, stalePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil // Broken by others.
+		}
+		return fmt.Errorf("failed to break stale lock %s -> %w", path, err)
+	}
+	defer os.Remove(stalePath)
+
+	// The stale lock may be released and re-acquired by others after inspected,
+	// put it back in that case.
+	current, err := readOwner(stalePath)
+	if holder != nil && err == nil && current.Token != holder.Token {
+		os.Link(stalePath, path)
+		return nil
+	}
+
+	if holder != nil {
+		color.PrintWarning("broke stale lock %s held by %s", path, holder)
+	} else {
+		color.PrintWarning("broke stale lock %s", path)
+	}
+	return nil
+}
+
+func readOwner(path string) (*owner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var holder owner
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, err
+	}
+	return &holder, nil
+}
+
+func newToken() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,155 @@
+package filelock
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeOwner(t *testing.T, path string, holder owner) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquire_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "entry.lock")
+
+	var (
+		waitGroup sync.WaitGroup
+		holders   int
+		maxHolder int
+		mutex     sync.Mutex
+	)
+	for range 8 {
+		waitGroup.Go(func() {
+			lock, err := Acquire(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mutex.Lock()
+			holders++
+			maxHolder = max(maxHolder, holders)
+			mutex.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mutex.Lock()
+			holders--
+			mutex.Unlock()
+
+			if err := lock.Release(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	waitGroup.Wait()
+
+	if maxHolder != 1 {
+		t.Errorf("lock is held by %d goroutines at the same time", maxHolder)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file should be removed after released, got %v", err)
+	}
+}
+
+func TestAcquire_DeadProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entry.lock")
+	hostName, _ := os.Hostname()
+
+	// Pid far beyond pid_max never exists.
+	writeOwner(t, path, owner{Pid: 1 << 30, Host: hostName, Token: "dead", Acquired: time.Now()})
+
+	done := make(chan error, 1)
+	go func() {
+		lock, err := Acquire(path)
+		if err == nil {
+			err = lock.Release()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock of dead process is not broken")
+	}
+}
+
+func TestAcquire_Expired(t *testing.T) {
+	staleAfter := StaleAfter
+	StaleAfter = time.Second
+	defer func() { StaleAfter = staleAfter }()
+
+	// Lock of another host can only be broken when it's not refreshed.
+	path := filepath.Join(t.TempDir(), "entry.lock")
+	writeOwner(t, path, owner{Pid: os.Getpid(), Host: "another-host", Token: "remote", Acquired: time.Now()})
+
+	acquired := make(chan *Lock, 1)
+	go func() {
+		lock, err := Acquire(path)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- lock
+	}()
+
+	select {
+	case lock := <-acquired:
+		lock.Release()
+		t.Fatal("lock is acquired before it expires")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Make it look not refreshed for a while.
+	expired := time.Now().Add(-2 * StaleAfter)
+	if err := os.Chtimes(path, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case lock := <-acquired:
+		if err := lock.Release(); err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expired lock is not broken")
+	}
+}
+
+func TestRelease_Refreshed(t *testing.T) {
+	staleAfter := StaleAfter
+	StaleAfter = 100 * time.Millisecond
+	defer func() { StaleAfter = staleAfter }()
+
+	path := filepath.Join(t.TempDir(), "entry.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Holder keeps lock fresh, so it's never taken as stale.
+	time.Sleep(3 * StaleAfter)
+	if _, stale := inspect(path); stale {
+		t.Error("lock of alive holder should not be stale")
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("release twice should be no-op, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package filelock
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ProcessAlive reports whether process of pid is running on current host.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	// Signal 0 checks existence only, EPERM means it exists but owned by others.
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
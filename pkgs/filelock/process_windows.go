@@ -0,0 +1,29 @@
+//go:build windows
+
+package filelock
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// ProcessAlive reports whether process of pid is running on current host.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means it exists but owned by others.
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(handle)
+
+	var exitCode uint32
+	if err := windows.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return true
+	}
+	return exitCode == 259 // STILL_ACTIVE
+}
@@ -428,6 +428,18 @@ func ApplyPatch(nameVersion, repoDir, patchFile string) error {
 	return nil
 }
 
+// CheckPatch checks if patch can be applied cleanly, repoDir is not required to be a git repo.
+func CheckPatch(nameVersion, repoDir, patchFile string) error {
+	title := fmt.Sprintf("[check patch: %s]", nameVersion)
+	args := []string{"apply", "--check", "--ignore-space-change", "--ignore-whitespace", patchFile}
+	executor := cmd.NewExecutor(title, "git", args...)
+	executor.SetWorkDir(repoDir)
+	if output, err := executor.ExecuteOutput(); err != nil {
+		return fmt.Errorf("patch %s doesn't apply -> %s -> %w", filepath.Base(patchFile), strings.TrimSpace(output), err)
+	}
+	return nil
+}
+
 func pathExists(path string) bool {
 	_, err := os.Stat(path)
 	if err == nil {
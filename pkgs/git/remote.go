@@ -101,3 +101,24 @@ func GetRemoteRefCommit(target, repoUrl, repoRef string) (string, error) {
 	// The repoRef may be a commit.
 	return repoRef, nil
 }
+
+// ListRemoteTags lists tag names of a remote repository quietly, peeled refs are skipped.
+func ListRemoteTags(repoUrl string) ([]string, error) {
+	output, err := cmd.NewExecutor("", "git", "ls-remote", "--tags", repoUrl).
+		WithRetry(retryMaxAttempts).ExecuteOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote tags of %s -> %s -> %w", repoUrl, output, err)
+	}
+
+	var tags []string
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.HasSuffix(fields[1], "^{}") {
+			continue
+		}
+		if tag, ok := strings.CutPrefix(fields[1], "refs/tags/"); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
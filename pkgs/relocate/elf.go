@@ -0,0 +1,327 @@
+package relocate
+
+import (
+	"bytes"
+	"debug/elf"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// dynamicPath is a DT_RPATH or DT_RUNPATH entry, offset is where the string locates in file.
+type dynamicPath struct {
+	kind   string
+	value  string
+	offset int64
+}
+
+// CheckELFAbsPaths scans shared libraries and executables under packageDir for
+// absolute workspace paths in DT_RPATH/DT_RUNPATH and embedded read-only strings.
+func CheckELFAbsPaths(packageDir, workspaceDir string) ([]Finding, error) {
+	workspaceDir = filepath.ToSlash(workspaceDir)
+
+	var findings []Finding
+	err := walkELFFiles(packageDir, func(path string, file *elf.File) error {
+		relPath, _ := filepath.Rel(packageDir, path)
+
+		paths, err := dynamicPaths(file)
+		if err != nil {
+			return err
+		}
+		for _, entry := range paths {
+			if hasWorkspacePath(entry.value, workspaceDir) {
+				findings = append(findings, Finding{File: relPath, Kind: entry.kind, Value: entry.value})
+			}
+		}
+
+		for _, value := range embeddedPaths(file, workspaceDir) {
+			findings = append(findings, Finding{File: relPath, Kind: KindString, Value: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// FixELFRPaths rewrites workspace paths in DT_RPATH/DT_RUNPATH to be relative to $ORIGIN.
+// Prefixes are dirs that have the same layout as package, like package dir, deps dir and
+// installed dir, paths under them are mapped into package, and other workspace paths
+// like build dirs are dropped since they only exist on build machine.
+// Strings are rewritten in place like chrpath, so a new value longer than the old one is
+// left as it is, and the returned files are the ones that are rewritten.
+func FixELFRPaths(packageDir, workspaceDir string, prefixes []string) ([]string, error) {
+	workspaceDir = filepath.ToSlash(workspaceDir)
+
+	var fixed []string
+	err := walkELFFiles(packageDir, func(path string, file *elf.File) error {
+		paths, err := dynamicPaths(file)
+		if err != nil {
+			return err
+		}
+
+		relPath, _ := filepath.Rel(packageDir, path)
+		fileDir := filepath.ToSlash(filepath.Dir(relPath))
+
+		var patches []dynamicPath
+		for _, entry := range paths {
+			if !hasWorkspacePath(entry.value, workspaceDir) {
+				continue
+			}
+			relocated := relocateRPath(entry.value, fileDir, workspaceDir, prefixes)
+			if len(relocated) > len(entry.value) {
+				continue
+			}
+
+			// Pad with NUL to overwrite the whole old value.
+			entry.value = relocated + strings.Repeat("\x00", len(entry.value)-len(relocated))
+			patches = append(patches, entry)
+		}
+		if len(patches) == 0 {
+			return nil
+		}
+
+		if err := writeStrings(path, patches); err != nil {
+			return err
+		}
+		fixed = append(fixed, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fixed, nil
+}
+
+// relocateRPath converts workspace paths in rpath to $ORIGIN relative paths, fileDir is dir
+// of the ELF file relative to package dir, like "lib" or "bin".
+func relocateRPath(rpath, fileDir, workspaceDir string, prefixes []string) string {
+	var entries []string
+	for entry := range strings.SplitSeq(rpath, ":") {
+		if entry == "" {
+			continue
+		}
+		if !hasWorkspacePath(entry, workspaceDir) {
+			if !slices.Contains(entries, entry) {
+				entries = append(entries, entry)
+			}
+			continue
+		}
+
+		for _, prefix := range prefixes {
+			prefix = strings.TrimSuffix(filepath.ToSlash(prefix), "/")
+			if entry != prefix && !strings.HasPrefix(entry, prefix+"/") {
+				continue
+			}
+
+			subPath := strings.TrimPrefix(strings.TrimPrefix(entry, prefix), "/")
+			relPath, err := filepath.Rel(filepath.FromSlash(fileDir), filepath.FromSlash(subPath))
+			if err != nil {
+				break
+			}
+
+			relocated := "$ORIGIN"
+			if relPath != "." {
+				relocated += "/" + filepath.ToSlash(relPath)
+			}
+			if !slices.Contains(entries, relocated) {
+				entries = append(entries, relocated)
+			}
+			break
+		}
+	}
+
+	return strings.Join(entries, ":")
+}
+
+func hasWorkspacePath(value, workspaceDir string) bool {
+	return strings.Contains(value, workspaceDir+"/") || strings.HasSuffix(value, workspaceDir)
+}
+
+// walkELFFiles calls fn with every regular ELF file under root.
+func walkELFFiles(root string, fn func(path string, file *elf.File) error) error {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		isELF, err := isELFFile(path)
+		if err != nil || !isELF {
+			return err
+		}
+
+		file, err := elf.Open(path)
+		if err != nil {
+			// Not a valid ELF file although it has ELF magic, ignore it.
+			return nil
+		}
+		defer file.Close()
+
+		return fn(path, file)
+	})
+}
+
+func isELFFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false, nil
+	}
+	return string(magic) == elf.ELFMAG, nil
+}
+
+// dynamicPaths reads DT_RPATH and DT_RUNPATH with the offsets of their strings,
+// which are not provided by elf.File.DynString.
+func dynamicPaths(file *elf.File) ([]dynamicPath, error) {
+	dynamic := file.Section(".dynamic")
+	dynstr := file.Section(".dynstr")
+	if dynamic == nil || dynstr == nil || dynamic.Type == elf.SHT_NOBITS {
+		return nil, nil
+	}
+
+	data, err := dynamic.Data()
+	if err != nil {
+		return nil, err
+	}
+	strtab, err := dynstr.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	entrySize := 8
+	if file.Class == elf.ELFCLASS64 {
+		entrySize = 16
+	}
+
+	var paths []dynamicPath
+	for index := 0; index+entrySize <= len(data); index += entrySize {
+		var tag elf.DynTag
+		var value uint64
+		if file.Class == elf.ELFCLASS64 {
+			tag = elf.DynTag(file.ByteOrder.Uint64(data[index:]))
+			value = file.ByteOrder.Uint64(data[index+8:])
+		} else {
+			tag = elf.DynTag(file.ByteOrder.Uint32(data[index:]))
+			value = uint64(file.ByteOrder.Uint32(data[index+4:]))
+		}
+
+		var kind string
+		switch tag {
+		case elf.DT_NULL:
+			return paths, nil
+		case elf.DT_RPATH:
+			kind = KindRPath
+		case elf.DT_RUNPATH:
+			kind = KindRunPath
+		default:
+			continue
+		}
+
+		if value >= uint64(len(strtab)) {
+			continue
+		}
+		end := bytes.IndexByte(strtab[value:], 0)
+		if end < 0 {
+			end = len(strtab) - int(value)
+		}
+		paths = append(paths, dynamicPath{
+			kind:   kind,
+			value:  string(strtab[value : int(value)+end]),
+			offset: int64(dynstr.Offset + value),
+		})
+	}
+
+	return paths, nil
+}
+
+// embeddedPaths returns strings that contain workspace path in read-only and initialized data,
+// debug sections are skipped since source paths in them don't affect relocatability.
+func embeddedPaths(file *elf.File, workspaceDir string) []string {
+	needle := []byte(workspaceDir + "/")
+
+	var values []string
+	for _, section := range file.Sections {
+		if section.Type != elf.SHT_PROGBITS ||
+			section.Flags&elf.SHF_ALLOC == 0 ||
+			section.Flags&elf.SHF_EXECINSTR != 0 {
+			continue
+		}
+
+		data, err := section.Data()
+		if err != nil {
+			continue
+		}
+
+		for offset := 0; offset < len(data); {
+			index := bytes.Index(data[offset:], needle)
+			if index < 0 {
+				break
+			}
+
+			// Expand to the whole C string.
+			start := offset + index
+			for start > offset && data[start-1] != 0 {
+				start--
+			}
+			end := bytes.IndexByte(data[offset+index:], 0)
+			if end < 0 {
+				end = len(data)
+			} else {
+				end += offset + index
+			}
+
+			if value := string(data[start:end]); !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+			offset = end + 1
+		}
+	}
+
+	return values
+}
+
+func writeStrings(path string, patches []dynamicPath) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	// Installed files may be read-only, make it writable temporarily.
+	if info.Mode().Perm()&0200 == 0 {
+		if err := os.Chmod(path, info.Mode().Perm()|0200); err != nil {
+			return err
+		}
+		defer os.Chmod(path, info.Mode().Perm())
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, patch := range patches {
+		if _, err := file.WriteAt([]byte(patch.value), patch.offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
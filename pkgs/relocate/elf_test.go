@@ -0,0 +1,112 @@
+package relocate
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestRelocateRPath(t *testing.T) {
+	prefixes := []string{
+		"/workspace/packages/zlib@1.3.1",
+		"/workspace/tmp/deps/x86_64-linux/project_001/release",
+	}
+
+	tests := []struct {
+		rpath    string
+		fileDir  string
+		expected string
+	}{
+		{"/workspace/packages/zlib@1.3.1/lib", "lib", "$ORIGIN"},
+		{"/workspace/packages/zlib@1.3.1/lib", "bin", "$ORIGIN/../lib"},
+		{"/workspace/tmp/deps/x86_64-linux/project_001/release/lib:/usr/local/lib", "lib/plugins", "$ORIGIN/..:/usr/local/lib"},
+		{"/workspace/buildtrees/zlib@1.3.1/build:/workspace/packages/zlib@1.3.1/lib", "lib", "$ORIGIN"},
+		{"/workspace/buildtrees/zlib@1.3.1/build", "lib", ""},
+		{"$ORIGIN:/workspace/packages/zlib@1.3.1/lib", "lib", "$ORIGIN"},
+	}
+
+	for _, test := range tests {
+		actual := relocateRPath(test.rpath, test.fileDir, "/workspace", prefixes)
+		if actual != test.expected {
+			t.Errorf("relocateRPath(%q, %q) = %q, want %q", test.rpath, test.fileDir, actual, test.expected)
+		}
+	}
+}
+
+func TestFixELFRPaths(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ELF is only built on linux.")
+	}
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is not found.")
+	}
+
+	workspaceDir := t.TempDir()
+	packageDir := filepath.Join(workspaceDir, "packages", "foo@1.0.0")
+	depsDir := filepath.Join(workspaceDir, "tmp", "deps", "x86_64-linux", "project_001", "release")
+	libDir := filepath.Join(packageDir, "lib")
+	if err := os.MkdirAll(libDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	source := filepath.Join(workspaceDir, "foo.c")
+	content := `const char *foo_data_dir(void) { return "` + packageDir + `/share/foo"; }`
+	if err := os.WriteFile(source, []byte(content), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	library := filepath.Join(libDir, "libfoo.so")
+	rpath := filepath.Join(workspaceDir, "buildtrees", "foo@1.0.0", "build") + ":" + filepath.Join(depsDir, "lib")
+	output, err := exec.Command(gcc, "-shared", "-fPIC", "-o", library, source,
+		"-Wl,--enable-new-dtags", "-Wl,-rpath,"+rpath).CombinedOutput()
+	if err != nil {
+		t.Skipf("failed to build test library: %s", output)
+	}
+
+	findings, err := CheckELFAbsPaths(packageDir, workspaceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		kinds = append(kinds, finding.Kind)
+	}
+	if !slices.Contains(kinds, KindRunPath) || !slices.Contains(kinds, KindString) {
+		t.Fatalf("both RUNPATH and embedded string should be found, but got %v", findings)
+	}
+
+	fixed, err := FixELFRPaths(packageDir, workspaceDir, []string{packageDir, depsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(fixed, []string{filepath.Join("lib", "libfoo.so")}) {
+		t.Fatalf("lib/libfoo.so should be fixed, but got %v", fixed)
+	}
+
+	file, err := elf.Open(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	runpaths, err := file.DynString(elf.DT_RUNPATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(runpaths, []string{"$ORIGIN"}) {
+		t.Fatalf("RUNPATH should be rewritten to $ORIGIN, but got %v", runpaths)
+	}
+
+	// Embedded string is reported only.
+	findings, err = CheckELFAbsPaths(packageDir, workspaceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(findings, Finding.Blocking) {
+		t.Fatalf("no blocking finding should be left, but got %v", findings)
+	}
+}
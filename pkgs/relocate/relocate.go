@@ -0,0 +1,139 @@
+package relocate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of absolute paths found in package.
+const (
+	KindRPath     = "RPATH"
+	KindRunPath   = "RUNPATH"
+	KindString    = "string"
+	KindPkgConfig = "pkg-config"
+	KindLibtool   = "libtool"
+)
+
+// Finding is an absolute workspace path found in a file of package,
+// which makes the package non-relocatable between machines.
+type Finding struct {
+	File  string // Relative to package dir.
+	Kind  string
+	Value string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", filepath.ToSlash(f.File), f.Kind, f.Value)
+}
+
+// Blocking tells whether the finding breaks the package once it's moved to another machine.
+// Embedded strings are only reported, since they're usually default config paths or
+// source locations baked by compiler, and cannot be rewritten safely.
+func (f Finding) Blocking() bool {
+	return f.Kind != KindString
+}
+
+// CheckPkgConfigAbsPaths scans pkg-config files that are not fully normalized by
+// pc.FixupPkgConfigFile, for example paths of dependencies in Libs and Cflags.
+func CheckPkgConfigAbsPaths(packageDir, workspaceDir string) ([]Finding, error) {
+	pkgConfigDirs := []string{
+		filepath.Join(packageDir, "share", "pkgconfig"),
+		filepath.Join(packageDir, "lib", "pkgconfig"),
+		filepath.Join(packageDir, "lib64", "pkgconfig"),
+	}
+
+	workspaceDir = filepath.ToSlash(workspaceDir) + "/"
+
+	var findings []Finding
+	for _, pkgConfigDir := range pkgConfigDirs {
+		entities, err := os.ReadDir(pkgConfigDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, entity := range entities {
+			if entity.IsDir() || filepath.Ext(entity.Name()) != ".pc" {
+				continue
+			}
+
+			pcFile := filepath.Join(pkgConfigDir, entity.Name())
+			lines, err := linesContaining(pcFile, workspaceDir)
+			if err != nil {
+				return nil, err
+			}
+
+			relPath, _ := filepath.Rel(packageDir, pcFile)
+			for _, line := range lines {
+				findings = append(findings, Finding{File: relPath, Kind: KindPkgConfig, Value: line})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckLibtoolAbsPaths scans libtool archives, whose libdir and dependency_libs
+// are absolute paths of the package and its dependencies when built.
+func CheckLibtoolAbsPaths(packageDir, workspaceDir string) ([]Finding, error) {
+	libDirs := []string{
+		filepath.Join(packageDir, "lib"),
+		filepath.Join(packageDir, "lib64"),
+	}
+
+	workspaceDir = filepath.ToSlash(workspaceDir) + "/"
+
+	var findings []Finding
+	for _, libDir := range libDirs {
+		entities, err := os.ReadDir(libDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, entity := range entities {
+			if entity.IsDir() || filepath.Ext(entity.Name()) != ".la" {
+				continue
+			}
+
+			laFile := filepath.Join(libDir, entity.Name())
+			lines, err := linesContaining(laFile, workspaceDir)
+			if err != nil {
+				return nil, err
+			}
+
+			relPath, _ := filepath.Rel(packageDir, laFile)
+			for _, line := range lines {
+				findings = append(findings, Finding{File: relPath, Kind: KindLibtool, Value: line})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+func linesContaining(file, substr string) ([]string, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || !strings.Contains(line, substr) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,48 @@
+package relocate
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckLibtoolAbsPaths(t *testing.T) {
+	workspaceDir := t.TempDir()
+	packageDir := filepath.Join(workspaceDir, "packages", "foo@1.0.0")
+	depsDir := filepath.ToSlash(filepath.Join(workspaceDir, "tmp", "deps", "x86_64-linux", "release"))
+	libDir := filepath.Join(packageDir, "lib")
+	if err := os.MkdirAll(libDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `# libfoo.la - a libtool library file
+dlname='libfoo.so.1'
+library_names='libfoo.so.1.0.0 libfoo.so.1 libfoo.so'
+dependency_libs=' -L` + depsDir + `/lib ` + depsDir + `/lib/libz.la'
+libdir='` + filepath.ToSlash(libDir) + `'
+`
+	if err := os.WriteFile(filepath.Join(libDir, "libfoo.la"), []byte(content), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(libDir, "libfoo.a"), []byte(depsDir), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := CheckLibtoolAbsPaths(packageDir, workspaceDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Finding{
+		{File: filepath.Join("lib", "libfoo.la"), Kind: KindLibtool, Value: "dependency_libs=' -L" + depsDir + "/lib " + depsDir + "/lib/libz.la'"},
+		{File: filepath.Join("lib", "libfoo.la"), Kind: KindLibtool, Value: "libdir='" + filepath.ToSlash(libDir) + "'"},
+	}
+	if !slices.Equal(findings, expected) {
+		t.Errorf("CheckLibtoolAbsPaths() = %v, want %v", findings, expected)
+	}
+	for _, finding := range findings {
+		if !finding.Blocking() {
+			t.Errorf("%s should be blocking", finding)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a version requirement like ">=1.2.13,<1.4", "~1.3" or "^2.0",
+// comma separated clauses must all be met, and "||" separates alternatives.
+type Constraint struct {
+	raw          string
+	alternatives [][]clause
+}
+
+type clause struct {
+	operator string // One of "=", "!=", ">", ">=", "<", "<=".
+	version  string
+}
+
+// IsConstraint tells whether version of a dependency is a constraint instead of an exact version.
+func IsConstraint(version string) bool {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return false
+	}
+	return strings.ContainsAny(version[:1], "<>=!~^*") ||
+		strings.Contains(version, ",") ||
+		strings.Contains(version, "||")
+}
+
+// ParseConstraint parses constraint, supported clauses are:
+//   - "1.3.1", "=1.3.1": exactly the version
+//   - "!=1.3.0": any version but it
+//   - ">1.2", ">=1.2.13", "<1.4", "<=1.3.1": compared with Compare
+//   - "~1.3": >=1.3,<1.4, and "~1.3.1" is >=1.3.1,<1.4
+//   - "^1.2.3": >=1.2.3,<2, and "^0.2.3" is >=0.2.3,<0.3
+//   - "*": any version
+func ParseConstraint(raw string) (Constraint, error) {
+	constraint := Constraint{raw: strings.TrimSpace(raw)}
+	if constraint.raw == "" {
+		return Constraint{}, fmt.Errorf("empty version constraint")
+	}
+
+	for alternative := range strings.SplitSeq(constraint.raw, "||") {
+		var clauses []clause
+		for item := range strings.SplitSeq(alternative, ",") {
+			parsed, err := parseClause(strings.TrimSpace(item))
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid version constraint %q -> %w", raw, err)
+			}
+			clauses = append(clauses, parsed...)
+		}
+		constraint.alternatives = append(constraint.alternatives, clauses)
+	}
+	return constraint, nil
+}
+
+func parseClause(item string) ([]clause, error) {
+	if item == "*" {
+		return nil, nil
+	}
+
+	for _, operator := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+		if !strings.HasPrefix(item, operator) {
+			continue
+		}
+
+		version := strings.TrimSpace(strings.TrimPrefix(item, operator))
+		if version == "" || strings.ContainsAny(version, "<>=!~^* ") {
+			return nil, fmt.Errorf("invalid clause %q", item)
+		}
+
+		switch operator {
+		case "==":
+			return []clause{{"=", version}}, nil
+		case "~":
+			return bounded(version, tildeUpper)
+		case "^":
+			return bounded(version, caretUpper)
+		default:
+			return []clause{{operator, version}}, nil
+		}
+	}
+
+	if strings.ContainsAny(item, "<>=!~^* ") || item == "" {
+		return nil, fmt.Errorf("invalid clause %q", item)
+	}
+	return []clause{{"=", item}}, nil
+}
+
+// bounded converts "~" and "^" into a lower and an upper bound.
+func bounded(version string, upper func(numbers []int) []int) ([]clause, error) {
+	numbers := leadingNumbers(version)
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("%q has no numeric part to bound", version)
+	}
+
+	var parts []string
+	for _, number := range upper(numbers) {
+		parts = append(parts, strconv.Itoa(number))
+	}
+	return []clause{{">=", version}, {"<", strings.Join(parts, ".")}}, nil
+}
+
+// tildeUpper allows changes after minor when minor is given, otherwise after major.
+func tildeUpper(numbers []int) []int {
+	if len(numbers) == 1 {
+		return []int{numbers[0] + 1}
+	}
+	return []int{numbers[0], numbers[1] + 1}
+}
+
+// caretUpper allows changes that don't modify the left-most non-zero number.
+func caretUpper(numbers []int) []int {
+	for index, number := range numbers {
+		if number != 0 || index == len(numbers)-1 {
+			upper := append([]int{}, numbers[:index]...)
+			return append(upper, number+1)
+		}
+	}
+	return []int{numbers[0] + 1}
+}
+
+// leadingNumbers returns the leading dot separated numbers of version, like [1 2 13] of "v1.2.13-rc1".
+func leadingNumbers(version string) []int {
+	var numbers []int
+	for part := range strings.SplitSeq(normalize(version), ".") {
+		digits := part
+		if index := strings.IndexFunc(part, func(char rune) bool { return char < '0' || char > '9' }); index >= 0 {
+			digits = part[:index]
+		}
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, number)
+		if len(digits) != len(part) {
+			break
+		}
+	}
+	return numbers
+}
+
+// Check tells whether version meets the constraint.
+func (c Constraint) Check(version string) bool {
+	for _, clauses := range c.alternatives {
+		matched := true
+		for _, clause := range clauses {
+			if !clause.check(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c clause) check(version string) bool {
+	result := Compare(version, c.version)
+	switch c.operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	default:
+		return false
+	}
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,138 @@
+package version
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+
+	"github.com/celer-pkg/celer/pkgs/expr"
+)
+
+// Compare compares two versions of third-party libraries, which don't always follow
+// semver, such as "1.1.1w", "2.16.03" and "v1.3.1". Versions are split into runs of
+// digits and non-digits, digit runs are compared numerically and others lexically.
+// A leading "v" is ignored. It returns -1, 0 or 1 like strings.Compare.
+func Compare(a, b string) int {
+	partsA := split(normalize(a))
+	partsB := split(normalize(b))
+
+	for index := range max(len(partsA), len(partsB)) {
+		var partA, partB string
+		if index < len(partsA) {
+			partA = partsA[index]
+		}
+		if index < len(partsB) {
+			partB = partsB[index]
+		}
+		if result := comparePart(partA, partB); result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func normalize(version string) string {
+	version = strings.TrimSpace(version)
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') && unicode.IsDigit(rune(version[1])) {
+		version = version[1:]
+	}
+	return version
+}
+
+// split splits version into runs of digits and letters, separators like "." and "-"
+// only end a run.
+func split(version string) []string {
+	var (
+		parts   []string
+		current strings.Builder
+		digit   bool
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, char := range version {
+		switch {
+		case unicode.IsDigit(char):
+			if !digit {
+				flush()
+			}
+			digit = true
+			current.WriteRune(char)
+		case unicode.IsLetter(char):
+			if digit {
+				flush()
+			}
+			digit = false
+			current.WriteRune(unicode.ToLower(char))
+		default:
+			flush()
+			digit = false
+		}
+	}
+	flush()
+	return parts
+}
+
+// comparePart compares a single part, a missing part is greater than pre-release
+// tags but less than others, so that "1.0-rc1" < "1.0" < "1.0.1" < "1.0.1a".
+func comparePart(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	numberA, numberB := isNumber(a), isNumber(b)
+	switch {
+	case numberA && numberB:
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			return cmp.Compare(len(a), len(b))
+		}
+		return strings.Compare(a, b)
+	case a == "":
+		return -rank(b)
+	case b == "":
+		return rank(a)
+	case preRelease[a] != preRelease[b]:
+		return expr.If(preRelease[a], -1, 1)
+	case numberA:
+		return 1
+	case numberB:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+var preRelease = map[string]bool{
+	"alpha":    true,
+	"beta":     true,
+	"pre":      true,
+	"preview":  true,
+	"rc":       true,
+	"dev":      true,
+	"snapshot": true,
+}
+
+// rank tells whether a part makes version greater than the one without it.
+func rank(part string) int {
+	if preRelease[part] {
+		return -1
+	}
+	return 1
+}
+
+func isNumber(part string) bool {
+	if part == "" {
+		return false
+	}
+	for _, char := range part {
+		if !unicode.IsDigit(char) {
+			return false
+		}
+	}
+	return true
+}
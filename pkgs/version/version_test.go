@@ -0,0 +1,88 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.3.1", "1.3.1", 0},
+		{"v1.3.1", "1.3.1", 0},
+		{"1.2.13", "1.3", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.16.03", "2.16.3", 0},
+		{"1.0", "1.0.1", -1},
+		{"1.1.1w", "1.1.1", 1},
+		{"1.1.1w", "1.1.1k", 1},
+		{"3.0.0", "1.1.1w", 1},
+		{"1.0-rc1", "1.0", -1},
+		{"1.0-rc2", "1.0-rc1", 1},
+		{"1.0-beta", "1.0-rc1", -1},
+		{"1.0.0", "1.0.0-alpha", 1},
+	}
+	for _, test := range tests {
+		if got := Compare(test.a, test.b); got != test.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := Compare(test.b, test.a); got != -test.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matched    []string
+		unmatched  []string
+	}{
+		{">=1.2.13,<1.4", []string{"1.2.13", "1.3", "1.3.1"}, []string{"1.2.12", "1.4", "1.4.0"}},
+		{"~1.3", []string{"1.3", "1.3.1", "1.3.9"}, []string{"1.2.13", "1.4.0"}},
+		{"~1.3.1", []string{"1.3.1", "1.3.2"}, []string{"1.3.0", "1.4"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"1.3.1", []string{"1.3.1", "v1.3.1"}, []string{"1.3.0"}},
+		{"!=1.3.0", []string{"1.3.1"}, []string{"1.3.0"}},
+		{"<1.2 || >=1.3", []string{"1.1", "1.3.1"}, []string{"1.2.13"}},
+		{"*", []string{"0.1", "stable"}, nil},
+	}
+	for _, test := range tests {
+		constraint, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, version := range test.matched {
+			if !constraint.Check(version) {
+				t.Errorf("%q should match %s", test.constraint, version)
+			}
+		}
+		for _, version := range test.unmatched {
+			if constraint.Check(version) {
+				t.Errorf("%q should not match %s", test.constraint, version)
+			}
+		}
+	}
+
+	for _, invalid := range []string{"", ">=", "~stable", ">=1.2,", "1.2 1.3"} {
+		if _, err := ParseConstraint(invalid); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestIsConstraint(t *testing.T) {
+	for version, want := range map[string]bool{
+		"1.3.1":         false,
+		"stable":        false,
+		">=1.2.13,<1.4": true,
+		"~1.3":          true,
+		"^1.2":          true,
+		"*":             true,
+		"=1.3.1":        true,
+	} {
+		if got := IsConstraint(version); got != want {
+			t.Errorf("IsConstraint(%q) = %t, want %t", version, got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/refs"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Scope              string           `json:"scope,omitempty"`
+	Hashes             []cdxHash        `json:"hashes,omitempty"`
+	Licenses           []cdxLicense     `json:"licenses,omitempty"`
+	CPE                string           `json:"cpe,omitempty"`
+	Purl               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cdxLicense struct {
+	License    *cdxLicenseID `json:"license,omitempty"`
+	Expression string        `json:"expression,omitempty"`
+}
+
+type cdxLicenseID struct {
+	ID string `json:"id"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX encodes sbom as CycloneDX 1.5 json.
+func (d Document) CycloneDX() ([]byte, error) {
+	const projectRef = "project"
+
+	document := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + d.Serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: d.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{{
+				Type:    "application",
+				Name:    "celer",
+				Version: d.CelerVersion,
+			}}},
+			Component: cdxComponent{
+				Type:   "application",
+				BOMRef: projectRef,
+				Name:   d.Project,
+			},
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: projectRef, DependsOn: nonNil(d.Roots)}},
+	}
+
+	for _, component := range d.Components {
+		cdxComponent := cdxComponent{
+			Type:    "library",
+			BOMRef:  component.NameVersion(),
+			Name:    component.Name,
+			Version: component.Version,
+			Scope:   "required",
+			CPE:     component.CPE,
+			Purl:    purl(component),
+		}
+		if component.DevOnly {
+			cdxComponent.Scope = "excluded"
+		}
+		if component.SHA256 != "" {
+			cdxComponent.Hashes = []cdxHash{{Algorithm: "SHA-256", Content: component.SHA256}}
+		}
+		if component.License != "" {
+			cdxComponent.Licenses = []cdxLicense{cdxLicenseOf(component.License)}
+		}
+		if component.Url != "" {
+			cdxComponent.ExternalReferences = []cdxExternalRef{{
+				Type: expr.If(component.Source == refs.SourceGit, "vcs", "distribution"),
+				Url:  component.Url,
+			}}
+		}
+		for _, property := range []cdxProperty{
+			{Name: "celer:source", Value: string(component.Source)},
+			{Name: "celer:ref", Value: component.Ref},
+			{Name: "celer:commit", Value: component.Commit},
+			{Name: "celer:dev_dependencies", Value: strings.Join(component.DevDependencies, ",")},
+		} {
+			if property.Value != "" {
+				cdxComponent.Properties = append(cdxComponent.Properties, property)
+			}
+		}
+		document.Components = append(document.Components, cdxComponent)
+
+		// CycloneDX has no dev scope on edges, dev dependencies are kept in properties
+		// and marked as excluded when they're never shipped.
+		document.Dependencies = append(document.Dependencies, cdxDependency{
+			Ref:       component.NameVersion(),
+			DependsOn: nonNil(component.Dependencies),
+		})
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// cdxLicenseOf takes license as SPDX id when it's a single word, otherwise an expression.
+func cdxLicenseOf(license string) cdxLicense {
+	if strings.ContainsAny(license, " ()") {
+		return cdxLicense{Expression: license}
+	}
+	return cdxLicense{License: &cdxLicenseID{ID: license}}
+}
+
+// purl returns package url of component, ports are not from any package registry,
+// so they're generic packages located by their sources unless declared in port.toml.
+func purl(component Component) string {
+	if component.Purl != "" {
+		return component.Purl
+	}
+
+	purl := fmt.Sprintf("pkg:generic/%s@%s", url.PathEscape(component.Name), url.PathEscape(component.Version))
+	switch {
+	case component.Url == "":
+		return purl
+	case component.Source == refs.SourceGit && component.Commit != "":
+		return purl + "?vcs_url=" + url.QueryEscape(fmt.Sprintf("git+%s@%s", component.Url, component.Commit))
+	case component.Source == refs.SourceGit:
+		return purl + "?vcs_url=" + url.QueryEscape("git+"+component.Url)
+	default:
+		return purl + "?download_url=" + url.QueryEscape(component.Url)
+	}
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,210 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/configs"
+	"github.com/celer-pkg/celer/context"
+	"github.com/celer-pkg/celer/lockfile"
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/refs"
+)
+
+const (
+	SpecSPDX      = "spdx"
+	SpecCycloneDX = "cyclonedx"
+)
+
+// Specs are all supported sbom specs.
+var Specs = []string{SpecSPDX, SpecCycloneDX}
+
+// Document is the spec independent sbom of a project.
+type Document struct {
+	Project      string
+	CelerVersion string
+	Created      time.Time
+	Serial       string      // UUID shared by all specs of the same sbom.
+	Roots        []string    // Ports required by project directly.
+	Components   []Component // Sorted by name@version.
+}
+
+// Component is a port in the dependency tree of project.
+type Component struct {
+	Name            string
+	Version         string
+	Source          refs.SourceType
+	Url             string
+	Ref             string
+	Commit          string // Resolved commit of git source.
+	SHA256          string // SHA-256 of archive source.
+	License         string // SPDX license expression declared in port.toml.
+	CPE             string // CPE declared in port.toml.
+	Purl            string // Package url declared in port.toml.
+	Dependencies    []string
+	DevDependencies []string
+	DevOnly         bool // Not reachable from project through runtime dependencies.
+}
+
+// NameVersion returns name@version of component.
+func (c Component) NameVersion() string {
+	return c.Name + "@" + c.Version
+}
+
+// Generate generates sbom of project with ports and their resolved refs.
+func Generate(ctx context.Context, ports []configs.Port, resolvedRefs []refs.ResolvedRef) (*Document, error) {
+	// Source of ports is resolved the same way as lock file.
+	lock, err := lockfile.Generate(ctx, ports, resolvedRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	document := Document{
+		Project:      ctx.Project().GetName(),
+		CelerVersion: ctx.Version(),
+		Created:      time.Now().UTC(),
+		Serial:       serial,
+		Roots:        []string{},
+	}
+	for _, nameVersion := range ctx.Project().GetPorts() {
+		nameVersion, _ := configs.SplitFeatures(nameVersion)
+		document.Roots = append(document.Roots, nameVersion)
+	}
+	for _, port := range ports {
+		locked, _ := lock.Find(port.NameVersion())
+		component := Component{
+			Name:    port.Name,
+			Version: port.Version,
+			Source:  refs.SourceType(locked.Source),
+			Url:     locked.Url,
+			Ref:     locked.Ref,
+			Commit:  locked.Commit,
+			SHA256:  locked.SHA256,
+			License: port.Package.License,
+			CPE:     port.Package.CPE,
+			Purl:    port.Package.Purl,
+		}
+		if port.MatchedConfig != nil {
+			component.Dependencies = port.MatchedConfig.Dependencies
+			component.DevDependencies = port.MatchedConfig.DevDependencies
+		}
+		document.Components = append(document.Components, component)
+	}
+	document.markDevOnly()
+
+	return &document, nil
+}
+
+// markDevOnly marks components that are only required to build others, they're
+// never shipped with the project.
+func (d *Document) markDevOnly() {
+	components := make(map[string]*Component, len(d.Components))
+	for index := range d.Components {
+		components[d.Components[index].NameVersion()] = &d.Components[index]
+	}
+
+	runtime := make(map[string]bool)
+	var visit func(nameVersion string)
+	visit = func(nameVersion string) {
+		if runtime[nameVersion] {
+			return
+		}
+		runtime[nameVersion] = true
+		if component, ok := components[nameVersion]; ok {
+			for _, dependency := range component.Dependencies {
+				visit(dependency)
+			}
+		}
+	}
+	for _, root := range d.Roots {
+		visit(root)
+	}
+
+	for index := range d.Components {
+		d.Components[index].DevOnly = !runtime[d.Components[index].NameVersion()]
+	}
+}
+
+// Write writes sbom of the spec into dir and returns the file path.
+func (d Document) Write(dir, spec string) (string, error) {
+	if err := ValidateSpec(spec); err != nil {
+		return "", err
+	}
+
+	fileName := d.Project + expr.If(spec == SpecSPDX, ".spdx.json", ".cdx.json")
+	filePath := filepath.Join(dir, fileName)
+	if err := d.WriteFile(filePath, spec); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// WriteFile writes sbom of the spec into file path.
+func (d Document) WriteFile(filePath, spec string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch spec {
+	case SpecSPDX:
+		data, err = d.SPDX()
+	case SpecCycloneDX:
+		data, err = d.CycloneDX()
+	default:
+		return fmt.Errorf("unsupported sbom spec %q, it should be one of %v", spec, Specs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate %s sbom -> %w", spec, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create sbom dir -> %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write %s -> %w", filePath, err)
+	}
+	return nil
+}
+
+// SpecOfFile tells spec of sbom by its file name, like "app.spdx.json" or "app.cdx.json",
+// false is returned when it cannot be told.
+func SpecOfFile(filePath string) (string, bool) {
+	name := strings.ToLower(filepath.Base(filePath))
+	switch {
+	case strings.HasSuffix(name, ".spdx.json"), strings.HasSuffix(name, ".spdx"):
+		return SpecSPDX, true
+	case strings.HasSuffix(name, ".cdx.json"), strings.HasSuffix(name, ".cyclonedx.json"), strings.HasSuffix(name, ".cdx"):
+		return SpecCycloneDX, true
+	default:
+		return "", false
+	}
+}
+
+// ValidateSpec checks whether spec is supported.
+func ValidateSpec(spec string) error {
+	if !slices.Contains(Specs, spec) {
+		return fmt.Errorf("unsupported sbom spec %q, it should be one of %v", spec, Specs)
+	}
+	return nil
+}
+
+// newUUID generates a random UUID in version 4.
+func newUUID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	bytes[6] = bytes[6]&0x0f | 0x40
+	bytes[8] = bytes[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:16]), nil
+}
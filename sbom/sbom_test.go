@@ -0,0 +1,184 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testDocument() Document {
+	document := Document{
+		Project:      "test_project",
+		CelerVersion: "v1.0.0",
+		Created:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Serial:       "0b6f2e43-3f3c-4bd5-9c62-4a5a0cf1e5a1",
+		Roots:        []string{"libpng@1.6.43"},
+		Components: []Component{
+			{
+				Name:            "libpng",
+				Version:         "1.6.43",
+				Source:          "git",
+				Url:             "https://github.com/glennrp/libpng.git",
+				Ref:             "v1.6.43",
+				Commit:          "ed217e3e601d8e462f7fd1e04bed43ac42212429",
+				License:         "Libpng",
+				Dependencies:    []string{"zlib@1.3.1"},
+				DevDependencies: []string{"nasm@2.16.03"},
+			},
+			{
+				Name:    "nasm",
+				Version: "2.16.03",
+				Source:  "archive",
+				Url:     "https://www.nasm.us/pub/nasm/releasebuilds/2.16.03/nasm-2.16.03.tar.gz",
+				Ref:     "2.16.03",
+				SHA256:  "5bc940dd8a4245686976a8f7e96ba9340a0915f2d5b88356874890e207bdb581",
+				License: "BSD-2-Clause",
+			},
+			{
+				Name:    "zlib",
+				Version: "1.3.1",
+				Source:  "git",
+				Url:     "https://github.com/madler/zlib.git",
+				Ref:     "v1.3.1",
+				Commit:  "51b7f2abdade71cd9bb0e7a373ef2610ec6f9daf",
+				License: "Zlib OR MIT",
+			},
+		},
+	}
+	document.markDevOnly()
+	return document
+}
+
+func TestDocument_MarkDevOnly(t *testing.T) {
+	document := testDocument()
+	for _, component := range document.Components {
+		wantDevOnly := component.Name == "nasm"
+		if component.DevOnly != wantDevOnly {
+			t.Errorf("%s dev only = %t, want %t", component.NameVersion(), component.DevOnly, wantDevOnly)
+		}
+	}
+}
+
+func TestSpecOfFile(t *testing.T) {
+	for filePath, expected := range map[string]string{
+		"dist/app.spdx.json":      SpecSPDX,
+		"dist/APP.SPDX.JSON":      SpecSPDX,
+		"dist/app.cdx.json":       SpecCycloneDX,
+		"dist/app.cyclonedx.json": SpecCycloneDX,
+		"dist/app.json":           "",
+		"dist/app":                "",
+	} {
+		spec, ok := SpecOfFile(filePath)
+		if spec != expected || ok != (expected != "") {
+			t.Errorf("SpecOfFile(%s) = %q, %t, want %q", filePath, spec, ok, expected)
+		}
+	}
+}
+
+func TestDocument_WriteFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "dist", "sbom.json")
+	if err := testDocument().WriteFile(filePath, SpecCycloneDX); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bom map[string]any
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom["bomFormat"] != "CycloneDX" {
+		t.Errorf("bomFormat = %v, want CycloneDX", bom["bomFormat"])
+	}
+}
+
+func TestDocument_SPDX(t *testing.T) {
+	data, err := testDocument().SPDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document spdxDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.SPDXVersion != "SPDX-2.3" || len(document.Packages) != 4 {
+		t.Fatalf("unexpected spdx document: %s", data)
+	}
+
+	packages := make(map[string]spdxPackage)
+	for _, spdxPackage := range document.Packages {
+		packages[spdxPackage.SPDXID] = spdxPackage
+	}
+	libpng := packages["SPDXRef-Package-libpng-1.6.43"]
+	if libpng.DownloadLocation != "git+https://github.com/glennrp/libpng.git@ed217e3e601d8e462f7fd1e04bed43ac42212429" {
+		t.Errorf("libpng download location = %s", libpng.DownloadLocation)
+	}
+	if libpng.LicenseDeclared != "Libpng" {
+		t.Errorf("libpng license = %s", libpng.LicenseDeclared)
+	}
+	nasm := packages["SPDXRef-Package-nasm-2.16.03"]
+	if len(nasm.Checksums) != 1 || nasm.Checksums[0].Algorithm != "SHA256" {
+		t.Errorf("nasm checksums = %+v", nasm.Checksums)
+	}
+
+	wants := []spdxRelationship{
+		{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Project"},
+		{"SPDXRef-Project", "DEPENDS_ON", "SPDXRef-Package-libpng-1.6.43"},
+		{"SPDXRef-Package-libpng-1.6.43", "DEPENDS_ON", "SPDXRef-Package-zlib-1.3.1"},
+		{"SPDXRef-Package-nasm-2.16.03", "DEV_DEPENDENCY_OF", "SPDXRef-Package-libpng-1.6.43"},
+	}
+	for _, want := range wants {
+		if !slices.Contains(document.Relationships, want) {
+			t.Errorf("relationship %+v is missing", want)
+		}
+	}
+}
+
+func TestDocument_CycloneDX(t *testing.T) {
+	data, err := testDocument().CycloneDX()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document cdxDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.SpecVersion != "1.5" || document.SerialNumber != "urn:uuid:0b6f2e43-3f3c-4bd5-9c62-4a5a0cf1e5a1" {
+		t.Fatalf("unexpected cyclonedx document: %s", data)
+	}
+
+	components := make(map[string]cdxComponent)
+	for _, component := range document.Components {
+		components[component.BOMRef] = component
+	}
+	if scope := components["nasm@2.16.03"].Scope; scope != "excluded" {
+		t.Errorf("nasm scope = %s, want excluded", scope)
+	}
+	if scope := components["zlib@1.3.1"].Scope; scope != "required" {
+		t.Errorf("zlib scope = %s, want required", scope)
+	}
+	if licenses := components["zlib@1.3.1"].Licenses; len(licenses) != 1 || licenses[0].Expression != "Zlib OR MIT" {
+		t.Errorf("zlib licenses = %+v", licenses)
+	}
+	if purl := components["nasm@2.16.03"].Purl; purl != "pkg:generic/nasm@2.16.03?download_url=https%3A%2F%2Fwww.nasm.us%2Fpub%2Fnasm%2Freleasebuilds%2F2.16.03%2Fnasm-2.16.03.tar.gz" {
+		t.Errorf("nasm purl = %s", purl)
+	}
+
+	dependencies := make(map[string][]string)
+	for _, dependency := range document.Dependencies {
+		dependencies[dependency.Ref] = dependency.DependsOn
+	}
+	if !slices.Equal(dependencies["project"], []string{"libpng@1.6.43"}) {
+		t.Errorf("project depends on %v", dependencies["project"])
+	}
+	if !slices.Equal(dependencies["libpng@1.6.43"], []string{"zlib@1.3.1"}) {
+		t.Errorf("libpng depends on %v", dependencies["libpng@1.6.43"])
+	}
+}
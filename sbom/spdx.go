@@ -0,0 +1,173 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/celer-pkg/celer/pkgs/expr"
+	"github.com/celer-pkg/celer/pkgs/refs"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string         `json:"SPDXID"`
+	Name             string         `json:"name"`
+	VersionInfo      string         `json:"versionInfo,omitempty"`
+	DownloadLocation string         `json:"downloadLocation"`
+	FilesAnalyzed    bool           `json:"filesAnalyzed"`
+	Checksums        []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	LicenseDeclared  string         `json:"licenseDeclared"`
+	CopyrightText    string         `json:"copyrightText"`
+	SourceInfo       string         `json:"sourceInfo,omitempty"`
+	ExternalRefs     []spdxExtRef   `json:"externalRefs,omitempty"`
+	PrimaryPurpose   string         `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExtRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX encodes sbom as SPDX 2.3 json.
+func (d Document) SPDX() ([]byte, error) {
+	const projectID = "SPDXRef-Project"
+
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Project,
+		DocumentNamespace: fmt.Sprintf("https://celer-pkg.github.io/spdx/%s-%s", d.Project, d.Serial),
+		CreationInfo: spdxCreationInfo{
+			Created:  d.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: celer-" + d.CelerVersion},
+		},
+		Packages: []spdxPackage{{
+			SPDXID:           projectID,
+			Name:             d.Project,
+			DownloadLocation: spdxNoAssertion,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			PrimaryPurpose:   "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: projectID,
+		}},
+	}
+
+	for _, component := range d.Components {
+		spdxPackage := spdxPackage{
+			SPDXID:           spdxID(component.NameVersion()),
+			Name:             component.Name,
+			VersionInfo:      component.Version,
+			DownloadLocation: spdxDownloadLocation(component),
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			PrimaryPurpose:   "LIBRARY",
+		}
+		if component.License != "" {
+			spdxPackage.LicenseDeclared = component.License
+		}
+		if component.SHA256 != "" {
+			spdxPackage.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: component.SHA256}}
+		}
+		if component.Commit != "" {
+			spdxPackage.SourceInfo = fmt.Sprintf("ref %s resolved to commit %s", component.Ref, component.Commit)
+		}
+		if component.CPE != "" {
+			spdxPackage.ExternalRefs = append(spdxPackage.ExternalRefs, spdxExtRef{
+				ReferenceCategory: "SECURITY",
+				ReferenceType:     expr.If(strings.HasPrefix(component.CPE, "cpe:2.3:"), "cpe23Type", "cpe22Type"),
+				ReferenceLocator:  component.CPE,
+			})
+		}
+		spdxPackage.ExternalRefs = append(spdxPackage.ExternalRefs, spdxExtRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl(component),
+		})
+		document.Packages = append(document.Packages, spdxPackage)
+
+		for _, dependency := range component.Dependencies {
+			document.Relationships = append(document.Relationships, spdxRelationship{
+				SPDXElementID:      spdxID(component.NameVersion()),
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: spdxID(dependency),
+			})
+		}
+		for _, dependency := range component.DevDependencies {
+			document.Relationships = append(document.Relationships, spdxRelationship{
+				SPDXElementID:      spdxID(dependency),
+				RelationshipType:   "DEV_DEPENDENCY_OF",
+				RelatedSPDXElement: spdxID(component.NameVersion()),
+			})
+		}
+	}
+
+	for _, root := range d.Roots {
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SPDXElementID:      projectID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: spdxID(root),
+		})
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+var spdxInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxID converts name@version to SPDX identifier, which only allows letters, numbers, "." and "-".
+func spdxID(nameVersion string) string {
+	return "SPDXRef-Package-" + spdxInvalidChars.ReplaceAllString(nameVersion, "-")
+}
+
+func spdxDownloadLocation(component Component) string {
+	switch {
+	case component.Url == "":
+		return spdxNoAssertion
+	case component.Source == refs.SourceGit && component.Commit != "":
+		return fmt.Sprintf("git+%s@%s", component.Url, component.Commit)
+	case component.Source == refs.SourceGit:
+		return "git+" + component.Url
+	default:
+		return component.Url
+	}
+}
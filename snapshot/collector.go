@@ -39,8 +39,9 @@ func (c *Collector) CollectUsedPorts(ctx context.Context) (map[string]*configs.P
 }
 
 func (c *Collector) collectRecursive(nameVersion string) error {
-	// Skip if already collected.
-	if _, exists := c.collected[nameVersion]; exists {
+	// Skip if already collected, selected features are not part of the key.
+	key, _ := configs.SplitFeatures(nameVersion)
+	if _, exists := c.collected[key]; exists {
 		return nil
 	}
 
@@ -49,7 +50,7 @@ func (c *Collector) collectRecursive(nameVersion string) error {
 	if err := port.Init(c.ctx, nameVersion); err != nil {
 		return fmt.Errorf("failed to init port %s -> %w", nameVersion, err)
 	}
-	c.collected[nameVersion] = &port
+	c.collected[key] = &port
 
 	// Recursively collect dependencies.
 	for _, config := range port.BuildConfigs {
@@ -150,7 +150,7 @@ func (e *Exporter) exportPorts() error {
 
 		// Copy all supplementary files from the source port directory
 		// (patches, cmake_config.toml, CMakeLists.txt, etc.).
-		publicPortDir := dirs.GetPortDir(parts[0], parts[1])
+		publicPortDir, _ := e.celer.Registries().FindPortDir(parts[0], parts[1])
 		if fileio.PathExists(publicPortDir) {
 			entries, err := os.ReadDir(publicPortDir)
 			if err != nil {